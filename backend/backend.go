@@ -0,0 +1,43 @@
+// Package backend opens the persistence backend selected by the
+// environment, shared by the server and the command line tools.
+package backend
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sakura/diskstore"
+	"sakura/game"
+	"sakura/mango"
+	"sakura/server"
+)
+
+// OpenFromEnv picks the persistence backend from STORE_BACKEND. The disk
+// backend keeps its files below STORE_PATH, data by default.
+func OpenFromEnv() (game.Store, server.Registry, error) {
+	switch os.Getenv("STORE_BACKEND") {
+	case "", "mongo":
+		return &mango.MangoStore{}, &mango.MangoRegistry{}, nil
+	case "disk":
+		dir := os.Getenv("STORE_PATH")
+		if dir == "" {
+			dir = "data"
+		}
+
+		store, err := diskstore.NewDiskStore(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Println("Using disk store at", dir)
+		return store, &diskstore.DiskRegistry{Store: store}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown STORE_BACKEND %q", os.Getenv("STORE_BACKEND"))
+	}
+}
+
+// OpenStoreFromEnv opens the game store of OpenFromEnv, for tools that
+// need no registry
+func OpenStoreFromEnv() (game.Store, error) {
+	store, _, err := OpenFromEnv()
+	return store, err
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// agent runs a program in a seat of a game. Every message of the server is
+// written to its stdin as one line of JSON, and every line it prints is
+// sent back as a command.
+func main() {
+	server := flag.String("server", "http://localhost:8080", "address of the backend")
+	id := flag.String("id", "", "game to join")
+	token := flag.String("token", "", "token of the player, a new anonymous one if empty")
+	username := flag.String("username", "", "name of the anonymous player")
+	level := flag.String("level", "normal", "built-in AI that plays while the program stalls")
+	flag.Parse()
+
+	if *id == "" || flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: agent -id <game> [-server <url>] [-token <jwt> | -username <name>] [-level <level>] <program> [args...]")
+		os.Exit(2)
+	}
+
+	if *token == "" {
+		t, err := anonymousToken(*server, *username)
+		if err != nil {
+			log.Fatal(err)
+		}
+		*token = t
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(*server, "/") + "/agent")
+	if err != nil {
+		log.Fatal(err)
+	}
+	u.Scheme = strings.Replace(u.Scheme, "http", "ws", 1)
+	u.RawQuery = url.Values{"id": {*id}, "token": {*token}, "level": {*level}}.Encode()
+
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	cmd := exec.Command(flag.Arg(0), flag.Args()[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		log.Fatal(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		log.Fatal(err)
+	}
+
+	go func() {
+		defer stdin.Close()
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				log.Println("connection closed:", err)
+				return
+			}
+			if _, err := stdin.Write(append(message, '\n')); err != nil {
+				log.Println("program closed its input:", err)
+				return
+			}
+		}
+	}()
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, line); err != nil {
+			log.Println("error sending command:", err)
+			break
+		}
+	}
+
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	if err := cmd.Wait(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// anonymousToken asks the backend for a token of a new anonymous player
+func anonymousToken(server string, username string) (string, error) {
+	body := []byte("{}")
+	if username != "" {
+		body, _ = json.Marshal(map[string]string{"username": username})
+	}
+
+	res, err := http.Post(strings.TrimSuffix(server, "/")+"/anon", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var data map[string]string
+	if err := json.NewDecoder(res.Body).Decode(&data); err != nil {
+		return "", err
+	}
+	if data["error"] != "" {
+		return "", fmt.Errorf("anonymous token: %s", data["error"])
+	}
+	return data["token"], nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sakura/backend"
+	"sakura/game"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	fmt.Fprintln(os.Stderr, "  archive export -id <game> [-o file]")
+	fmt.Fprintln(os.Stderr, "  archive import -f <file> [-id game]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	store, err := backend.OpenStoreFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch os.Args[1] {
+	case "export":
+		fs := flag.NewFlagSet("export", flag.ExitOnError)
+		id := fs.String("id", "", "game to export")
+		out := fs.String("o", "", "archive file, defaults to <game>.sakura")
+		fs.Parse(os.Args[2:])
+		if *id == "" {
+			usage()
+		}
+		if *out == "" {
+			*out = *id + ".sakura"
+		}
+
+		a, err := game.ExportGame(store, *id)
+		if err != nil {
+			log.Fatal(err)
+		}
+		b, err := game.EncodeArchive(a)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*out, b, 0644); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Exported %s (%d journal entries) to %s", *id, len(a.Journal), *out)
+
+	case "import":
+		fs := flag.NewFlagSet("import", flag.ExitOnError)
+		file := fs.String("f", "", "archive file to import")
+		id := fs.String("id", "", "game id to import as, defaults to the archived id")
+		fs.Parse(os.Args[2:])
+		if *file == "" {
+			usage()
+		}
+
+		b, err := os.ReadFile(*file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		a, err := game.DecodeArchive(b)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *id == "" {
+			*id = a.ID
+		}
+		if err := game.ImportGame(store, a, *id, ""); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Imported %s as %s", *file, *id)
+
+	default:
+		usage()
+	}
+}
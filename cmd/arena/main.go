@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sakura/entities"
+	"sakura/game"
+	"sakura/maps"
+	"strings"
+	"time"
+)
+
+// Game of the arena, with the level playing each seat
+type match struct {
+	index  int
+	seed   int64
+	mode   entities.GameMode
+	defn   *entities.MapDefinition
+	levels []string
+}
+
+// What came out of a match. A game that panicked, got stuck or ran out of
+// turns has failure set.
+type result struct {
+	match
+	winner     int
+	turns      int
+	production [][9]int
+	dice       entities.DiceStats
+	failure    string
+	elapsed    time.Duration
+}
+
+// arena plays games of bots against each other as fast as the engine goes
+// and prints how each strategy and seat did.
+func main() {
+	games := flag.Int("n", 100, "number of games")
+	workers := flag.Int("workers", runtime.NumCPU(), "games played at the same time")
+	bots := flag.String("bots", "hard,normal,easy", "level of each seat, comma separated")
+	mapNames := flag.String("maps", maps.BaseMapName, "maps played in turn, comma separated")
+	mapFiles := flag.String("mapfiles", "", "JSON map definitions played in turn, comma separated")
+	modeName := flag.String("mode", "auto", "auto, base, ck, seafarers or seafarers-ck")
+	vp := flag.Int("vp", 0, "victory points to win, the default of the map when 0")
+	seed := flag.Int64("seed", 1, "seed of the first game, the next games count up")
+	maxTurns := flag.Int("turns", 500, "turns after which a game counts as stuck")
+	timeout := flag.Duration("timeout", 5*time.Minute, "time after which a game counts as deadlocked")
+	rotate := flag.Bool("rotate", true, "move the levels one seat on every game")
+	verbose := flag.Bool("v", false, "keep the log of the games")
+	flag.Parse()
+
+	levels := strings.Split(*bots, ",")
+	for _, level := range levels {
+		if !entities.IsBotLevel(level) {
+			log.Fatalf("unknown bot level %q", level)
+		}
+	}
+	if len(levels) < 2 {
+		log.Fatal("at least two seats are needed")
+	}
+
+	defns, err := loadMaps(*mapNames, *mapFiles)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *workers < 1 {
+		*workers = 1
+	}
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+	logger := log.New(os.Stderr, "", 0)
+
+	matches := make(chan match)
+	results := make(chan result)
+	for i := 0; i < *workers; i++ {
+		go func() {
+			for m := range matches {
+				results <- play(m, *vp, *maxTurns, *timeout)
+			}
+		}()
+	}
+
+	go func() {
+		for i := 0; i < *games; i++ {
+			defn := defns[i%len(defns)]
+			mode, err := gameMode(*modeName, defn)
+			if err != nil {
+				logger.Fatal(err)
+			}
+
+			seats := make([]string, len(levels))
+			for s := range seats {
+				shift := 0
+				if *rotate {
+					shift = i
+				}
+				seats[s] = levels[(s+shift)%len(levels)]
+			}
+			matches <- match{index: i, seed: *seed + int64(i), mode: mode, defn: defn, levels: seats}
+		}
+		close(matches)
+	}()
+
+	start := time.Now()
+	r := newReport(levels, len(levels))
+	for i := 0; i < *games; i++ {
+		res := <-results
+		r.add(&res)
+		if res.failure != "" {
+			logger.Printf("game %d (seed %d, %s): %s", res.index, res.seed, res.defn.Name, res.failure)
+		}
+	}
+
+	r.print(os.Stdout, time.Since(start))
+	if r.failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// loadMaps finds the official maps by name and reads the ones in files
+func loadMaps(names string, files string) ([]*entities.MapDefinition, error) {
+	defns := make([]*entities.MapDefinition, 0)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		defn := maps.GetMapByName(name)
+		if defn == nil {
+			return nil, fmt.Errorf("unknown map %q", name)
+		}
+		defn.Name = name
+		defns = append(defns, defn)
+	}
+
+	for _, file := range strings.Split(files, ",") {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var defn entities.MapDefinition
+		if err := json.Unmarshal(b, &defn); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if defn.Name == "" {
+			defn.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+		defns = append(defns, &defn)
+	}
+
+	if len(defns) == 0 {
+		return nil, errors.New("no maps given")
+	}
+	return defns, nil
+}
+
+// gameMode picks the mode of a game, with auto playing Seafarers maps
+// with Seafarers and the others with the base game
+func gameMode(name string, defn *entities.MapDefinition) (entities.GameMode, error) {
+	seafarers := defn.Scenario != nil && defn.Scenario.Expansion == "Seafarers"
+	switch name {
+	case "auto":
+		if seafarers {
+			return entities.Seafarers, nil
+		}
+		return entities.Base, nil
+	case "base":
+		return entities.Base, nil
+	case "ck":
+		return entities.CitiesAndKnights, nil
+	case "seafarers":
+		return entities.Seafarers, nil
+	case "seafarers-ck":
+		return entities.SeafarersCitiesAndKnights, nil
+	}
+	return 0, fmt.Errorf("unknown mode %q", name)
+}
+
+// play runs a match to the end. Games that do not finish in time are
+// reported as deadlocked and stop at their next step.
+func play(m match, vp int, maxTurns int, timeout time.Duration) result {
+	return guard(m, timeout, func(ctx context.Context, res *result) error {
+		return run(ctx, res, vp, maxTurns)
+	})
+}
+
+// guard runs a game, reporting a panic or a game still running after the
+// timeout as its failure. The context of the game is cancelled when the
+// time is up.
+func guard(m match, timeout time.Duration, game func(context.Context, *result) error) result {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan result, 1)
+	start := time.Now()
+	go func() {
+		res := result{match: m, winner: -1}
+		defer func() {
+			if r := recover(); r != nil {
+				res.failure = fmt.Sprintf("panic: %v\n%s", r, debug.Stack())
+			}
+			res.elapsed = time.Since(start)
+			done <- res
+		}()
+		if err := game(ctx, &res); err != nil {
+			res.failure = err.Error()
+		}
+	}()
+
+	select {
+	case res := <-done:
+		return res
+	case <-ctx.Done():
+		return result{match: m, winner: -1, failure: fmt.Sprintf("deadlock: no end after %s", timeout), elapsed: timeout}
+	}
+}
+
+func run(ctx context.Context, res *result, vp int, maxTurns int) error {
+	// Games may change their map, so each plays on its own copy
+	var defn *entities.MapDefinition
+	b, err := json.Marshal(res.defn)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &defn); err != nil {
+		return err
+	}
+
+	// Scenarios bring their own target, which the game picks when unset
+	if vp == 0 && (defn.Scenario == nil || defn.Scenario.VictoryPoints == 0) {
+		vp = 10
+		if res.mode.HasCitiesAndKnights() {
+			vp = 13
+		}
+	}
+	settings := entities.GameSettings{
+		Mode:          res.mode,
+		MapName:       defn.Name,
+		MapDefn:       defn,
+		DiscardLimit:  7,
+		VictoryPoints: vp,
+		MaxPlayers:    len(res.levels),
+		Speed:         entities.NormalSpeed,
+	}
+
+	usernames := make([]string, len(res.levels))
+	for i, level := range res.levels {
+		usernames[i] = fmt.Sprintf("%s-%d*", level, i)
+	}
+	e, err := game.NewEngine(settings, res.seed, usernames)
+	if err != nil {
+		return err
+	}
+	res.production = make([][9]int, len(res.levels))
+	for i, level := range res.levels {
+		level := level
+		if _, err := e.Do(uint16(i), func(g *game.Game, p *entities.Player) error {
+			g.SetBotLevel(p, level)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	events, err := e.Start()
+	if err != nil {
+		return err
+	}
+	res.collect(events)
+
+	g := e.Game()
+	idle := 0
+	for !g.GameOver {
+		if ctx.Err() != nil {
+			return fmt.Errorf("deadlock: cancelled at turn %d", g.TurnCount)
+		}
+		if g.TurnCount > maxTurns {
+			points := make([]string, len(g.Players))
+			for i, p := range g.Players {
+				points[i] = fmt.Sprint(g.GetVictoryPoints(p, false))
+			}
+			return fmt.Errorf("stuck: no winner after %d turns, points %s", maxTurns, strings.Join(points, "/"))
+		}
+		if len(e.Decisions()) > 0 {
+			return fmt.Errorf("deadlock: bots asked to decide at turn %d", g.TurnCount)
+		}
+
+		acted, events, err := e.Step()
+		if err != nil {
+			return fmt.Errorf("turn %d: %w", g.TurnCount, err)
+		}
+		res.collect(events)
+
+		if acted {
+			idle = 0
+		} else if idle++; idle > 1000 {
+			return fmt.Errorf("deadlock: nobody acts at turn %d", g.TurnCount)
+		}
+	}
+
+	res.turns = g.TurnCount
+	res.dice = *g.DiceStats
+	return nil
+}
+
+// collect counts the cards the dice gave and finds the winner. Messages
+// for everyone are only counted once, from the first seat.
+func (res *result) collect(events []game.Event) {
+	for _, ev := range events {
+		if ev.Player != 0 {
+			continue
+		}
+		switch data := ev.Message.Data.(type) {
+		case *entities.DieRollState:
+			for _, gain := range data.GainInfo {
+				if gain.GainerOrder >= 0 && gain.GainerOrder < len(res.production) {
+					res.production[gain.GainerOrder][gain.CardType] += gain.Quantity
+				}
+			}
+		case entities.GameOverMessage:
+			res.winner = int(data.Winner)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGuardClassifiesFailures(t *testing.T) {
+	m := match{index: 3, seed: 7}
+
+	res := guard(m, time.Second, func(ctx context.Context, res *result) error {
+		res.turns = 42
+		return nil
+	})
+	if res.failure != "" || res.turns != 42 || res.index != 3 {
+		t.Fatalf("expected a finished game, got %+v", res)
+	}
+
+	res = guard(m, time.Second, func(ctx context.Context, res *result) error {
+		panic("boom")
+	})
+	if !strings.HasPrefix(res.failure, "panic: boom") {
+		t.Fatalf("expected a panic, got %q", res.failure)
+	}
+
+	// A game past its time is reported right away and stops once it
+	// sees the cancelled context
+	stopped := make(chan struct{})
+	res = guard(m, 10*time.Millisecond, func(ctx context.Context, res *result) error {
+		defer close(stopped)
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return ctx.Err()
+	})
+	if !strings.HasPrefix(res.failure, "deadlock: ") || res.seed != 7 {
+		t.Fatalf("expected a deadlock, got %q", res.failure)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("expected the timed out game to stop")
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sakura/entities"
+	"text/tabwriter"
+	"time"
+)
+
+// Totals of a level over its games
+type levelStats struct {
+	games      int
+	wins       int
+	production [9]int
+}
+
+type report struct {
+	levels []string
+	stats  map[string]*levelStats
+
+	seatGames []int
+	seatWins  []int
+
+	finished int
+	failed   int
+	turns    int
+	elapsed  time.Duration
+	dice     entities.DiceStats
+}
+
+func newReport(levels []string, seats int) *report {
+	r := &report{
+		levels:    make([]string, 0, len(levels)),
+		stats:     make(map[string]*levelStats),
+		seatGames: make([]int, seats),
+		seatWins:  make([]int, seats),
+	}
+	for _, level := range levels {
+		if r.stats[level] == nil {
+			r.stats[level] = &levelStats{}
+			r.levels = append(r.levels, level)
+		}
+	}
+	return r
+}
+
+func (r *report) add(res *result) {
+	r.elapsed += res.elapsed
+	if res.failure != "" {
+		r.failed++
+		return
+	}
+
+	r.finished++
+	r.turns += res.turns
+	for i := range r.dice.Rolls {
+		r.dice.Rolls[i] += res.dice.Rolls[i]
+	}
+	for i := range r.dice.EventRolls {
+		r.dice.EventRolls[i] += res.dice.EventRolls[i]
+	}
+
+	for seat, level := range res.levels {
+		s := r.stats[level]
+		s.games++
+		r.seatGames[seat]++
+		if res.winner == seat {
+			s.wins++
+			r.seatWins[seat]++
+		}
+		for t, n := range res.production[seat] {
+			s.production[t] += n
+		}
+	}
+}
+
+func percent(n int, of int) float64 {
+	if of == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(of)
+}
+
+func average(n int, of int) float64 {
+	if of == 0 {
+		return 0
+	}
+	return float64(n) / float64(of)
+}
+
+func (r *report) print(out io.Writer, wall time.Duration) {
+	games := r.finished + r.failed
+	fmt.Fprintf(out, "%d games in %s, %d finished, %d failed\n", games, wall.Round(time.Millisecond), r.finished, r.failed)
+	fmt.Fprintf(out, "average length %.1f turns, %s per game\n\n", average(r.turns, r.finished), (r.elapsed / time.Duration(max(games, 1))).Round(time.Millisecond))
+
+	// Commodities only show up in games with Cities & Knights
+	last := entities.CardTypeOre
+	for _, s := range r.stats {
+		for t := entities.CardTypePaper; t <= entities.CardTypeCoin; t++ {
+			if s.production[t] > 0 {
+				last = entities.CardTypeCoin
+			}
+		}
+	}
+	names := []string{"", "wood", "brick", "wool", "wheat", "ore", "paper", "cloth", "coin"}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(w, "level\tgames\twins\twin %\t")
+	for t := entities.CardTypeWood; t <= last; t++ {
+		fmt.Fprintf(w, "%s\t", names[t])
+	}
+	fmt.Fprintln(w)
+	for _, level := range r.levels {
+		s := r.stats[level]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f\t", level, s.games, s.wins, percent(s.wins, s.games))
+		for t := entities.CardTypeWood; t <= last; t++ {
+			fmt.Fprintf(w, "%.1f\t", average(s.production[t], s.games))
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "seat\tgames\twins\twin %\t")
+	for seat := range r.seatGames {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%.1f\t\n", seat+1, r.seatGames[seat], r.seatWins[seat], percent(r.seatWins[seat], r.seatGames[seat]))
+	}
+	fmt.Fprintln(w)
+
+	rolls := 0
+	for _, n := range r.dice.Rolls {
+		rolls += n
+	}
+	fmt.Fprintln(w, "roll\tcount\t%\texpected %\t")
+	for i := 1; i < len(r.dice.Rolls); i++ {
+		ways := 6 - abs(7-(i+1))
+		fmt.Fprintf(w, "%d\t%d\t%.2f\t%.2f\t\n", i+1, r.dice.Rolls[i], percent(r.dice.Rolls[i], rolls), 100*float64(ways)/36)
+	}
+
+	events := 0
+	for _, n := range r.dice.EventRolls {
+		events += n
+	}
+	if events > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "event die\tcount\t%\t")
+		for i, n := range r.dice.EventRolls {
+			fmt.Fprintf(w, "%d\t%d\t%.2f\t\n", i+1, n, percent(n, events))
+		}
+	}
+	w.Flush()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
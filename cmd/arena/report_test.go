@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportAddAndPrint(t *testing.T) {
+	r := newReport([]string{"hard", "easy", "hard"}, 3)
+	if len(r.levels) != 2 {
+		t.Fatalf("expected each level once, got %v", r.levels)
+	}
+
+	won := &result{
+		match:      match{levels: []string{"hard", "easy", "hard"}},
+		winner:     2,
+		turns:      40,
+		production: [][9]int{{1: 4}, {1: 2}, {1: 6}},
+		elapsed:    time.Second,
+	}
+	won.dice.Rolls[6] = 5
+	r.add(won)
+	r.add(&result{match: match{levels: won.levels}, winner: -1, failure: "deadlock: no end after 1s", elapsed: time.Second})
+
+	if r.finished != 1 || r.failed != 1 || r.turns != 40 {
+		t.Fatalf("expected one finished and one failed game, got %d and %d", r.finished, r.failed)
+	}
+	if s := r.stats["hard"]; s.games != 2 || s.wins != 1 || s.production[1] != 10 {
+		t.Fatalf("unexpected totals for hard: %+v", s)
+	}
+	if r.seatGames[2] != 1 || r.seatWins[2] != 1 || r.seatWins[0] != 0 {
+		t.Fatalf("unexpected seat totals %v %v", r.seatGames, r.seatWins)
+	}
+
+	var out bytes.Buffer
+	r.print(&out, 2*time.Second)
+	lines := strings.Split(out.String(), "\n")
+	if lines[0] != "2 games in 2s, 1 finished, 1 failed" {
+		t.Fatalf("unexpected summary %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "average length 40.0 turns, 1s per game") {
+		t.Fatalf("unexpected averages %q", lines[1])
+	}
+
+	rows := make(map[string][]string)
+	for _, line := range lines {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			rows[fields[0]] = fields
+		}
+	}
+	if got := strings.Join(rows["hard"], " "); got != "hard 2 1 50.0 5.0 0.0 0.0 0.0 0.0" {
+		t.Fatalf("unexpected row %q", got)
+	}
+	if got := strings.Join(rows["7"], " "); got != "7 5 100.00 16.67" {
+		t.Fatalf("unexpected roll row %q", got)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sakura/backend"
+	"sakura/game"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	id := flag.String("id", "", "game to read from the store")
+	file := flag.String("f", "", "archive file to read instead of the store")
+	quiet := flag.Bool("q", false, "only print violations")
+	flag.Parse()
+
+	var store game.Store
+	switch {
+	case *file != "":
+		b, err := os.ReadFile(*file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		a, err := game.DecodeArchive(b)
+		if err != nil {
+			log.Fatal(err)
+		}
+		store = a.Store()
+		*id = a.ID
+	case *id != "":
+		s, err := backend.OpenStoreFromEnv()
+		if err != nil {
+			log.Fatal(err)
+		}
+		store = s
+	default:
+		fmt.Fprintln(os.Stderr, "usage: journal (-id <game> | -f <archive>) [-q]")
+		os.Exit(2)
+	}
+
+	report, err := game.VerifyJournal(store, *id)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !*quiet {
+		for i := range report.Entries {
+			fmt.Println(game.FormatJournalEntry(&report.Entries[i]))
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%s: format %d, %d entries, %d played\n", *id, report.Version, len(report.Entries), report.Played)
+	for _, v := range report.Violations {
+		fmt.Println(v)
+	}
+	if len(report.Violations) > 0 {
+		os.Exit(1)
+	}
+}
@@ -1,11 +1,18 @@
 package main
 
 import (
+	"log"
+	"sakura/backend"
 	"sakura/server"
 
 	_ "github.com/joho/godotenv/autoload"
 )
 
 func main() {
-	server.RunServer()
+	store, registry, err := backend.OpenFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server.RunServer(store, registry)
 }
@@ -13,7 +13,7 @@ import (
 var done map[string]bool
 
 func isPrimitive(ftn string) string {
-	if ftn == "int" || ftn == "int16" || ftn == "int32" || ftn == "int64" || ftn == "uint" || ftn == "uint16" || ftn == "uint32" || ftn == "uint64" {
+	if ftn == "int" || ftn == "int8" || ftn == "uint8" || ftn == "int16" || ftn == "int32" || ftn == "int64" || ftn == "uint" || ftn == "uint16" || ftn == "uint32" || ftn == "uint64" {
 		return "number"
 	} else if ftn == "bool" {
 		return "boolean"
@@ -64,7 +64,8 @@ func gen(inp reflect.Type) string {
 
 	if inp.Kind() != reflect.Struct {
 		prim := isPrimitiveRec(inp)
-		if prim != "" && !done[inp.Name()] {
+		// Go strings are already TypeScript strings
+		if prim != "" && prim != inp.Name() && !done[inp.Name()] {
 			log.Println(prim, inp.Kind().String())
 			done[inp.Name()] = true
 			res := fmt.Sprintf("export type %s = %s;\n", inp.Name(), prim)
@@ -0,0 +1,194 @@
+package diskstore
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	ServersDir    = "servers"
+	GamesDir      = "games"
+	JournalsDir   = "journals"
+	GameStatesDir = "game_states"
+	SnapshotsDir  = "snapshots"
+	UsersDir      = "users"
+	MapsDir       = "maps"
+)
+
+var errNotFound = errors.New("not found")
+
+// fileKey turns an arbitrary id into a single safe path component
+func fileKey(key string) string {
+	escaped := url.PathEscape(key)
+	escaped = strings.ReplaceAll(escaped, ".", "%2E")
+	if escaped == "" {
+		escaped = "%00"
+	}
+	return escaped
+}
+
+func (ds *DiskStore) path(dir, key, ext string) string {
+	return filepath.Join(ds.root, dir, fileKey(key)+ext)
+}
+
+// writeFileAtomic writes the file to a temporary sibling and renames
+// it into place so that readers never observe a partial record.
+func writeFileAtomic(name string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+func readJson(name string, v interface{}) error {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return errNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func writeJson(name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(name, data)
+}
+
+// listJson returns the file names of every record in a directory in
+// lexical order
+func listJson(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		names = append(names, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// appendFrames appends length-prefixed records to the file and syncs it.
+// A failed write is cut off again, so the next append starts on a frame.
+func appendFrames(name string, frames [][]byte) error {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	size := 0
+	for _, frame := range frames {
+		size += 4 + len(frame)
+	}
+
+	buf := make([]byte, 0, size)
+	for _, frame := range frames {
+		var header [4]byte
+		binary.BigEndian.PutUint32(header[:], uint32(len(frame)))
+		buf = append(buf, header[:]...)
+		buf = append(buf, frame...)
+	}
+
+	if _, err := f.Write(buf); err != nil {
+		f.Truncate(info.Size())
+		return err
+	}
+	return f.Sync()
+}
+
+// splitFrames returns every complete length-prefixed record in data and
+// the length they take up. Anything after them is a torn record.
+func splitFrames(data []byte) ([][]byte, int) {
+	frames := make([][]byte, 0)
+	end := 0
+	for len(data)-end >= 4 {
+		size := int(binary.BigEndian.Uint32(data[end : end+4]))
+		if len(data)-end-4 < size {
+			break
+		}
+		frames = append(frames, data[end+4:end+4+size])
+		end += 4 + size
+	}
+	return frames, end
+}
+
+// readFrames reads every complete length-prefixed record from the file.
+// A torn record at the tail (e.g. from a crash mid-append) is ignored.
+func readFrames(name string) ([][]byte, error) {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return [][]byte{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	frames, _ := splitFrames(data)
+	return frames, nil
+}
+
+// repairFrames cuts a torn record off the tail of the file, which would
+// otherwise swallow the records appended after it
+func repairFrames(name string) error {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, end := splitFrames(data); end < len(data) {
+		return os.Truncate(name, int64(end))
+	}
+	return nil
+}
+
+func removeIfExists(name string) error {
+	err := os.Remove(name)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
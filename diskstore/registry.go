@@ -0,0 +1,152 @@
+package diskstore
+
+import (
+	"errors"
+	"path/filepath"
+	"time"
+)
+
+type (
+	// DiskRegistry keeps users and server heartbeats in a DiskStore
+	DiskRegistry struct {
+		Store *DiskStore
+	}
+
+	serverRecord struct {
+		URL       string    `json:"url"`
+		CreatedAt time.Time `json:"createdAt"`
+		UpdatedAt time.Time `json:"updatedAt"`
+	}
+)
+
+func (dr *DiskRegistry) Init() {}
+
+func (dr *DiskRegistry) Register(url, region string) error {
+	return dr.Heartbeat(url)
+}
+
+func (dr *DiskRegistry) Heartbeat(url string) error {
+	ds := dr.Store
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	name := ds.path(ServersDir, url, ".json")
+
+	var s serverRecord
+	err := readJson(name, &s)
+	if errors.Is(err, errNotFound) {
+		s = serverRecord{URL: url, CreatedAt: time.Now()}
+	} else if err != nil {
+		return err
+	}
+
+	s.UpdatedAt = time.Now()
+	return writeJson(name, &s)
+}
+
+func (dr *DiskRegistry) CreateUser(id, username string) error {
+	return dr.CreateUserWithEmail(id, username, username+"@sakura.app")
+}
+
+func (dr *DiskRegistry) CreateUserWithEmail(id, username, email string) error {
+	ds := dr.Store
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if _, err := ds.readUser(id); err == nil {
+		return errors.New("user id already exists")
+	}
+
+	users, err := dr.readUsers()
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		if u.Username == username {
+			return errors.New("username already exists")
+		}
+	}
+
+	now := time.Now()
+	return ds.writeUser(&userRecord{
+		ID:        id,
+		Username:  username,
+		Email:     email,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Games:     []string{},
+	})
+}
+
+func (dr *DiskRegistry) readUsers() ([]*userRecord, error) {
+	files, err := listJson(filepath.Join(dr.Store.root, UsersDir))
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*userRecord, 0, len(files))
+	for _, file := range files {
+		var u userRecord
+		if err := readJson(file, &u); err != nil {
+			continue
+		}
+		users = append(users, &u)
+	}
+	return users, nil
+}
+
+func (dr *DiskRegistry) CheckIfUserExists(id string) (bool, error) {
+	ds := dr.Store
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	_, err := ds.readUser(id)
+	if errors.Is(err, errNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (dr *DiskRegistry) CheckIfUserEmailExists(email string) (map[string]interface{}, error) {
+	ds := dr.Store
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	users, err := dr.readUsers()
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		if u.Email == email {
+			return u.toMap(), nil
+		}
+	}
+	return nil, errors.New("email not found")
+}
+
+func (dr *DiskRegistry) UpdateUsername(id, username string) error {
+	return dr.Store.updateUser(id, func(u *userRecord) {
+		u.Username = username
+	})
+}
+
+func (dr *DiskRegistry) UpdateEmail(id, email string) error {
+	return dr.Store.updateUser(id, func(u *userRecord) {
+		u.Email = email
+	})
+}
+
+func (dr *DiskRegistry) CountUsers() (int64, error) {
+	ds := dr.Store
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	files, err := listJson(filepath.Join(ds.root, UsersDir))
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(files)), nil
+}
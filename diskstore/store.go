@@ -0,0 +1,633 @@
+package diskstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sakura/entities"
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// DiskStore keeps every record as a file below a single root
+	// directory, so a server can run without any external database.
+	DiskStore struct {
+		root  string
+		mutex sync.Mutex
+
+		// Journals checked for a torn tail since the store was opened
+		repaired map[string]bool
+	}
+
+	gameRecord struct {
+		ID                   string     `json:"id"`
+		CreatedAt            time.Time  `json:"createdAt"`
+		UpdatedAt            time.Time  `json:"updatedAt"`
+		Stage                int        `json:"stage"`
+		Players              int        `json:"players"`
+		ActivePlayers        int        `json:"active_players"`
+		ConnectedPlayers     int        `json:"connected_players"`
+		ConnectedHumans      int        `json:"connected_humans"`
+		LastHumanSeenAt      *time.Time `json:"last_human_seen_at"`
+		LastPresenceUpdateAt time.Time  `json:"last_presence_update_at"`
+		Server               string     `json:"server"`
+		Host                 string     `json:"host"`
+		HostId               string     `json:"host_id"`
+		ParticipantIds       []string   `json:"participant_ids"`
+		Private              bool       `json:"private"`
+		Settings             []byte     `json:"settings"`
+		HasState             bool       `json:"has_state"`
+	}
+
+	userRecord struct {
+		ID        string    `json:"id"`
+		Username  string    `json:"username"`
+		Email     string    `json:"email"`
+		CreatedAt time.Time `json:"createdAt"`
+		UpdatedAt time.Time `json:"updatedAt"`
+		Games     []string  `json:"games"`
+		Started   int32     `json:"started"`
+		Finished  int32     `json:"finished"`
+	}
+
+	mapRecord struct {
+		Name     string                  `json:"name"`
+		Creator  string                  `json:"creator"`
+		Official bool                    `json:"official"`
+		Map      *entities.MapDefinition `json:"map"`
+	}
+)
+
+// NewDiskStore opens (creating if needed) a store rooted at dir
+func NewDiskStore(dir string) (*DiskStore, error) {
+	ds := &DiskStore{root: dir, repaired: make(map[string]bool)}
+	for _, sub := range []string{ServersDir, GamesDir, JournalsDir, GameStatesDir, SnapshotsDir, UsersDir, MapsDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return nil, err
+		}
+	}
+	return ds, nil
+}
+
+// Root returns the directory the store was opened at
+func (ds *DiskStore) Root() string {
+	return ds.root
+}
+
+func (ds *DiskStore) Init(id string) error {
+	return ds.CreateGameIfNotExists(id)
+}
+
+func (ds *DiskStore) readGame(id string) (*gameRecord, error) {
+	var g gameRecord
+	if err := readJson(ds.path(GamesDir, id, ".json"), &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (ds *DiskStore) writeGame(g *gameRecord) error {
+	return writeJson(ds.path(GamesDir, g.ID, ".json"), g)
+}
+
+// updateGame applies fn to the stored record of game id and writes it back.
+// If fn returns false the record is left untouched.
+func (ds *DiskStore) updateGame(id string, fn func(g *gameRecord) bool) error {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	g, err := ds.readGame(id)
+	if errors.Is(err, errNotFound) {
+		// Matches the upsert-less update semantics of the mongo store
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !fn(g) {
+		return nil
+	}
+	return ds.writeGame(g)
+}
+
+func (ds *DiskStore) CreateGameIfNotExists(id string) error {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if _, err := ds.readGame(id); err == nil {
+		return nil
+	} else if !errors.Is(err, errNotFound) {
+		return err
+	}
+
+	now := time.Now()
+	return ds.writeGame(&gameRecord{
+		ID:                   id,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+		LastPresenceUpdateAt: now,
+		Server:               os.Getenv("SERVER_URL"),
+		ParticipantIds:       []string{},
+	})
+}
+
+func (ds *DiskStore) TerminateGame(id string) error {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if err := removeIfExists(ds.path(GamesDir, id, ".json")); err != nil {
+		return err
+	}
+	if err := removeIfExists(ds.path(SnapshotsDir, id, ".bin")); err != nil {
+		return err
+	}
+	return removeIfExists(ds.path(JournalsDir, id, ".log"))
+}
+
+func (ds *DiskStore) WriteGameServer(id string) error {
+	return ds.updateGame(id, func(g *gameRecord) bool {
+		g.Server = os.Getenv("SERVER_URL")
+		return true
+	})
+}
+
+func (ds *DiskStore) WriteGameStarted(id string) error {
+	return ds.updateGame(id, func(g *gameRecord) bool {
+		g.Stage = 1
+		g.UpdatedAt = time.Now()
+		return true
+	})
+}
+
+func (ds *DiskStore) WriteGameFinished(id string) error {
+	return ds.updateGame(id, func(g *gameRecord) bool {
+		g.Stage = 2
+		g.UpdatedAt = time.Now()
+		return true
+	})
+}
+
+func (ds *DiskStore) WriteGameActivePlayers(id string, numPlayers int32, host string) error {
+	return ds.updateGame(id, func(g *gameRecord) bool {
+		g.ActivePlayers = int(numPlayers)
+		g.UpdatedAt = time.Now()
+		if host != "" {
+			g.Host = host
+		}
+		return true
+	})
+}
+
+func (ds *DiskStore) WriteGamePresence(
+	id string,
+	connectedPlayers int32,
+	connectedHumans int32,
+	host string,
+	hostId string,
+	lastHumanSeenAt *time.Time,
+) error {
+	return ds.updateGame(id, func(g *gameRecord) bool {
+		g.ActivePlayers = int(connectedPlayers)
+		g.ConnectedPlayers = int(connectedPlayers)
+		g.ConnectedHumans = int(connectedHumans)
+		g.LastPresenceUpdateAt = time.Now()
+		g.UpdatedAt = time.Now()
+		if host != "" {
+			g.Host = host
+		}
+		if hostId != "" {
+			g.HostId = hostId
+		}
+		if lastHumanSeenAt != nil {
+			seen := *lastHumanSeenAt
+			g.LastHumanSeenAt = &seen
+		}
+		return true
+	})
+}
+
+func (ds *DiskStore) WriteGameParticipants(id string, participantIds []string) error {
+	return ds.updateGame(id, func(g *gameRecord) bool {
+		g.ParticipantIds = append([]string{}, participantIds...)
+		g.UpdatedAt = time.Now()
+		return true
+	})
+}
+
+// CleanupInactiveGames removes games nobody is connected to any more,
+// using the same cutoffs as the mongo store.
+func (ds *DiskStore) CleanupInactiveGames() (int64, int64, error) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	files, err := listJson(filepath.Join(ds.root, GamesDir))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	now := time.Now()
+	prestartCutoff := now.Add(-1 * time.Minute)
+	playingCutoff := now.Add(-10 * time.Minute)
+
+	var deletedPrestart, deletedPlaying int64
+	for _, file := range files {
+		var g gameRecord
+		if err := readJson(file, &g); err != nil {
+			continue
+		}
+
+		if g.ConnectedHumans > 0 {
+			continue
+		}
+
+		lastSeen := g.LastPresenceUpdateAt
+		if g.LastHumanSeenAt != nil {
+			lastSeen = *g.LastHumanSeenAt
+		} else if lastSeen.IsZero() {
+			lastSeen = g.UpdatedAt
+		}
+
+		var counter *int64
+		switch {
+		case g.Stage == 0 && lastSeen.Before(prestartCutoff):
+			counter = &deletedPrestart
+		case g.Stage == 1 && lastSeen.Before(playingCutoff):
+			counter = &deletedPlaying
+		default:
+			continue
+		}
+
+		if err := removeIfExists(file); err != nil {
+			return deletedPrestart, deletedPlaying, err
+		}
+		if err := removeIfExists(ds.path(JournalsDir, g.ID, ".log")); err != nil {
+			return deletedPrestart, deletedPlaying, err
+		}
+		if err := removeIfExists(ds.path(SnapshotsDir, g.ID, ".bin")); err != nil {
+			return deletedPrestart, deletedPlaying, err
+		}
+		*counter++
+	}
+
+	return deletedPrestart, deletedPlaying, nil
+}
+
+func (ds *DiskStore) WriteGamePlayers(id string, numPlayers int32) error {
+	return ds.updateGame(id, func(g *gameRecord) bool {
+		if g.Stage != 0 {
+			return false
+		}
+		g.Players = int(numPlayers)
+		g.UpdatedAt = time.Now()
+		return true
+	})
+}
+
+func (ds *DiskStore) WriteGamePrivacy(id string, private bool) error {
+	return ds.updateGame(id, func(g *gameRecord) bool {
+		g.Private = private
+		g.UpdatedAt = time.Now()
+		return true
+	})
+}
+
+func (ds *DiskStore) WriteGameSettings(id string, settings []byte) error {
+	return ds.updateGame(id, func(g *gameRecord) bool {
+		if g.Stage != 0 {
+			return false
+		}
+		g.Settings = append([]byte(nil), settings...)
+		g.UpdatedAt = time.Now()
+		return true
+	})
+}
+
+// ReadGameSettings returns the last settings written for a game
+func (ds *DiskStore) ReadGameSettings(id string) ([]byte, error) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	g, err := ds.readGame(id)
+	if err != nil {
+		return nil, errors.New("database entry for game not found")
+	}
+	return g.Settings, nil
+}
+
+func (ds *DiskStore) WriteJournalEntries(id string, entries [][]byte) error {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	g, err := ds.readGame(id)
+	if errors.Is(err, errNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// A crash may have left half a record behind
+	name := ds.path(JournalsDir, id, ".log")
+	if !ds.repaired[name] {
+		if err := repairFrames(name); err != nil {
+			return err
+		}
+		ds.repaired[name] = true
+	}
+
+	if err := appendFrames(name, entries); err != nil {
+		return err
+	}
+
+	g.UpdatedAt = time.Now()
+	return ds.writeGame(g)
+}
+
+func (ds *DiskStore) ReadJournal(id string) ([][]byte, error) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if _, err := ds.readGame(id); err != nil {
+		return nil, errors.New("database entry for journal not found")
+	}
+	return readFrames(ds.path(JournalsDir, id, ".log"))
+}
+
+// WriteGameSnapshot replaces the stored snapshot, only the latest is kept
+func (ds *DiskStore) WriteGameSnapshot(id string, snapshot []byte) error {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if _, err := ds.readGame(id); err != nil {
+		return errors.New("database entry for game not found")
+	}
+	return writeFileAtomic(ds.path(SnapshotsDir, id, ".bin"), snapshot)
+}
+
+func (ds *DiskStore) ReadGameSnapshot(id string) ([]byte, error) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	snapshot, err := os.ReadFile(ds.path(SnapshotsDir, id, ".bin"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return snapshot, err
+}
+
+func (ds *DiskStore) ReadGamePlayers(id string) (int, error) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	g, err := ds.readGame(id)
+	if err != nil {
+		return 0, errors.New("database entry for game not found")
+	}
+	return g.Players, nil
+}
+
+func (ds *DiskStore) CheckIfJournalExists(id string) (bool, error) {
+	j, err := ds.ReadJournal(id)
+	if err != nil {
+		return false, err
+	}
+	return len(j) > 0, nil
+}
+
+func (ds *DiskStore) CreateGameStateIfNotExists(id string, state []byte) error {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	g, err := ds.readGame(id)
+	if err != nil {
+		return errors.New("database entry for game not found")
+	}
+	if g.HasState {
+		return errors.New("game state already created")
+	}
+
+	if err := writeFileAtomic(ds.path(GameStatesDir, id, ".bin"), state); err != nil {
+		return err
+	}
+
+	g.HasState = true
+	return ds.writeGame(g)
+}
+
+func (ds *DiskStore) WriteGameState(id string, state []byte) error {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	g, err := ds.readGame(id)
+	if err != nil || !g.HasState {
+		return errors.New("game state not created")
+	}
+	return writeFileAtomic(ds.path(GameStatesDir, id, ".bin"), state)
+}
+
+func (ds *DiskStore) ReadGameState(id string) ([]byte, error) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	state, err := os.ReadFile(ds.path(GameStatesDir, id, ".bin"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errors.New("game state not created")
+	}
+	return state, err
+}
+
+func (ds *DiskStore) readUser(id string) (*userRecord, error) {
+	var u userRecord
+	if err := readJson(ds.path(UsersDir, id, ".json"), &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (ds *DiskStore) writeUser(u *userRecord) error {
+	return writeJson(ds.path(UsersDir, u.ID, ".json"), u)
+}
+
+func (ds *DiskStore) updateUser(id string, fn func(u *userRecord)) error {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	u, err := ds.readUser(id)
+	if errors.Is(err, errNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fn(u)
+	u.UpdatedAt = time.Now()
+	return ds.writeUser(u)
+}
+
+func (ds *DiskStore) WriteGameIdForUser(id string, userId string, settings *entities.GameSettings) error {
+	ds.mutex.Lock()
+	g, err := ds.readGame(id)
+	ds.mutex.Unlock()
+	if err != nil || !g.HasState {
+		return errors.New("game state not created")
+	}
+
+	startedInc := int32(1)
+	if !settings.EnableKarma {
+		startedInc = 0
+	}
+
+	return ds.updateUser(userId, func(u *userRecord) {
+		u.Games = append(u.Games, id)
+		u.Started += startedInc
+	})
+}
+
+// ReadUserGames returns the ids of every game the user has played in
+func (ds *DiskStore) ReadUserGames(userId string) ([]string, error) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	u, err := ds.readUser(userId)
+	if err != nil {
+		return nil, errors.New("database entry for user not found")
+	}
+	return u.Games, nil
+}
+
+func (ds *DiskStore) WriteGameCompletedForUser(id string) error {
+	return ds.updateUser(id, func(u *userRecord) {
+		u.Finished++
+	})
+}
+
+func (ds *DiskStore) ReadUser(id string) (map[string]interface{}, error) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	u, err := ds.readUser(id)
+	if errors.Is(err, errNotFound) {
+		// An unknown user has no history, same as the mongo store
+		return map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return u.toMap(), nil
+}
+
+func (u *userRecord) toMap() map[string]interface{} {
+	games := make([]interface{}, 0, len(u.Games))
+	for _, g := range u.Games {
+		games = append(games, g)
+	}
+
+	return map[string]interface{}{
+		"id":        u.ID,
+		"username":  u.Username,
+		"email":     u.Email,
+		"createdAt": u.CreatedAt,
+		"updatedAt": u.UpdatedAt,
+		"games":     games,
+		"started":   u.Started,
+		"finished":  u.Finished,
+	}
+}
+
+// WriteMap stores a map definition under its name. Official maps are
+// listed to every user, the rest only to (or excluding) their creator.
+func (ds *DiskStore) WriteMap(creator string, official bool, defn *entities.MapDefinition) error {
+	if defn == nil || defn.Name == "" {
+		return errors.New("map must have a name")
+	}
+
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	return writeJson(ds.path(MapsDir, defn.Name, ".json"), &mapRecord{
+		Name:     defn.Name,
+		Creator:  creator,
+		Official: official,
+		Map:      defn,
+	})
+}
+
+func (ds *DiskStore) readMaps() ([]*mapRecord, error) {
+	files, err := listJson(filepath.Join(ds.root, MapsDir))
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*mapRecord, 0, len(files))
+	for _, file := range files {
+		var m mapRecord
+		if err := readJson(file, &m); err != nil {
+			continue
+		}
+		records = append(records, &m)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Name < records[j].Name
+	})
+	return records, nil
+}
+
+func (ds *DiskStore) GetOfficalMapNames() []string {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	records, err := ds.readMaps()
+	if err != nil {
+		return make([]string, 0)
+	}
+
+	var names []string
+	for _, m := range records {
+		if m.Official && m.Name != "" {
+			names = append(names, m.Name)
+		}
+	}
+	return names
+}
+
+// Get all maps excluding user maps if exclude
+func (ds *DiskStore) GetAllMapNamesForUser(userId string, exclude bool) ([]string, error) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	records, err := ds.readMaps()
+	if err != nil {
+		return nil, errors.New("could not get maps")
+	}
+
+	var names []string
+	for _, m := range records {
+		if !exclude && m.Creator != userId {
+			continue
+		}
+		if exclude && (m.Creator == userId || m.Official) {
+			continue
+		}
+		if m.Name != "" {
+			names = append(names, m.Name)
+		}
+	}
+	return names, nil
+}
+
+func (ds *DiskStore) GetMap(name string) *entities.MapDefinition {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	var m mapRecord
+	if err := readJson(ds.path(MapsDir, name, ".json"), &m); err != nil {
+		return nil
+	}
+	return m.Map
+}
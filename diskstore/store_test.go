@@ -0,0 +1,326 @@
+package diskstore
+
+import (
+	"os"
+	"sakura/entities"
+	"sakura/game"
+	"sakura/maps"
+	"testing"
+	"time"
+)
+
+var _ game.Store = (*DiskStore)(nil)
+
+func newTestStore(t *testing.T) *DiskStore {
+	t.Helper()
+	ds, err := NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	return ds
+}
+
+func TestDiskStoreJournalRoundTrip(t *testing.T) {
+	ds := newTestStore(t)
+
+	if err := ds.Init("abcd"); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+
+	exists, err := ds.CheckIfJournalExists("abcd")
+	if err != nil || exists {
+		t.Fatalf("expected empty journal, got exists=%v err=%v", exists, err)
+	}
+
+	if err := ds.WriteJournalEntries("abcd", [][]byte{{1, 2, 3}, {}}); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := ds.WriteJournalEntries("abcd", [][]byte{{4}}); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	// Reopen to make sure nothing lives only in memory
+	reopened, err := NewDiskStore(ds.Root())
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+
+	entries, err := reopened.ReadJournal("abcd")
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if len(entries) != 3 || len(entries[0]) != 3 || len(entries[1]) != 0 || entries[2][0] != 4 {
+		t.Fatalf("unexpected journal contents %v", entries)
+	}
+
+	// A torn trailing record is ignored
+	f, err := os.OpenFile(ds.path(JournalsDir, "abcd", ".log"), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	f.Write([]byte{0, 0, 0, 9, 1})
+	f.Close()
+
+	entries, err = reopened.ReadJournal("abcd")
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("expected torn tail to be ignored, got %d entries err=%v", len(entries), err)
+	}
+
+	// and cut off before the next append after a restart
+	restarted, err := NewDiskStore(ds.Root())
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if err := restarted.WriteJournalEntries("abcd", [][]byte{{5, 6}}); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	entries, err = restarted.ReadJournal("abcd")
+	if err != nil || len(entries) != 4 || entries[2][0] != 4 || len(entries[3]) != 2 || entries[3][1] != 6 {
+		t.Fatalf("expected the append after the torn tail to be read back, got %v err=%v", entries, err)
+	}
+
+	if err := reopened.TerminateGame("abcd"); err != nil {
+		t.Fatalf("terminate failed: %v", err)
+	}
+	if _, err := reopened.ReadJournal("abcd"); err == nil {
+		t.Fatalf("expected terminated game journal to be gone")
+	}
+}
+
+func TestDiskStoreSnapshot(t *testing.T) {
+	ds := newTestStore(t)
+	ds.Init("game")
+
+	if snapshot, err := ds.ReadGameSnapshot("game"); err != nil || snapshot != nil {
+		t.Fatalf("expected no snapshot, got %v err=%v", snapshot, err)
+	}
+
+	if err := ds.WriteGameSnapshot("missing", []byte{1}); err == nil {
+		t.Fatalf("expected error writing snapshot for missing game")
+	}
+
+	ds.WriteGameSnapshot("game", []byte{1})
+	ds.WriteGameSnapshot("game", []byte{2, 3})
+	snapshot, err := ds.ReadGameSnapshot("game")
+	if err != nil || len(snapshot) != 2 || snapshot[0] != 2 {
+		t.Fatalf("expected latest snapshot, got %v err=%v", snapshot, err)
+	}
+
+	ds.TerminateGame("game")
+	if snapshot, _ := ds.ReadGameSnapshot("game"); snapshot != nil {
+		t.Fatalf("expected snapshot to be removed with the game")
+	}
+}
+
+func TestDiskStoreGameRecord(t *testing.T) {
+	ds := newTestStore(t)
+	ds.Init("game")
+
+	if err := ds.WriteGamePlayers("game", 3); err != nil {
+		t.Fatalf("write players failed: %v", err)
+	}
+	if err := ds.WriteGameSettings("game", []byte("settings")); err != nil {
+		t.Fatalf("write settings failed: %v", err)
+	}
+	ds.WriteGameStarted("game")
+
+	// Lobby-only fields are frozen once the game starts
+	ds.WriteGamePlayers("game", 4)
+	ds.WriteGameSettings("game", []byte("changed"))
+
+	players, err := ds.ReadGamePlayers("game")
+	if err != nil || players != 3 {
+		t.Fatalf("expected 3 players, got %d err=%v", players, err)
+	}
+	settings, _ := ds.ReadGameSettings("game")
+	if string(settings) != "settings" {
+		t.Fatalf("expected settings to be frozen, got %q", settings)
+	}
+
+	if _, err := ds.ReadGamePlayers("missing"); err == nil {
+		t.Fatalf("expected error for missing game")
+	}
+
+	if err := ds.WriteGameState("game", []byte("s0")); err == nil {
+		t.Fatalf("expected error writing state before creation")
+	}
+	if err := ds.CreateGameStateIfNotExists("game", []byte("s1")); err != nil {
+		t.Fatalf("create state failed: %v", err)
+	}
+	if err := ds.CreateGameStateIfNotExists("game", []byte("s2")); err == nil {
+		t.Fatalf("expected error creating state twice")
+	}
+	ds.WriteGameState("game", []byte("s3"))
+	state, err := ds.ReadGameState("game")
+	if err != nil || string(state) != "s3" {
+		t.Fatalf("expected state s3, got %q err=%v", state, err)
+	}
+}
+
+func TestDiskStoreCleanupInactiveGames(t *testing.T) {
+	ds := newTestStore(t)
+	ds.Init("idle")
+	ds.Init("busy")
+	ds.Init("play")
+	ds.WriteGameStarted("play")
+
+	old := time.Now().Add(-time.Hour)
+	ds.WriteGamePresence("idle", 0, 0, "", "", &old)
+	ds.WriteGamePresence("busy", 1, 1, "", "", &old)
+	ds.WriteGamePresence("play", 0, 0, "", "", &old)
+
+	prestart, playing, err := ds.CleanupInactiveGames()
+	if err != nil || prestart != 1 || playing != 1 {
+		t.Fatalf("expected 1/1 removed, got %d/%d err=%v", prestart, playing, err)
+	}
+
+	if _, err := ds.ReadGamePlayers("busy"); err != nil {
+		t.Fatalf("expected connected game to survive cleanup")
+	}
+	if _, err := ds.ReadGamePlayers("idle"); err == nil {
+		t.Fatalf("expected idle game to be removed")
+	}
+}
+
+func TestDiskStoreUsersAndMaps(t *testing.T) {
+	ds := newTestStore(t)
+	registry := &DiskRegistry{Store: ds}
+
+	if err := registry.CreateUser("u1", "alice"); err != nil {
+		t.Fatalf("create user failed: %v", err)
+	}
+	if err := registry.CreateUser("u2", "alice"); err == nil {
+		t.Fatalf("expected duplicate username to fail")
+	}
+	if exists, _ := registry.CheckIfUserExists("u1"); !exists {
+		t.Fatalf("expected user to exist")
+	}
+	if count, _ := registry.CountUsers(); count != 1 {
+		t.Fatalf("expected 1 user, got %d", count)
+	}
+	if err := registry.UpdateEmail("u1", "alice@example.com"); err != nil {
+		t.Fatalf("update email failed: %v", err)
+	}
+	if user, err := registry.CheckIfUserEmailExists("alice@example.com"); err != nil || user["id"] != "u1" {
+		t.Fatalf("expected lookup by email to find u1, got %v err=%v", user, err)
+	}
+
+	ds.Init("game")
+	settings := &entities.GameSettings{EnableKarma: true}
+	if err := ds.WriteGameIdForUser("game", "u1", settings); err == nil {
+		t.Fatalf("expected error before game state exists")
+	}
+	ds.CreateGameStateIfNotExists("game", []byte{})
+	if err := ds.WriteGameIdForUser("game", "u1", settings); err != nil {
+		t.Fatalf("write game for user failed: %v", err)
+	}
+	ds.WriteGameCompletedForUser("u1")
+
+	user, err := ds.ReadUser("u1")
+	if err != nil || user["started"] != int32(1) || user["finished"] != int32(1) {
+		t.Fatalf("unexpected user record %v err=%v", user, err)
+	}
+	if games, _ := ds.ReadUserGames("u1"); len(games) != 1 || games[0] != "game" {
+		t.Fatalf("expected user game list [game], got %v", games)
+	}
+
+	base := maps.GetBaseMap()
+	ds.WriteMap("", true, base)
+	custom := *base
+	custom.Name = "Alice's Map"
+	ds.WriteMap("u1", false, &custom)
+	other := *base
+	other.Name = "../Other"
+	ds.WriteMap("u2", false, &other)
+
+	if names := ds.GetOfficalMapNames(); len(names) != 1 || names[0] != base.Name {
+		t.Fatalf("unexpected official maps %v", names)
+	}
+	if names, _ := ds.GetAllMapNamesForUser("u1", false); len(names) != 1 || names[0] != custom.Name {
+		t.Fatalf("unexpected user maps %v", names)
+	}
+	if names, _ := ds.GetAllMapNamesForUser("u1", true); len(names) != 1 || names[0] != other.Name {
+		t.Fatalf("unexpected community maps %v", names)
+	}
+	if defn := ds.GetMap(other.Name); defn == nil || defn.Name != other.Name {
+		t.Fatalf("expected to read back map %q", other.Name)
+	}
+	if ds.GetMap("missing") != nil {
+		t.Fatalf("expected missing map to be nil")
+	}
+}
+
+func TestDiskStoreArchiveRoundTrip(t *testing.T) {
+	src := newTestStore(t)
+	custom := *maps.GetBaseMap()
+	custom.Name = "Exported Map"
+	src.WriteMap("u1", false, &custom)
+
+	src.Init("abcd")
+	src.WriteGamePlayers("abcd", 3)
+	g := &game.Game{
+		Store: src,
+		Seed:  5,
+		Settings: entities.GameSettings{
+			Mode:          entities.Base,
+			MapName:       custom.Name,
+			MapDefn:       &custom,
+			VictoryPoints: 10,
+			Speed:         entities.NormalSpeed,
+		},
+	}
+	if _, err := g.Initialize("abcd", 3); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	g.SetUsername(g.Players[0], "alice")
+	g.SetId(g.Players[0], "u1")
+	g.Terminate()
+
+	a, err := game.ExportGame(src, "abcd")
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	b, err := game.EncodeArchive(a)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	dst := newTestStore(t)
+	decoded, err := game.DecodeArchive(b)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if err := game.ImportGame(dst, decoded, "wxyz", "u1"); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if err := game.ImportGame(dst, decoded, "wxyz", "u1"); err == nil {
+		t.Fatalf("expected importing over an existing game to fail")
+	}
+
+	if dst.GetMap(custom.Name) == nil {
+		t.Fatalf("expected the map to be imported")
+	}
+	if names, _ := dst.GetAllMapNamesForUser("u1", false); len(names) != 1 || names[0] != custom.Name {
+		t.Fatalf("expected the map to belong to the importing user, got %v", names)
+	}
+	if state, err := dst.ReadGameState("wxyz"); err != nil || len(state) == 0 {
+		t.Fatalf("expected imported game state, got err=%v", err)
+	}
+	if p, _ := dst.ReadGamePlayers("wxyz"); p != 3 {
+		t.Fatalf("expected 3 players, got %d", p)
+	}
+	if record, _ := dst.readGame("wxyz"); len(record.ParticipantIds) != 3 || record.ParticipantIds[0] != "u1" {
+		t.Fatalf("expected participants to be imported, got %v", record.ParticipantIds)
+	}
+
+	again, err := game.ExportGame(dst, "wxyz")
+	if err != nil {
+		t.Fatalf("export of imported game failed: %v", err)
+	}
+	if len(again.Journal) != len(a.Journal) || again.Players[0].Username != "alice" || again.State.Seed != 5 {
+		t.Fatalf("imported game does not match the export")
+	}
+	if again.MapDefn == nil || again.MapDefn.Name != custom.Name {
+		t.Fatalf("expected imported game to use %q", custom.Name)
+	}
+}
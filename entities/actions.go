@@ -10,6 +10,7 @@ const (
 	PlayerActionTypeChooseBuildable   = "cb"
 	PlayerActionTypeChooseDice        = "cd"
 	PlayerActionTypeChooseImprovement = "ci"
+	PlayerActionTypeChooseWarship     = "cw"
 
 	MessageTypeTileFog            = "tf"
 	MessageTypePlayerSecretState  = "ss"
@@ -26,6 +27,8 @@ const (
 	MessageTypeSpectatorList      = "spec"
 	MessageTypeError              = "err"
 	MessageTypeEndsess            = "endsess"
+	MessageTypeReplayFrame        = "rp"
+	MessageTypeFortressAttack     = "fa"
 
 	WsMsgLocationLobby = "l"
 	WsMsgLocationGame  = "g"
@@ -1,18 +1,29 @@
 package entities
 
+import "math/rand"
+
 type (
 	Bank struct {
 		Hand                  *Hand
 		DevelopmentCardOrder  map[CardType][]DevelopmentCardType
 		DevelopmentCardCursor int
+		EventCardOrder        []int
+		EventCardCursor       int
 	}
 )
 
-func GetNewBank(gameMode GameMode) (*Bank, error) {
+func GetNewBank(gameMode GameMode, r *rand.Rand) (*Bank, error) {
+	return GetNewBankForPlayers(gameMode, 4, r)
+}
+
+// GetNewBankForPlayers sets up the bank, with the cards of the 5-6 player
+// extension for that many players
+func GetNewBankForPlayers(gameMode GameMode, numPlayers uint16, r *rand.Rand) (*Bank, error) {
 	bank := &Bank{DevelopmentCardCursor: 0}
+	extension := numPlayers >= ExtensionPlayers
 
 	// Create bank
-	hand, err := GetNewHand(gameMode, true)
+	hand, err := getNewHand(gameMode, true, extension)
 	if err != nil {
 		return nil, err
 	}
@@ -21,12 +32,12 @@ func GetNewBank(gameMode GameMode) (*Bank, error) {
 	bank.DevelopmentCardOrder = make(map[CardType][]DevelopmentCardType)
 
 	// Create development card order
-	if gameMode == Base || gameMode == Seafarers {
-		bank.DevelopmentCardOrder[0] = GenerateDevelopmentCardOrder()
-	} else if gameMode == CitiesAndKnights {
-		bank.DevelopmentCardOrder[CardTypePaper] = GenerateProgessCardOrder(CardTypePaper)
-		bank.DevelopmentCardOrder[CardTypeCloth] = GenerateProgessCardOrder(CardTypeCloth)
-		bank.DevelopmentCardOrder[CardTypeCoin] = GenerateProgessCardOrder(CardTypeCoin)
+	if gameMode.HasCitiesAndKnights() {
+		bank.DevelopmentCardOrder[CardTypePaper] = GenerateProgessCardOrder(CardTypePaper, r)
+		bank.DevelopmentCardOrder[CardTypeCloth] = GenerateProgessCardOrder(CardTypeCloth, r)
+		bank.DevelopmentCardOrder[CardTypeCoin] = GenerateProgessCardOrder(CardTypeCoin, r)
+	} else {
+		bank.DevelopmentCardOrder[0] = GenerateDevelopmentCardOrder(r, extension)
 	}
 
 	return bank, nil
@@ -0,0 +1,178 @@
+package entities
+
+type (
+	// BuildCost is the number of cards of each type paid for a piece,
+	// indexed by card type
+	BuildCost [9]int
+
+	// BuildRules are the prices and piece limits of a game, by name of the
+	// piece. Maps and house rules only list what they change.
+	BuildRules struct {
+		Costs           map[string]BuildCost `json:"costs,omitempty"`
+		DevelopmentCard *BuildCost           `json:"development_card,omitempty"`
+
+		// Commodity cards for each level of city improvement
+		Improvements []int `json:"improvements,omitempty"`
+
+		// Pieces each player starts with
+		Pieces map[string]int `json:"pieces,omitempty"`
+	}
+)
+
+// Names of the pieces in build rules
+var BuildableNames = map[BuildableType]string{
+	BTSettlement: "settlement",
+	BTCity:       "city",
+	BTRoad:       "road",
+	BTKnight1:    "knight1",
+	BTKnight2:    "knight2",
+	BTKnight3:    "knight3",
+	BTWall:       "wall",
+	BTShip:       "ship",
+}
+
+// Name of the price of activating a knight in build rules
+const ActivateKnightCost = "activate_knight"
+
+var defaultBuildCosts = map[string]BuildCost{
+	"settlement": {CardTypeWood: 1, CardTypeBrick: 1, CardTypeWool: 1, CardTypeWheat: 1},
+	"city":       {CardTypeWheat: 2, CardTypeOre: 3},
+	"road":       {CardTypeWood: 1, CardTypeBrick: 1},
+	"ship":       {CardTypeWood: 1, CardTypeWool: 1},
+	"knight1":    {CardTypeWool: 1, CardTypeOre: 1},
+	"knight2":    {CardTypeWool: 1, CardTypeOre: 1},
+	"knight3":    {CardTypeWool: 1, CardTypeOre: 1},
+	"wall":       {CardTypeBrick: 2},
+
+	ActivateKnightCost: {CardTypeWheat: 1},
+}
+
+var defaultDevelopmentCardCost = BuildCost{CardTypeWool: 1, CardTypeWheat: 1, CardTypeOre: 1}
+
+var defaultImprovementCosts = []int{1, 2, 3, 4, 5}
+
+// GetDefaultBuildRules returns the rulebook prices and the pieces of the
+// game mode
+func GetDefaultBuildRules(g GameMode) *BuildRules {
+	rules := &BuildRules{
+		Costs:        make(map[string]BuildCost),
+		Improvements: append([]int{}, defaultImprovementCosts...),
+		Pieces:       make(map[string]int),
+	}
+	for t, c := range defaultBuildCosts {
+		rules.Costs[t] = c
+	}
+	cost := defaultDevelopmentCardCost
+	rules.DevelopmentCard = &cost
+
+	rules.Pieces["settlement"] = 5
+	rules.Pieces["city"] = 4
+	rules.Pieces["road"] = 15
+	if g.HasSeafarers() {
+		rules.Pieces["ship"] = 15
+	}
+
+	if g.HasCitiesAndKnights() {
+		rules.Pieces["knight1"] = 2
+		rules.Pieces["knight2"] = 2
+		rules.Pieces["knight3"] = 2
+		rules.Pieces["wall"] = 3
+	}
+
+	return rules
+}
+
+// GetBuildRules applies the changes of a map and the house rules, in
+// that order, to the defaults of the game mode. Pieces the mode does not
+// play with stay out, improvement prices must list every level and
+// negative numbers are ignored.
+func GetBuildRules(g GameMode, changes ...*BuildRules) *BuildRules {
+	rules := GetDefaultBuildRules(g)
+	for _, c := range changes {
+		if c == nil {
+			continue
+		}
+
+		for t, cost := range c.Costs {
+			if _, ok := rules.Costs[t]; ok && cost.valid() {
+				rules.Costs[t] = cost
+			}
+		}
+		if c.DevelopmentCard != nil && c.DevelopmentCard.valid() {
+			cost := *c.DevelopmentCard
+			rules.DevelopmentCard = &cost
+		}
+		if len(c.Improvements) == len(rules.Improvements) && validCounts(c.Improvements) {
+			rules.Improvements = append([]int{}, c.Improvements...)
+		}
+		for t, n := range c.Pieces {
+			if _, ok := rules.Pieces[t]; ok && n >= 0 {
+				rules.Pieces[t] = n
+			}
+		}
+	}
+	return rules
+}
+
+func (c BuildCost) valid() bool {
+	return validCounts(c[:])
+}
+
+func validCounts(counts []int) bool {
+	for _, n := range counts {
+		if n < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// GetCost returns the price of a piece, the rulebook one without rules
+func (r *BuildRules) GetCost(t BuildableType) (BuildCost, bool) {
+	if r == nil {
+		cost, ok := defaultBuildCosts[BuildableNames[t]]
+		return cost, ok
+	}
+	cost, ok := r.Costs[BuildableNames[t]]
+	return cost, ok
+}
+
+// GetDevelopmentCardCost returns the price of a development card
+func (r *BuildRules) GetDevelopmentCardCost() BuildCost {
+	if r == nil || r.DevelopmentCard == nil {
+		return defaultDevelopmentCardCost
+	}
+	return *r.DevelopmentCard
+}
+
+// GetActivateKnightCost returns the price of activating a knight
+func (r *BuildRules) GetActivateKnightCost() BuildCost {
+	if r == nil {
+		return defaultBuildCosts[ActivateKnightCost]
+	}
+	return r.Costs[ActivateKnightCost]
+}
+
+// GetImprovementCost returns the commodity cards needed to reach the
+// next level of city improvement, and false at the last level
+func (r *BuildRules) GetImprovementCost(level int) (int, bool) {
+	costs := defaultImprovementCosts
+	if r != nil && len(r.Improvements) > 0 {
+		costs = r.Improvements
+	}
+	if level < 0 || level >= len(costs) {
+		return 0, false
+	}
+	return costs[level], true
+}
+
+// GetPieces returns a copy of the pieces each player starts with
+func (r *BuildRules) GetPieces() map[BuildableType]int {
+	pieces := make(map[BuildableType]int, len(r.Pieces))
+	for t, name := range BuildableNames {
+		if n, ok := r.Pieces[name]; ok {
+			pieces[t] = n
+		}
+	}
+	return pieces
+}
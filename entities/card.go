@@ -2,7 +2,6 @@ package entities
 
 import (
 	"math/rand"
-	"time"
 )
 
 type (
@@ -105,7 +104,7 @@ const (
 	CardCoin5  DevelopmentCardType = 115
 )
 
-func GetInitialDevelopmentCardQuantity(isBank bool) (int16, int16, int16, int16, int16) {
+func GetInitialDevelopmentCardQuantity(isBank bool, extension bool) (int16, int16, int16, int16, int16) {
 	knightQuantity := int16(0)
 	vpQuantity := int16(0)
 	roadBuildingQuantity := int16(0)
@@ -119,52 +118,73 @@ func GetInitialDevelopmentCardQuantity(isBank bool) (int16, int16, int16, int16,
 		yearOfPlentyQuantity = IQBaseYearOfPlenty
 		monopolyQuantity = IQBaseMonopoly
 	}
+	if isBank && extension {
+		knightQuantity = IQExtensionKnight
+		roadBuildingQuantity = IQExtensionRoadBuilding
+		yearOfPlentyQuantity = IQExtensionYearOfPlenty
+		monopolyQuantity = IQExtensionMonopoly
+	}
 
 	return knightQuantity, vpQuantity, roadBuildingQuantity, yearOfPlentyQuantity, monopolyQuantity
 }
 
-func GetInitialCardQuantity(g GameMode, t CardType) int16 {
+func GetInitialCardQuantity(g GameMode, t CardType, extension bool) int16 {
 	if t == CardTypeWood ||
 		t == CardTypeBrick ||
 		t == CardTypeWool ||
 		t == CardTypeWheat ||
 		t == CardTypeOre {
+		if extension {
+			return IQExtensionResource
+		}
 		return IQBaseResource
 	}
 
 	if t == CardTypePaper ||
 		t == CardTypeCloth || t == CardTypeCoin {
+		if extension {
+			return IQExtensionCKCommodity
+		}
 		return IQCKCommodity
 	}
 
 	return 0
 }
 
-func GenerateDevelopmentCardOrder() []DevelopmentCardType {
+func GenerateDevelopmentCardOrder(r *rand.Rand, extension bool) []DevelopmentCardType {
 	order := make([]DevelopmentCardType, 0)
-	knightQuantity, vpQuantity, roadBuildingQuantity, yearOfPlentyQuantity, monopolyQuantity := GetInitialDevelopmentCardQuantity(true)
-	developmentCards := make(map[DevelopmentCardType]int16)
-	developmentCards[DevelopmentCardKnight] = knightQuantity
-	developmentCards[DevelopmentCardVictoryPoint] = vpQuantity
-	developmentCards[DevelopmentCardRoadBuilding] = roadBuildingQuantity
-	developmentCards[DevelopmentCardYearOfPlenty] = yearOfPlentyQuantity
-	developmentCards[DevelopmentCardMonopoly] = monopolyQuantity
-
-	for dCType, dCQuantity := range developmentCards {
-		for i := int16(0); i < dCQuantity; i++ {
-			order = append(order, dCType)
+	knightQuantity, vpQuantity, roadBuildingQuantity, yearOfPlentyQuantity, monopolyQuantity := GetInitialDevelopmentCardQuantity(true, extension)
+	developmentCards := []DevelopmentCardDeck{
+		{Type: DevelopmentCardKnight, Quantity: knightQuantity},
+		{Type: DevelopmentCardVictoryPoint, Quantity: vpQuantity},
+		{Type: DevelopmentCardRoadBuilding, Quantity: roadBuildingQuantity},
+		{Type: DevelopmentCardYearOfPlenty, Quantity: yearOfPlentyQuantity},
+		{Type: DevelopmentCardMonopoly, Quantity: monopolyQuantity},
+	}
+
+	for _, deck := range developmentCards {
+		for i := int16(0); i < deck.Quantity; i++ {
+			order = append(order, deck.Type)
 		}
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(order), func(i, j int) {
+	r.Shuffle(len(order), func(i, j int) {
 		order[i], order[j] = order[j], order[i]
 	})
 
 	return order
 }
 
-func GenerateProgessCardOrder(card CardType) []DevelopmentCardType {
+func GenerateProgessCardOrder(card CardType, r *rand.Rand) []DevelopmentCardType {
+	order := progressCardTypes(card)
+	r.Shuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+	return order
+}
+
+// progressCardTypes lists the cards of a progress deck in a fixed order
+func progressCardTypes(card CardType) []DevelopmentCardType {
 	order := make([]DevelopmentCardType, 0)
 
 	switch card {
@@ -232,10 +252,6 @@ func GenerateProgessCardOrder(card CardType) []DevelopmentCardType {
 		}
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(order), func(i, j int) {
-		order[i], order[j] = order[j], order[i]
-	})
 	return order
 }
 
@@ -8,6 +8,8 @@ type (
 		PlayerHandDeltas []*Hand        `msgpack:"-"`
 		GainInfo         []CardMoveInfo `msgpack:"g"`
 		IsInit           bool           `msgpack:"ii,omitempty"`
+		Event            EventCardEvent `msgpack:"ev,omitempty"`
+		NewYear          bool           `msgpack:"ny,omitempty"`
 	}
 
 	DiceStats struct {
@@ -0,0 +1,83 @@
+package entities
+
+import "math/rand"
+
+type (
+	EventCardEvent int
+
+	// EventCard is drawn instead of rolling the dice
+	EventCard struct {
+		Red   int
+		White int
+		Event EventCardEvent
+	}
+)
+
+const (
+	EventCardNone EventCardEvent = iota
+	EventCardPlentifulYear
+	EventCardTournament
+	EventCardTradeAdvantage
+	EventCardEarthquake
+	EventCardEpidemic
+	EventCardGoodNeighbors
+	EventCardCalmSea
+	EventCardConflict
+	EventCardNeighborlyAssistance
+	EventCardNewYear
+)
+
+// Number of roll cards in the event deck, one for each roll of two dice
+const EventCardDeckSize = 36
+
+// The New Year card is shuffled into this many cards at the bottom
+const EventCardNewYearDepth = 5
+
+// Events printed on the roll cards, by sum of the roll
+var eventCardEvents = map[int][]EventCardEvent{
+	4:  {EventCardPlentifulYear},
+	5:  {EventCardTournament, EventCardTradeAdvantage},
+	6:  {EventCardEarthquake, EventCardEpidemic, EventCardGoodNeighbors},
+	8:  {EventCardEpidemic},
+	9:  {EventCardCalmSea, EventCardConflict},
+	10: {EventCardNeighborlyAssistance},
+	11: {EventCardNeighborlyAssistance},
+	12: {EventCardCalmSea},
+}
+
+// GetEventCards returns the cards of the event deck. The New Year card
+// is the last one.
+func GetEventCards() []EventCard {
+	cards := make([]EventCard, 0, EventCardDeckSize+1)
+	used := make(map[int]int)
+	for red := 1; red <= 6; red++ {
+		for white := 1; white <= 6; white++ {
+			card := EventCard{Red: red, White: white}
+			events := eventCardEvents[red+white]
+			if used[red+white] < len(events) {
+				card.Event = events[used[red+white]]
+				used[red+white]++
+			}
+			cards = append(cards, card)
+		}
+	}
+	return append(cards, EventCard{Event: EventCardNewYear})
+}
+
+// GenerateEventCardOrder shuffles the event deck and puts the New Year
+// card among the cards at the bottom
+func GenerateEventCardOrder(r *rand.Rand) []int {
+	order := make([]int, EventCardDeckSize)
+	for i := range order {
+		order[i] = i
+	}
+	r.Shuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+
+	pos := EventCardDeckSize - r.Intn(EventCardNewYearDepth+1)
+	order = append(order, 0)
+	copy(order[pos+1:], order[pos:])
+	order[pos] = EventCardDeckSize
+	return order
+}
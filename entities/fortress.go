@@ -0,0 +1,23 @@
+package entities
+
+type (
+	// PirateFortress is the fortress of The Pirate Islands one player has
+	// to conquer. Every won attack takes away one of its strength, at zero
+	// it becomes a settlement of the player.
+	PirateFortress struct {
+		Vertex   *Vertex `msgpack:"v"`
+		Owner    uint16  `msgpack:"o"`
+		Strength int     `msgpack:"s"`
+
+		// Turn of the last attack, plus one
+		AttackedTurn int `msgpack:"-"`
+	}
+
+	// FortressAttack tells the players how an attack on a fortress went
+	FortressAttack struct {
+		Player   uint16 `msgpack:"p"`
+		Warships int    `msgpack:"w"`
+		Roll     int    `msgpack:"r"`
+		Won      bool   `msgpack:"o"`
+	}
+)
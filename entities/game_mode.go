@@ -3,18 +3,31 @@ package entities
 type GameMode uint16
 
 const (
-	Base               GameMode = 1
-	CitiesAndKnights   GameMode = 2
-	Seafarers          GameMode = 3
-	IQBaseResource     int16    = 19
-	IQBaseKnight       int16    = 14
-	IQBaseVP           int16    = 5
-	IQBaseRoadBuilding int16    = 2
-	IQBaseYearOfPlenty int16    = 2
-	IQBaseMonopoly     int16    = 2
+	Base             GameMode = 1
+	CitiesAndKnights GameMode = 2
+	Seafarers        GameMode = 3
+
+	// Seafarers scenarios played with the Cities & Knights rules
+	SeafarersCitiesAndKnights GameMode = 4
+
+	IQBaseResource     int16 = 19
+	IQBaseKnight       int16 = 14
+	IQBaseVP           int16 = 5
+	IQBaseRoadBuilding int16 = 2
+	IQBaseYearOfPlenty int16 = 2
+	IQBaseMonopoly     int16 = 2
 
 	IQCKCommodity int16 = 12
 
+	// The 5-6 player extension adds to the bank and development cards
+	ExtensionPlayers        uint16 = 5
+	IQExtensionResource     int16  = 24
+	IQExtensionKnight       int16  = 20
+	IQExtensionRoadBuilding int16  = 3
+	IQExtensionYearOfPlenty int16  = 3
+	IQExtensionMonopoly     int16  = 3
+	IQExtensionCKCommodity  int16  = 15
+
 	SlowSpeed   string = "slow"
 	NormalSpeed string = "normal"
 	FastSpeed   string = "fast"
@@ -26,6 +39,17 @@ const (
 	Speed200m string = "200m"
 )
 
+// HasCitiesAndKnights checks if the mode plays with commodities, knights
+// and barbarians
+func (g GameMode) HasCitiesAndKnights() bool {
+	return g == CitiesAndKnights || g == SeafarersCitiesAndKnights
+}
+
+// HasSeafarers checks if the mode plays with ships and the pirate
+func (g GameMode) HasSeafarers() bool {
+	return g == Seafarers || g == SeafarersCitiesAndKnights
+}
+
 type GameSettings struct {
 	Mode          GameMode
 	Private       bool
@@ -39,10 +63,18 @@ type GameSettings struct {
 	Speed         string
 	Advanced      bool
 	MapDefn       *MapDefinition `json:"-" msgpack:"-"`
+
+	// House rule prices and piece limits, on top of those of the map
+	BuildRules *BuildRules `msgpack:"BuildRules,omitempty"`
 }
 
 type AdvancedSettings struct {
-	RerollOn7 bool
+	RerollOn7             bool
+	EventCards            bool
+	FriendlyRobber        bool
+	RobberFromRound       int
+	RerollEarly7          bool
+	RobberReturnsToDesert bool
 }
 
 var SpeedMultiplier = map[string]float32{
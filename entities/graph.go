@@ -2,6 +2,7 @@ package entities
 
 import (
 	"errors"
+	"sort"
 )
 
 const (
@@ -72,6 +73,12 @@ type (
 		Map             [][]int           `json:"map"`
 		RandomTiles     []TileType        `json:"tiles"`
 		Scenario        *ScenarioMetadata `json:"scenario,omitempty"`
+
+		// Most players the map is made for, 4 when unset
+		MaxPlayers int `json:"max_players,omitempty"`
+
+		// Prices and piece limits the scenario changes
+		BuildRules *BuildRules `json:"build_rules,omitempty"`
 	}
 
 	ScenarioMetadata struct {
@@ -255,5 +262,36 @@ func (g *Graph) GetBeachEdges() []*Edge {
 		}
 	}
 
+	SortEdges(edges)
 	return edges
 }
+
+// Less orders coordinates by X, then Y
+func (c Coordinate) Less(o Coordinate) bool {
+	if c.X != o.X {
+		return c.X < o.X
+	}
+	return c.Y < o.Y
+}
+
+func (c EdgeCoordinate) Less(o EdgeCoordinate) bool {
+	if c.C1 != o.C1 {
+		return c.C1.Less(o.C1)
+	}
+	return c.C2.Less(o.C2)
+}
+
+// SortVertices orders vertices by coordinate, so that lists built from
+// maps come out the same on every run
+func SortVertices(vertices []*Vertex) {
+	sort.Slice(vertices, func(i, j int) bool {
+		return vertices[i].C.Less(vertices[j].C)
+	})
+}
+
+// SortEdges orders edges by coordinate
+func SortEdges(edges []*Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i].C.Less(edges[j].C)
+	})
+}
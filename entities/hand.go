@@ -3,6 +3,7 @@ package entities
 import (
 	"errors"
 	"math/rand"
+	"sort"
 )
 
 type (
@@ -52,6 +53,20 @@ func (h *Hand) HasResources(wood int, brick int, wool int, wheat int, ore int) b
 		h.GetCardDeck(CardTypeOre).Quantity >= int16(ore)
 }
 
+// HasCost checks if the hand has the cards to pay the cost
+func (h *Hand) HasCost(cost BuildCost) bool {
+	for ct, q := range cost {
+		if q <= 0 {
+			continue
+		}
+		deck := h.GetCardDeck(CardType(ct))
+		if deck == nil || int(deck.Quantity) < q {
+			return false
+		}
+	}
+	return true
+}
+
 func (h *Hand) EnsureHasResources(wood int, brick int, wool int, wheat int, ore int) error {
 	if !h.HasResources(wood, brick, wool, wheat, ore) {
 		return errors.New("not enough resources")
@@ -71,14 +86,21 @@ func (h *Hand) GetCardCount() int16 {
 	return count
 }
 
-func (h *Hand) ChooseRandomCardType() *CardType {
+func (h *Hand) ChooseRandomCardType(r *rand.Rand) *CardType {
 	cardCount := h.GetCardCount()
 	if cardCount <= 0 {
 		return nil
 	}
 
-	randomIndex := rand.Intn(int(cardCount))
-	for _, deck := range h.GetCardDeckMap() {
+	types := make([]CardType, 0, len(h.CardDeckMap))
+	for t := range h.CardDeckMap {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	randomIndex := r.Intn(int(cardCount))
+	for _, t := range types {
+		deck := h.CardDeckMap[t]
 		randomIndex -= int(deck.Quantity)
 		if randomIndex < 0 {
 			return &deck.Type
@@ -95,14 +117,21 @@ func (h *Hand) GetDevelopmentCardCount() int16 {
 	return count
 }
 
-func (h *Hand) ChooseRandomDevCardType() *DevelopmentCardType {
+func (h *Hand) ChooseRandomDevCardType(r *rand.Rand) *DevelopmentCardType {
 	cardCount := h.GetDevelopmentCardCount()
 	if cardCount <= 0 {
 		return nil
 	}
 
-	randomIndex := rand.Intn(int(cardCount))
-	for _, deck := range h.DevelopmentCardDeckMap {
+	types := make([]DevelopmentCardType, 0, len(h.DevelopmentCardDeckMap))
+	for t := range h.DevelopmentCardDeckMap {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	randomIndex := r.Intn(int(cardCount))
+	for _, t := range types {
+		deck := h.DevelopmentCardDeckMap[t]
 		randomIndex -= int(deck.Quantity)
 		if randomIndex < 0 {
 			return &deck.Type
@@ -120,12 +149,16 @@ func newHand() *Hand {
 }
 
 func GetNewHand(g GameMode, isBank bool) (*Hand, error) {
+	return getNewHand(g, isBank, false)
+}
+
+func getNewHand(g GameMode, isBank bool, extension bool) (*Hand, error) {
 	hand := newHand()
 
 	addCard := func(hand *Hand, c CardType) {
 		quantity := int16(0)
 		if isBank {
-			quantity = GetInitialCardQuantity(g, c)
+			quantity = GetInitialCardQuantity(g, c, extension)
 		}
 		hand.addCardDeck(&CardDeck{Type: c, Quantity: quantity})
 	}
@@ -138,20 +171,20 @@ func GetNewHand(g GameMode, isBank bool) (*Hand, error) {
 
 	switch g {
 	case Base, Seafarers:
-		knightQuantity, vpQuantity, roadBuildingQuantity, yearOfPlentyQuantity, monopolyQuantity := GetInitialDevelopmentCardQuantity(isBank)
+		knightQuantity, vpQuantity, roadBuildingQuantity, yearOfPlentyQuantity, monopolyQuantity := GetInitialDevelopmentCardQuantity(isBank, extension)
 		hand.addDevelopmentCardDeck(DevelopmentCardDeck{Type: DevelopmentCardKnight, Quantity: knightQuantity})
 		hand.addDevelopmentCardDeck(DevelopmentCardDeck{Type: DevelopmentCardVictoryPoint, Quantity: vpQuantity})
 		hand.addDevelopmentCardDeck(DevelopmentCardDeck{Type: DevelopmentCardRoadBuilding, Quantity: roadBuildingQuantity})
 		hand.addDevelopmentCardDeck(DevelopmentCardDeck{Type: DevelopmentCardYearOfPlenty, Quantity: yearOfPlentyQuantity})
 		hand.addDevelopmentCardDeck(DevelopmentCardDeck{Type: DevelopmentCardMonopoly, Quantity: monopolyQuantity})
-	case CitiesAndKnights:
+	case CitiesAndKnights, SeafarersCitiesAndKnights:
 		addCard(hand, CardTypePaper)
 		addCard(hand, CardTypeCloth)
 		addCard(hand, CardTypeCoin)
 
-		cards := GenerateProgessCardOrder(CardTypePaper)
-		cards = append(cards, GenerateProgessCardOrder(CardTypeCloth)...)
-		cards = append(cards, GenerateProgessCardOrder(CardTypeCoin)...)
+		cards := progressCardTypes(CardTypePaper)
+		cards = append(cards, progressCardTypes(CardTypeCloth)...)
+		cards = append(cards, progressCardTypes(CardTypeCoin)...)
 		for _, ct := range cards {
 			if hand.GetDevelopmentCardDeck(ct) == nil {
 				hand.addDevelopmentCardDeck(DevelopmentCardDeck{Type: ct, Quantity: 0})
@@ -10,6 +10,14 @@ import (
 	"github.com/vmihailenco/msgpack/v5"
 )
 
+// Levels of the strategies a bot seat plays with
+const (
+	BotLevelEasy   string = "easy"
+	BotLevelNormal string = "normal"
+	BotLevelHard   string = "hard"
+	BotLevelExpert string = "expert"
+)
+
 type (
 	Player struct {
 		Id               string            `msgpack:"-"`
@@ -26,6 +34,9 @@ type (
 		MessageChannel chan []byte      `msgpack:"-"`
 		Expect         chan interface{} `msgpack:"-"`
 
+		// Receives messages instead of the channel when set
+		Listener func(*Message) `msgpack:"-"`
+
 		BuildablesLeft map[BuildableType]int `msgpack:"-"`
 
 		Improvements         map[int]int         `msgpack:"-"`
@@ -43,6 +54,9 @@ type (
 		InactiveSeconds int32 `msgpack:"-"`
 		IsSpectator     bool  `msgpack:"-"`
 
+		// Strategy of the seat while a bot plays it, normal when empty
+		BotLevel string `msgpack:"-"`
+
 		Embargos []bool `msgpack:"-"`
 	}
 
@@ -68,6 +82,12 @@ type (
 		BarbarianStrength int       `msgpack:"bs"`
 		BarbarianKnights  int       `msgpack:"bk"`
 		Merchant          *Merchant `msgpack:"tm"`
+
+		TribeMarkers  []*TribeMarker    `msgpack:"fm,omitempty"`
+		ClothVillages []*ClothVillage   `msgpack:"cv,omitempty"`
+		Fortresses    []*PirateFortress `msgpack:"pf,omitempty"`
+		Warships      []EdgeCoordinate  `msgpack:"ws,omitempty"`
+		Wonders       []*Wonder         `msgpack:"wd,omitempty"`
 	}
 
 	PlayerState struct {
@@ -87,11 +107,17 @@ type (
 		Improvements        map[int]int `msgpack:"ci"`
 		DiscardLimit        int16       `msgpack:"l"`
 		IsBot               bool        `msgpack:"b,omitempty"`
+		BotLevel            string      `msgpack:"bl,omitempty"`
 
 		HasLongestRoad bool `msgpack:"lr,omitempty"`
 		HasLargestArmy bool `msgpack:"la,omitempty"`
 
 		DevCardVp *int16 `msgpack:"dv,omitempty"`
+
+		Cloth int `msgpack:"cl,omitempty"`
+
+		Wonder      WonderType `msgpack:"wo,omitempty"`
+		WonderStage int        `msgpack:"wst,omitempty"`
 	}
 
 	LobbyPlayerState struct {
@@ -101,6 +127,7 @@ type (
 		Ready         bool   `msgpack:"r"`
 		GamesStarted  int32  `msgpack:"s"`
 		GamesFinished int32  `msgpack:"f"`
+		BotLevel      string `msgpack:"bl,omitempty"`
 	}
 
 	AllowedActionsMap struct {
@@ -123,6 +150,10 @@ type (
 		ImproveCoin    bool `msgpack:"ic,omitempty"`
 
 		SpecialBuild bool `msgpack:"sb,omitempty"`
+
+		AttackFortress bool `msgpack:"af,omitempty"`
+		ClaimWonder    bool `msgpack:"wc,omitempty"`
+		BuildWonder    bool `msgpack:"wb,omitempty"`
 	}
 
 	PlayerSecretState struct {
@@ -187,20 +218,7 @@ func NewPlayer(g GameMode, id, username string, order uint16) (*Player, error) {
 	player.MessageChannel = make(chan []byte, 1024)
 	player.Expect = make(chan interface{}, 4)
 
-	player.BuildablesLeft = make(map[BuildableType]int)
-	player.BuildablesLeft[BTSettlement] = 5
-	player.BuildablesLeft[BTCity] = 4
-	player.BuildablesLeft[BTRoad] = 15
-	if g == Seafarers {
-		player.BuildablesLeft[BTShip] = 15
-	}
-
-	if g == CitiesAndKnights {
-		player.BuildablesLeft[BTKnight1] = 2
-		player.BuildablesLeft[BTKnight2] = 2
-		player.BuildablesLeft[BTKnight3] = 2
-		player.BuildablesLeft[BTWall] = 3
-	}
+	player.BuildablesLeft = GetDefaultBuildRules(g).GetPieces()
 
 	player.Improvements = make(map[int]int)
 	player.Improvements[int(CardTypePaper)] = 0
@@ -328,59 +346,24 @@ func (e *Edge) RemovePlacement() error {
 	return nil
 }
 
-func (p *Player) CanBuild(t BuildableType) error {
+func (p *Player) CanBuild(t BuildableType, rules *BuildRules) error {
 	left, ok := p.BuildablesLeft[t]
 	if !ok || left <= 0 {
 		return errors.New("not enough pieces left to build")
 	}
 
-	if t == BTSettlement {
-		if !p.CurrentHand.HasResources(1, 1, 1, 1, 0) {
-			return errors.New("not enough resources")
-		}
-		return nil
-	}
-
-	if t == BTCity {
-		if !p.CurrentHand.HasResources(0, 0, 0, 2, 3) {
-			return errors.New("not enough resources")
-		}
-		return nil
-	}
-
-	if t == BTRoad {
-		if !p.CurrentHand.HasResources(1, 1, 0, 0, 0) {
-			return errors.New("not enough resources")
-		}
-		return nil
-	}
-
-	if t == BTShip {
-		if !p.CurrentHand.HasResources(1, 0, 1, 0, 0) {
-			return errors.New("not enough resources")
-		}
-		return nil
-	}
-
-	if t == BTKnight1 || t == BTKnight2 || t == BTKnight3 {
-		if !p.CurrentHand.HasResources(0, 0, 1, 0, 1) {
-			return errors.New("not enough resources")
-		}
-		return nil
+	cost, ok := rules.GetCost(t)
+	if !ok {
+		return errors.New("unknown type of buildable")
 	}
-
-	if t == BTWall {
-		if !p.CurrentHand.HasResources(0, 2, 0, 0, 0) {
-			return errors.New("not enough resources")
-		}
-		return nil
+	if !p.CurrentHand.HasCost(cost) {
+		return errors.New("not enough resources")
 	}
-
-	return errors.New("unknown type of buildable")
+	return nil
 }
 
-func (p *Player) CanBuyDevelopmentCard() bool {
-	return p.CurrentHand.HasResources(0, 0, 1, 1, 1)
+func (p *Player) CanBuyDevelopmentCard(rules *BuildRules) bool {
+	return p.CurrentHand.HasCost(rules.GetDevelopmentCardCost())
 }
 
 func (p *Player) HasInactiveKnight() bool {
@@ -418,6 +401,11 @@ func (p *Player) SendExpect(val interface{}) {
 func (p *Player) SendMessage(msg *Message) {
 	msg.Location = WsMsgLocationGame
 
+	if p.Initialized && p.Listener != nil {
+		p.Listener(msg)
+		return
+	}
+
 	if p.Initialized {
 		serialized, err := msgpack.Marshal(msg)
 		if err != nil {
@@ -446,6 +434,11 @@ func (p *Player) SetIsBot(val bool) {
 	atomic.StoreInt32(&p.IsBot, store)
 }
 
+// IsBotLevel checks if bots can play with the level
+func IsBotLevel(level string) bool {
+	return level == BotLevelEasy || level == BotLevelNormal || level == BotLevelHard || level == BotLevelExpert
+}
+
 func (p *Player) ResetInactivity() {
 	atomic.StoreInt32(&p.InactiveSeconds, 0)
 	p.SetIsBot(false)
@@ -81,6 +81,7 @@ func (p *Player) GetBuildLocationsRoad(g *Graph, init bool) []*Edge {
 	for k := range edges {
 		keys = append(keys, k)
 	}
+	SortEdges(keys)
 	return keys
 }
 
@@ -124,6 +125,7 @@ func (p *Player) GetBuildLocationsShip(g *Graph) []*Edge {
 	for k := range edges {
 		keys = append(keys, k)
 	}
+	SortEdges(keys)
 	return keys
 }
 
@@ -108,5 +108,6 @@ func (p *Player) GetBuildLocationsSettlement(g *Graph, init bool, allowFog bool)
 	for k := range vertices {
 		keys = append(keys, k)
 	}
+	SortVertices(keys)
 	return keys
 }
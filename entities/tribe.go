@@ -0,0 +1,19 @@
+package entities
+
+type (
+	TribeMarkerType uint16
+
+	// TribeMarker is a reward lying on a sea edge next to the forgotten
+	// tribe, taken by the first ship built on the edge
+	TribeMarker struct {
+		Edge *Edge           `msgpack:"e"`
+		Type TribeMarkerType `msgpack:"t"`
+		Port PortType        `msgpack:"p,omitempty"`
+	}
+)
+
+const (
+	TribeMarkerVictoryPoint    TribeMarkerType = 1
+	TribeMarkerDevelopmentCard TribeMarkerType = 2
+	TribeMarkerHarbor          TribeMarkerType = 3
+)
@@ -0,0 +1,9 @@
+package entities
+
+// ClothVillage is a village of Cloth for Catan. When its number is rolled
+// every player with a ship along its tile takes cloth from the supply.
+type ClothVillage struct {
+	Tile   *Tile  `msgpack:"t"`
+	Number uint16 `msgpack:"n"`
+	Cloth  int    `msgpack:"c"`
+}
@@ -0,0 +1,24 @@
+package entities
+
+type WonderType int8
+
+const (
+	WonderGreatWall   WonderType = 1
+	WonderGreatBridge WonderType = 2
+	WonderLighthouse  WonderType = 3
+	WonderMonument    WonderType = 4
+	WonderTheater     WonderType = 5
+)
+
+// WonderStages is the number of stages of a finished wonder
+const WonderStages = 5
+
+// Wonder of The Wonders of Catan. A player who meets its prerequisite may
+// claim it, nobody else can build it after that.
+type Wonder struct {
+	Type WonderType `msgpack:"t"`
+
+	// Order of the player who claimed it, -1 before that
+	Owner int16 `msgpack:"o"`
+	Stage int   `msgpack:"s"`
+}
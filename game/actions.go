@@ -2,7 +2,6 @@ package game
 
 import (
 	"errors"
-	"math/rand"
 	"reflect"
 	"sakura/entities"
 	"strconv"
@@ -16,7 +15,7 @@ func (g *Game) IsCreativeMode() bool {
 
 func (g *Game) ensureCanBuild(player *entities.Player, t entities.BuildableType) error {
 	if !g.IsCreativeMode() {
-		return player.CanBuild(t)
+		return player.CanBuild(t, g.BuildRules)
 	}
 
 	left, ok := player.BuildablesLeft[t]
@@ -26,6 +25,49 @@ func (g *Game) ensureCanBuild(player *entities.Player, t entities.BuildableType)
 	return nil
 }
 
+// payBuildCost moves the price of a piece from the player to the bank
+func (g *Game) payBuildCost(player *entities.Player, t entities.BuildableType) {
+	cost, _ := g.BuildRules.GetCost(t)
+	g.payCost(player, cost, false)
+}
+
+// payCost moves the cards of a cost from the player to the bank
+func (g *Game) payCost(player *entities.Player, cost entities.BuildCost, journal bool) {
+	for ct, q := range cost {
+		if q > 0 {
+			g.MoveCards(int(player.Order), -1, entities.CardType(ct), q, journal, false)
+		}
+	}
+}
+
+// lendCost puts the cards of a cost in the hand of the player without
+// the bank, or takes them back when the sign is negative
+func (g *Game) lendCost(player *entities.Player, cost entities.BuildCost, sign int) {
+	for ct, q := range cost {
+		if q > 0 {
+			player.CurrentHand.UpdateCards(entities.CardType(ct), sign*q)
+			g.j.WUpdateCard(player, entities.CardType(ct), int16(sign*q))
+		}
+	}
+}
+
+// repayCost takes lent cards back out of the bank once a build has paid
+// them, so free pieces do not add cards to the game
+func (g *Game) repayCost(player *entities.Player, cost entities.BuildCost) {
+	// Nothing was paid in creative mode
+	if g.IsCreativeMode() {
+		g.lendCost(player, cost, -1)
+		return
+	}
+
+	for ct, q := range cost {
+		if q > 0 {
+			g.Bank.Hand.UpdateCards(entities.CardType(ct), -q)
+			g.j.WUpdateCard(nil, entities.CardType(ct), int16(-q))
+		}
+	}
+}
+
 // Checks if the player is current and no other players have pending actions
 func (g *Game) EnsureCurrentPlayer(player *entities.Player) error {
 	if player != g.CurrentPlayer {
@@ -81,7 +123,11 @@ func (g *Game) BuildSettlement(player *entities.Player, coordinates entities.Coo
 	}
 
 	canBuild := func() bool {
-		for _, v := range player.GetBuildLocationsSettlement(g.Graph, init, false) {
+		locations := player.GetBuildLocationsSettlement(g.Graph, init, false)
+		if !init {
+			locations = g.GetBuildLocationsSettlement(player)
+		}
+		for _, v := range locations {
 			if v.C == coordinates {
 				return true
 			}
@@ -94,10 +140,7 @@ func (g *Game) BuildSettlement(player *entities.Player, coordinates entities.Coo
 	}
 
 	if !init && !g.IsCreativeMode() {
-		g.MoveCards(int(player.Order), -1, entities.CardTypeWood, 1, false, false)
-		g.MoveCards(int(player.Order), -1, entities.CardTypeBrick, 1, false, false)
-		g.MoveCards(int(player.Order), -1, entities.CardTypeWool, 1, false, false)
-		g.MoveCards(int(player.Order), -1, entities.CardTypeWheat, 1, false, false)
+		g.payBuildCost(player, entities.BTSettlement)
 	}
 
 	vertex, _ := g.Graph.GetVertex(coordinates)
@@ -163,8 +206,7 @@ func (g *Game) BuildCity(player *entities.Player, coordinates entities.Coordinat
 	}
 
 	if !init && !g.IsCreativeMode() {
-		g.MoveCards(int(player.Order), -1, entities.CardTypeOre, 3, false, false)
-		g.MoveCards(int(player.Order), -1, entities.CardTypeWheat, 2, false, false)
+		g.payBuildCost(player, entities.BTCity)
 	}
 
 	vertex, _ := g.Graph.GetVertex(coordinates)
@@ -226,8 +268,7 @@ func (g *Game) BuildRoad(player *entities.Player, c entities.EdgeCoordinate) err
 	}
 
 	if !init && !g.IsCreativeMode() {
-		g.MoveCards(int(player.Order), -1, entities.CardTypeWood, 1, false, false)
-		g.MoveCards(int(player.Order), -1, entities.CardTypeBrick, 1, false, false)
+		g.payBuildCost(player, entities.BTRoad)
 	}
 
 	e, err := g.Graph.GetEdge(c)
@@ -256,6 +297,7 @@ func (g *Game) BuildRoad(player *entities.Player, c entities.EdgeCoordinate) err
 	})
 
 	g.j.WEdgeBuild(e)
+	g.onScenarioEdgeBuilt(player, e)
 	g.applyCurrentPlayerTimerBonus(player, g.TimerVals.ActionBonusPlaceRoad)
 
 	g.CheckForVictory()
@@ -283,7 +325,7 @@ func (g *Game) chooseDiscoveryGoldCardType(player *entities.Player) entities.Car
 		canPrompt = false
 	}
 	if player == nil || player.GetIsBot() || !canPrompt {
-		return available[rand.Intn(len(available))]
+		return available[g.Rand().Intn(len(available))]
 	}
 
 	exp, err := g.BlockForAction(player, g.TimerVals.DevCardSelect1ResourceForMonopoly, &entities.PlayerAction{
@@ -307,7 +349,7 @@ func (g *Game) chooseDiscoveryGoldCardType(player *entities.Player) entities.Car
 		}
 	}
 
-	return available[rand.Intn(len(available))]
+	return available[g.Rand().Intn(len(available))]
 }
 
 func (g *Game) giveDiscoveryRewardForTile(player *entities.Player, tile *entities.Tile) {
@@ -382,7 +424,7 @@ func (g *Game) RevealFogAdjacentToEdge(player *entities.Player, edge *entities.E
 }
 
 func (g *Game) IsSeaRobberBlockingEdge(e *entities.Edge) bool {
-	if g.Mode != entities.Seafarers || g.Pirate == nil || g.Pirate.Tile == nil || g.Pirate.Tile.Type != entities.TileTypeSea {
+	if !g.Mode.HasSeafarers() || g.Pirate == nil || g.Pirate.Tile == nil || g.Pirate.Tile.Type != entities.TileTypeSea {
 		return false
 	}
 	for _, t := range e.AdjacentTiles {
@@ -394,7 +436,7 @@ func (g *Game) IsSeaRobberBlockingEdge(e *entities.Edge) bool {
 }
 
 func (g *Game) BuildShip(player *entities.Player, c entities.EdgeCoordinate) error {
-	if g.Mode != entities.Seafarers {
+	if !g.Mode.HasSeafarers() {
 		return errors.New("ships are only available in Seafarers")
 	}
 	init := g.IsInitPhase()
@@ -436,8 +478,7 @@ func (g *Game) BuildShip(player *entities.Player, c entities.EdgeCoordinate) err
 	}
 
 	if !g.IsCreativeMode() {
-		g.MoveCards(int(player.Order), -1, entities.CardTypeWood, 1, false, false)
-		g.MoveCards(int(player.Order), -1, entities.CardTypeWool, 1, false, false)
+		g.payBuildCost(player, entities.BTShip)
 	}
 
 	err = player.BuildAtEdge(e, entities.BTShip)
@@ -459,6 +500,7 @@ func (g *Game) BuildShip(player *entities.Player, c entities.EdgeCoordinate) err
 		Data: e.Placement,
 	})
 	g.j.WEdgeBuild(e)
+	g.onScenarioEdgeBuilt(player, e)
 	g.applyCurrentPlayerTimerBonus(player, g.TimerVals.ActionBonusPlaceRoad)
 	g.CheckForVictory()
 
@@ -512,6 +554,24 @@ func (g *Game) GetMovableShips(player *entities.Player) []*entities.Edge {
 			return shipDegreeAtVertex(v, e) == 0
 		}
 
+		// Knights must stay connected to a route of their color
+		strandsKnight := func(v *entities.Vertex) bool {
+			if v.Placement == nil || v.Placement.GetOwner() != player ||
+				v.Placement.GetType() < entities.BTKnight1 || v.Placement.GetType() > entities.BTKnight3 {
+				return false
+			}
+			for _, adjE := range g.Graph.GetAdjacentVertexEdges(v) {
+				if adjE != e && adjE.Placement != nil && adjE.Placement.GetOwner() == player {
+					return false
+				}
+			}
+			return true
+		}
+
+		if strandsKnight(v1) || strandsKnight(v2) {
+			continue
+		}
+
 		if isEndpoint(v1) || isEndpoint(v2) {
 			movable = append(movable, e)
 		}
@@ -521,7 +581,7 @@ func (g *Game) GetMovableShips(player *entities.Player) []*entities.Edge {
 }
 
 func (g *Game) MoveShip(player *entities.Player, fromC, toC entities.EdgeCoordinate) error {
-	if g.Mode != entities.Seafarers {
+	if !g.Mode.HasSeafarers() {
 		return errors.New("ships are only available in Seafarers")
 	}
 	if err := g.EnsureCurrentPlayer(player); err != nil {
@@ -596,6 +656,8 @@ func (g *Game) MoveShip(player *entities.Player, fromC, toC entities.EdgeCoordin
 		Data: to.Placement,
 	})
 	player.ShipMoved = true
+	g.onScenarioShipMoved(player, from, to)
+	g.onScenarioEdgeBuilt(player, to)
 	g.SetExtraVictoryPoints()
 	g.SendPlayerSecret(player)
 	g.CheckForVictory()
@@ -603,7 +665,7 @@ func (g *Game) MoveShip(player *entities.Player, fromC, toC entities.EdgeCoordin
 }
 
 func (g *Game) MoveShipInteractive(player *entities.Player) error {
-	if g.Mode != entities.Seafarers {
+	if !g.Mode.HasSeafarers() {
 		return errors.New("ships are only available in Seafarers")
 	}
 	if err := g.EnsureCurrentPlayer(player); err != nil {
@@ -696,24 +758,15 @@ func (g *Game) BuyDevelopmentCard(player *entities.Player) error {
 		return err
 	}
 
-	if (!g.IsCreativeMode() && !player.CanBuyDevelopmentCard()) || g.Bank.DevelopmentCardCursor >= len(g.Bank.DevelopmentCardOrder[0]) {
+	if (!g.IsCreativeMode() && !player.CanBuyDevelopmentCard(g.BuildRules)) || g.Bank.DevelopmentCardCursor >= len(g.Bank.DevelopmentCardOrder[0]) {
 		return errors.New("cannot buy development card")
 	}
 
 	if !g.IsCreativeMode() {
-		g.MoveCards(int(player.Order), -1, entities.CardTypeWool, 1, true, false)
-		g.MoveCards(int(player.Order), -1, entities.CardTypeWheat, 1, true, false)
-		g.MoveCards(int(player.Order), -1, entities.CardTypeOre, 1, true, false)
+		g.payCost(player, g.BuildRules.GetDevelopmentCardCost(), true)
 	}
 
-	developmentCardType := g.Bank.DevelopmentCardOrder[0][g.Bank.DevelopmentCardCursor]
-	g.Bank.DevelopmentCardCursor++
-	g.j.WDevelopmentCardCursor(g.Bank.DevelopmentCardCursor)
-
-	developmentCardDeck := player.CurrentHand.GetDevelopmentCardDeck(developmentCardType)
-	developmentCardDeck.Quantity += 1
-	g.MoveDevelopmentCard(-1, int(player.Order), developmentCardType, true)
-	g.j.WUpdateDevelopmentCard(player, developmentCardType, developmentCardDeck.Quantity, developmentCardDeck.NumUsed, developmentCardDeck.CanUse)
+	g.drawDevelopmentCard(player)
 
 	g.SendPlayerSecret(player)
 	g.BroadcastState()
@@ -729,6 +782,19 @@ func (g *Game) BuyDevelopmentCard(player *entities.Player) error {
 	return nil
 }
 
+// drawDevelopmentCard gives the next card of the stack to a player. The
+// stack must not be empty.
+func (g *Game) drawDevelopmentCard(player *entities.Player) {
+	developmentCardType := g.Bank.DevelopmentCardOrder[0][g.Bank.DevelopmentCardCursor]
+	g.Bank.DevelopmentCardCursor++
+	g.j.WDevelopmentCardCursor(g.Bank.DevelopmentCardCursor)
+
+	developmentCardDeck := player.CurrentHand.GetDevelopmentCardDeck(developmentCardType)
+	developmentCardDeck.Quantity += 1
+	g.MoveDevelopmentCard(-1, int(player.Order), developmentCardType, true)
+	g.j.WUpdateDevelopmentCard(player, developmentCardType, developmentCardDeck.Quantity, developmentCardDeck.NumUsed, developmentCardDeck.CanUse)
+}
+
 func (g *Game) BuildKnight(player *entities.Player, coordinates entities.Coordinate) error {
 	if err := g.EnsureCurrentPlayer(player); err != nil {
 		return err
@@ -772,8 +838,7 @@ func (g *Game) BuildKnight(player *entities.Player, coordinates entities.Coordin
 	}
 
 	if !g.IsCreativeMode() {
-		g.MoveCards(int(player.Order), -1, entities.CardTypeWool, 1, false, false)
-		g.MoveCards(int(player.Order), -1, entities.CardTypeOre, 1, false, false)
+		g.payBuildCost(player, knightType)
 	}
 
 	isActivated := false
@@ -838,12 +903,13 @@ func (g *Game) ActivateKnight(player *entities.Player, coordinates entities.Coor
 		return errors.New("cannot activate warrior here")
 	}
 
-	if !g.IsCreativeMode() && !player.CurrentHand.HasResources(0, 0, 0, 1, 0) {
+	cost := g.BuildRules.GetActivateKnightCost()
+	if !g.IsCreativeMode() && !player.CurrentHand.HasCost(cost) {
 		return errors.New("not enough resources")
 	}
 
 	if !g.IsCreativeMode() {
-		g.MoveCards(int(player.Order), -1, entities.CardTypeWheat, 1, true, false)
+		g.payCost(player, cost, true)
 	}
 
 	g.setKnightActive(vertex, true, false)
@@ -902,7 +968,7 @@ func (g *Game) BuildWall(player *entities.Player, coordinates entities.Coordinat
 	}
 
 	if !g.IsCreativeMode() {
-		g.MoveCards(int(player.Order), -1, entities.CardTypeBrick, 2, false, false)
+		g.payBuildCost(player, entities.BTWall)
 	}
 	player.BuildablesLeft[entities.BTWall]--
 	vertex.Placement.(*entities.City).Wall = true
@@ -936,12 +1002,22 @@ func (g *Game) KnightChaseRobber(player *entities.Player, dry bool) error {
 		return errors.New("no robber tile")
 	}
 
+	// Knights on the corners of a sea tile chase the pirate the same way
+	targets := []*entities.Tile{g.Robber.Tile}
+	if g.Mode.HasSeafarers() && g.Pirate != nil && g.Pirate.Tile != nil {
+		targets = append(targets, g.Pirate.Tile)
+	}
+
 	vertices := make([]*entities.Vertex, 0)
-	for _, vp := range g.Graph.GetTilePlacements(g.Robber.Tile) {
-		if vp.GetOwner() == player && vp.GetType() >= entities.BTKnight1 && vp.GetType() <= entities.BTKnight3 {
-			k := vp.(*entities.Knight)
-			if k.CanUse && k.Activated {
-				vertices = append(vertices, k.GetLocation())
+	chased := make(map[*entities.Vertex]*entities.Tile)
+	for _, t := range targets {
+		for _, vp := range g.Graph.GetTilePlacements(t) {
+			if vp.GetOwner() == player && vp.GetType() >= entities.BTKnight1 && vp.GetType() <= entities.BTKnight3 {
+				k := vp.(*entities.Knight)
+				if k.CanUse && k.Activated && chased[k.GetLocation()] == nil {
+					vertices = append(vertices, k.GetLocation())
+					chased[k.GetLocation()] = t
+				}
 			}
 		}
 	}
@@ -969,7 +1045,7 @@ func (g *Game) KnightChaseRobber(player *entities.Player, dry bool) error {
 	v, _ := g.Graph.GetVertex(loc)
 
 	if player.GetIsBot() {
-		v = vertices[rand.Intn(len(vertices))]
+		v = vertices[g.Rand().Intn(len(vertices))]
 	}
 
 	found := false
@@ -989,6 +1065,14 @@ func (g *Game) KnightChaseRobber(player *entities.Player, dry bool) error {
 		Data: v.Placement,
 	})
 
+	if chased[v] != g.Robber.Tile {
+		tile, err := g.moveRobberOrPirateInteractive(g.TimerVals.PlaceRobber, false, true)
+		if err == nil {
+			g.StealCardAtTile(tile)
+		}
+		return nil
+	}
+
 	g.MoveRobberInteractive(g.TimerVals.PlaceRobber)
 	g.StealCardWithRobber()
 
@@ -1216,6 +1300,10 @@ func (g *Game) EndTurn(player *entities.Player) error {
 	}
 
 	g.j.WEndTurn(player)
+	g.TurnCount++
+	if !g.SpecialBuildPhase {
+		g.returnRobberAfterTurn(player)
+	}
 
 	g.CurrentOffers = make([]*entities.TradeOffer, 0)
 	g.resetTimeLeft()
@@ -1262,6 +1350,9 @@ func (g *Game) EndTurn(player *entities.Player) error {
 	}
 
 	if !g.SpecialBuildPhase {
+		if g.CurrentPlayer == g.Players[0] {
+			g.RoundCount++
+		}
 		g.DiceState = 0
 		g.EndTurnResetDevelopmentCards()
 		g.CurrentPlayer.ResetTurnState()
@@ -1273,7 +1364,7 @@ func (g *Game) EndTurn(player *entities.Player) error {
 
 	// Previous player
 	if player != g.CurrentPlayer { // Possible in case of build phase
-		if g.Mode == entities.CitiesAndKnights {
+		if g.Mode.HasCitiesAndKnights() {
 			if player.CurrentHand.GetDevelopmentCardCount() > 4 {
 				g.DiscardProgressCard(player)
 			}
@@ -1289,20 +1380,21 @@ func (g *Game) EndTurn(player *entities.Player) error {
 	g.BroadcastState()
 	g.BroadcastMessage(&entities.Message{Type: entities.MessageTypeTradeCloseOffers})
 
-	g.ai.Reset()
+	g.resetBots()
+	g.writeSnapshotIfDue()
 
 	return nil
 }
 
 func (g *Game) EndTurnResetDevelopmentCards() {
 	// Make all development cards usable
-	if g.Mode == entities.Base || g.Mode == entities.Seafarers {
+	if !g.Mode.HasCitiesAndKnights() {
 		for _, deck := range g.CurrentPlayer.CurrentHand.DevelopmentCardDeckMap {
 			if deck.Quantity > 0 && deck.Type != entities.DevelopmentCardVictoryPoint {
 				deck.CanUse = true
 			}
 		}
-	} else if g.Mode == entities.CitiesAndKnights {
+	} else if g.Mode.HasCitiesAndKnights() {
 		g.CurrentPlayer.CurrentHand.GetDevelopmentCardDeck(entities.ProgressPaperAlchemist).CanUse = true
 		g.MerchantFleets = [9]int{-1, 4, 4, 4, 4, 4, 4, 4, 4}
 
@@ -1369,7 +1461,7 @@ func (g *Game) GetRatiosForPlayer(player *entities.Player) [9]int {
 		ratios = MergeRatios(ratios, ratios2)
 	}
 
-	if g.Mode == entities.CitiesAndKnights {
+	if g.Mode.HasCitiesAndKnights() {
 		if player.Improvements[int(entities.CardTypeCloth)] >= 3 {
 			tradingHouseRatios := [9]int{-1, 4, 4, 4, 4, 4, 2, 2, 2}
 			ratios = MergeRatios(ratios, tradingHouseRatios)
@@ -1694,6 +1786,7 @@ func (g *Game) RejectOffer(offerId int, player *entities.Player) (*entities.Trad
 	}
 
 	offer.Acceptances[player.Order] = -1
+	g.botOfferRefused(offer, player)
 
 	if offer.CreatedBy == player.Order || offer.CurrentPlayer == player.Order {
 		g.DestroyOffer(offer)
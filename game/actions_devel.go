@@ -31,7 +31,7 @@ func (g *Game) UseDevelopmentCard(player *entities.Player, developmentCardType e
 		thisDeck.Quantity--
 		thisDeck.NumUsed++
 		g.MoveDevelopmentCard(int(player.Order), -1, thisDeck.Type, false)
-		if g.Mode == entities.Base || g.Mode == entities.Seafarers {
+		if !g.Mode.HasCitiesAndKnights() {
 			for _, deck := range g.CurrentPlayer.CurrentHand.DevelopmentCardDeckMap {
 				deck.CanUse = false
 				g.j.WUpdateDevelopmentCard(player, deck.Type, deck.Quantity, deck.NumUsed, deck.CanUse)
@@ -41,6 +41,21 @@ func (g *Game) UseDevelopmentCard(player *entities.Player, developmentCardType e
 
 	switch developmentCardType {
 	case entities.DevelopmentCardKnight:
+		if g.ScenarioHooks.PlayKnight != nil {
+			if err := g.ScenarioHooks.PlayKnight(g, player, true); err != nil {
+				return err
+			}
+			useCard()
+			g.BroadcastDevCardUse(thisDeck.Type, 0, -1)
+			g.SetExtraVictoryPoints()
+			if err := g.ScenarioHooks.PlayKnight(g, player, false); err != nil {
+				return err
+			}
+			g.CheckForVictory()
+			g.BroadcastDevCardUse(thisDeck.Type, 500, -1)
+			break
+		}
+
 		useCard()
 		g.BroadcastDevCardUse(thisDeck.Type, 0, -1)
 		g.SetExtraVictoryPoints()
@@ -164,7 +179,7 @@ func (g *Game) UseDevelopmentCard(player *entities.Player, developmentCardType e
 
 		for resourcesLeft > 0 {
 			// Only for base game - bank only has valid cards
-			ct := g.Bank.Hand.ChooseRandomCardType()
+			ct := g.Bank.Hand.ChooseRandomCardType(g.Rand())
 			if ct == nil {
 				break
 			}
@@ -190,13 +205,14 @@ func (g *Game) UseDevelopmentCard(player *entities.Player, developmentCardType e
 }
 
 func (g *Game) UseDevRoadBuilding(player *entities.Player, types []entities.BuildableType) {
-	buildOne := func(timeout int) {
+	// A type of 0 lets the player pick a road or a ship
+	buildOne := func(timeout int, only entities.BuildableType) {
 		roadLocations := player.GetBuildLocationsRoad(g.Graph, false)
 		shipLocations := make([]*entities.Edge, 0)
-		if g.Mode == entities.Seafarers && player.BuildablesLeft[entities.BTShip] > 0 {
+		if g.Mode.HasSeafarers() && player.BuildablesLeft[entities.BTShip] > 0 && only != entities.BTRoad {
 			shipLocations = player.GetBuildLocationsShip(g.Graph)
 		}
-		if player.BuildablesLeft[entities.BTRoad] <= 0 {
+		if player.BuildablesLeft[entities.BTRoad] <= 0 || only == entities.BTShip {
 			roadLocations = []*entities.Edge{}
 		}
 
@@ -247,36 +263,36 @@ func (g *Game) UseDevRoadBuilding(player *entities.Player, types []entities.Buil
 		}
 
 		buildType := entities.BTRoad
-		if g.Mode == entities.Seafarers && selected.IsWaterEdge() {
+		if g.Mode.HasSeafarers() && selected.IsWaterEdge() {
 			buildType = entities.BTShip
 		}
 
-		// Make sure the player has cards; they will immediately be spent by build.
+		// Lend the price of the piece, which the build spends right away.
 		// We bypass bank checks for Road Building card.
-		if buildType == entities.BTRoad {
-			player.CurrentHand.UpdateResources(1, 1, 0, 0, 0)
-			g.j.WUpdateResources(player, 1, 1, 0, 0, 0)
-		} else {
-			player.CurrentHand.UpdateResources(1, 0, 1, 0, 0)
-			g.j.WUpdateResources(player, 1, 0, 1, 0, 0)
-		}
+		cost, _ := g.BuildRules.GetCost(buildType)
+		g.lendCost(player, cost, 1)
 
 		orig := player.UsingDevCard
 		player.UsingDevCard = entities.DevelopmentCardRoadBuilding
 		if buildType == entities.BTRoad {
-			_ = g.BuildRoad(player, selected.C)
+			err = g.BuildRoad(player, selected.C)
 		} else {
-			_ = g.BuildShip(player, selected.C)
+			err = g.BuildShip(player, selected.C)
 		}
 		player.UsingDevCard = orig
+		if err != nil {
+			g.lendCost(player, cost, -1)
+		} else {
+			g.repayCost(player, cost)
+		}
 	}
 
-	for i := range types {
+	for i, t := range types {
 		timeout := g.TimerVals.DevCardPlace2MoreRoadBuilding
 		if i > 0 {
 			timeout = g.TimerVals.DevCardPlace1MoreRoadBuilding
 		}
-		buildOne(timeout)
+		buildOne(timeout, t)
 	}
 }
 
@@ -348,7 +364,7 @@ func (g *Game) UseProgressCard(ct entities.DevelopmentCardType, player *entities
 }
 
 func (g *Game) ComputeProgressCardsUsable(p *entities.Player) {
-	if g.Mode != entities.CitiesAndKnights {
+	if !g.Mode.HasCitiesAndKnights() {
 		return
 	}
 
@@ -3,7 +3,6 @@ package game
 import (
 	"errors"
 	"sakura/entities"
-	"math/rand"
 
 	"github.com/mitchellh/mapstructure"
 )
@@ -139,7 +138,7 @@ func (g *Game) UseProgressClothCommercialHarbor(p *entities.Player, dry bool) er
 			put(entities.CardTypeCloth)
 			put(entities.CardTypeCoin)
 
-			return has[rand.Intn(len(has))]
+			return has[g.Rand().Intn(len(has))]
 		}
 
 		commodity := entities.CardType(0)
@@ -208,7 +207,7 @@ func (g *Game) UseProgressClothMasterMerchant(p *entities.Player, dry bool) erro
 	stoleOrder := 0
 	err = mapstructure.Decode(exp, &stoleOrder)
 	if err != nil || stoleOrder < 0 || stoleOrder > len(g.Players) || !stealChoices[stoleOrder] {
-		stoleOrder = int(stealPlayers[rand.Intn(len(stealPlayers))].Order)
+		stoleOrder = int(stealPlayers[g.Rand().Intn(len(stealPlayers))].Order)
 	}
 
 	stoleFrom := g.Players[stoleOrder]
@@ -255,7 +254,7 @@ func (g *Game) UseProgressClothMasterMerchant(p *entities.Player, dry bool) erro
 	}
 
 	for count < quantityToSteal {
-		ct := stoleFrom.CurrentHand.ChooseRandomCardType()
+		ct := stoleFrom.CurrentHand.ChooseRandomCardType(g.Rand())
 		if ct == nil {
 			break
 		}
@@ -276,7 +275,7 @@ func (g *Game) UseProgressClothMerchant(p *entities.Player, dry bool) error {
 	}
 
 	tiles := make([]*entities.Tile, 0)
-	for _, t := range g.Tiles {
+	for _, t := range g.sortedTiles() {
 		if t.Type < entities.TileTypeWood || t.Type > entities.TileTypeOre || t.Fog {
 			// No gold or fog or sea
 			continue
@@ -311,7 +310,7 @@ func (g *Game) UseProgressClothMerchant(p *entities.Player, dry bool) error {
 	selTile := g.Graph.Tiles[resp]
 
 	if p.GetIsBot() {
-		selTile = g.ai.ChooseMerchantLocation(g.CurrentPlayer, tiles)
+		selTile = g.botStrategy(g.CurrentPlayer).ChooseMerchantLocation(g.CurrentPlayer, tiles)
 	}
 
 	{ // Check if tile valid
@@ -360,7 +359,7 @@ func (g *Game) UseProgressClothMerchantFleet(p *entities.Player, dry bool) error
 
 	var cards [9]int
 	if p.GetIsBot() {
-		cards = g.ai.ChooseMerchantFleet(p)
+		cards = g.botStrategy(p).ChooseMerchantFleet(p)
 	} else {
 		err = mapstructure.Decode(exp, &cards)
 		if err != nil || len(cards) != 9 {
@@ -429,7 +428,7 @@ func (g *Game) UseProgressClothMonopoly(p *entities.Player, dry bool, ct entitie
 	var cards [9]int
 	if p.GetIsBot() {
 		cards = [9]int{0, 0, 0, 0, 0, 0, 0, 0, 0}
-		cards[allowedTypes[rand.Intn(len(allowedTypes))]] = 1
+		cards[allowedTypes[g.Rand().Intn(len(allowedTypes))]] = 1
 	} else {
 		err = mapstructure.Decode(exp, &cards)
 		if err != nil || len(cards) != 9 {
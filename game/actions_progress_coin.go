@@ -3,7 +3,6 @@ package game
 import (
 	"errors"
 	"sakura/entities"
-	"math/rand"
 	"strconv"
 	"sync"
 
@@ -32,7 +31,7 @@ func (g *Game) UseProgressCoinBishop(p *entities.Player, dry bool) error {
 
 		stolen[owner.Order] = true
 
-		ct := owner.CurrentHand.ChooseRandomCardType()
+		ct := owner.CurrentHand.ChooseRandomCardType(g.Rand())
 		if ct != nil {
 			g.MoveCards(int(owner.Order), int(p.Order), *ct, 1, true, true)
 			g.SendPlayerSecret(owner)
@@ -90,7 +89,7 @@ func (g *Game) UseProgressCoinDeserter(p *entities.Player, dry bool) error {
 	stoleOrder := 0
 	err = mapstructure.Decode(exp, &stoleOrder)
 	if err != nil || stoleOrder < 0 || stoleOrder > len(g.Players) || !stealChoices[stoleOrder] {
-		stoleOrder = int(stealPlayers[rand.Intn(len(stealPlayers))].Order)
+		stoleOrder = int(stealPlayers[g.Rand().Intn(len(stealPlayers))].Order)
 	}
 
 	vertices := make([]*entities.Vertex, 0)
@@ -120,7 +119,7 @@ func (g *Game) UseProgressCoinDeserter(p *entities.Player, dry bool) error {
 		}
 	}
 	if !found {
-		v = vertices[rand.Intn(len(vertices))]
+		v = vertices[g.Rand().Intn(len(vertices))]
 	}
 
 	wasActivated := v.Placement.(*entities.Knight).Activated
@@ -156,7 +155,7 @@ func (g *Game) UseProgressCoinDeserter(p *entities.Player, dry bool) error {
 			}
 		}
 		if !found {
-			v = buildLocations[rand.Intn(len(buildLocations))]
+			v = buildLocations[g.Rand().Intn(len(buildLocations))]
 		}
 
 		err := p.BuildAtVertex(v, level)
@@ -210,7 +209,8 @@ func (g *Game) UseProgressCoinDiplomat(p *entities.Player, dry bool) error {
 		}
 
 		for _, ep := range p.EdgePlacements {
-			if ep.GetType() != entities.BTRoad {
+			// Ships count as roads with Seafarers
+			if ep.GetType() != entities.BTRoad && ep.GetType() != entities.BTShip {
 				continue
 			}
 
@@ -230,7 +230,7 @@ func (g *Game) UseProgressCoinDiplomat(p *entities.Player, dry bool) error {
 
 	exp, err := g.BlockForAction(p, g.TimerVals.DevCardSelect1ResourceForMonopoly, &entities.PlayerAction{
 		Type:    entities.PlayerActionTypeChooseEdge,
-		Message: "Choose road to remove",
+		Message: "Choose road or ship to remove",
 		Data: entities.PlayerActionChooseEdge{
 			Allowed: edges,
 		},
@@ -249,7 +249,7 @@ func (g *Game) UseProgressCoinDiplomat(p *entities.Player, dry bool) error {
 		}
 	}
 	if redge == nil || redge.Placement == nil {
-		redge = edges[rand.Intn(len(edges))]
+		redge = edges[g.Rand().Intn(len(edges))]
 	}
 
 	owner := redge.Placement.GetOwner()
@@ -265,8 +265,9 @@ func (g *Game) UseProgressCoinDiplomat(p *entities.Player, dry bool) error {
 	g.j.WEdgeBuild(redge)
 	g.ReinsertDevelopmentCard(p, entities.ProgressCoinDiplomat, false)
 
+	// A removed ship can only be built again as a ship
 	if owner == p {
-		g.UseDevRoadBuilding(p, []entities.BuildableType{entities.BTRoad})
+		g.UseDevRoadBuilding(p, []entities.BuildableType{bt})
 	}
 
 	g.SendPlayerSecret(p)
@@ -363,7 +364,8 @@ func (g *Game) UseProgressCoinWedding(p *entities.Player, dry bool) error {
 		wg.Add(1)
 		p.ClearExpect()
 
-		go func(wg *sync.WaitGroup, stoleFrom *entities.Player, action *entities.PlayerActionSelectCards) {
+		stoleFrom := stoleFrom
+		g.spawn(func() {
 			defer wg.Done()
 
 			defer g.Unlock()
@@ -401,7 +403,7 @@ func (g *Game) UseProgressCoinWedding(p *entities.Player, dry bool) error {
 			}
 
 			for sum < action.Quantity {
-				t := stoleFrom.CurrentHand.ChooseRandomCardType()
+				t := stoleFrom.CurrentHand.ChooseRandomCardType(g.Rand())
 				if t == nil {
 					break
 				}
@@ -413,12 +415,12 @@ func (g *Game) UseProgressCoinWedding(p *entities.Player, dry bool) error {
 			stoleFrom.SendAction(&entities.PlayerAction{Type: entities.PlayerActionTypeSelectCardsDone})
 			g.SendPlayerSecret(stoleFrom)
 			g.BroadcastState()
-		}(&wg, stoleFrom, action)
+		})
 	}
 
 	g.TickerPause = true
 	g.Unlock()
-	wg.Wait()
+	g.wait(&wg)
 	if !g.Lock() {
 		return nil
 	}
@@ -538,7 +540,7 @@ func (g *Game) UseProgressCoinSpy(p *entities.Player, dry bool) error {
 	stoleOrder := 0
 	err = mapstructure.Decode(exp, &stoleOrder)
 	if err != nil || stoleOrder < 0 || stoleOrder > len(g.Players) || !stealChoices[stoleOrder] {
-		stoleOrder = int(stealPlayers[rand.Intn(len(stealPlayers))].Order)
+		stoleOrder = int(stealPlayers[g.Rand().Intn(len(stealPlayers))].Order)
 	}
 
 	stoleFrom := g.Players[stoleOrder]
@@ -579,7 +581,7 @@ func (g *Game) UseProgressCoinSpy(p *entities.Player, dry bool) error {
 	}
 
 	if stealCard == 0 {
-		stealCard = *stoleFrom.CurrentHand.ChooseRandomDevCardType()
+		stealCard = *stoleFrom.CurrentHand.ChooseRandomDevCardType(g.Rand())
 	}
 
 	stealDeck := stoleFrom.CurrentHand.GetDevelopmentCardDeck(stealCard)
@@ -3,7 +3,6 @@ package game
 import (
 	"errors"
 	"sakura/entities"
-	"math/rand"
 
 	"github.com/mitchellh/mapstructure"
 )
@@ -31,8 +30,8 @@ func (g *Game) UseProgressPaperAlchemist(p *entities.Player, dry bool) error {
 	err = mapstructure.Decode(exp, &res)
 	if err != nil || res == nil || len(res) != 2 || res[0] <= 0 || res[0] > 6 || res[1] <= 0 || res[1] > 6 {
 		res = make([]int, 2)
-		res[0] = rand.Intn(6) + 1
-		res[1] = rand.Intn(6) + 1
+		res[0] = g.Rand().Intn(6) + 1
+		res[1] = g.Rand().Intn(6) + 1
 	}
 	redRoll := res[0]
 	whiteRoll := res[1]
@@ -125,15 +124,15 @@ func (g *Game) UseProgressPaperEngineer(p *entities.Player, dry bool) error {
 
 	// Give the player resources
 	// Do not check the bank
-	p.CurrentHand.UpdateResources(0, 2, 0, 0, 0)
-	g.j.WUpdateResources(p, 0, 2, 0, 0, 0)
+	cost, _ := g.BuildRules.GetCost(entities.BTWall)
+	g.lendCost(p, cost, 1)
 
 	err = g.BuildWall(p, vertex.C)
 	if err != nil {
-		p.CurrentHand.UpdateResources(0, -2, 0, 0, 0)
-		g.j.WUpdateResources(p, 0, -2, 0, 0, 0)
+		g.lendCost(p, cost, -1)
 		return err
 	}
+	g.repayCost(p, cost)
 
 	g.BroadcastDevCardUse(entities.ProgressPaperEngineer, DevCardShowTime, -1)
 	g.ReinsertDevelopmentCard(p, entities.ProgressPaperEngineer, false)
@@ -151,7 +150,7 @@ func (g *Game) UseProgressPaperInventor(p *entities.Player, dry bool) error {
 
 	tiles1 := make([]*entities.Tile, 0)
 	tiles2 := make([]*entities.Tile, 0)
-	for _, t := range g.Tiles {
+	for _, t := range g.sortedTiles() {
 		if t.Number > 1 && t.Number != 6 && t.Number != 8 && t.Number != 2 && t.Number != 12 && !t.Fog {
 			tiles1 = append(tiles1, t)
 		}
@@ -172,7 +171,7 @@ func (g *Game) UseProgressPaperInventor(p *entities.Player, dry bool) error {
 	err = mapstructure.Decode(exp, &resp1)
 	selTile1 := g.Graph.Tiles[resp1]
 	if err != nil {
-		selTile1 = tiles1[rand.Intn(len(tiles1))]
+		selTile1 = tiles1[g.Rand().Intn(len(tiles1))]
 	}
 
 	{ // Check if valid
@@ -210,7 +209,7 @@ func (g *Game) UseProgressPaperInventor(p *entities.Player, dry bool) error {
 	selTile2 := g.Graph.Tiles[resp2]
 
 	if err != nil {
-		selTile2 = tiles2[rand.Intn(len(tiles2))]
+		selTile2 = tiles2[g.Rand().Intn(len(tiles2))]
 	}
 
 	{ // Check if valid
@@ -238,7 +237,16 @@ func (g *Game) UseProgressPaperInventor(p *entities.Player, dry bool) error {
 }
 
 func (g *Game) UseProgressPaperMedicine(p *entities.Player, dry bool) error {
-	if !p.CurrentHand.HasResources(0, 0, 0, 1, 2) {
+	// One wheat and one ore off the price of a town
+	cost, _ := g.BuildRules.GetCost(entities.BTCity)
+	discount := entities.BuildCost{entities.CardTypeWheat: 1, entities.CardTypeOre: 1}
+	for ct := range discount {
+		if discount[ct] > cost[ct] {
+			discount[ct] = cost[ct]
+		}
+		cost[ct] -= discount[ct]
+	}
+	if !p.CurrentHand.HasCost(cost) {
 		return errors.New("not enough resources")
 	}
 
@@ -276,15 +284,14 @@ func (g *Game) UseProgressPaperMedicine(p *entities.Player, dry bool) error {
 
 	// Make sure the player has cards
 	// Do not check the bank
-	p.CurrentHand.UpdateResources(0, 0, 0, 1, 1)
-	g.j.WUpdateResources(p, 0, 0, 0, 1, 1)
+	g.lendCost(p, discount, 1)
 
 	err = g.BuildCity(p, loc)
 	if err != nil {
-		p.CurrentHand.UpdateResources(0, 0, 0, -1, -1)
-		g.j.WUpdateResources(p, 0, 0, 0, -1, -1)
+		g.lendCost(p, discount, -1)
 		return err
 	}
+	g.repayCost(p, discount)
 
 	g.BroadcastDevCardUse(entities.ProgressPaperMedicine, DevCardShowTime, -1)
 	g.ReinsertDevelopmentCard(p, entities.ProgressPaperMedicine, false)
@@ -294,7 +301,7 @@ func (g *Game) UseProgressPaperMedicine(p *entities.Player, dry bool) error {
 func (g *Game) UseProgressPaperIrrigation(p *entities.Player, dry bool) error {
 	count := 0
 	tiles := make([]*entities.Tile, 0)
-	for _, t := range g.Tiles {
+	for _, t := range g.sortedTiles() {
 		if t.Type != entities.TileTypeWheat {
 			continue
 		}
@@ -354,7 +361,7 @@ func (g *Game) UseProgressPaperIrrigation(p *entities.Player, dry bool) error {
 func (g *Game) UseProgressPaperMining(p *entities.Player, dry bool) error {
 	count := 0
 	tiles := make([]*entities.Tile, 0)
-	for _, t := range g.Tiles {
+	for _, t := range g.sortedTiles() {
 		if t.Type != entities.TileTypeOre {
 			continue
 		}
@@ -412,12 +419,16 @@ func (g *Game) UseProgressPaperMining(p *entities.Player, dry bool) error {
 }
 
 func (g *Game) UseProgressPaperRoadBuilding(p *entities.Player, dry bool) error {
-	if p.BuildablesLeft[entities.BTRoad] <= 0 {
-		return errors.New("not enough pieces to build")
-	}
+	// Ships can be built instead of roads with Seafarers
+	canShip := g.Mode.HasSeafarers() && p.BuildablesLeft[entities.BTShip] > 0 && len(p.GetBuildLocationsShip(g.Graph)) > 0
+	if !canShip {
+		if p.BuildablesLeft[entities.BTRoad] <= 0 {
+			return errors.New("not enough pieces to build")
+		}
 
-	if len(p.GetBuildLocationsRoad(g.Graph, false)) == 0 {
-		return errors.New("no location to build")
+		if len(p.GetBuildLocationsRoad(g.Graph, false)) == 0 {
+			return errors.New("no location to build")
+		}
 	}
 
 	if dry {
@@ -500,9 +511,7 @@ func (g *Game) UseProgressPaperSmith(p *entities.Player, dry bool) error {
 	}
 
 	// Upgrade knight at v1
-	p.CurrentHand.UpdateResources(0, 0, 1, 0, 1)
-	g.j.WUpdateResources(p, 0, 0, 1, 0, 1)
-	g.BuildKnight(p, v1.C)
+	g.smithUpgrade(p, v1)
 
 	g.ReinsertDevelopmentCard(p, entities.ProgressPaperSmith, false)
 
@@ -533,16 +542,31 @@ func (g *Game) UseProgressPaperSmith(p *entities.Player, dry bool) error {
 	}
 	if !found {
 		if p.GetIsBot() {
-			v2 = vertices2[rand.Intn(len(vertices2))]
+			v2 = vertices2[g.Rand().Intn(len(vertices2))]
 		} else {
 			return nil
 		}
 	}
 
 	// upgrade knight at v2
-	p.CurrentHand.UpdateResources(0, 0, 1, 0, 1)
-	g.j.WUpdateResources(p, 0, 0, 1, 0, 1)
-	g.BuildKnight(p, v2.C)
+	g.smithUpgrade(p, v2)
 
 	return nil
 }
+
+// smithUpgrade lends the price of the next knight level and upgrades the
+// knight on the vertex with it, so the upgrade is free
+func (g *Game) smithUpgrade(p *entities.Player, v *entities.Vertex) {
+	t := entities.BTKnight2
+	if v.Placement != nil && v.Placement.GetType() == entities.BTKnight2 {
+		t = entities.BTKnight3
+	}
+
+	cost, _ := g.BuildRules.GetCost(t)
+	g.lendCost(p, cost, 1)
+	if err := g.BuildKnight(p, v.C); err != nil {
+		g.lendCost(p, cost, -1)
+		return
+	}
+	g.repayCost(p, cost)
+}
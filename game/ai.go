@@ -3,10 +3,10 @@ package game
 import (
 	"log"
 	"math"
-	"math/rand"
 	"sakura/entities"
 )
 
+// AI is the normal bot strategy
 type AI struct {
 	g *Game
 
@@ -19,6 +19,65 @@ type AI struct {
 	robberOnMe    int
 	barbarianBad  int
 	failedDev     map[entities.DevelopmentCardType]bool
+
+	// Offers to other players a turn and the chance in percent added to
+	// trading for a build more than one card away
+	maxTradeCheck int
+	tradeBonus    int
+
+	// Refuse offers of a player well ahead
+	refuseLeader bool
+
+	// Who refused the trades of the bot this turn, and the offers it
+	// already countered
+	refusedBy map[uint16]bool
+	refused   map[tradeRefusal]bool
+	countered map[int]bool
+}
+
+func newAI(g *Game) *AI {
+	ai := &AI{g: g, maxTradeCheck: 4}
+	ai.Reset()
+	return ai
+}
+
+func (ai *AI) Level() string {
+	return entities.BotLevelNormal
+}
+
+func (ai *AI) Clone(g *Game) BotStrategy {
+	c := *ai
+	c.g = g
+	c.failedDev = make(map[entities.DevelopmentCardType]bool)
+	for t, failed := range ai.failedDev {
+		c.failedDev[t] = failed
+	}
+	c.refusedBy = make(map[uint16]bool)
+	for order, refused := range ai.refusedBy {
+		c.refusedBy[order] = refused
+	}
+	c.refused = make(map[tradeRefusal]bool)
+	for r, refused := range ai.refused {
+		c.refused[r] = refused
+	}
+	c.countered = make(map[int]bool)
+	for id, countered := range ai.countered {
+		c.countered[id] = countered
+	}
+	return &c
+}
+
+func (ai *AI) ChooseSetupSettlement(p *entities.Player, allowed []*entities.Vertex) *entities.Vertex {
+	return ai.ChooseBestVertexSettlement(p, allowed)
+}
+
+func (ai *AI) ChooseSetupRoad(p *entities.Player, allowed []*entities.Edge) *entities.Edge {
+	return ai.ChooseBestEdgeRoad(p, allowed)
+}
+
+// ChooseDiscard throws random cards, like a player that lets the timer run out
+func (ai *AI) ChooseDiscard(p *entities.Player, quantity int) [9]int {
+	return randomDiscard(ai.g, p, quantity)
 }
 
 type TileScoreMap = map[entities.TileType]float64
@@ -30,8 +89,10 @@ func (ai *AI) ChooseBestVertexSettlement(p *entities.Player, allowed []*entities
 
 	maxScore := -9999.0
 	maxScoreVertex := allowed[0]
-	for v, s := range ai.getVertexSettlementScoreMap(p, allowed) {
-		if s > maxScore {
+	// Walk the sorted list so ties always go the same way
+	scores := ai.getVertexSettlementScoreMap(p, allowed)
+	for _, v := range allowed {
+		if s := scores[v]; s > maxScore {
 			maxScore = s
 			maxScoreVertex = v
 		}
@@ -65,13 +126,13 @@ func (ai *AI) getNumberScore(num uint16) float64 {
 	return 6.0 - math.Abs(float64(num)-7.0)
 }
 
-func (ai *AI) GetRobberTile(p *entities.Player, tiles []*entities.Tile) *entities.Tile {
+func (ai *AI) ChooseRobberTile(p *entities.Player, tiles []*entities.Tile) *entities.Tile {
 	// Invalid coordinates, place at best position
 	maxScore := 0.0
 	selTile := tiles[0]
 
 	for _, t := range tiles {
-		if t.Type == entities.TileTypeDesert {
+		if t.Type == entities.TileTypeDesert || ai.g.robberProtectsTile(t) {
 			continue
 		}
 
@@ -172,8 +233,9 @@ func (ai *AI) ChooseBestEdgeRoad(p *entities.Player, allowed []*entities.Edge) *
 		return nil
 	}
 
-	currVert := p.GetBuildLocationsSettlement(ai.g.Graph, false, false) // currently possible locations
-	allVert := p.GetBuildLocationsSettlement(ai.g.Graph, true, true)    // all possible locations
+	// Currently possible locations and all possible locations
+	currVert := ai.g.GetBuildLocationsSettlement(p)
+	allVert := ai.g.applySettlementScenarioHooks(p, p.GetBuildLocationsSettlement(ai.g.Graph, true, true))
 
 	currScoreMap := ai.getVertexSettlementScoreMap(p, currVert)
 	allScoreMap := ai.getVertexSettlementScoreMap(p, allVert)
@@ -187,7 +249,7 @@ func (ai *AI) ChooseBestEdgeRoad(p *entities.Player, allowed []*entities.Edge) *
 	maxScoreEdge := allowed[0]
 
 	for _, e := range allowed {
-		s := ai.g.ai.getEdgeRoadScore(p, e, currScoreMap, allScoreMap, allowedMap, 3)
+		s := ai.getEdgeRoadScore(p, e, currScoreMap, allScoreMap, allowedMap, 3)
 		if s > maxScore {
 			maxScore = s
 			maxScoreEdge = e
@@ -234,20 +296,15 @@ func (ai *AI) getEdgeRoadScore(
 			}
 			allowedMap[adje] = true
 			defer delete(allowedMap, adje)
-			score += 0.4 * ai.g.ai.getEdgeRoadScore(p, adje, currScoreMap, allScoreMap, allowedMap, dfsMaxDepth-1)
+			score += 0.4 * ai.getEdgeRoadScore(p, adje, currScoreMap, allScoreMap, allowedMap, dfsMaxDepth-1)
 		}
 	}
 
 	return score
 }
 
-func (ai *AI) tickPlayer(p *entities.Player) bool {
-	if p.PendingAction != nil {
-		p.SendExpect(nil)
-		return true
-	}
-
-	if ai.g.HasPlayerPendingAction() {
+func (ai *AI) Tick(p *entities.Player) bool {
+	if ai.g.botWaiting(p) {
 		return true
 	}
 
@@ -258,7 +315,7 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 	getLocs := func() {
 		if cityLocs == nil {
 			cityLocs = p.GetBuildLocationsCity(ai.g.Graph)
-			settlementLocs = p.GetBuildLocationsSettlement(ai.g.Graph, false, false)
+			settlementLocs = ai.g.GetBuildLocationsSettlement(p)
 		}
 	}
 
@@ -292,10 +349,18 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 		scoreType := func(ct entities.CardType, want int, priority float64) {
 			excess := int(p.CurrentHand.GetCardDeck(ct).Quantity) - want
 			if excess > 0 {
-				excess = rand.Intn(excess + 1)
+				excess = ai.g.Rand().Intn(excess + 1)
 			}
 			score += float64(excess+gain[ct]) * priority
 		}
+		scoreCost := func(t entities.BuildableType) {
+			cost, _ := ai.g.BuildRules.GetCost(t)
+			for ct, q := range cost {
+				if q > 0 {
+					scoreType(entities.CardType(ct), q, 1)
+				}
+			}
+		}
 
 		for i, q := range offer.Details.Ask {
 			deck := p.CurrentHand.GetCardDeck(entities.CardType(i))
@@ -308,7 +373,7 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 
 			score -= float64(lose[i])
 
-			if ai.g.Mode == entities.CitiesAndKnights {
+			if ai.g.Mode.HasCitiesAndKnights() {
 				if ai.barbarianBad == 1 {
 					if p.HasInactiveKnight() {
 						scoreType(entities.CardTypeWheat, 1, 2)
@@ -320,23 +385,18 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 			}
 
 			if len(cityLocs) > 0 && p.BuildablesLeft[entities.BTCity] > 0 {
-				scoreType(entities.CardTypeWheat, 2, 1)
-				scoreType(entities.CardTypeOre, 3, 1)
+				scoreCost(entities.BTCity)
 			}
 
 			if len(settlementLocs) > 0 && p.BuildablesLeft[entities.BTSettlement] > 0 {
-				scoreType(entities.CardTypeWood, 1, 1)
-				scoreType(entities.CardTypeBrick, 1, 1)
-				scoreType(entities.CardTypeWool, 1, 1)
-				scoreType(entities.CardTypeWheat, 1, 1)
+				scoreCost(entities.BTSettlement)
 			}
 
 			if len(settlementLocs) == 0 {
-				scoreType(entities.CardTypeWood, 1, 1)
-				scoreType(entities.CardTypeBrick, 1, 1)
+				scoreCost(entities.BTRoad)
 			}
 
-			if ai.g.Mode == entities.CitiesAndKnights {
+			if ai.g.Mode.HasCitiesAndKnights() {
 				if i >= int(entities.CardTypePaper) {
 					score -= float64(lose[i])
 					score += float64(2 * gain[i])
@@ -351,10 +411,14 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 	if ai.g.CurrentPlayer != p {
 		for _, o := range ai.g.CurrentOffers {
 			if o.Acceptances[p.Order] == 0 {
-				if offerScore(o) > 0 {
+				leaderOver := ai.refuseLeader && ai.g.isLeaderOver(o.CreatedBy, p)
+				if offerScore(o) > 0 && !leaderOver {
 					ai.g.AcceptOffer(o.Id, p)
 				} else {
 					ai.g.RejectOffer(o.Id, p)
+					if !leaderOver {
+						ai.counterOffer(p, o)
+					}
 				}
 			}
 		}
@@ -368,7 +432,7 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 	currentOffers := make([]*entities.TradeOfferDetails, 0)
 
 	// Bring hand to this point if one card missing
-	convergeHand := func(want [9]int, bank bool, priority int) {
+	convergeHand := func(want entities.BuildCost, bank bool, priority int) {
 		missingCards := 0
 		for i, q := range want {
 			deck := p.CurrentHand.GetCardDeck(entities.CardType(i))
@@ -380,7 +444,7 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 				missingCards += q - int(deck.Quantity)
 			}
 		}
-		if missingCards != 1 && rand.Intn(100) > priority {
+		if missingCards != 1 && ai.g.Rand().Intn(100) > priority+ai.tradeBonus {
 			return
 		}
 
@@ -396,13 +460,14 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 				continue
 			}
 
-			for t, giveDeck := range p.CurrentHand.CardDeckMap {
+			for _, t := range sortedCardTypes(p.CurrentHand) {
+				giveDeck := p.CurrentHand.CardDeckMap[t]
 				if giveDeck.Quantity <= 0 {
 					continue
 				}
 
-				if ai.g.Mode == entities.CitiesAndKnights {
-					if t >= entities.CardTypePaper && rand.Intn(10) >= 3 {
+				if ai.g.Mode.HasCitiesAndKnights() {
+					if t >= entities.CardTypePaper && ai.g.Rand().Intn(10) >= 3 {
 						continue
 					}
 				}
@@ -431,7 +496,7 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 		// iterate over currentOffers
 		for len(currentOffers) > 0 && len(ai.g.CurrentOffers) < 4 {
 			// get a random offer from currentoffers and remove
-			oid := rand.Intn(len(currentOffers))
+			oid := ai.g.Rand().Intn(len(currentOffers))
 			offer := currentOffers[oid]
 
 			// remove oid-th element from currentOffers
@@ -450,7 +515,29 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 		return false
 	}
 
-	if ai.g.Mode == entities.CitiesAndKnights {
+	// Attack the pirate fortress whenever a ship is next to it
+	if ai.g.CanAttackPirateFortress(p) == nil {
+		if err := ai.g.AttackPirateFortress(p); err != nil {
+			log.Println("[BUG] Bot failed to attack fortress", err)
+		}
+		return true
+	}
+
+	// Build the wonder before anything else, claim one as soon as possible
+	if ai.g.CanBuildWonderStage(p) == nil {
+		if err := ai.g.BuildWonderStage(p); err != nil {
+			log.Println("[BUG] Bot failed to build wonder", err)
+		}
+		return true
+	}
+	if wonders := ai.g.getClaimableWonders(p); len(wonders) > 0 {
+		if err := ai.g.ClaimWonder(p, wonders[0]); err != nil {
+			log.Println("[BUG] Bot failed to claim wonder", err)
+		}
+		return true
+	}
+
+	if ai.g.Mode.HasCitiesAndKnights() {
 		for _, it := range [3]entities.CardType{entities.CardTypePaper, entities.CardTypeCloth, entities.CardTypeCoin} {
 			if ai.g.CanBuildImprovement(p, it) == nil {
 				if err := ai.g.BuildCityImprovement(p, it); err != nil {
@@ -461,11 +548,11 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 		}
 
 		// Activate knight
-		if p.CurrentHand.HasResources(0, 0, 0, 1, 0) {
+		if p.CurrentHand.HasCost(ai.g.BuildRules.GetActivateKnightCost()) {
 			locs := p.GetActivateLocationsKnight(ai.g.Graph)
 			if len(locs) > 0 {
-				loc := locs[rand.Intn(len(locs))]
-				if err := ai.g.ai.g.ActivateKnight(p, loc.C); err != nil {
+				loc := locs[ai.g.Rand().Intn(len(locs))]
+				if err := ai.g.ActivateKnight(p, loc.C); err != nil {
 					log.Println("[BUG]: bot failed to activate knight", err)
 				}
 				recalculateBarbarianBad()
@@ -474,10 +561,10 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 		}
 
 		// Build and upgrade knight
-		if !ai.noBuyDevCard && p.CanBuild(entities.BTKnight1) == nil {
+		if !ai.noBuyDevCard && p.CanBuild(entities.BTKnight1, ai.g.BuildRules) == nil {
 			if len(settlementLocs) > 0 || len(cityLocs) > 0 {
 				// Save the cards to build settlement/city
-				if rand.Intn(8) >= 3 &&
+				if ai.g.Rand().Intn(8) >= 3 &&
 					p.CurrentHand.GetCardCount() < ai.g.GetDiscardLimit(p) &&
 					(ai.barbarianBad != 1 || p.HasInactiveKnight()) {
 					ai.noBuyDevCard = true
@@ -487,7 +574,7 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 
 			locs := p.GetBuildLocationsKnight(ai.g.Graph, true)
 			if len(locs) > 0 {
-				loc := locs[rand.Intn(len(locs))]
+				loc := locs[ai.g.Rand().Intn(len(locs))]
 
 				// Try to find a place where settlement cant be built
 				settlementLocsMap := make(map[*entities.Vertex]bool)
@@ -501,7 +588,7 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 					}
 				}
 				if len(nonIntrusiveLocs) > 0 {
-					loc = nonIntrusiveLocs[rand.Intn(len(nonIntrusiveLocs))]
+					loc = nonIntrusiveLocs[ai.g.Rand().Intn(len(nonIntrusiveLocs))]
 				}
 
 				if err := ai.g.BuildKnight(p, loc.C); err != nil {
@@ -532,7 +619,7 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 		}
 	}
 
-	if len(cityLocs) > 0 && p.CanBuild(entities.BTCity) == nil {
+	if len(cityLocs) > 0 && p.CanBuild(entities.BTCity, ai.g.BuildRules) == nil {
 		vertex := ai.ChooseBestVertexSettlement(p, cityLocs)
 		if err := ai.g.BuildCity(p, vertex.C); err != nil {
 			log.Println("[BUG] Bot failed to build city", err)
@@ -541,7 +628,7 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 		return true
 	}
 
-	if len(settlementLocs) > 0 && p.CanBuild(entities.BTSettlement) == nil {
+	if len(settlementLocs) > 0 && p.CanBuild(entities.BTSettlement, ai.g.BuildRules) == nil {
 		vertex := ai.ChooseBestVertexSettlement(p, settlementLocs)
 		if err := ai.g.BuildSettlement(p, vertex.C); err != nil {
 			log.Println("[BUG] Bot failed to build settlement", err)
@@ -553,12 +640,12 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 	// Trade
 	tradeCheck := func(bank bool) bool {
 		ai.numTradeCheck++
-		if !bank && ai.numTradeCheck > 4 {
+		if !bank && ai.numTradeCheck > ai.maxTradeCheck {
 			return false
 		}
 
 		if p.CurrentHand.GetCardCount() > 0 {
-			if ai.g.Mode == entities.CitiesAndKnights {
+			if ai.g.Mode.HasCitiesAndKnights() {
 				if ai.barbarianBad == 1 {
 					hand := [9]int{0, 0, 0, 0, 0, 0, 0, 0, 0}
 					if p.HasInactiveKnight() {
@@ -572,14 +659,16 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 			}
 
 			if len(cityLocs) > 0 && p.BuildablesLeft[entities.BTCity] > 0 {
-				convergeHand([9]int{0, 0, 0, 0, 2, 3, 0, 0, 0}, bank, 30)
+				cost, _ := ai.g.BuildRules.GetCost(entities.BTCity)
+				convergeHand(cost, bank, 30)
 			}
 			if len(settlementLocs) > 0 && p.BuildablesLeft[entities.BTSettlement] > 0 {
-				convergeHand([9]int{0, 1, 1, 1, 1, 0, 0, 0, 0}, bank, 20)
-
+				cost, _ := ai.g.BuildRules.GetCost(entities.BTSettlement)
+				convergeHand(cost, bank, 20)
 			}
 			if p.BuildablesLeft[entities.BTRoad] > 0 {
-				convergeHand([9]int{0, 1, 1, 0, 0, 0, 0, 0, 0}, bank, 10)
+				cost, _ := ai.g.BuildRules.GetCost(entities.BTRoad)
+				convergeHand(cost, bank, 10)
 			}
 
 			if executeHand(bank) {
@@ -591,7 +680,9 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 
 	// Player trade
 	if !ai.tradeChecked {
-		tradeCheck(false)
+		if !ai.proposeTrades(p) {
+			tradeCheck(false)
+		}
 		ai.tradeChecked = true
 		return true
 	} else {
@@ -618,7 +709,7 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 				}
 
 				if len(acceptors) > 0 {
-					aorder := acceptors[rand.Intn(len(acceptors))]
+					aorder := acceptors[ai.g.Rand().Intn(len(acceptors))]
 					if offerScore(o) > 0 {
 						err := ai.g.CloseOffer(o.Id, p, uint16(aorder))
 						if err == nil {
@@ -630,6 +721,12 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 				}
 			}
 			ai.g.BroadcastMessage(&entities.Message{Type: entities.MessageTypeTradeCloseOffers})
+
+			// Sweeten the trades everyone refused
+			if ai.proposeTrades(p) {
+				ai.tradeTime = 6
+				return true
+			}
 		} else {
 			return true
 		}
@@ -640,10 +737,10 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 		return true
 	}
 
-	if !ai.noBuildRoad && p.CanBuild(entities.BTRoad) == nil {
+	if !ai.noBuildRoad && p.CanBuild(entities.BTRoad, ai.g.BuildRules) == nil {
 		if len(settlementLocs) > 0 {
 			// Save the cards to build settlement
-			if rand.Intn(10) >= 2 && p.CurrentHand.GetCardCount() < ai.g.GetDiscardLimit(p)+2 {
+			if ai.g.Rand().Intn(10) >= 2 && p.CurrentHand.GetCardCount() < ai.g.GetDiscardLimit(p)+2 {
 				ai.noBuildRoad = true
 				return true
 			}
@@ -663,29 +760,21 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 	if ai.g.Mode == entities.Base && !ai.noBuyDevCard {
 		if len(settlementLocs) > 0 || len(cityLocs) > 0 {
 			// Save the cards to build settlement/city
-			if rand.Intn(8) >= 3 && p.CurrentHand.GetCardCount() < ai.g.GetDiscardLimit(p) {
+			if ai.g.Rand().Intn(8) >= 3 && p.CurrentHand.GetCardCount() < ai.g.GetDiscardLimit(p) {
 				ai.noBuyDevCard = true
 				return true
 			}
 		}
 
-		if p.CanBuyDevelopmentCard() {
+		if p.CanBuyDevelopmentCard(ai.g.BuildRules) {
 			ai.g.BuyDevelopmentCard(p)
 			return true
 		}
 	}
 
-	devCards := make([]entities.DevelopmentCardType, 0)
-	for t, deck := range p.CurrentHand.DevelopmentCardDeckMap {
-		if deck.CanUse && !ai.failedDev[t] {
-			for i := 0; i < int(deck.Quantity); i++ {
-				devCards = append(devCards, t)
-			}
-		}
-	}
-
+	devCards := usableDevCards(p, ai.failedDev)
 	if len(devCards) > 0 {
-		dc := devCards[rand.Intn(len(devCards))]
+		dc := devCards[ai.g.Rand().Intn(len(devCards))]
 		err := ai.g.UseDevelopmentCard(p, dc)
 		if err != nil {
 			ai.failedDev[dc] = true
@@ -693,10 +782,10 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 		return true
 	}
 
-	if ai.g.Mode == entities.CitiesAndKnights && !ai.noBuildWall && p.CanBuild(entities.BTWall) == nil {
+	if ai.g.Mode.HasCitiesAndKnights() && !ai.noBuildWall && p.CanBuild(entities.BTWall, ai.g.BuildRules) == nil {
 		if len(settlementLocs) > 0 {
 			// Save the cards to build settlement
-			if rand.Intn(8) >= 3 && p.CurrentHand.GetCardCount() < ai.g.GetDiscardLimit(p) {
+			if ai.g.Rand().Intn(8) >= 3 && p.CurrentHand.GetCardCount() < ai.g.GetDiscardLimit(p) {
 				ai.noBuildWall = true
 				return true
 			}
@@ -704,7 +793,7 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 
 		vertices := p.GetBuildLocationsWall(ai.g.Graph)
 		if len(vertices) > 0 {
-			v := vertices[rand.Intn(len(vertices))]
+			v := vertices[ai.g.Rand().Intn(len(vertices))]
 			if err := ai.g.BuildWall(p, v.C); err != nil {
 				log.Println("[BUG] Bot failed to build wall", err)
 				return false
@@ -716,23 +805,6 @@ func (ai *AI) tickPlayer(p *entities.Player) bool {
 	return false
 }
 
-func (ai *AI) Tick() bool {
-	if !ai.g.Initialized ||
-		ai.g.GameOver ||
-		ai.g.DiceState == 0 ||
-		ai.g.InitPhase {
-		return false
-	}
-
-	acted := false
-	for _, p := range ai.g.Players {
-		if p.GetIsBot() {
-			acted = ai.tickPlayer(p) || acted
-		}
-	}
-	return acted
-}
-
 func (ai *AI) Reset() {
 	ai.noBuildRoad = false
 	ai.noBuildWall = false
@@ -743,4 +815,7 @@ func (ai *AI) Reset() {
 	ai.robberOnMe = 0
 	ai.barbarianBad = 0
 	ai.failedDev = make(map[entities.DevelopmentCardType]bool)
+	ai.refusedBy = make(map[uint16]bool)
+	ai.refused = make(map[tradeRefusal]bool)
+	ai.countered = make(map[int]bool)
 }
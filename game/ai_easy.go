@@ -0,0 +1,206 @@
+package game
+
+import (
+	"log"
+	"sakura/entities"
+)
+
+// easyAI builds wherever it can, moves the robber anywhere and never
+// starts a trade
+type easyAI struct {
+	g *Game
+
+	playedDev bool
+	failedDev map[entities.DevelopmentCardType]bool
+}
+
+func newEasyAI(g *Game) *easyAI {
+	ai := &easyAI{g: g}
+	ai.Reset()
+	return ai
+}
+
+func (ai *easyAI) Level() string {
+	return entities.BotLevelEasy
+}
+
+func (ai *easyAI) Reset() {
+	ai.playedDev = false
+	ai.failedDev = make(map[entities.DevelopmentCardType]bool)
+}
+
+func (ai *easyAI) Clone(g *Game) BotStrategy {
+	c := *ai
+	c.g = g
+	c.failedDev = make(map[entities.DevelopmentCardType]bool)
+	for t, failed := range ai.failedDev {
+		c.failedDev[t] = failed
+	}
+	return &c
+}
+
+func (ai *easyAI) ChooseSetupSettlement(p *entities.Player, allowed []*entities.Vertex) *entities.Vertex {
+	if len(allowed) == 0 {
+		return nil
+	}
+	return allowed[ai.g.Rand().Intn(len(allowed))]
+}
+
+func (ai *easyAI) ChooseSetupRoad(p *entities.Player, allowed []*entities.Edge) *entities.Edge {
+	if len(allowed) == 0 {
+		return nil
+	}
+	return allowed[ai.g.Rand().Intn(len(allowed))]
+}
+
+// ChooseRobberTile picks any tile the bot does not build on
+func (ai *easyAI) ChooseRobberTile(p *entities.Player, tiles []*entities.Tile) *entities.Tile {
+	choices := make([]*entities.Tile, 0, len(tiles))
+	for _, t := range tiles {
+		if ai.g.robberProtectsTile(t) {
+			continue
+		}
+
+		own := false
+		for _, vp := range ai.g.Graph.GetTilePlacements(t) {
+			own = own || vp.GetOwner() == p
+		}
+		if !own {
+			choices = append(choices, t)
+		}
+	}
+
+	if len(choices) == 0 {
+		return tiles[0]
+	}
+	return choices[ai.g.Rand().Intn(len(choices))]
+}
+
+func (ai *easyAI) ChooseDiscard(p *entities.Player, quantity int) [9]int {
+	return randomDiscard(ai.g, p, quantity)
+}
+
+func (ai *easyAI) ChooseMerchantLocation(p *entities.Player, allowed []*entities.Tile) *entities.Tile {
+	return allowed[ai.g.Rand().Intn(len(allowed))]
+}
+
+// ChooseMerchantFleet takes the type of a random card of the hand
+func (ai *easyAI) ChooseMerchantFleet(p *entities.Player) [9]int {
+	ans := randomDiscard(ai.g, p, 1)
+	if ans == ([9]int{}) {
+		ans[entities.CardTypeWood] = 1
+	}
+	return ans
+}
+
+// isGenerous checks if the offer gives the bot more cards than it asks
+func (ai *easyAI) isGenerous(p *entities.Player, offer *entities.TradeOffer) bool {
+	gain, lose := 0, 0
+	for i, q := range offer.Details.Ask {
+		deck := p.CurrentHand.GetCardDeck(entities.CardType(i))
+		if q > 0 && (deck == nil || int(deck.Quantity) < q) {
+			return false
+		}
+		gain += offer.Details.Give[i]
+		lose += q
+	}
+	return gain > lose
+}
+
+func (ai *easyAI) Tick(p *entities.Player) bool {
+	g := ai.g
+	if g.botWaiting(p) {
+		return true
+	}
+
+	if g.CurrentPlayer != p {
+		for _, o := range g.CurrentOffers {
+			if o.CreatedBy == p.Order || o.Acceptances[p.Order] != 0 {
+				continue
+			}
+			if ai.isGenerous(p, o) {
+				g.AcceptOffer(o.Id, p)
+			} else {
+				g.RejectOffer(o.Id, p)
+			}
+		}
+		return false
+	}
+
+	if locs := p.GetBuildLocationsCity(g.Graph); len(locs) > 0 && p.CanBuild(entities.BTCity, g.BuildRules) == nil {
+		v := locs[g.Rand().Intn(len(locs))]
+		if err := g.BuildCity(p, v.C); err != nil {
+			log.Println("[BUG] Bot failed to build city", err)
+			return false
+		}
+		return true
+	}
+
+	if locs := g.GetBuildLocationsSettlement(p); len(locs) > 0 && p.CanBuild(entities.BTSettlement, g.BuildRules) == nil {
+		v := locs[g.Rand().Intn(len(locs))]
+		if err := g.BuildSettlement(p, v.C); err != nil {
+			log.Println("[BUG] Bot failed to build settlement", err)
+			return false
+		}
+		return true
+	}
+
+	if g.Mode.HasCitiesAndKnights() {
+		for _, it := range [3]entities.CardType{entities.CardTypePaper, entities.CardTypeCloth, entities.CardTypeCoin} {
+			if g.CanBuildImprovement(p, it) == nil {
+				if err := g.BuildCityImprovement(p, it); err != nil {
+					log.Println("[BUG] Bot failed to build improvement", err)
+				}
+				return true
+			}
+		}
+
+		if locs := p.GetBuildLocationsKnight(g.Graph, true); len(locs) > 0 && p.CanBuild(entities.BTKnight1, g.BuildRules) == nil {
+			v := locs[g.Rand().Intn(len(locs))]
+			if err := g.BuildKnight(p, v.C); err != nil {
+				log.Println("[BUG] Bot failed to build knight", err)
+			}
+			return true
+		}
+
+		if locs := p.GetActivateLocationsKnight(g.Graph); len(locs) > 0 && p.CurrentHand.HasCost(g.BuildRules.GetActivateKnightCost()) {
+			v := locs[g.Rand().Intn(len(locs))]
+			if err := g.ActivateKnight(p, v.C); err != nil {
+				log.Println("[BUG] Bot failed to activate knight", err)
+			}
+			return true
+		}
+	}
+
+	// Half the time the bot keeps its cards for later
+	if g.Rand().Intn(2) == 0 && p.CanBuild(entities.BTRoad, g.BuildRules) == nil {
+		if edges := p.GetBuildLocationsRoad(g.Graph, false); len(edges) > 0 {
+			e := edges[g.Rand().Intn(len(edges))]
+			if err := g.BuildRoad(p, e.C); err != nil {
+				log.Println("[BUG] Bot failed to build road", err)
+				return false
+			}
+			return true
+		}
+	}
+
+	if g.Mode == entities.Base && g.Rand().Intn(3) == 0 && p.CanBuyDevelopmentCard(g.BuildRules) {
+		g.BuyDevelopmentCard(p)
+		return true
+	}
+
+	// At most one card a turn, and only sometimes
+	if !ai.playedDev {
+		ai.playedDev = true
+		devCards := usableDevCards(p, ai.failedDev)
+		if len(devCards) > 0 && g.Rand().Intn(2) == 0 {
+			dc := devCards[g.Rand().Intn(len(devCards))]
+			if err := g.UseDevelopmentCard(p, dc); err != nil {
+				ai.failedDev[dc] = true
+			}
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,105 @@
+package game
+
+import (
+	"math"
+	"sakura/entities"
+)
+
+// hardAI plays the turn like the normal AI but trades more, keeps the
+// cards of its next build when it discards and goes after the leader
+// with the robber
+type hardAI struct {
+	*AI
+}
+
+func newHardAI(g *Game) *hardAI {
+	ai := newAI(g)
+	ai.maxTradeCheck = 6
+	ai.tradeBonus = 20
+	ai.refuseLeader = true
+	return &hardAI{AI: ai}
+}
+
+func (ai *hardAI) Level() string {
+	return entities.BotLevelHard
+}
+
+func (ai *hardAI) Clone(g *Game) BotStrategy {
+	return &hardAI{AI: ai.AI.Clone(g).(*AI)}
+}
+
+// ChooseRobberTile blocks the best number of the leader, and keeps away
+// from the tiles of the bot
+func (ai *hardAI) ChooseRobberTile(p *entities.Player, tiles []*entities.Tile) *entities.Tile {
+	leader := ai.g.getLeader(p)
+	if leader == nil {
+		return ai.AI.ChooseRobberTile(p, tiles)
+	}
+
+	var selTile *entities.Tile
+	maxScore := 0.0
+	for _, t := range tiles {
+		// Tiles without a number produce nothing to block
+		if t.Number == 0 || ai.g.robberProtectsTile(t) {
+			continue
+		}
+
+		score := 0.0
+		for _, vp := range ai.g.Graph.GetTilePlacements(t) {
+			weight := 0.0
+			switch vp.GetType() {
+			case entities.BTSettlement:
+				weight = 1
+			case entities.BTCity:
+				weight = 2
+			}
+
+			switch vp.GetOwner() {
+			case p:
+				weight *= -10
+			case leader:
+				weight *= 3
+			}
+			score += weight
+		}
+		score *= ai.getNumberScore(t.Number)
+
+		if score > maxScore {
+			selTile = t
+			maxScore = score
+		}
+	}
+
+	if selTile == nil {
+		return ai.AI.ChooseRobberTile(p, tiles)
+	}
+	return selTile
+}
+
+// ChooseDiscard throws the cards the next build needs least, the most
+// plentiful first
+func (ai *hardAI) ChooseDiscard(p *entities.Player, quantity int) [9]int {
+	var left [9]int
+	for t, deck := range p.CurrentHand.CardDeckMap {
+		left[t] = int(deck.Quantity)
+	}
+	keep := ai.nextBuildCost(p)
+
+	var discard [9]int
+	for ; quantity > 0; quantity-- {
+		best := -1
+		bestExcess := math.MinInt32
+		for t := range left {
+			if left[t] > 0 && left[t]-keep[t] > bestExcess {
+				best = t
+				bestExcess = left[t] - keep[t]
+			}
+		}
+		if best < 0 {
+			break
+		}
+		left[best]--
+		discard[best]++
+	}
+	return discard
+}
@@ -0,0 +1,424 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sakura/entities"
+	"time"
+)
+
+const (
+	// Searches of a live game stop after this long, or earlier when the
+	// turn timer runs low
+	mctsMaxThink = time.Second
+
+	// Searches of a headless game run a fixed number of simulations, so
+	// the same seed always gives the same game
+	mctsEngineIterations = 200
+
+	mctsMinIterations = 8
+	mctsMaxIterations = 400
+
+	// Moves of the turn searched before the rest is left to the rollout
+	mctsMaxDepth = 4
+
+	// Rounds each rollout plays past the current turn
+	mctsRolloutRounds = 3
+	mctsRolloutSteps  = 2000
+
+	mctsExploration = 0.7
+
+	// Victory points a settlement is worth for each pip of its numbers
+	mctsProductionWeight = 0.06
+)
+
+type (
+	// mctsAI searches its own turn with Monte Carlo tree search. Every
+	// simulation starts from a copy of the game where the cards the bot
+	// cannot see are dealt again, plays a few moves of the tree and has
+	// the bots of the copy play on for a couple of rounds. The search
+	// picks a move when it does better than leaving the turn to the hard
+	// bot, which also plays out of turn and answers prompts.
+	mctsAI struct {
+		*hardAI
+
+		// Simulations per search in headless games
+		iterations int
+
+		// The search left the rest of the turn to the hard bot
+		handedOff bool
+	}
+
+	// mctsMove is a move of the bot turn, applied to a copy of the game
+	mctsMove struct {
+		key string
+		do  func(g *Game, p *entities.Player) error
+	}
+
+	mctsNode struct {
+		visits   int
+		reward   float64
+		avail    int
+		children map[string]*mctsNode
+	}
+)
+
+// The move that leaves the rest of the turn to the hard bot
+const mctsHeuristic = "auto"
+
+func newMctsAI(g *Game) *mctsAI {
+	return &mctsAI{hardAI: newHardAI(g), iterations: mctsEngineIterations}
+}
+
+func (ai *mctsAI) Level() string {
+	return entities.BotLevelExpert
+}
+
+func (ai *mctsAI) Reset() {
+	ai.hardAI.Reset()
+	ai.handedOff = false
+}
+
+func (ai *mctsAI) Clone(g *Game) BotStrategy {
+	c := *ai
+	c.hardAI = ai.hardAI.Clone(g).(*hardAI)
+	return &c
+}
+
+func (ai *mctsAI) Tick(p *entities.Player) bool {
+	g := ai.g
+
+	// Copies of the game are played by the heuristics, a search never
+	// starts another one
+	if g.simulated || g.CurrentPlayer != p || g.SpecialBuildPhase || ai.handedOff {
+		return ai.hardAI.Tick(p)
+	}
+	if g.botWaiting(p) {
+		return true
+	}
+
+	move := ai.search(p)
+	if move == nil || move.key == mctsHeuristic {
+		ai.handedOff = true
+		return ai.hardAI.Tick(p)
+	}
+	if err := move.do(g, p); err != nil {
+		log.Println("[BUG] Bot failed to play", move.key, err)
+		ai.handedOff = true
+	}
+	return true
+}
+
+// search returns the move of the turn with the most visits
+func (ai *mctsAI) search(p *entities.Player) *mctsMove {
+	g := ai.g
+	moves := mctsMoves(g, p)
+
+	base := newCheckpoint(g)
+	root := &mctsNode{}
+
+	iterations := ai.iterations
+	deadline := time.Time{}
+	if g.engine == nil {
+		iterations = mctsMaxIterations
+		think := time.Duration(g.TimerVals.Turn) * time.Second / 10
+		if left := time.Duration(p.TimeLeft) * time.Second / 3; left < think {
+			think = left
+		}
+		if think > mctsMaxThink {
+			think = mctsMaxThink
+		}
+		deadline = time.Now().Add(think)
+	}
+
+	// One draw from the game seeds every simulation, so the random state
+	// of the game does not depend on how many the clock allows
+	seeds := rand.New(rand.NewSource(g.Rand().Int63()))
+	for i := 0; i < iterations; i++ {
+		if !deadline.IsZero() && i >= mctsMinIterations && time.Now().After(deadline) {
+			break
+		}
+		if err := ai.simulate(base, root, p, seeds.Int63()); err != nil {
+			log.Println("Bot search failed:", err)
+			break
+		}
+	}
+
+	// Most visits, ties go to the better result
+	var best *mctsMove
+	var bestChild *mctsNode
+	for i := range moves {
+		child := root.children[moves[i].key]
+		if child == nil || child.visits == 0 {
+			continue
+		}
+		if bestChild == nil || child.visits > bestChild.visits ||
+			(child.visits == bestChild.visits && child.reward > bestChild.reward) {
+			best = &moves[i]
+			bestChild = child
+		}
+	}
+	return best
+}
+
+// simulate plays one determinized copy of the game down the tree and
+// back propagates the result
+func (ai *mctsAI) simulate(base *engineCheckpoint, root *mctsNode, p *entities.Player, seed int64) error {
+	e, err := base.simulate(ai.g)
+	if err != nil {
+		return err
+	}
+	sim := e.Game()
+	sim.seedRandom(seed)
+	sim.determinize(sim.Players[p.Order])
+
+	path := []*mctsNode{root}
+	node := root
+	for depth := 0; depth < mctsMaxDepth; depth++ {
+		simP := sim.Players[p.Order]
+		if sim.GameOver || sim.CurrentPlayer != simP {
+			break
+		}
+
+		move, child := node.selectChild(mctsMoves(sim, simP))
+		path = append(path, child)
+		node = child
+
+		if move.key == mctsHeuristic {
+			break
+		}
+
+		// A move may fail when the dealt cards differ, the rollout goes
+		// on from there
+		e.Do(p.Order, move.do)
+		if child.visits == 0 {
+			break
+		}
+	}
+
+	rolloutUntil := sim.RoundCount + mctsRolloutRounds
+	for steps := 0; steps < mctsRolloutSteps && !sim.GameOver && sim.RoundCount < rolloutUntil; steps++ {
+		acted, _, err := e.Step()
+		if err != nil || !acted {
+			break
+		}
+	}
+
+	reward := mctsReward(sim, sim.Players[p.Order])
+	for _, n := range path {
+		n.visits++
+		n.reward += reward
+	}
+	return nil
+}
+
+// selectChild picks the move to try with UCB1 among the moves allowed in
+// this copy, trying each once first
+func (n *mctsNode) selectChild(moves []mctsMove) (*mctsMove, *mctsNode) {
+	if n.children == nil {
+		n.children = make(map[string]*mctsNode)
+	}
+
+	var best *mctsMove
+	var bestChild *mctsNode
+	bestScore := math.Inf(-1)
+	for i := range moves {
+		child := n.children[moves[i].key]
+		if child == nil {
+			child = &mctsNode{}
+			n.children[moves[i].key] = child
+		}
+		child.avail++
+
+		score := math.Inf(1)
+		if child.visits > 0 {
+			score = child.reward/float64(child.visits) +
+				mctsExploration*math.Sqrt(math.Log(float64(child.avail))/float64(child.visits))
+		}
+		if score > bestScore {
+			best = &moves[i]
+			bestChild = child
+			bestScore = score
+		}
+	}
+	return best, bestChild
+}
+
+// mctsReward scores the end of a simulation for p between 0 and 1. A win
+// is worth 1, otherwise the lead over the best opponent counts.
+func mctsReward(g *Game, p *entities.Player) float64 {
+	if g.GameOver {
+		if g.getScenarioVictoryWinner() == p {
+			return 1
+		}
+		return 0
+	}
+
+	best := math.Inf(-1)
+	for _, o := range g.Players {
+		if v := mctsValue(g, o); o != p && v > best {
+			best = v
+		}
+	}
+	reward := 0.5 + (mctsValue(g, p)-best)/float64(2*g.getScenarioVictoryTarget())
+	return math.Max(0, math.Min(1, reward))
+}
+
+// mctsValue counts the victory points of a player, and a little for what
+// their settlements and cities produce, which wins points later
+func mctsValue(g *Game, p *entities.Player) float64 {
+	value := float64(g.GetVictoryPoints(p, false))
+	for _, vp := range p.VertexPlacements {
+		weight := 0.0
+		switch vp.GetType() {
+		case entities.BTSettlement:
+			weight = mctsProductionWeight
+		case entities.BTCity:
+			weight = 2 * mctsProductionWeight
+		}
+		for _, t := range vp.GetLocation().AdjacentTiles {
+			if t.Number != 0 {
+				value += weight * (6 - math.Abs(float64(t.Number)-7))
+			}
+		}
+	}
+	return value
+}
+
+// mctsMoves lists what the bot can do next in its turn. Moves are found by
+// their key in every copy, so they name coordinates rather than pieces.
+func mctsMoves(g *Game, p *entities.Player) []mctsMove {
+	moves := []mctsMove{{key: mctsHeuristic}}
+
+	if p.CanBuild(entities.BTCity, g.BuildRules) == nil {
+		for _, v := range p.GetBuildLocationsCity(g.Graph) {
+			c := v.C
+			moves = append(moves, mctsMove{
+				key: fmt.Sprintf("city %d,%d", c.X, c.Y),
+				do:  func(g *Game, p *entities.Player) error { return g.BuildCity(p, c) },
+			})
+		}
+	}
+
+	if p.CanBuild(entities.BTSettlement, g.BuildRules) == nil {
+		for _, v := range g.GetBuildLocationsSettlement(p) {
+			c := v.C
+			moves = append(moves, mctsMove{
+				key: fmt.Sprintf("settlement %d,%d", c.X, c.Y),
+				do:  func(g *Game, p *entities.Player) error { return g.BuildSettlement(p, c) },
+			})
+		}
+	}
+
+	// Only the best road, the tree gets too wide with all of them
+	if p.CanBuild(entities.BTRoad, g.BuildRules) == nil {
+		if edges := p.GetBuildLocationsRoad(g.Graph, false); len(edges) > 0 {
+			c := newAI(g).ChooseBestEdgeRoad(p, edges).C
+			moves = append(moves, mctsMove{
+				key: fmt.Sprintf("road %d,%d-%d,%d", c.C1.X, c.C1.Y, c.C2.X, c.C2.Y),
+				do:  func(g *Game, p *entities.Player) error { return g.BuildRoad(p, c) },
+			})
+		}
+	}
+
+	if g.Mode.HasCitiesAndKnights() {
+		for _, it := range [3]entities.CardType{entities.CardTypePaper, entities.CardTypeCloth, entities.CardTypeCoin} {
+			it := it
+			if g.CanBuildImprovement(p, it) == nil {
+				moves = append(moves, mctsMove{
+					key: fmt.Sprintf("improve %d", it),
+					do:  func(g *Game, p *entities.Player) error { return g.BuildCityImprovement(p, it) },
+				})
+			}
+		}
+
+		if p.CanBuild(entities.BTKnight1, g.BuildRules) == nil {
+			if locs := p.GetBuildLocationsKnight(g.Graph, true); len(locs) > 0 {
+				c := locs[0].C
+				moves = append(moves, mctsMove{
+					key: "knight",
+					do:  func(g *Game, p *entities.Player) error { return g.BuildKnight(p, c) },
+				})
+			}
+		}
+
+		if p.CurrentHand.HasCost(g.BuildRules.GetActivateKnightCost()) {
+			if locs := p.GetActivateLocationsKnight(g.Graph); len(locs) > 0 {
+				c := locs[0].C
+				moves = append(moves, mctsMove{
+					key: "activate",
+					do:  func(g *Game, p *entities.Player) error { return g.ActivateKnight(p, c) },
+				})
+			}
+		}
+	} else if g.Bank.DevelopmentCardCursor < len(g.Bank.DevelopmentCardOrder[0]) && p.CanBuyDevelopmentCard(g.BuildRules) {
+		moves = append(moves, mctsMove{
+			key: "buy",
+			do:  func(g *Game, p *entities.Player) error { return g.BuyDevelopmentCard(p) },
+		})
+	}
+
+	if !g.Mode.HasCitiesAndKnights() {
+		played := make(map[entities.DevelopmentCardType]bool)
+		for _, dc := range usableDevCards(p, nil) {
+			if played[dc] || dc == entities.DevelopmentCardVictoryPoint {
+				continue
+			}
+			played[dc] = true
+			dc := dc
+			moves = append(moves, mctsMove{
+				key: fmt.Sprintf("play %d", dc),
+				do:  func(g *Game, p *entities.Player) error { return g.UseDevelopmentCard(p, dc) },
+			})
+		}
+	}
+
+	return append(moves, mctsBankTrades(g, p)...)
+}
+
+// mctsBankTrades lists trades with the bank for a card the next city,
+// settlement or road is missing, paid with cards it does not need
+func mctsBankTrades(g *Game, p *entities.Player) []mctsMove {
+	var want [9]int
+	for _, t := range []entities.BuildableType{entities.BTCity, entities.BTSettlement, entities.BTRoad} {
+		if p.BuildablesLeft[t] <= 0 {
+			continue
+		}
+		cost, _ := g.BuildRules.GetCost(t)
+		for ct, q := range cost {
+			if q > want[ct] {
+				want[ct] = q
+			}
+		}
+	}
+
+	moves := make([]mctsMove, 0)
+	ratios := g.GetRatiosForPlayer(p)
+	for _, give := range sortedCardTypes(p.CurrentHand) {
+		deck := p.CurrentHand.CardDeckMap[give]
+		if ratios[give] <= 0 || int(deck.Quantity)-ratios[give] < want[give] {
+			continue
+		}
+
+		for ask := entities.CardTypeWood; ask <= entities.CardTypeOre; ask++ {
+			if ask == give || int(p.CurrentHand.GetCardDeck(ask).Quantity) >= want[ask] {
+				continue
+			}
+
+			details := entities.TradeOfferDetails{}
+			details.Give[give] = ratios[give]
+			details.Ask[ask] = 1
+			moves = append(moves, mctsMove{
+				key: fmt.Sprintf("trade %d-%d", give, ask),
+				do: func(g *Game, p *entities.Player) error {
+					d := details
+					_, err := g.CreateOffer(p, &d, "bank")
+					return err
+				},
+			})
+		}
+	}
+	return moves
+}
@@ -0,0 +1,181 @@
+package game
+
+import (
+	"log"
+	"sakura/entities"
+)
+
+// A player that said no to a trade of the bot
+type tradeRefusal struct {
+	details entities.TradeOfferDetails
+	by      uint16
+}
+
+// nextBuildCost returns the price of what the bot wants to build next
+func (ai *AI) nextBuildCost(p *entities.Player) entities.BuildCost {
+	t := entities.BTRoad
+	if len(p.GetBuildLocationsCity(ai.g.Graph)) > 0 && p.BuildablesLeft[entities.BTCity] > 0 {
+		t = entities.BTCity
+	} else if len(ai.g.GetBuildLocationsSettlement(p)) > 0 && p.BuildablesLeft[entities.BTSettlement] > 0 {
+		t = entities.BTSettlement
+	}
+	cost, _ := ai.g.BuildRules.GetCost(t)
+	return cost
+}
+
+// tradeNeeds splits the hand into the cards the next build misses and
+// the resources left over after it. Commodities are never spared.
+func (ai *AI) tradeNeeds(p *entities.Player) (need [9]int, spare [9]int) {
+	cost := ai.nextBuildCost(p)
+	for t := entities.CardTypeWood; t <= entities.CardTypeCoin; t++ {
+		deck := p.CurrentHand.GetCardDeck(t)
+		if deck == nil {
+			continue
+		}
+		have := int(deck.Quantity)
+		if have < cost[t] {
+			need[t] = cost[t] - have
+		} else if t <= entities.CardTypeOre {
+			spare[t] = have - cost[t]
+		}
+	}
+	return need, spare
+}
+
+// mostOf returns the card type with the highest count, preferring the
+// types in prefer, or 0 if all are empty
+func mostOf(counts [9]int, prefer [9]int) entities.CardType {
+	best := entities.CardType(0)
+	for t := entities.CardTypeWood; t <= entities.CardTypeCoin; t++ {
+		if counts[t] <= 0 {
+			continue
+		}
+		if best == 0 ||
+			(prefer[t] > 0 && prefer[best] <= 0) ||
+			((prefer[t] > 0) == (prefer[best] > 0) && counts[t] > counts[best]) {
+			best = t
+		}
+	}
+	return best
+}
+
+// offerRefused remembers who said no to an offer of the bot this turn
+func (ai *AI) offerRefused(offer *entities.TradeOffer, by *entities.Player) {
+	ai.refusedBy[by.Order] = true
+	ai.refused[tradeRefusal{details: *offer.Details, by: by.Order}] = true
+}
+
+// refusedByAll checks if every player that can trade with p refused the
+// trade this turn
+func (ai *AI) refusedByAll(p *entities.Player, details entities.TradeOfferDetails) bool {
+	for _, o := range ai.g.Players {
+		if o == p || o.Embargos[p.Order] {
+			continue
+		}
+		if !ai.refused[tradeRefusal{details: details, by: o.Order}] {
+			return false
+		}
+	}
+	return true
+}
+
+// proposeTrades offers the other players a spare card for each card the
+// next build misses. Trades everyone refused are withdrawn and offered
+// again with two cards for one. Reports if any offer was made.
+func (ai *AI) proposeTrades(p *entities.Player) bool {
+	need, spare := ai.tradeNeeds(p)
+	missing := 0
+	for _, q := range need {
+		missing += q
+	}
+	if missing == 0 || missing > 2 {
+		return false
+	}
+
+	ai.numTradeCheck++
+	if ai.numTradeCheck > ai.maxTradeCheck {
+		return false
+	}
+
+	for _, o := range ai.g.CurrentOffers {
+		if o.CreatedBy == p.Order && ai.refusedByAll(p, *o.Details) {
+			ai.g.RejectOffer(o.Id, p)
+		}
+	}
+
+	offered := false
+	for m := entities.CardTypeWood; m <= entities.CardTypeCoin; m++ {
+		if need[m] <= 0 {
+			continue
+		}
+		s := mostOf(spare, [9]int{})
+		if s == 0 {
+			break
+		}
+
+		for give := 1; give <= 2 && give <= spare[s]; give++ {
+			details := entities.TradeOfferDetails{}
+			details.Give[s] = give
+			details.Ask[m] = 1
+			if ai.refusedByAll(p, details) {
+				continue
+			}
+
+			if ai.hasOffer(details) || len(ai.g.CurrentOffers) >= 4 {
+				break
+			}
+			if _, err := ai.g.CreateOffer(p, &details, "auto"); err != nil {
+				log.Println("Error creating offer:", err)
+				break
+			}
+			spare[s] -= give
+			offered = true
+			break
+		}
+	}
+	return offered
+}
+
+func (ai *AI) hasOffer(details entities.TradeOfferDetails) bool {
+	for _, o := range ai.g.CurrentOffers {
+		if *o.Details == details {
+			return true
+		}
+	}
+	return false
+}
+
+// counterOffer answers an offer of the player on turn the bot refused
+// with one card it can spare for one its next build misses, taken from
+// the cards of the offer when it can. Players that refused a counter of
+// the bot this turn get no more. Reports if it made one.
+func (ai *AI) counterOffer(p *entities.Player, o *entities.TradeOffer) bool {
+	current := ai.g.CurrentPlayer
+	if o.CreatedBy != current.Order || ai.countered[o.Id] || ai.refusedBy[current.Order] ||
+		p.Embargos[current.Order] || current.Embargos[p.Order] {
+		return false
+	}
+	ai.countered[o.Id] = true
+
+	need, spare := ai.tradeNeeds(p)
+	want := mostOf(need, o.Details.Give)
+	give := mostOf(spare, o.Details.Ask)
+	if want == 0 || give == 0 {
+		return false
+	}
+
+	// From the side of the bot, which CreateOffer turns around. Offers
+	// are kept from the side of the player on turn.
+	details := entities.TradeOfferDetails{}
+	details.Give[give] = 1
+	details.Ask[want] = 1
+	if ai.hasOffer(entities.TradeOfferDetails{Give: details.Ask, Ask: details.Give}) {
+		return false
+	}
+
+	if _, err := ai.g.CreateOffer(p, &details, ""); err != nil {
+		log.Println("Error creating counter offer:", err)
+		return false
+	}
+	return true
+}
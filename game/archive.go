@@ -0,0 +1,248 @@
+package game
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sakura/entities"
+	"sakura/maps"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ArchiveVersion is bumped whenever GameArchive changes incompatibly
+const ArchiveVersion = 1
+
+// MaxArchiveSize is the largest archive accepted, compressed or not
+const MaxArchiveSize = 16 << 20
+
+type (
+	// GameArchive is everything needed to move a game to another store
+	GameArchive struct {
+		Version          int                       `msgpack:"v"`
+		ID               string                    `msgpack:"id"`
+		NumPlayers       uint16                    `msgpack:"n"`
+		Settings         entities.GameSettings     `msgpack:"s"`
+		AdvancedSettings entities.AdvancedSettings `msgpack:"as"`
+		MapDefn          *entities.MapDefinition   `msgpack:"md"`
+		Journal          [][]byte                  `msgpack:"j"`
+		State            *StoreGameState           `msgpack:"gs"`
+		Players          []ArchivePlayer           `msgpack:"p"`
+	}
+
+	ArchivePlayer struct {
+		Order    uint16 `msgpack:"o"`
+		Id       string `msgpack:"id"`
+		Username string `msgpack:"u"`
+		IsBot    bool   `msgpack:"b"`
+	}
+
+	// archiveStore serves the archived game read-only
+	archiveStore struct {
+		replayStore
+		a *GameArchive
+	}
+
+	// MapWriter is implemented by stores that can save map definitions
+	MapWriter interface {
+		WriteMap(creator string, official bool, defn *entities.MapDefinition) error
+	}
+)
+
+// ExportGame collects a stored game into an archive. The state and
+// players are those at the end of the journal.
+func ExportGame(store Store, id string) (*GameArchive, error) {
+	replay, err := NewReplay(store, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := replay.Seek(replay.Len()); err != nil {
+		return nil, err
+	}
+
+	journal, err := store.ReadJournal(id)
+	if err != nil {
+		return nil, err
+	}
+
+	g := replay.Game()
+	a := &GameArchive{
+		Version:          ArchiveVersion,
+		ID:               id,
+		NumPlayers:       replay.NumPlayers,
+		Settings:         g.Settings,
+		AdvancedSettings: g.AdvancedSettings,
+		MapDefn:          g.Settings.MapDefn,
+		Journal:          journal,
+		State:            g.GenerateStoreGameState(),
+	}
+	for _, p := range g.Players {
+		a.Players = append(a.Players, ArchivePlayer{
+			Order:    p.Order,
+			Id:       p.Id,
+			Username: p.Username,
+			IsBot:    strings.HasSuffix(p.Username, "*"),
+		})
+	}
+
+	return a, nil
+}
+
+// ImportGame writes an archived game to the store under the given id.
+// The map definition is saved too if the store has no map of that name,
+// with the importing user as its creator.
+func ImportGame(store Store, a *GameArchive, id string, creator string) error {
+	if a.Version != ArchiveVersion {
+		return fmt.Errorf("archive version %d, expected %d", a.Version, ArchiveVersion)
+	}
+	if a.NumPlayers < 1 || len(a.Journal) == 0 || a.State == nil {
+		return errors.New("archive has no game")
+	}
+	if _, _, err := DecodeJournal(a.Journal); err != nil {
+		return err
+	}
+	if exists, _ := store.CheckIfJournalExists(id); exists {
+		return errors.New("game already exists")
+	}
+
+	if a.MapDefn != nil && store.GetMap(a.MapDefn.Name) == nil && maps.GetMapByName(a.MapDefn.Name) == nil {
+		if a.MapDefn.Name == "" || len(a.MapDefn.Map) == 0 {
+			return errors.New("archive has an invalid map")
+		}
+		writer, ok := store.(MapWriter)
+		if !ok {
+			return fmt.Errorf("store cannot save map %s", a.MapDefn.Name)
+		}
+		if err := writer.WriteMap(creator, false, a.MapDefn); err != nil {
+			return err
+		}
+	}
+
+	settings, err := msgpack.Marshal(a.Settings)
+	if err != nil {
+		return err
+	}
+
+	state := *a.State
+	state.ID = id
+	serialized, err := msgpack.Marshal(&state)
+	if err != nil {
+		return err
+	}
+
+	if err := store.Init(id); err != nil {
+		return err
+	}
+	if err := store.WriteGameSettings(id, settings); err != nil {
+		return err
+	}
+	if err := store.WriteJournalEntries(id, a.Journal); err != nil {
+		return err
+	}
+	if err := store.CreateGameStateIfNotExists(id, serialized); err != nil {
+		return err
+	}
+
+	participantIds := make([]string, 0, len(a.Players))
+	for _, p := range a.Players {
+		if !p.IsBot && p.Id != "" {
+			participantIds = append(participantIds, p.Id)
+		}
+	}
+	if err := store.WriteGameParticipants(id, participantIds); err != nil {
+		return err
+	}
+
+	// Written last, a hub only resumes games with players
+	if err := store.WriteGamePlayers(id, int32(a.NumPlayers)); err != nil {
+		return err
+	}
+	if err := store.WriteGameStarted(id); err != nil {
+		return err
+	}
+	if a.State.GameOver {
+		return store.WriteGameFinished(id)
+	}
+	return nil
+}
+
+// Store gives read-only access to the archived game, for example to
+// replay or verify it without importing it first
+func (a *GameArchive) Store() Store {
+	return &archiveStore{a: a}
+}
+
+func (s *archiveStore) ReadJournal(id string) ([][]byte, error) {
+	if id != s.a.ID {
+		return nil, errors.New("game not in archive")
+	}
+	return s.a.Journal, nil
+}
+func (s *archiveStore) CheckIfJournalExists(id string) (bool, error) {
+	return id == s.a.ID && len(s.a.Journal) > 0, nil
+}
+func (s *archiveStore) ReadGamePlayers(id string) (int, error) {
+	if id != s.a.ID {
+		return 0, errors.New("game not in archive")
+	}
+	return int(s.a.NumPlayers), nil
+}
+func (s *archiveStore) ReadGameSnapshot(id string) ([]byte, error) { return nil, nil }
+func (s *archiveStore) ReadUser(id string) (map[string]interface{}, error) {
+	return map[string]interface{}{}, nil
+}
+func (s *archiveStore) GetOfficalMapNames() []string { return []string{} }
+func (s *archiveStore) GetAllMapNamesForUser(userId string, exclude bool) ([]string, error) {
+	return []string{}, nil
+}
+func (s *archiveStore) GetMap(name string) *entities.MapDefinition {
+	if s.a.MapDefn != nil && s.a.MapDefn.Name == name {
+		return s.a.MapDefn
+	}
+	return nil
+}
+
+// EncodeArchive serializes an archive into a single compressed file
+func EncodeArchive(a *GameArchive) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := msgpack.NewEncoder(zw).Encode(a); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeArchive reads a file written by EncodeArchive
+func DecodeArchive(b []byte) (*GameArchive, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(io.LimitReader(zr, MaxArchiveSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxArchiveSize {
+		return nil, errors.New("archive too large")
+	}
+
+	var a GameArchive
+	if err := msgpack.Unmarshal(data, &a); err != nil {
+		return nil, err
+	}
+	if a.Version != ArchiveVersion {
+		return nil, fmt.Errorf("archive version %d, expected %d", a.Version, ArchiveVersion)
+	}
+	if a.State == nil {
+		return nil, errors.New("archive has no game state")
+	}
+	return &a, nil
+}
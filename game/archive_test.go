@@ -0,0 +1,21 @@
+package game
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+func TestDecodeArchiveRejectsOversizedData(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(make([]byte, MaxArchiveSize+1))
+	zw.Close()
+
+	if buf.Len() > MaxArchiveSize {
+		t.Fatal("expected the archive to compress below the limit")
+	}
+	if _, err := DecodeArchive(buf.Bytes()); err == nil {
+		t.Fatal("expected an archive over the limit once decompressed to be rejected")
+	}
+}
@@ -2,20 +2,20 @@ package game
 
 import (
 	"sakura/entities"
-	"math/rand"
-	"reflect"
-	"time"
+	"sort"
 )
 
 func (g *Game) assignTileTypes(types []entities.TileType) {
-	rand.Seed(time.Now().UnixNano())
+	// Shuffle a copy, the map definition is kept as given
+	types = append([]entities.TileType(nil), types...)
+
 	for i := range types {
-		j := rand.Intn(i + 1)
+		j := g.Rand().Intn(i + 1)
 		types[i], types[j] = types[j], types[i]
 	}
 
 	i := 0
-	for _, t := range g.Tiles {
+	for _, t := range g.sortedTiles() {
 		if t.Type == entities.TileTypeRandom || t.Type == entities.TileTypeFog {
 			if len(types) > i {
 				t.Type = types[i]
@@ -28,8 +28,6 @@ func (g *Game) assignTileTypes(types []entities.TileType) {
 }
 
 func (g *Game) assignNumbers(allNumbers []uint16) {
-	rand.Seed(time.Now().UnixNano())
-
 	redNumbers := make([]uint16, 0)
 	whiteNumbers := make([]uint16, 0)
 	for _, num := range allNumbers {
@@ -42,7 +40,8 @@ func (g *Game) assignNumbers(allNumbers []uint16) {
 
 	tileCoords := make(map[entities.Coordinate]*entities.Tile)
 	allTileCoords := make(map[entities.Coordinate]*entities.Tile)
-	for _, t := range g.Tiles {
+	sortedTiles := g.sortedTiles()
+	for _, t := range sortedTiles {
 		if t.Type != entities.TileTypeDesert && t.Type != entities.TileTypeSea {
 			tileCoords[t.Center] = t
 			allTileCoords[t.Center] = t
@@ -51,15 +50,11 @@ func (g *Game) assignNumbers(allNumbers []uint16) {
 				g.Robber.Move(t)
 				g.j.WSetRobber(t)
 			}
-			if !t.Fog && g.Mode == entities.Seafarers && t.Type == entities.TileTypeSea && g.Pirate.Tile == nil {
-				g.Pirate.Move(t)
-				g.j.WSetPirate(t)
-			}
 		}
 	}
 
 	if g.Robber.Tile == nil {
-		for _, t := range g.Tiles {
+		for _, t := range sortedTiles {
 			if !t.Fog && t.Type != entities.TileTypeSea {
 				g.Robber.Move(t)
 				g.j.WSetRobber(t)
@@ -68,14 +63,8 @@ func (g *Game) assignNumbers(allNumbers []uint16) {
 		}
 	}
 
-	if g.Mode == entities.Seafarers && g.Pirate.Tile == nil {
-		for _, t := range g.Tiles {
-			if !t.Fog && t.Type == entities.TileTypeSea {
-				g.Pirate.Move(t)
-				g.j.WSetPirate(t)
-				break
-			}
-		}
+	if g.Mode.HasSeafarers() && !g.pirateWaitsForBarbarians() {
+		g.placeInitialPirate()
 	}
 
 	for _, num := range redNumbers {
@@ -84,8 +73,8 @@ func (g *Game) assignNumbers(allNumbers []uint16) {
 			continue
 		}
 
-		coords := reflect.ValueOf(tileCoords).MapKeys()
-		C := coords[rand.Intn(len(tileCoords))].Interface().(entities.Coordinate)
+		coords := sortedCoordinates(tileCoords)
+		C := coords[g.Rand().Intn(len(coords))]
 		tileCoords[C].Number = num
 		g.j.WSetTileType(tileCoords[C])
 		delete(tileCoords, C)
@@ -103,10 +92,42 @@ func (g *Game) assignNumbers(allNumbers []uint16) {
 			break
 		}
 
-		coords := reflect.ValueOf(allTileCoords).MapKeys()
-		C := coords[rand.Intn(len(allTileCoords))].Interface().(entities.Coordinate)
+		coords := sortedCoordinates(allTileCoords)
+		C := coords[g.Rand().Intn(len(coords))]
 		allTileCoords[C].Number = num
 		g.j.WSetTileType(allTileCoords[C])
 		delete(allTileCoords, C)
 	}
 }
+
+func sortedCoordinates(tiles map[entities.Coordinate]*entities.Tile) []entities.Coordinate {
+	coords := make([]entities.Coordinate, 0, len(tiles))
+	for c := range tiles {
+		coords = append(coords, c)
+	}
+	sort.Slice(coords, func(i, j int) bool {
+		return coords[i].Less(coords[j])
+	})
+	return coords
+}
+
+// placeInitialPirate puts the pirate on the first open sea tile, unless a
+// scenario already placed it
+func (g *Game) placeInitialPirate() {
+	if g.Pirate.Tile != nil {
+		return
+	}
+	for _, t := range g.sortedTiles() {
+		if !t.Fog && t.Type == entities.TileTypeSea {
+			g.Pirate.Move(t)
+			g.j.WSetPirate(t)
+			return
+		}
+	}
+}
+
+// pirateWaitsForBarbarians checks if the pirate is still off the board.
+// With Cities & Knights it only sails after the first barbarian attack.
+func (g *Game) pirateWaitsForBarbarians() bool {
+	return g.Mode == entities.SeafarersCitiesAndKnights && g.NumBarbarianAttacks == 0
+}
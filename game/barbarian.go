@@ -8,7 +8,7 @@ import (
 )
 
 func (g *Game) GetBarbarianStrength() int {
-	if g.Mode != entities.CitiesAndKnights {
+	if !g.Mode.HasCitiesAndKnights() {
 		return -1
 	}
 
@@ -24,7 +24,7 @@ func (g *Game) GetBarbarianStrength() int {
 }
 
 func (g *Game) GetBarbarianKnights() int {
-	if g.Mode != entities.CitiesAndKnights {
+	if !g.Mode.HasCitiesAndKnights() {
 		return -1
 	}
 
@@ -42,6 +42,9 @@ func (g *Game) MoveBarbarian() {
 		// Attack!
 		g.NumBarbarianAttacks++
 		g.BarbarianPosition = 7
+		if g.Mode.HasSeafarers() {
+			g.placeInitialPirate()
+		}
 
 		totalKnights := 0
 		maxKnights := 0
@@ -93,7 +96,7 @@ func (g *Game) MoveBarbarian() {
 			// Victory
 			if len(maxKnightPlayers) > 1 {
 				if !g.j.playing {
-					go g.BarbarianDistributeProgressCards(maxKnightPlayers)
+					g.spawn(func() { g.BarbarianDistributeProgressCards(maxKnightPlayers) })
 				}
 			} else if len(maxKnightPlayers) == 1 {
 				// Give victory point to maxKnightPlayers[0]
@@ -109,7 +112,7 @@ func (g *Game) MoveBarbarian() {
 		} else {
 			// Defeat
 			if !g.j.playing && len(minKnightPlayers) > 0 {
-				go g.BarbarianDestruction(minKnightPlayers)
+				g.spawn(func() { g.BarbarianDestruction(minKnightPlayers) })
 			}
 		}
 	}
@@ -203,7 +206,8 @@ func (g *Game) BarbarianDestruction(players []*entities.Player) {
 		wg.Add(1)
 		p.ClearExpect()
 
-		go func(wg *sync.WaitGroup, p *entities.Player) {
+		p := p
+		g.spawn(func() {
 			defer wg.Done()
 
 			defer g.Unlock()
@@ -261,12 +265,12 @@ func (g *Game) BarbarianDestruction(players []*entities.Player) {
 
 			g.SendPlayerSecret(p)
 			g.BroadcastState()
-		}(&wg, p)
+		})
 	}
 
 	g.TickerPause = true
 	g.Unlock()
-	wg.Wait()
+	g.wait(&wg)
 	if !g.Lock() {
 		return
 	}
@@ -0,0 +1,188 @@
+package game
+
+import (
+	"sakura/entities"
+	"sort"
+)
+
+// BotStrategy plays a bot seat. Tick takes one step of the turn of the
+// seat: trades, builds, development and progress cards, and answers to
+// the offers of others. The Choose methods answer the prompts of the
+// seat, which bots leave to the default answer.
+type BotStrategy interface {
+	Level() string
+
+	// Reset forgets the plans of the turn
+	Reset()
+
+	// Clone copies the strategy to play in another game
+	Clone(g *Game) BotStrategy
+
+	Tick(p *entities.Player) bool
+
+	ChooseSetupSettlement(p *entities.Player, allowed []*entities.Vertex) *entities.Vertex
+	ChooseSetupRoad(p *entities.Player, allowed []*entities.Edge) *entities.Edge
+	ChooseRobberTile(p *entities.Player, tiles []*entities.Tile) *entities.Tile
+	ChooseDiscard(p *entities.Player, quantity int) [9]int
+	ChooseMerchantLocation(p *entities.Player, allowed []*entities.Tile) *entities.Tile
+	ChooseMerchantFleet(p *entities.Player) [9]int
+}
+
+// NewBotStrategy returns the strategy of a level, normal when unknown
+func NewBotStrategy(g *Game, level string) BotStrategy {
+	switch level {
+	case entities.BotLevelEasy:
+		return newEasyAI(g)
+	case entities.BotLevelHard:
+		return newHardAI(g)
+	case entities.BotLevelExpert:
+		return newMctsAI(g)
+	}
+	return newAI(g)
+}
+
+// botStrategy returns the strategy of the seat. Seats without a level,
+// and humans whose prompts time out, get the normal one.
+func (g *Game) botStrategy(p *entities.Player) BotStrategy {
+	level := p.BotLevel
+	if !entities.IsBotLevel(level) {
+		level = entities.BotLevelNormal
+	}
+
+	if g.bots == nil {
+		g.bots = make(map[uint16]BotStrategy)
+	}
+	s := g.bots[p.Order]
+	if s == nil || s.Level() != level {
+		s = NewBotStrategy(g, level)
+		g.bots[p.Order] = s
+	}
+	return s
+}
+
+// refusalListener is a strategy that remembers who refused its trades
+type refusalListener interface {
+	offerRefused(offer *entities.TradeOffer, by *entities.Player)
+}
+
+// botOfferRefused tells the bot that made the offer that the player
+// refused it
+func (g *Game) botOfferRefused(offer *entities.TradeOffer, p *entities.Player) {
+	creator := g.Players[offer.CreatedBy]
+	if creator == p || !creator.GetIsBot() {
+		return
+	}
+	if l, ok := g.botStrategy(creator).(refusalListener); ok {
+		l.offerRefused(offer, p)
+	}
+}
+
+// cloneBots copies the strategies of the seats for another game
+func cloneBots(bots map[uint16]BotStrategy, g *Game) map[uint16]BotStrategy {
+	c := make(map[uint16]BotStrategy, len(bots))
+	for order, s := range bots {
+		c[order] = s.Clone(g)
+	}
+	return c
+}
+
+// tickBots lets every bot act once and reports if any did
+func (g *Game) tickBots() bool {
+	if !g.Initialized ||
+		g.GameOver ||
+		g.DiceState == 0 ||
+		g.InitPhase {
+		return false
+	}
+
+	acted := false
+	for _, p := range g.Players {
+		if p.GetIsBot() {
+			acted = g.botStrategy(p).Tick(p) || acted
+		}
+	}
+	return acted
+}
+
+func (g *Game) resetBots() {
+	for _, s := range g.bots {
+		s.Reset()
+	}
+}
+
+// botWaiting answers the prompt of a bot with the default and reports
+// if the bot has to wait for someone
+func (g *Game) botWaiting(p *entities.Player) bool {
+	if p.PendingAction != nil {
+		p.SendExpect(nil)
+		return true
+	}
+	return g.HasPlayerPendingAction()
+}
+
+// randomDiscard draws the cards to throw like the timeout of the prompt
+func randomDiscard(g *Game, p *entities.Player, quantity int) [9]int {
+	var left [9]int
+	count := 0
+	for t, deck := range p.CurrentHand.CardDeckMap {
+		left[t] = int(deck.Quantity)
+		count += int(deck.Quantity)
+	}
+
+	var discard [9]int
+	for ; quantity > 0 && count > 0; quantity-- {
+		i := g.Rand().Intn(count)
+		for t, q := range left {
+			i -= q
+			if i < 0 {
+				left[t]--
+				discard[t]++
+				break
+			}
+		}
+		count--
+	}
+	return discard
+}
+
+// getLeader returns the opponent with the most public victory points,
+// the one with more cards on a tie
+func (g *Game) getLeader(p *entities.Player) *entities.Player {
+	var leader *entities.Player
+	leaderVP := -1
+	for _, o := range g.Players {
+		if o == p {
+			continue
+		}
+		vp := g.GetVictoryPoints(o, true)
+		if vp > leaderVP || (vp == leaderVP && o.CurrentHand.GetCardCount() > leader.CurrentHand.GetCardCount()) {
+			leader = o
+			leaderVP = vp
+		}
+	}
+	return leader
+}
+
+// isLeaderOver checks if the player of the order is the leader for p
+// and at least two points ahead of it
+func (g *Game) isLeaderOver(order uint16, p *entities.Player) bool {
+	leader := g.getLeader(p)
+	return leader != nil && leader.Order == order &&
+		g.GetVictoryPoints(leader, true) >= g.GetVictoryPoints(p, true)+2
+}
+
+// usableDevCards lists the cards the player can play, one entry per card
+func usableDevCards(p *entities.Player, failed map[entities.DevelopmentCardType]bool) []entities.DevelopmentCardType {
+	devCards := make([]entities.DevelopmentCardType, 0)
+	for t, deck := range p.CurrentHand.DevelopmentCardDeckMap {
+		if deck.CanUse && !failed[t] {
+			for i := 0; i < int(deck.Quantity); i++ {
+				devCards = append(devCards, t)
+			}
+		}
+	}
+
+	// Map order is random, keep the choice reproducible from the seed
+	sort.Slice(devCards, func(i, j int) bool { return devCards[i] < devCards[j] })
+	return devCards
+}
@@ -0,0 +1,203 @@
+package game
+
+import (
+	"sakura/entities"
+	"testing"
+)
+
+func TestBotLevelsPlayGame(t *testing.T) {
+	e, err := NewEngine(snapshotTestSettings(), 11, []string{"easy*", "normal*", "hard*"})
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	g := e.Game()
+	g.SetBotLevel(g.Players[0], entities.BotLevelEasy)
+	g.SetBotLevel(g.Players[2], entities.BotLevelHard)
+
+	if _, err := e.Start(); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	for steps := 0; g.TurnCount < 40 && !g.GameOver; steps++ {
+		if steps > 10000 {
+			t.Fatalf("game stuck at turn %d", g.TurnCount)
+		}
+		acted, _, err := e.Step()
+		if err != nil {
+			t.Fatalf("step failed: %v", err)
+		}
+		if !acted {
+			t.Fatalf("bot %d did nothing on its turn", g.CurrentPlayer.Order)
+		}
+	}
+
+	for i, level := range []string{entities.BotLevelEasy, entities.BotLevelNormal, entities.BotLevelHard} {
+		if got := g.botStrategy(g.Players[i]).Level(); got != level {
+			t.Fatalf("expected seat %d to play %s, got %s", i, level, got)
+		}
+	}
+
+	report, err := VerifyJournal(e.Store(), EngineGameID)
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if report.Played != len(report.Entries) {
+		t.Fatalf("replay stopped after %d of %d entries", report.Played, len(report.Entries))
+	}
+}
+
+func TestBotLevelSurvivesReplay(t *testing.T) {
+	store := &memoryStore{skipSnapshot: true}
+	g := newRobberRulesTestGame(t, store, entities.AdvancedSettings{})
+	if level := g.botStrategy(g.Players[1]).Level(); level != entities.BotLevelNormal {
+		t.Fatalf("expected seats without a level to play normal, got %s", level)
+	}
+
+	g.SetBotLevel(g.Players[1], entities.BotLevelHard)
+	g.j.Flush()
+
+	resumed := newRobberRulesTestGame(t, &memoryStore{journal: store.journal}, entities.AdvancedSettings{})
+	if level := resumed.botStrategy(resumed.Players[1]).Level(); level != entities.BotLevelHard {
+		t.Fatalf("expected the hard level after replay, got %s", level)
+	}
+
+	restored := newRobberRulesTestGame(t, &noopStore{}, entities.AdvancedSettings{})
+	if err := restored.RestoreSnapshot(g.CreateSnapshot()); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if restored.Players[1].BotLevel != entities.BotLevelHard {
+		t.Fatal("expected the level in the snapshot")
+	}
+}
+
+func TestHardBotRobberTargetsLeader(t *testing.T) {
+	g := newRobberRulesTestGame(t, &noopStore{}, entities.AdvancedSettings{})
+	bot, small, leader := g.Players[0], g.Players[1], g.Players[2]
+	settleRobberRulesTestPlayer(t, g, bot, 1)
+	settleRobberRulesTestPlayer(t, g, small, 1)
+	settleRobberRulesTestPlayer(t, g, leader, 3)
+
+	hard := NewBotStrategy(g, entities.BotLevelHard)
+	easy := NewBotStrategy(g, entities.BotLevelEasy)
+	owners := func(tile *entities.Tile) map[*entities.Player]bool {
+		m := make(map[*entities.Player]bool)
+		for _, vp := range g.Graph.GetTilePlacements(tile) {
+			m[vp.GetOwner()] = true
+		}
+		return m
+	}
+
+	if o := owners(hard.ChooseRobberTile(bot, g.sortedTiles())); !o[leader] || o[bot] {
+		t.Fatal("expected the hard bot to block the leader")
+	}
+	if o := owners(easy.ChooseRobberTile(bot, g.sortedTiles())); o[bot] {
+		t.Fatal("expected the easy bot to keep the robber off its own tiles")
+	}
+}
+
+func TestHardBotDiscardKeepsNextBuild(t *testing.T) {
+	g := newRobberRulesTestGame(t, &noopStore{}, entities.AdvancedSettings{})
+	p := g.Players[1]
+	p.CurrentHand.UpdateResources(1, 1, 0, 0, 4)
+
+	// Without a place to settle, the next build is a road
+	discard := NewBotStrategy(g, entities.BotLevelHard).ChooseDiscard(p, 3)
+	if discard != ([9]int{entities.CardTypeOre: 3}) {
+		t.Fatalf("expected to throw the ore, got %v", discard)
+	}
+
+	discard = NewBotStrategy(g, entities.BotLevelNormal).ChooseDiscard(p, 3)
+	total := 0
+	for _, q := range discard {
+		total += q
+	}
+	if total != 3 {
+		t.Fatalf("expected 3 random cards, got %v", discard)
+	}
+}
+
+func TestBotSweetensRefusedTrade(t *testing.T) {
+	g := newRobberRulesTestGame(t, &noopStore{}, entities.AdvancedSettings{})
+	g.DiceState = 1
+	bot := g.CurrentPlayer
+	bot.SetIsBot(true)
+	ai := g.botStrategy(bot).(*AI)
+
+	// The next build is a road, one wood short
+	bot.CurrentHand.UpdateResources(0, 1, 0, 0, 3)
+	if !ai.proposeTrades(bot) || len(g.CurrentOffers) != 1 {
+		t.Fatal("expected an offer for the missing wood")
+	}
+	offer := g.CurrentOffers[0]
+	if *offer.Details != (entities.TradeOfferDetails{
+		Give: [9]int{entities.CardTypeOre: 1},
+		Ask:  [9]int{entities.CardTypeWood: 1},
+	}) {
+		t.Fatalf("expected one ore for one wood, got %v", *offer.Details)
+	}
+
+	for _, o := range g.Players {
+		if o != bot {
+			g.RejectOffer(offer.Id, o)
+		}
+	}
+	if !ai.refusedByAll(bot, *offer.Details) {
+		t.Fatal("expected the bot to remember who refused")
+	}
+
+	if !ai.proposeTrades(bot) || len(g.CurrentOffers) != 1 {
+		t.Fatal("expected the refused offer to be replaced")
+	}
+	if g.CurrentOffers[0].Details.Give[entities.CardTypeOre] != 2 {
+		t.Fatalf("expected two ore for the wood, got %v", *g.CurrentOffers[0].Details)
+	}
+
+	ai.Reset()
+	if ai.refusedByAll(bot, *offer.Details) {
+		t.Fatal("expected refusals to be forgotten after the turn")
+	}
+}
+
+func TestBotCountersOfferOnce(t *testing.T) {
+	g := newRobberRulesTestGame(t, &noopStore{}, entities.AdvancedSettings{})
+	g.DiceState = 1
+	human := g.CurrentPlayer
+	human.SetIsBot(false)
+	bot := g.Players[(human.Order+1)%3]
+	bot.SetIsBot(true)
+	g.Players[(human.Order+2)%3].SetIsBot(false)
+	ai := g.botStrategy(bot)
+
+	human.CurrentHand.UpdateResources(2, 0, 0, 2, 0)
+	bot.CurrentHand.UpdateResources(0, 1, 2, 0, 0)
+	if _, err := g.CreateOffer(human, &entities.TradeOfferDetails{
+		Give: [9]int{entities.CardTypeWheat: 1},
+		Ask:  [9]int{entities.CardTypeOre: 1},
+	}, ""); err != nil {
+		t.Fatalf("create offer failed: %v", err)
+	}
+
+	// The bot has no ore, but wants wood for a road
+	ai.Tick(bot)
+	counter := g.GetOffer(int(bot.Order))
+	if counter == nil || counter.CreatedBy != bot.Order {
+		t.Fatal("expected a counter offer")
+	}
+	if *counter.Details != (entities.TradeOfferDetails{
+		Give: [9]int{entities.CardTypeWood: 1},
+		Ask:  [9]int{entities.CardTypeWool: 1},
+	}) {
+		t.Fatalf("expected wood for wool, got %v", *counter.Details)
+	}
+
+	g.RejectOffer(counter.Id, human)
+	if _, err := g.CreateOffer(human, &entities.TradeOfferDetails{
+		Give: [9]int{entities.CardTypeWheat: 2},
+		Ask:  [9]int{entities.CardTypeOre: 1},
+	}, ""); err != nil {
+		t.Fatalf("create offer failed: %v", err)
+	}
+	ai.Tick(bot)
+	if g.GetOffer(int(bot.Order)) != nil {
+		t.Fatal("expected no counter to a player who refused one this turn")
+	}
+}
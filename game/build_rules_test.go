@@ -0,0 +1,205 @@
+package game
+
+import (
+	"reflect"
+	"sakura/entities"
+	"testing"
+)
+
+func newBuildRulesTestGame(t *testing.T, store Store, mode entities.GameMode, mapRules, houseRules *entities.BuildRules) *Game {
+	t.Helper()
+
+	settings := snapshotTestSettings()
+	settings.Mode = mode
+	settings.MapDefn.BuildRules = mapRules
+	settings.BuildRules = houseRules
+	g := &Game{Store: store, Settings: settings}
+	if _, err := g.Initialize("build-rules", 3); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	stopTickerForTest(g)
+	return g
+}
+
+func TestBuildRulesMapAndHouseRules(t *testing.T) {
+	mapRules := &entities.BuildRules{
+		Costs: map[string]entities.BuildCost{
+			"ship": {entities.CardTypeWood: 1},
+			"city": {entities.CardTypeOre: 2},
+		},
+		Pieces: map[string]int{"city": 6, "knight1": 3},
+	}
+	houseRules := &entities.BuildRules{
+		Costs:  map[string]entities.BuildCost{"city": {entities.CardTypeOre: 4}},
+		Pieces: map[string]int{"ship": 10, "road": -1},
+	}
+
+	rules := entities.GetBuildRules(entities.Seafarers, mapRules, houseRules)
+	if cost, _ := rules.GetCost(entities.BTShip); cost != (entities.BuildCost{entities.CardTypeWood: 1}) {
+		t.Fatalf("expected the cheaper ship of the map, got %v", cost)
+	}
+	if cost, _ := rules.GetCost(entities.BTCity); cost != (entities.BuildCost{entities.CardTypeOre: 4}) {
+		t.Fatalf("expected the house rule to win over the map, got %v", cost)
+	}
+	pieces := rules.GetPieces()
+	if pieces[entities.BTCity] != 6 || pieces[entities.BTShip] != 10 || pieces[entities.BTRoad] != 15 {
+		t.Fatalf("unexpected pieces %v", pieces)
+	}
+	if _, ok := pieces[entities.BTKnight1]; ok {
+		t.Fatal("expected no knights without Cities and Knights")
+	}
+
+	g := newBuildRulesTestGame(t, &noopStore{}, entities.Base, mapRules, houseRules)
+	for _, p := range g.Players {
+		if p.BuildablesLeft[entities.BTCity] != 6 {
+			t.Fatalf("expected 6 cities, got %d", p.BuildablesLeft[entities.BTCity])
+		}
+		if _, ok := p.BuildablesLeft[entities.BTShip]; ok {
+			t.Fatal("expected no ships without Seafarers")
+		}
+	}
+}
+
+func TestBuildRulesPrices(t *testing.T) {
+	houseRules := &entities.BuildRules{
+		Costs: map[string]entities.BuildCost{"road": {entities.CardTypeOre: 2}},
+	}
+	store := &memoryStore{skipSnapshot: true}
+	g := newBuildRulesTestGame(t, store, entities.Base, nil, houseRules)
+	g.InitPhase = false
+	g.DiceState = 1
+	p := g.CurrentPlayer
+
+	vertices := p.GetBuildLocationsSettlement(g.Graph, true, false)
+	if err := p.BuildAtVertex(vertices[0], entities.BTSettlement); err != nil {
+		t.Fatalf("failed to place settlement: %v", err)
+	}
+	p.CurrentHand.UpdateResources(1, 1, 0, 0, 1)
+	if g.ensureCanBuild(p, entities.BTRoad) == nil {
+		t.Fatal("expected the road to need two ore")
+	}
+
+	p.CurrentHand.UpdateResources(0, 0, 0, 0, 1)
+	edges := p.GetBuildLocationsRoad(g.Graph, false)
+	if err := g.BuildRoad(p, edges[0].C); err != nil {
+		t.Fatalf("failed to build road: %v", err)
+	}
+	if !p.CurrentHand.HasResources(1, 1, 0, 0, 0) || p.CurrentHand.HasResources(0, 0, 0, 0, 1) {
+		t.Fatal("expected the road to be paid with ore only")
+	}
+	g.j.Flush()
+
+	// The house rules come back with the journaled settings
+	settings := snapshotTestSettings()
+	resumed := &Game{Store: &memoryStore{journal: store.journal}, Settings: settings}
+	if _, err := resumed.Initialize("build-rules", 3); err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	stopTickerForTest(resumed)
+	if cost, _ := resumed.BuildRules.GetCost(entities.BTRoad); cost != (entities.BuildCost{entities.CardTypeOre: 2}) {
+		t.Fatalf("expected the road price after replay, got %v", cost)
+	}
+}
+
+func TestBuildRulesImprovements(t *testing.T) {
+	houseRules := &entities.BuildRules{Improvements: []int{2, 2, 2, 2, 2}}
+	g := newBuildRulesTestGame(t, &noopStore{}, entities.CitiesAndKnights, nil, houseRules)
+	g.InitPhase = false
+	g.DiceState = 1
+	p := g.CurrentPlayer
+
+	vertices := p.GetBuildLocationsSettlement(g.Graph, true, false)
+	if err := p.BuildAtVertex(vertices[0], entities.BTCity); err != nil {
+		t.Fatalf("failed to place city: %v", err)
+	}
+
+	p.CurrentHand.UpdateCards(entities.CardTypePaper, 1)
+	if g.CanBuildImprovement(p, entities.CardTypePaper) == nil {
+		t.Fatal("expected the first level to need two paper")
+	}
+
+	p.CurrentHand.UpdateCards(entities.CardTypePaper, 1)
+	if err := g.BuildCityImprovement(p, entities.CardTypePaper); err != nil {
+		t.Fatalf("failed to improve: %v", err)
+	}
+	if p.CurrentHand.GetCardDeck(entities.CardTypePaper).Quantity != 0 {
+		t.Fatal("expected both paper to be paid")
+	}
+}
+
+func TestBuildRulesActivateKnight(t *testing.T) {
+	houseRules := &entities.BuildRules{
+		Costs: map[string]entities.BuildCost{entities.ActivateKnightCost: {entities.CardTypeWool: 1}},
+	}
+	g := newBuildRulesTestGame(t, &noopStore{}, entities.CitiesAndKnights, nil, houseRules)
+	g.InitPhase = false
+	g.DiceState = 1
+	p := g.CurrentPlayer
+
+	vertices := p.GetBuildLocationsSettlement(g.Graph, true, false)
+	if err := p.BuildAtVertex(vertices[0], entities.BTKnight1); err != nil {
+		t.Fatalf("failed to place knight: %v", err)
+	}
+
+	p.CurrentHand.UpdateCards(entities.CardTypeWheat, 1)
+	if g.GetPlayerSecretState(p).AllowedActions.ActivateKnight || g.ActivateKnight(p, vertices[0].C) == nil {
+		t.Fatal("expected wheat to no longer activate a knight")
+	}
+
+	p.CurrentHand.UpdateCards(entities.CardTypeWool, 1)
+	if !g.GetPlayerSecretState(p).AllowedActions.ActivateKnight {
+		t.Fatal("expected the knight to be activated for wool")
+	}
+	if err := g.ActivateKnight(p, vertices[0].C); err != nil {
+		t.Fatalf("failed to activate: %v", err)
+	}
+	if p.CurrentHand.GetCardDeck(entities.CardTypeWool).Quantity != 0 ||
+		p.CurrentHand.GetCardDeck(entities.CardTypeWheat).Quantity != 1 {
+		t.Fatal("expected wool to be paid and wheat kept")
+	}
+}
+
+func TestBuildRulesRoadBuildingLendsPrice(t *testing.T) {
+	settings := snapshotTestSettings()
+	settings.BuildRules = &entities.BuildRules{
+		Costs: map[string]entities.BuildCost{"road": {entities.CardTypeOre: 2}},
+	}
+	e, err := NewEngine(settings, 4, []string{"a*", "b*", "c*"})
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	if _, err := e.Start(); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	g := e.Game()
+	p := g.CurrentPlayer
+	roads := p.BuildablesLeft[entities.BTRoad]
+
+	if _, err := e.Do(p.Order, func(g *Game, p *entities.Player) error {
+		return g.RollDice(p, 2, 3)
+	}); err != nil {
+		t.Fatalf("roll failed: %v", err)
+	}
+	hand := snapshotHand(p.CurrentHand)
+	if _, err := e.Do(p.Order, func(g *Game, p *entities.Player) error {
+		g.UseDevRoadBuilding(p, []entities.BuildableType{entities.BTRoad, entities.BTRoad})
+		return nil
+	}); err != nil {
+		t.Fatalf("road building failed: %v", err)
+	}
+
+	if left := p.BuildablesLeft[entities.BTRoad]; left != roads-2 {
+		t.Fatalf("expected two free roads, %d of %d left", left, roads)
+	}
+	if got := snapshotHand(p.CurrentHand); !reflect.DeepEqual(got, hand) {
+		t.Fatalf("expected the hand to stay as it was, got %v for %v", got, hand)
+	}
+
+	report, err := VerifyJournal(e.Store(), EngineGameID)
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if report.Played != len(report.Entries) || len(report.Violations) != 0 {
+		t.Fatalf("replay stopped after %d of %d entries: %v", report.Played, len(report.Entries), report.Violations)
+	}
+}
@@ -1,6 +1,7 @@
 package game
 
 import (
+	"math/rand"
 	"sakura/entities"
 	"testing"
 )
@@ -10,7 +11,7 @@ func TestMoveCardsRefusesToOverdrawPlayerHand(t *testing.T) {
 	if err != nil {
 		t.Fatalf("GetNewPlayers failed: %v", err)
 	}
-	bank, err := entities.GetNewBank(entities.Base)
+	bank, err := entities.GetNewBank(entities.Base, rand.New(rand.NewSource(1)))
 	if err != nil {
 		t.Fatalf("GetNewBank failed: %v", err)
 	}
@@ -44,7 +45,7 @@ func TestCanTradeWithBankRejectsOfferAbovePlayerHand(t *testing.T) {
 	if err != nil {
 		t.Fatalf("GetNewPlayers failed: %v", err)
 	}
-	bank, err := entities.GetNewBank(entities.Base)
+	bank, err := entities.GetNewBank(entities.Base, rand.New(rand.NewSource(1)))
 	if err != nil {
 		t.Fatalf("GetNewBank failed: %v", err)
 	}
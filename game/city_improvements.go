@@ -8,7 +8,7 @@ import (
 )
 
 func (g *Game) CanBuildImprovement(p *entities.Player, ct entities.CardType) error {
-	if g.Mode != entities.CitiesAndKnights {
+	if !g.Mode.HasCitiesAndKnights() {
 		return errors.New("wrong game mode")
 	}
 
@@ -16,20 +16,21 @@ func (g *Game) CanBuildImprovement(p *entities.Player, ct entities.CardType) err
 		return errors.New("no such improvement")
 	}
 
+	cost, ok := g.BuildRules.GetImprovementCost(p.Improvements[int(ct)])
+	if !ok {
+		return errors.New("cannot improve further")
+	}
+
 	if !g.IsCreativeMode() {
-		haveQ := p.CurrentHand.GetCardDeck(ct).Quantity
+		haveQ := int(p.CurrentHand.GetCardDeck(ct).Quantity)
 		if p.UsingDevCard == entities.ProgressPaperCrane {
 			haveQ++
 		}
-		if haveQ <= int16(p.Improvements[int(ct)]) {
+		if haveQ < cost {
 			return errors.New("not enough commodity cards")
 		}
 	}
 
-	if p.Improvements[int(ct)] >= 5 {
-		return errors.New("cannot improve further")
-	}
-
 	// Check player has at least one city
 	hasCity := false
 	for _, vp := range p.VertexPlacements {
@@ -78,11 +79,11 @@ func (g *Game) BuildCityImprovement(p *entities.Player, ct entities.CardType) er
 
 	defer g.BroadcastState()
 
-	quantity := p.Improvements[int(ct)] + 1
-	if p.UsingDevCard == entities.ProgressPaperCrane {
+	quantity, _ := g.BuildRules.GetImprovementCost(p.Improvements[int(ct)])
+	if p.UsingDevCard == entities.ProgressPaperCrane && quantity > 0 {
 		quantity--
 	}
-	if !g.IsCreativeMode() {
+	if !g.IsCreativeMode() && quantity > 0 {
 		g.MoveCards(int(p.Order), -1, ct, quantity, true, false)
 	}
 	p.Improvements[int(ct)] += 1
@@ -0,0 +1,130 @@
+package game
+
+import (
+	"sakura/entities"
+)
+
+// Clone copies the game into a headless game for bots to play ahead on.
+// Every seat of the copy is a bot. It sends no messages, writes no
+// journal and never waits for anyone, and nothing done to it changes g.
+func (g *Game) Clone() (*Engine, error) {
+	return newCheckpoint(g).simulate(g)
+}
+
+// simulate starts a new copy of g from the checkpoint. The checkpoint is
+// not changed, so one can start many copies.
+func (c *engineCheckpoint) simulate(g *Game) (*Engine, error) {
+	store := &engineStore{numPlayers: len(g.Players)}
+	sim := &Game{
+		ID:               g.ID,
+		Store:            store,
+		Settings:         g.Settings,
+		AdvancedSettings: g.AdvancedSettings,
+		Seed:             g.Seed,
+		SnapshotInterval: -1,
+		TimerVals:        g.TimerVals,
+		Initialized:      true,
+		simulated:        true,
+	}
+	sim.j.g = sim
+	sim.j.Init()
+
+	e := &Engine{g: sim, store: store, checkpoint: c}
+	sim.engine = e
+	if err := e.restore(); err != nil {
+		return nil, err
+	}
+	e.checkpoint = nil
+
+	for _, p := range sim.Players {
+		p.SetIsBot(true)
+	}
+	return e, nil
+}
+
+// determinize deals the cards p cannot see again at random. Opponents
+// keep the size of their hands, and their development cards come from
+// the ones p has not seen, together with the stack of the bank. Progress
+// cards stay where they are.
+func (g *Game) determinize(p *entities.Player) {
+	others := make([]*entities.Player, 0, len(g.Players))
+	for _, o := range g.Players {
+		if o != p {
+			others = append(others, o)
+		}
+	}
+
+	// Resource and commodity cards
+	counts := make([]int, len(others))
+	cards := make([]entities.CardType, 0)
+	for i, o := range others {
+		for _, t := range sortedCardTypes(o.CurrentHand) {
+			deck := o.CurrentHand.CardDeckMap[t]
+			for n := int16(0); n < deck.Quantity; n++ {
+				cards = append(cards, t)
+			}
+			counts[i] += int(deck.Quantity)
+			deck.Quantity = 0
+		}
+	}
+	g.Rand().Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+	for i, o := range others {
+		for _, t := range cards[:counts[i]] {
+			o.CurrentHand.UpdateCards(t, 1)
+		}
+		cards = cards[counts[i]:]
+	}
+
+	if g.Mode.HasCitiesAndKnights() {
+		return
+	}
+
+	// Development cards
+	types := []entities.DevelopmentCardType{
+		entities.DevelopmentCardKnight,
+		entities.DevelopmentCardVictoryPoint,
+		entities.DevelopmentCardRoadBuilding,
+		entities.DevelopmentCardYearOfPlenty,
+		entities.DevelopmentCardMonopoly,
+	}
+	order := g.Bank.DevelopmentCardOrder[0]
+	cursor := g.Bank.DevelopmentCardCursor
+	if cursor > len(order) {
+		cursor = len(order)
+	}
+	devCards := append([]entities.DevelopmentCardType(nil), order[cursor:]...)
+	usable := make([]bool, len(others))
+	for i, o := range others {
+		counts[i] = 0
+		for _, t := range types {
+			deck := o.CurrentHand.GetDevelopmentCardDeck(t)
+			if deck == nil {
+				continue
+			}
+			for n := int16(0); n < deck.Quantity; n++ {
+				devCards = append(devCards, t)
+			}
+			counts[i] += int(deck.Quantity)
+			usable[i] = usable[i] || (deck.CanUse && t != entities.DevelopmentCardVictoryPoint)
+			deck.Quantity = 0
+			deck.CanUse = false
+		}
+	}
+	g.Rand().Shuffle(len(devCards), func(i, j int) { devCards[i], devCards[j] = devCards[j], devCards[i] })
+	for i, o := range others {
+		for _, t := range devCards[:counts[i]] {
+			deck := o.CurrentHand.GetDevelopmentCardDeck(t)
+			if deck == nil {
+				continue
+			}
+			deck.Quantity++
+
+			// Cards of the player whose turn it is stay locked if they
+			// already played one
+			deck.CanUse = t != entities.DevelopmentCardVictoryPoint &&
+				(o != g.CurrentPlayer || usable[i])
+		}
+		devCards = devCards[counts[i]:]
+	}
+	g.Bank.DevelopmentCardOrder[0] = append(append([]entities.DevelopmentCardType(nil), order[:cursor]...), devCards...)
+}
@@ -0,0 +1,145 @@
+package game
+
+import (
+	"bytes"
+	"reflect"
+	"sakura/entities"
+	"testing"
+)
+
+func newCloneTestEngine(t *testing.T, turns int) *Engine {
+	t.Helper()
+	e, err := NewEngine(snapshotTestSettings(), 5, []string{"a*", "b*", "c*"})
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	if _, err := e.Start(); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	for g := e.Game(); g.TurnCount < turns; {
+		if _, _, err := e.Step(); err != nil {
+			t.Fatalf("step failed: %v", err)
+		}
+	}
+	return e
+}
+
+func TestCloneLeavesGameAlone(t *testing.T) {
+	e := newCloneTestEngine(t, 6)
+	g := e.Game()
+	g.Players[1].SetIsBot(false)
+	before := encodedSnapshot(t, g)
+	journal := len(e.store.journal)
+
+	sim, err := g.Clone()
+	if err != nil {
+		t.Fatalf("clone failed: %v", err)
+	}
+	if !bytes.Equal(encodedSnapshot(t, sim.Game()), before) {
+		t.Fatal("expected the clone to start where the game is")
+	}
+	if !sim.Game().Players[1].GetIsBot() {
+		t.Fatal("expected bots in every seat of the clone")
+	}
+
+	for steps := 0; sim.Game().TurnCount < 12 && !sim.Game().GameOver; steps++ {
+		if steps > 5000 {
+			t.Fatal("clone stuck")
+		}
+		if _, _, err := sim.Step(); err != nil {
+			t.Fatalf("step failed: %v", err)
+		}
+	}
+	if len(sim.Decisions()) != 0 {
+		t.Fatal("expected the clone to never wait")
+	}
+	if len(sim.store.journal) != 0 || len(sim.Game().j.pending) != 0 {
+		t.Fatal("expected the clone to keep no journal")
+	}
+
+	if !bytes.Equal(encodedSnapshot(t, g), before) || len(e.store.journal) != journal {
+		t.Fatal("expected the game to be untouched by its clone")
+	}
+}
+
+func TestDeterminizeKeepsHandSizes(t *testing.T) {
+	e := newCloneTestEngine(t, 9)
+	sim, err := e.Game().Clone()
+	if err != nil {
+		t.Fatalf("clone failed: %v", err)
+	}
+	g := sim.Game()
+	p := g.Players[0]
+	g.Players[2].CurrentHand.GetDevelopmentCardDeck(entities.DevelopmentCardKnight).Quantity += 2
+	g.Bank.DevelopmentCardCursor += 2
+
+	hands := make([]int16, len(g.Players))
+	devCards := make([]int16, len(g.Players))
+	var cards [9]int
+	for i, o := range g.Players {
+		hands[i] = o.CurrentHand.GetCardCount()
+		devCards[i] = o.CurrentHand.GetDevelopmentCardCount()
+		for t, deck := range o.CurrentHand.CardDeckMap {
+			cards[t] += int(deck.Quantity)
+		}
+	}
+	own := snapshotHand(p.CurrentHand)
+	stack := len(g.Bank.DevelopmentCardOrder[0])
+
+	g.seedRandom(77)
+	g.determinize(p)
+
+	var after [9]int
+	for i, o := range g.Players {
+		if o.CurrentHand.GetCardCount() != hands[i] || o.CurrentHand.GetDevelopmentCardCount() != devCards[i] {
+			t.Fatalf("expected player %d to keep the size of their hand", i)
+		}
+		for t, deck := range o.CurrentHand.CardDeckMap {
+			after[t] += int(deck.Quantity)
+		}
+	}
+	if after != cards {
+		t.Fatalf("expected the same cards dealt again, got %v for %v", after, cards)
+	}
+
+	if !reflect.DeepEqual(snapshotHand(p.CurrentHand), own) {
+		t.Fatal("expected the hand of the player to stay as it is")
+	}
+	if len(g.Bank.DevelopmentCardOrder[0]) != stack {
+		t.Fatal("expected the development card stack to keep its size")
+	}
+}
+
+func TestExpertBotPlaysTurns(t *testing.T) {
+	e, err := NewEngine(snapshotTestSettings(), 3, []string{"expert*", "normal*", "hard*"})
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	g := e.Game()
+	g.SetBotLevel(g.Players[0], entities.BotLevelExpert)
+	g.SetBotLevel(g.Players[2], entities.BotLevelHard)
+	g.botStrategy(g.Players[0]).(*mctsAI).iterations = 6
+
+	if _, err := e.Start(); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	for steps := 0; g.TurnCount < 12 && !g.GameOver; steps++ {
+		if steps > 5000 {
+			t.Fatalf("game stuck at turn %d", g.TurnCount)
+		}
+		if _, _, err := e.Step(); err != nil {
+			t.Fatalf("step failed: %v", err)
+		}
+	}
+
+	if level := g.botStrategy(g.Players[0]).Level(); level != entities.BotLevelExpert {
+		t.Fatalf("expected the expert level, got %s", level)
+	}
+	report, err := VerifyJournal(e.Store(), EngineGameID)
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if report.Played != len(report.Entries) {
+		t.Fatalf("replay stopped after %d of %d entries", report.Played, len(report.Entries))
+	}
+}
@@ -7,7 +7,7 @@ import (
 )
 
 func (g *Game) BroadcastMessage(msg *entities.Message) {
-	if g.j.playing || !g.Initialized {
+	if g.j.playing || !g.Initialized || g.simulated {
 		return
 	}
 
@@ -18,6 +18,9 @@ func (g *Game) BroadcastMessage(msg *entities.Message) {
 
 func (g *Game) BroadcastState() {
 	g.StateSeq++
+	if g.simulated {
+		return
+	}
 	g.BroadcastMessage(&entities.Message{
 		Type: entities.MessageTypeGameState,
 		Data: g.GetGameState(),
@@ -41,7 +44,7 @@ func (g *Game) setCurrentPlayerTimeLeft(seconds int) {
 }
 
 func (g *Game) SendPlayerSecret(p *entities.Player) {
-	if g.j.playing || !g.Initialized {
+	if g.j.playing || !g.Initialized || g.simulated {
 		return
 	}
 
@@ -86,6 +89,33 @@ func (g *Game) BlockForAction(
 	g.SetPendingAction(p, action)
 	p.ClearExpect()
 
+	if g.engine != nil {
+		exp = g.engine.expect(p, action)
+	} else {
+		var err error
+		if exp, err = g.waitForExpect(p, timeout); err != nil {
+			return nil, err
+		}
+	}
+
+	if timeout > 0 {
+		g.setPlayerTimeLeft(p, oldTimeLeft)
+	}
+
+	if pauseTicker {
+		g.TickerPause = false
+	}
+
+	p.ClearPendingAction()
+	g.SendPlayerSecret(p)
+	g.SendPlayerSecret(g.CurrentPlayer)
+	g.BroadcastState()
+	return exp, nil
+}
+
+// waitForExpect unlocks the game until the player answers, the timeout
+// runs out or a bot takes over
+func (g *Game) waitForExpect(p *entities.Player, timeout int) (interface{}, error) {
 	g.Unlock()
 
 	getExpectWithTimeout := func(g *Game, p *entities.Player) interface{} {
@@ -109,6 +139,7 @@ func (g *Game) BlockForAction(
 		}
 	}
 
+	var exp interface{}
 	if timeout > 0 {
 		exp = getExpectWithTimeout(g, p)
 	} else {
@@ -118,19 +149,6 @@ func (g *Game) BlockForAction(
 	if !g.Lock() {
 		return nil, errors.New("failed to get lock or not initialized")
 	}
-
-	if timeout > 0 {
-		g.setPlayerTimeLeft(p, oldTimeLeft)
-	}
-
-	if pauseTicker {
-		g.TickerPause = false
-	}
-
-	p.ClearPendingAction()
-	g.SendPlayerSecret(p)
-	g.SendPlayerSecret(g.CurrentPlayer)
-	g.BroadcastState()
 	return exp, nil
 }
 
@@ -3,7 +3,6 @@ package game
 import (
 	"errors"
 	"log"
-	"math/rand"
 	"sakura/entities"
 	"strconv"
 	"sync"
@@ -24,8 +23,8 @@ func (g *Game) RollDice(p *entities.Player, givenRedRoll int, givenWhiteRoll int
 	g.ActionMutex.Lock()
 	defer g.ActionMutex.Unlock()
 
-	redRoll := rand.Intn(6) + 1
-	whiteRoll := rand.Intn(6) + 1
+	redRoll := g.Rand().Intn(6) + 1
+	whiteRoll := g.Rand().Intn(6) + 1
 
 	if givenRedRoll != 0 {
 		redRoll = givenRedRoll
@@ -35,13 +34,26 @@ func (g *Game) RollDice(p *entities.Player, givenRedRoll int, givenWhiteRoll int
 		whiteRoll = givenWhiteRoll
 	}
 
+	// The event deck decides the roll instead, unless it was chosen
+	var card entities.EventCard
+	newYear := false
+	if givenRedRoll == 0 && givenWhiteRoll == 0 && g.DiceState == 0 && g.usesEventCards() {
+		card, newYear = g.drawEventCard()
+		redRoll, whiteRoll = card.Red, card.White
+	}
+
 	dieRollState, err := g.RollDiceWith(redRoll, whiteRoll)
 	if err != nil {
 		return err
 	}
+	dieRollState.Event = card.Event
+	dieRollState.NewYear = newYear
+	g.resolveEventCard(card.Event, dieRollState)
 
-	if g.Mode == entities.CitiesAndKnights {
-		dieRollState.EventRoll = rand.Intn(6) + 1
+	// The event die waits for the roll that counts
+	rerolled := g.DiceState == 0
+	if g.Mode.HasCitiesAndKnights() && !rerolled {
+		dieRollState.EventRoll = g.Rand().Intn(6) + 1
 	}
 
 	g.BroadcastMessage(&entities.Message{
@@ -50,7 +62,7 @@ func (g *Game) RollDice(p *entities.Player, givenRedRoll int, givenWhiteRoll int
 		Data:     dieRollState,
 	})
 
-	if g.Mode == entities.CitiesAndKnights {
+	if g.Mode.HasCitiesAndKnights() && !rerolled {
 		g.RollEventDiceWith(dieRollState.EventRoll)
 	}
 
@@ -82,12 +94,21 @@ func (g *Game) RollDiceWith(redRoll int, whiteRoll int) (*entities.DieRollState,
 	dieRollState := &entities.DieRollState{RedRoll: redRoll, WhiteRoll: whiteRoll}
 	dieRollState.PlayerHandDeltas = make([]*entities.Hand, 0)
 
+	if roll == 7 && g.robberHeld() {
+		// Early 7s are rolled again or do nothing
+		if g.AdvancedSettings.RerollEarly7 {
+			g.DiceState = 0
+			g.setCurrentPlayerTimeLeft(g.TimerVals.Dice)
+		}
+		return dieRollState, nil
+	}
+
 	if roll == 7 {
 		// Send the dice status using the normal means before
 		// performing the robber movement.
 		// See the top comment of RollDice7 for more details
 		if !g.j.playing {
-			go g.RollDice7(dieRollState)
+			g.spawn(func() { g.RollDice7(dieRollState) })
 		}
 		return dieRollState, nil
 	}
@@ -109,7 +130,7 @@ func (g *Game) RollDiceWith(redRoll int, whiteRoll int) (*entities.DieRollState,
 
 	// Create NumberTiles map
 	numberTiles := make([]*entities.Tile, 0)
-	for _, tile := range g.Graph.Tiles {
+	for _, tile := range g.sortedTiles() {
 		if tile.Number == uint16(roll) {
 			numberTiles = append(numberTiles, tile)
 		}
@@ -126,7 +147,7 @@ func (g *Game) RollDiceWith(redRoll int, whiteRoll int) (*entities.DieRollState,
 		}
 
 		for _, placement := range g.Graph.GetTilePlacements(tile) {
-			if g.Mode == entities.CitiesAndKnights && placement.GetType() == entities.BTCity {
+			if g.Mode.HasCitiesAndKnights() && placement.GetType() == entities.BTCity {
 				if tile.Type == entities.TileTypeWood {
 					bankDiff.UpdateCards(entities.CardTypeWood, -1)
 					bankDiff.UpdateCards(entities.CardTypePaper, -1)
@@ -195,7 +216,7 @@ func (g *Game) RollDiceWith(redRoll int, whiteRoll int) (*entities.DieRollState,
 			owner := placement.GetOwner()
 			t := entities.CardType(tile.Type)
 
-			if g.Mode == entities.CitiesAndKnights && placement.GetType() == entities.BTCity {
+			if g.Mode.HasCitiesAndKnights() && placement.GetType() == entities.BTCity {
 				if tile.Type == entities.TileTypeWood {
 					giveCards(owner, entities.CardTypeWood, 1)
 					giveCards(owner, entities.CardTypePaper, 1)
@@ -227,7 +248,7 @@ func (g *Game) RollDiceWith(redRoll int, whiteRoll int) (*entities.DieRollState,
 		}
 	}
 
-	if !g.j.playing && g.Mode == entities.CitiesAndKnights {
+	if !g.j.playing && g.Mode.HasCitiesAndKnights() {
 		for _, p := range g.Players {
 			if p.Improvements[int(entities.CardTypePaper)] >= 3 && dieRollState.PlayerHandDeltas[p.Order].GetCardCount() == 0 {
 				goldCalls[p.Order].Quantity++
@@ -238,7 +259,7 @@ func (g *Game) RollDiceWith(redRoll int, whiteRoll int) (*entities.DieRollState,
 	if !g.j.playing {
 		for _, call := range goldCalls {
 			if call.Quantity > 0 {
-				go g.GiveGold(goldCalls)
+				g.spawn(func() { g.GiveGold(goldCalls) })
 				break
 			}
 		}
@@ -313,7 +334,11 @@ func (g *Game) RollDice7(state *entities.DieRollState) {
 
 	// No robber till first attack
 	// TODO: tell the UI about this
-	if g.Mode == entities.CitiesAndKnights && g.NumBarbarianAttacks == 0 {
+	if g.Mode.HasCitiesAndKnights() && g.NumBarbarianAttacks == 0 {
+		return
+	}
+
+	if g.ScenarioHooks.NoRobber {
 		return
 	}
 
@@ -334,7 +359,7 @@ func (g *Game) RollDice7(state *entities.DieRollState) {
 
 func (g *Game) GetDiscardLimit(p *entities.Player) int16 {
 	discardLimit := g.Settings.DiscardLimit
-	if g.Mode == entities.CitiesAndKnights {
+	if g.Mode.HasCitiesAndKnights() {
 		for _, p := range p.VertexPlacements {
 			if p.GetType() == entities.BTCity && p.(*entities.City).Wall {
 				discardLimit += 2
@@ -359,7 +384,8 @@ func (g *Game) DiscardHalfCards(players []*entities.Player, force bool) {
 			wg.Add(1)
 			p.ClearExpect()
 
-			go func(wg *sync.WaitGroup, p *entities.Player, action *entities.PlayerActionSelectCards) {
+			p := p
+			g.spawn(func() {
 				defer wg.Done()
 
 				defer g.Unlock()
@@ -381,6 +407,11 @@ func (g *Game) DiscardHalfCards(players []*entities.Player, force bool) {
 				if err != nil || len(resp) != 9 {
 					resp = make([]float64, 9)
 				}
+				if p.GetIsBot() {
+					for t, n := range g.botStrategy(p).ChooseDiscard(p, q) {
+						resp[t] = float64(n)
+					}
+				}
 
 				sum := 0
 				for _, ti := range action.AllowedTypes {
@@ -397,7 +428,7 @@ func (g *Game) DiscardHalfCards(players []*entities.Player, force bool) {
 				}
 
 				for sum < action.Quantity {
-					t := p.CurrentHand.ChooseRandomCardType()
+					t := p.CurrentHand.ChooseRandomCardType(g.Rand())
 					if t == nil {
 						break
 					}
@@ -409,13 +440,13 @@ func (g *Game) DiscardHalfCards(players []*entities.Player, force bool) {
 				p.SendAction(&entities.PlayerAction{Type: entities.PlayerActionTypeSelectCardsDone})
 				g.SendPlayerSecret(p)
 				g.BroadcastState()
-			}(&wg, p, action)
+			})
 		}
 	}
 
 	g.TickerPause = true
 	g.Unlock()
-	wg.Wait()
+	g.wait(&wg)
 	if !g.Lock() {
 		return
 	}
@@ -423,14 +454,19 @@ func (g *Game) DiscardHalfCards(players []*entities.Player, force bool) {
 }
 
 func (g *Game) MoveRobberOrPirateInteractive(timeout int) (*entities.Tile, error) {
-	// Move the robber (or the pirate in Seafarers).
+	return g.moveRobberOrPirateInteractive(timeout, true, g.Mode.HasSeafarers() && !g.pirateWaitsForBarbarians())
+}
+
+// moveRobberOrPirateInteractive lets the current player move the robber,
+// the pirate or either of them
+func (g *Game) moveRobberOrPirateInteractive(timeout int, robber bool, pirate bool) (*entities.Tile, error) {
 	tiles := make([]*entities.Tile, 0)
-	for _, t := range g.Graph.Tiles {
+	for _, t := range g.sortedTiles() {
 		if t.Fog {
 			continue
 		}
 
-		if g.Mode != entities.Seafarers {
+		if !pirate {
 			if t.Type == entities.TileTypeSea {
 				continue
 			}
@@ -451,6 +487,9 @@ func (g *Game) MoveRobberOrPirateInteractive(timeout int) (*entities.Tile, error
 			continue
 		}
 
+		if !robber {
+			continue
+		}
 		if g.Robber.Tile != t ||
 			(g.Robber.Tile != nil &&
 				g.Robber.Tile.Type == entities.TileTypeDesert &&
@@ -462,6 +501,7 @@ func (g *Game) MoveRobberOrPirateInteractive(timeout int) (*entities.Tile, error
 	if len(tiles) == 0 {
 		return nil, errors.New("no valid tile for robber/pirate")
 	}
+	tiles = g.filterRobberTiles(tiles)
 
 	robberAction := &entities.PlayerActionChooseTile{
 		Allowed: tiles,
@@ -485,14 +525,18 @@ func (g *Game) MoveRobberOrPirateInteractive(timeout int) (*entities.Tile, error
 	mapstructure.Decode(exp, &resp)
 
 	selTile := g.Graph.Tiles[resp]
-	if selTile == nil {
-		selTile = g.ai.GetRobberTile(g.CurrentPlayer, robberAction.Allowed)
+	allowed := false
+	for _, t := range robberAction.Allowed {
+		allowed = allowed || t == selTile
+	}
+	if !allowed {
+		selTile = g.botStrategy(g.CurrentPlayer).ChooseRobberTile(g.CurrentPlayer, robberAction.Allowed)
 	}
 	if selTile == nil {
 		return nil, errors.New("no selected tile for robber/pirate")
 	}
 
-	if g.Mode == entities.Seafarers && selTile.Type == entities.TileTypeSea {
+	if g.Mode.HasSeafarers() && selTile.Type == entities.TileTypeSea {
 		if g.Pirate == nil {
 			g.Pirate = &entities.Pirate{}
 		}
@@ -501,13 +545,14 @@ func (g *Game) MoveRobberOrPirateInteractive(timeout int) (*entities.Tile, error
 	} else {
 		g.Robber.Move(selTile)
 		g.j.WSetRobber(selTile)
+		g.setRobberReturn(g.CurrentPlayer)
 	}
 	g.BroadcastState()
 	return selTile, nil
 }
 
 func (g *Game) MoveRobberInteractive(timeout int) error {
-	_, err := g.MoveRobberOrPirateInteractive(timeout)
+	_, err := g.moveRobberOrPirateInteractive(timeout, true, false)
 	return err
 }
 
@@ -520,7 +565,7 @@ func (g *Game) StealCardAtTile(tile *entities.Tile) error {
 	stealChoicesSlice := make([]*entities.Player, 0)
 	stealChoices := make([]bool, len(g.Players))
 
-	if g.Mode == entities.Seafarers && tile.Type == entities.TileTypeSea {
+	if g.Mode.HasSeafarers() && tile.Type == entities.TileTypeSea {
 		for _, ec := range tile.GetEdgeCoordinates() {
 			e, err := g.Graph.GetEdge(ec)
 			if err != nil || e == nil || e.Placement == nil || e.Placement.GetType() != entities.BTShip {
@@ -528,7 +573,7 @@ func (g *Game) StealCardAtTile(tile *entities.Tile) error {
 			}
 
 			o := e.Placement.GetOwner()
-			if !stealChoices[o.Order] && o != g.CurrentPlayer && o.CurrentHand.GetCardCount() > 0 {
+			if !stealChoices[o.Order] && o != g.CurrentPlayer && o.CurrentHand.GetCardCount() > 0 && !g.robberProtects(o) {
 				stealChoices[o.Order] = true
 				stealChoicesSlice = append(stealChoicesSlice, o)
 			}
@@ -540,7 +585,7 @@ func (g *Game) StealCardAtTile(tile *entities.Tile) error {
 			}
 
 			o := vp.GetOwner()
-			if !stealChoices[o.Order] && o != g.CurrentPlayer && o.CurrentHand.GetCardCount() > 0 {
+			if !stealChoices[o.Order] && o != g.CurrentPlayer && o.CurrentHand.GetCardCount() > 0 && !g.robberProtects(o) {
 				stealChoices[o.Order] = true
 				stealChoicesSlice = append(stealChoicesSlice, vp.GetOwner())
 			}
@@ -581,6 +626,9 @@ func (g *Game) StealCardAtTile(tile *entities.Tile) error {
 		}
 	}
 
+	if tile == g.Robber.Tile {
+		g.setRobberReturn(g.Players[stoleOrder])
+	}
 	g.stealRandomCard(g.CurrentPlayer, g.Players[stoleOrder])
 	return nil
 }
@@ -590,7 +638,7 @@ func (g *Game) StealCardWithRobber() error {
 }
 
 func (g *Game) stealRandomCard(stealer *entities.Player, victim *entities.Player) {
-	cardType := victim.CurrentHand.ChooseRandomCardType()
+	cardType := victim.CurrentHand.ChooseRandomCardType(g.Rand())
 	if cardType != nil {
 		g.MoveCards(int(victim.Order), int(stealer.Order), *cardType, 1, true, true)
 	}
@@ -633,7 +681,8 @@ func (g *Game) GiveGold(calls []GoldCall) {
 		wg.Add(1)
 		call.Player.ClearExpect()
 
-		go func(wg *sync.WaitGroup, p *entities.Player, action *entities.PlayerActionSelectCards) {
+		p := call.Player
+		g.spawn(func() {
 			defer wg.Done()
 
 			defer g.Unlock()
@@ -679,7 +728,7 @@ func (g *Game) GiveGold(calls []GoldCall) {
 					break
 				}
 
-				ct := entities.CardType(rand.Intn(5) + 1)
+				ct := entities.CardType(g.Rand().Intn(5) + 1)
 				if g.Bank.Hand.GetCardDeck(ct).Quantity > 0 {
 					g.MoveCards(-1, int(p.Order), ct, 1, true, false)
 					sum++
@@ -689,12 +738,12 @@ func (g *Game) GiveGold(calls []GoldCall) {
 			p.SendAction(&entities.PlayerAction{Type: entities.PlayerActionTypeSelectCardsDone})
 			g.SendPlayerSecret(p)
 			g.BroadcastState()
-		}(&wg, call.Player, action)
+		})
 	}
 
 	g.TickerPause = true
 	g.Unlock()
-	wg.Wait()
+	g.wait(&wg)
 	if !g.Lock() {
 		return
 	}
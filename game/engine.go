@@ -0,0 +1,423 @@
+package game
+
+import (
+	"errors"
+	"sakura/entities"
+	"sakura/maps"
+	"sync"
+)
+
+// ID of games created by an engine
+const EngineGameID = "headless"
+
+type (
+	// Event is a message the game sent to one player
+	Event struct {
+		Player  uint16
+		Message *entities.Message
+	}
+
+	// Decision is an action the game waits for a player to answer
+	Decision struct {
+		Player uint16
+		Action *entities.PlayerAction
+	}
+
+	// Engine drives a game synchronously on the goroutine of its caller,
+	// without a store, ticker or connected players. Nothing runs between
+	// two calls.
+	//
+	// A command that needs a decision of a human player stops there,
+	// leaving the game as it was when the decision was asked. Answering
+	// the decision puts the game back to the state before the command
+	// and runs it again with the answers given so far. The seeded RNG
+	// makes both runs take the same path, so every event is only
+	// returned once.
+	Engine struct {
+		g     *Game
+		store *engineStore
+
+		// Command being run and the answers given for it so far
+		command    func() error
+		checkpoint *engineCheckpoint
+		answers    map[uint16][]interface{}
+		used       map[uint16]int
+
+		pending   []Decision
+		events    []Event
+		delivered int
+		spawned   []func()
+	}
+
+	// engineCheckpoint is everything needed to run a command again
+	engineCheckpoint struct {
+		snapshot []byte
+		journal  int
+		lastRand uint64
+		stateSeq uint64
+		timerId  uint64
+		offers   []entities.TradeOffer
+		bots     map[uint16]BotStrategy
+		embargos [][]bool
+	}
+
+	// engineAbort unwinds a command up to the engine when a player has
+	// to decide something first
+	engineAbort struct {
+		decision Decision
+		events   int
+	}
+
+	// engineStore keeps the journal of a headless game in memory
+	engineStore struct {
+		replayStore
+		journal    [][]byte
+		numPlayers int
+	}
+)
+
+func (s *engineStore) WriteJournalEntries(id string, entries [][]byte) error {
+	s.journal = append(s.journal, entries...)
+	return nil
+}
+func (s *engineStore) ReadJournal(id string) ([][]byte, error) {
+	return append([][]byte(nil), s.journal...), nil
+}
+func (s *engineStore) CheckIfJournalExists(id string) (bool, error) {
+	return len(s.journal) > 0, nil
+}
+func (s *engineStore) ReadGameSnapshot(id string) ([]byte, error) { return nil, nil }
+func (s *engineStore) ReadGamePlayers(id string) (int, error)     { return s.numPlayers, nil }
+func (s *engineStore) ReadUser(id string) (map[string]interface{}, error) {
+	return nil, errors.New("no users in a headless game")
+}
+func (s *engineStore) GetOfficalMapNames() []string { return maps.GetOfficialMapNames() }
+func (s *engineStore) GetAllMapNamesForUser(userId string, exclude bool) ([]string, error) {
+	return maps.GetOfficialMapNames(), nil
+}
+func (s *engineStore) GetMap(name string) *entities.MapDefinition {
+	return maps.GetMapByName(name)
+}
+
+// NewEngine sets up a game with one player per username. Usernames
+// ending in * are bots, empty ones keep the default name. The same
+// settings, seed and commands always give the same game.
+func NewEngine(settings entities.GameSettings, seed int64, usernames []string) (*Engine, error) {
+	if len(usernames) == 0 {
+		return nil, errors.New("no players given")
+	}
+
+	store := &engineStore{numPlayers: len(usernames)}
+	g := &Game{Store: store, Settings: settings, Seed: seed, SnapshotInterval: -1}
+	if g.Settings.MapDefn == nil && g.Settings.MapName != "" {
+		g.ensureMapDefn()
+	}
+
+	e := &Engine{g: g, store: store}
+	g.engine = e
+	if _, err := g.Initialize(EngineGameID, uint16(len(usernames))); err != nil {
+		return nil, err
+	}
+
+	for i, username := range usernames {
+		if username != "" {
+			g.SetUsername(g.Players[i], username)
+		}
+	}
+	e.listen()
+
+	return e, nil
+}
+
+// Game returns the game of the engine. It must only be changed through
+// the engine.
+func (e *Engine) Game() *Game {
+	return e.g
+}
+
+// Store returns the in-memory store holding the journal of the game
+func (e *Engine) Store() Store {
+	e.g.j.Flush()
+	return e.store
+}
+
+// Decisions lists what the game waits for. Players that have to decide
+// at the same time are asked one after the other.
+func (e *Engine) Decisions() []Decision {
+	return append([]Decision(nil), e.pending...)
+}
+
+// Start runs the initial placement of the game
+func (e *Engine) Start() ([]Event, error) {
+	return e.run(func() error {
+		if !e.g.InitPhase {
+			return errors.New("game already started")
+		}
+		e.g.RunInitPhase()
+		return nil
+	})
+}
+
+// Do runs an action for a player, such as rolling the dice or building
+func (e *Engine) Do(player uint16, action func(g *Game, p *entities.Player) error) ([]Event, error) {
+	if int(player) >= len(e.g.Players) {
+		return nil, errors.New("invalid player")
+	}
+	return e.run(func() error {
+		return action(e.g, e.g.Players[player])
+	})
+}
+
+// Answer gives the answer of a player to their pending decision, in the
+// same form the client sends it. A nil answer takes the default, like a
+// timeout would.
+func (e *Engine) Answer(player uint16, answer interface{}) ([]Event, error) {
+	waiting := false
+	for _, d := range e.pending {
+		waiting = waiting || d.Player == player
+	}
+	if !waiting {
+		return nil, errors.New("no decision pending for this player")
+	}
+
+	if err := e.restore(); err != nil {
+		return nil, err
+	}
+	e.answers[player] = append(e.answers[player], answer)
+	return e.exec()
+}
+
+// Step lets the bots act once, like one tick of a live game does. The
+// bot whose turn it is rolls the dice and ends its turn once it has
+// nothing left to do. Reports whether any bot did something.
+func (e *Engine) Step() (bool, []Event, error) {
+	acted := false
+	events, err := e.run(func() error {
+		g := e.g
+		acted = false
+		if g.InitPhase || g.GameOver {
+			return nil
+		}
+
+		if g.tickBots() {
+			acted = true
+			return nil
+		}
+
+		p := g.CurrentPlayer
+		if !p.GetIsBot() {
+			return nil
+		}
+		if g.DiceState == 0 {
+			acted = true
+			return g.RollDice(p, 0, 0)
+		}
+		if g.CanEndTurn() == nil {
+			acted = true
+			return g.EndTurn(p)
+		}
+		return nil
+	})
+	return acted, events, err
+}
+
+func (e *Engine) run(command func() error) ([]Event, error) {
+	if len(e.pending) > 0 {
+		return nil, errors.New("waiting for players to decide")
+	}
+
+	e.command = command
+	e.answers = make(map[uint16][]interface{})
+	e.delivered = 0
+	e.checkpoint = nil
+
+	// Only humans can stop a command, games of bots never go back
+	for _, p := range e.g.Players {
+		if !p.GetIsBot() {
+			e.checkpoint = e.save()
+			break
+		}
+	}
+
+	return e.exec()
+}
+
+// exec runs the current command and returns the events not returned
+// by an earlier run
+func (e *Engine) exec() ([]Event, error) {
+	e.used = make(map[uint16]int)
+	e.events = nil
+	e.pending = nil
+	e.spawned = nil
+
+	err := e.try()
+
+	var events []Event
+	if len(e.events) > e.delivered {
+		events = e.events[e.delivered:]
+	}
+	e.delivered = len(e.events)
+	e.events = nil
+
+	if len(e.pending) > 0 {
+		return events, nil
+	}
+
+	e.command = nil
+	e.checkpoint = nil
+	return events, err
+}
+
+func (e *Engine) try() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			abort, ok := r.(engineAbort)
+			if !ok {
+				panic(r)
+			}
+			e.events = e.events[:abort.events]
+			e.pending = []Decision{abort.decision}
+		}
+	}()
+
+	err = e.command()
+	e.runSpawned()
+	return err
+}
+
+// expect returns the next answer of a player, or stops the command if
+// they have not decided yet. Bots always take the default.
+func (e *Engine) expect(p *entities.Player, action *entities.PlayerAction) interface{} {
+	if p.GetIsBot() {
+		return nil
+	}
+
+	if i := e.used[p.Order]; i < len(e.answers[p.Order]) {
+		e.used[p.Order]++
+		return e.answers[p.Order][i]
+	}
+
+	if e.checkpoint == nil {
+		// A bot became human during the command
+		return nil
+	}
+	panic(engineAbort{
+		decision: Decision{Player: p.Order, Action: action},
+		events:   len(e.events),
+	})
+}
+
+// runSpawned runs work that a live game would start in a goroutine.
+// Anything it spawns itself but does not wait for runs after it.
+func (e *Engine) runSpawned() {
+	for len(e.spawned) > 0 {
+		fn := e.spawned[0]
+		rest := e.spawned[1:]
+		e.spawned = nil
+		fn()
+		e.spawned = append(rest, e.spawned...)
+	}
+}
+
+func (e *Engine) listen() {
+	for _, p := range e.g.Players {
+		order := p.Order
+		p.Listener = func(msg *entities.Message) {
+			e.events = append(e.events, Event{Player: order, Message: msg})
+		}
+	}
+}
+
+func (e *Engine) save() *engineCheckpoint {
+	e.g.j.Flush()
+	c := newCheckpoint(e.g)
+	c.journal = len(e.store.journal)
+	return c
+}
+
+// newCheckpoint copies the state of g that a snapshot leaves out
+func newCheckpoint(g *Game) *engineCheckpoint {
+	b, err := EncodeSnapshot(g.CreateSnapshot())
+	if err != nil {
+		panic(err)
+	}
+
+	c := &engineCheckpoint{
+		snapshot: b,
+		lastRand: g.j.lastRand,
+		stateSeq: g.StateSeq,
+		timerId:  g.TimerPhaseId,
+		offers:   make([]entities.TradeOffer, 0, len(g.CurrentOffers)),
+		bots:     cloneBots(g.bots, g),
+		embargos: make([][]bool, len(g.Players)),
+	}
+	for _, o := range g.CurrentOffers {
+		c.offers = append(c.offers, copyTradeOffer(o))
+	}
+	for i, p := range g.Players {
+		c.embargos[i] = append([]bool(nil), p.Embargos...)
+	}
+	return c
+}
+
+func (e *Engine) restore() error {
+	g := e.g
+	c := e.checkpoint
+
+	g.j.dropPending()
+	e.store.journal = e.store.journal[:c.journal]
+
+	s, err := DecodeSnapshot(c.snapshot)
+	if err != nil {
+		return err
+	}
+	if err := g.RestoreSnapshot(s); err != nil {
+		return err
+	}
+
+	g.j.lastRand = c.lastRand
+	g.StateSeq = c.stateSeq
+	g.TimerPhaseId = c.timerId
+	g.TickerPause = false
+
+	g.CurrentOffers = make([]*entities.TradeOffer, 0, len(c.offers))
+	for i := range c.offers {
+		o := copyTradeOffer(&c.offers[i])
+		g.CurrentOffers = append(g.CurrentOffers, &o)
+	}
+	g.bots = cloneBots(c.bots, g)
+	for i, p := range g.Players {
+		p.Embargos = append([]bool(nil), c.embargos[i]...)
+	}
+
+	e.listen()
+	return nil
+}
+
+func copyTradeOffer(o *entities.TradeOffer) entities.TradeOffer {
+	c := *o
+	if o.Details != nil {
+		details := *o.Details
+		c.Details = &details
+	}
+	c.Acceptances = append([]int(nil), o.Acceptances...)
+	return c
+}
+
+// spawn runs fn in a new goroutine. Headless games run it once the
+// current command or the next wait gets to it.
+func (g *Game) spawn(fn func()) {
+	if g.engine != nil {
+		g.engine.spawned = append(g.engine.spawned, fn)
+		return
+	}
+	go fn()
+}
+
+// wait blocks until everything spawned for wg is done
+func (g *Game) wait(wg *sync.WaitGroup) {
+	if g.engine != nil {
+		g.engine.runSpawned()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,188 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"sakura/entities"
+	"testing"
+)
+
+// answerForTest picks the first allowed location, or the default
+func answerForTest(d Decision) interface{} {
+	switch data := d.Action.Data.(type) {
+	case *entities.PlayerActionChooseVertex:
+		return data.Allowed[0].C
+	case *entities.PlayerActionChooseEdge:
+		return data.Allowed[0].C
+	}
+	return nil
+}
+
+func playEngineTestGame(t *testing.T, seed int64, turns int) (*Engine, []Event) {
+	t.Helper()
+
+	e, err := NewEngine(snapshotTestSettings(), seed, []string{"alice", "bob*", "carol*"})
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	g := e.Game()
+
+	all := make([]Event, 0)
+	collect := func(events []Event, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("engine call failed: %v", err)
+		}
+		all = append(all, events...)
+		for len(e.Decisions()) > 0 {
+			d := e.Decisions()[0]
+			if d.Player != 0 {
+				t.Fatalf("decision asked from bot %d", d.Player)
+			}
+			events, err := e.Answer(d.Player, answerForTest(d))
+			if err != nil {
+				t.Fatalf("answer failed: %v", err)
+			}
+			all = append(all, events...)
+		}
+	}
+
+	collect(e.Start())
+	if g.InitPhase {
+		t.Fatalf("expected the init phase to be over")
+	}
+
+	for steps := 0; g.TurnCount < turns && !g.GameOver; steps++ {
+		if steps > 5000 {
+			t.Fatalf("game stuck at turn %d", g.TurnCount)
+		}
+
+		if g.CurrentPlayer.Order != 0 {
+			acted, events, err := e.Step()
+			collect(events, err)
+			if !acted {
+				t.Fatalf("bot %d did nothing on its turn", g.CurrentPlayer.Order)
+			}
+			continue
+		}
+
+		if g.DiceState == 0 {
+			collect(e.Do(0, func(g *Game, p *entities.Player) error {
+				return g.RollDice(p, 0, 0)
+			}))
+			continue
+		}
+		collect(e.Do(0, func(g *Game, p *entities.Player) error {
+			return g.EndTurn(p)
+		}))
+	}
+
+	return e, all
+}
+
+func TestEngineRunsGameWithoutGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	e, events := playEngineTestGame(t, 42, 9)
+	if after := runtime.NumGoroutine(); after > before {
+		t.Fatalf("engine started %d goroutines", after-before)
+	}
+
+	g := e.Game()
+	if g.Ticker != nil {
+		t.Fatalf("expected no ticker")
+	}
+	if len(g.Players[0].VertexPlacements) < 2 {
+		t.Fatalf("expected the human player to have placed settlements")
+	}
+
+	rolls := 0
+	for _, ev := range events {
+		if ev.Player == 0 && ev.Message.Type == "d" {
+			rolls++
+		}
+	}
+	if rolls < 9 {
+		t.Fatalf("expected a dice event for every turn, got %d", rolls)
+	}
+
+	report, err := VerifyJournal(e.Store(), EngineGameID)
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if len(report.Violations) != 0 || report.Played != len(report.Entries) {
+		t.Fatalf("expected a clean journal, got %v after %d of %d entries",
+			report.Violations, report.Played, len(report.Entries))
+	}
+}
+
+func TestEngineIsDeterministic(t *testing.T) {
+	first, firstEvents := playEngineTestGame(t, 7, 30)
+	second, secondEvents := playEngineTestGame(t, 7, 30)
+
+	// Snapshots list everything in a fixed order, unlike the journal
+	a, _ := json.Marshal(first.Game().CreateSnapshot())
+	b, _ := json.Marshal(second.Game().CreateSnapshot())
+	if !bytes.Equal(a, b) {
+		t.Fatalf("same seed and commands gave different games")
+	}
+	if len(firstEvents) != len(secondEvents) {
+		t.Fatalf("got %d and %d events", len(firstEvents), len(secondEvents))
+	}
+}
+
+func TestEngineWaitsForDecision(t *testing.T) {
+	e, err := NewEngine(snapshotTestSettings(), 3, []string{"alice", "bob"})
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	events, err := e.Start()
+	if err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	decisions := e.Decisions()
+	if len(decisions) != 1 || decisions[0].Player != 0 ||
+		decisions[0].Action.Type != entities.PlayerActionTypeChooseVertex {
+		t.Fatalf("expected player 0 to choose a vertex, got %v", decisions)
+	}
+	prompts := 0
+	for _, ev := range events {
+		if ev.Player == 0 && ev.Message.Type == "a" {
+			prompts++
+		}
+	}
+	if prompts != 1 {
+		t.Fatalf("expected one prompt, got %d", prompts)
+	}
+
+	if _, err := e.Do(0, func(g *Game, p *entities.Player) error { return nil }); err == nil {
+		t.Fatalf("expected commands to wait for the decision")
+	}
+	if _, err := e.Answer(1, nil); err == nil {
+		t.Fatalf("expected an answer without a decision to fail")
+	}
+
+	chosen := answerForTest(decisions[0]).(entities.Coordinate)
+	events, err = e.Answer(0, chosen)
+	if err != nil {
+		t.Fatalf("answer failed: %v", err)
+	}
+	for _, ev := range events {
+		if ev.Player == 0 && ev.Message.Type == "a" {
+			if action, ok := ev.Message.Data.(*entities.PlayerAction); ok && action.Type == entities.PlayerActionTypeChooseVertex {
+				t.Fatalf("prompt for the answered decision was sent again")
+			}
+		}
+	}
+
+	v, err := e.Game().Graph.GetVertex(chosen)
+	if err != nil || v.Placement == nil || v.Placement.GetOwner().Order != 0 {
+		t.Fatalf("expected a settlement of player 0 at the chosen vertex")
+	}
+	decisions = e.Decisions()
+	if len(decisions) != 1 || decisions[0].Player != 0 ||
+		decisions[0].Action.Type != entities.PlayerActionTypeChooseEdge {
+		t.Fatalf("expected player 0 to choose an edge next, got %v", decisions)
+	}
+}
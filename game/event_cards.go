@@ -0,0 +1,130 @@
+package game
+
+import "sakura/entities"
+
+// usesEventCards checks if the event deck replaces the dice
+func (g *Game) usesEventCards() bool {
+	return g.Settings.Advanced && g.AdvancedSettings.EventCards
+}
+
+// shuffleEventCards starts a new event deck
+func (g *Game) shuffleEventCards() {
+	g.Bank.EventCardOrder = entities.GenerateEventCardOrder(g.Rand())
+	g.Bank.EventCardCursor = 0
+	g.j.WEventCardOrder(g.Bank.EventCardOrder)
+	g.j.WEventCardCursor(g.Bank.EventCardCursor)
+}
+
+// drawEventCard draws the next roll card. The deck is shuffled again when
+// the New Year card comes up, which is reported as well.
+func (g *Game) drawEventCard() (entities.EventCard, bool) {
+	cards := entities.GetEventCards()
+	newYear := false
+	for {
+		if g.Bank.EventCardCursor >= len(g.Bank.EventCardOrder) {
+			g.shuffleEventCards()
+		}
+
+		card := cards[g.Bank.EventCardOrder[g.Bank.EventCardCursor]]
+		g.Bank.EventCardCursor++
+		g.j.WEventCardCursor(g.Bank.EventCardCursor)
+
+		if card.Event == entities.EventCardNewYear {
+			newYear = true
+			g.shuffleEventCards()
+			continue
+		}
+		return card, newYear
+	}
+}
+
+// resolveEventCard plays the event printed on a drawn card after
+// production. Events that are not listed are only announced.
+func (g *Game) resolveEventCard(event entities.EventCardEvent, dieRollState *entities.DieRollState) {
+	if g.j.playing {
+		return
+	}
+
+	calls := make([]GoldCall, len(g.Players))
+	for i, p := range g.Players {
+		calls[i].Player = p
+	}
+
+	switch event {
+	case entities.EventCardEpidemic:
+		// Cities produce one card instead of two
+		for i, gain := range dieRollState.GainInfo {
+			if gain.Quantity < 2 {
+				continue
+			}
+			g.MoveCards(gain.GainerOrder, -1, gain.CardType, gain.Quantity-1, true, false)
+			dieRollState.PlayerHandDeltas[gain.GainerOrder].UpdateCards(gain.CardType, 1-gain.Quantity)
+			dieRollState.GainInfo[i].Quantity = 1
+		}
+		return
+
+	case entities.EventCardPlentifulYear:
+		// Everyone takes a resource of their choice
+		for i := range calls {
+			calls[i].Quantity = 1
+		}
+
+	case entities.EventCardTournament:
+		// The players who played the most knights take a resource
+		g.giveEventCardLeaders(calls, func(p *entities.Player) int {
+			deck := p.CurrentHand.DevelopmentCardDeckMap[entities.DevelopmentCardKnight]
+			if deck == nil {
+				return 0
+			}
+			return int(deck.NumUsed)
+		})
+
+	case entities.EventCardCalmSea:
+		// The players with the most harbors take a resource
+		g.giveEventCardLeaders(calls, g.countPlayerHarbors)
+
+	default:
+		return
+	}
+
+	for _, call := range calls {
+		if call.Quantity > 0 {
+			g.spawn(func() { g.GiveGold(calls) })
+			return
+		}
+	}
+}
+
+// giveEventCardLeaders gives one card to each player tied for the highest
+// non-zero count
+func (g *Game) giveEventCardLeaders(calls []GoldCall, count func(p *entities.Player) int) {
+	best := 0
+	for _, p := range g.Players {
+		if c := count(p); c > best {
+			best = c
+		}
+	}
+	if best == 0 {
+		return
+	}
+	for i, p := range g.Players {
+		if count(p) == best {
+			calls[i].Quantity = 1
+		}
+	}
+}
+
+// countPlayerHarbors counts the harbors a player has a settlement or city on
+func (g *Game) countPlayerHarbors(p *entities.Player) int {
+	count := 0
+	for _, port := range g.Ports {
+		for _, v := range port.Vertices {
+			if v != nil && v.Placement != nil && v.Placement.GetOwner() == p &&
+				(v.Placement.GetType() == entities.BTSettlement || v.Placement.GetType() == entities.BTCity) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
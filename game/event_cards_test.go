@@ -0,0 +1,207 @@
+package game
+
+import (
+	"math/rand"
+	"sakura/entities"
+	"testing"
+)
+
+func newEventCardsTestGame(t *testing.T, store Store) *Game {
+	t.Helper()
+
+	settings := snapshotTestSettings()
+	settings.Advanced = true
+	g := &Game{
+		Store:            store,
+		Settings:         settings,
+		AdvancedSettings: entities.AdvancedSettings{EventCards: true},
+	}
+	if _, err := g.Initialize("event-cards", 3); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	stopTickerForTest(g)
+	return g
+}
+
+func TestEventCardDeck(t *testing.T) {
+	cards := entities.GetEventCards()
+	if len(cards) != entities.EventCardDeckSize+1 {
+		t.Fatalf("expected %d cards, got %d", entities.EventCardDeckSize+1, len(cards))
+	}
+
+	rolls := make(map[[2]int]bool)
+	events := 0
+	for _, c := range cards[:entities.EventCardDeckSize] {
+		rolls[[2]int{c.Red, c.White}] = true
+		if c.Event != entities.EventCardNone {
+			events++
+		}
+	}
+	if len(rolls) != entities.EventCardDeckSize {
+		t.Fatalf("expected every roll of the dice once, got %d", len(rolls))
+	}
+	if events != 12 {
+		t.Fatalf("expected 12 event cards, got %d", events)
+	}
+
+	for seed := int64(0); seed < 20; seed++ {
+		order := entities.GenerateEventCardOrder(rand.New(rand.NewSource(seed)))
+		if len(order) != len(cards) {
+			t.Fatalf("expected the whole deck, got %d cards", len(order))
+		}
+		pos := -1
+		for i, c := range order {
+			if c == entities.EventCardDeckSize {
+				pos = i
+			}
+		}
+		if pos < entities.EventCardDeckSize-entities.EventCardNewYearDepth {
+			t.Fatalf("expected the New Year card at the bottom, got position %d", pos)
+		}
+	}
+}
+
+func TestEventCardsReplaceDice(t *testing.T) {
+	g := newEventCardsTestGame(t, &noopStore{})
+	if len(g.Bank.EventCardOrder) != entities.EventCardDeckSize+1 {
+		t.Fatal("expected a shuffled event deck")
+	}
+	g.InitPhase = false
+
+	// Start with the 1+1 card to keep the robber out of it
+	order := g.Bank.EventCardOrder
+	for i, c := range order {
+		if c == 0 {
+			order[0], order[i] = order[i], order[0]
+		}
+	}
+	if err := g.RollDice(g.CurrentPlayer, 0, 0); err != nil {
+		t.Fatalf("roll failed: %v", err)
+	}
+	if g.LastRollRed != 1 || g.LastRollWhite != 1 || g.Bank.EventCardCursor != 1 {
+		t.Fatalf("expected the 1+1 card, got %d+%d", g.LastRollRed, g.LastRollWhite)
+	}
+
+	// Chosen dice do not draw a card
+	g.DiceState = 0
+	if err := g.RollDice(g.CurrentPlayer, 2, 3); err != nil {
+		t.Fatalf("roll failed: %v", err)
+	}
+	if g.LastRollRed != 2 || g.LastRollWhite != 3 || g.Bank.EventCardCursor != 1 {
+		t.Fatal("expected the chosen roll without drawing")
+	}
+}
+
+func TestEventCardsNewYearReshuffles(t *testing.T) {
+	store := &memoryStore{skipSnapshot: true}
+	g := newEventCardsTestGame(t, store)
+
+	drawn := make(map[int]bool)
+	for {
+		card, newYear := g.drawEventCard()
+		if newYear {
+			if len(drawn) < entities.EventCardDeckSize-entities.EventCardNewYearDepth {
+				t.Fatalf("expected the New Year card near the bottom, drew %d cards", len(drawn))
+			}
+			if g.Bank.EventCardCursor != 1 {
+				t.Fatal("expected a new deck after the New Year card")
+			}
+			break
+		}
+		key := card.Red*10 + card.White
+		if drawn[key] {
+			t.Fatalf("drew %d+%d twice", card.Red, card.White)
+		}
+		drawn[key] = true
+	}
+	g.j.Flush()
+
+	resumed := newEventCardsTestGame(t, &memoryStore{journal: store.journal})
+	if resumed.Bank.EventCardCursor != g.Bank.EventCardCursor {
+		t.Fatalf("expected cursor %d after replay, got %d", g.Bank.EventCardCursor, resumed.Bank.EventCardCursor)
+	}
+	for i := range g.Bank.EventCardOrder {
+		if resumed.Bank.EventCardOrder[i] != g.Bank.EventCardOrder[i] {
+			t.Fatal("expected the same deck after replay")
+		}
+	}
+}
+
+func TestEventCardsEpidemic(t *testing.T) {
+	g := newEventCardsTestGame(t, &noopStore{})
+	g.InitPhase = false
+	p := g.CurrentPlayer
+
+	var tile *entities.Tile
+	for _, tl := range g.sortedTiles() {
+		if tl.Number == 8 && tl != g.Robber.Tile && tl.Type >= entities.TileTypeWood && tl.Type <= entities.TileTypeOre {
+			tile = tl
+			break
+		}
+	}
+	if tile == nil {
+		t.Skip("no resource tile with an 8")
+	}
+	v := g.Graph.Vertices[tile.GetVertexCoordinates()[0]]
+	if err := p.BuildAtVertex(v, entities.BTCity); err != nil {
+		t.Fatalf("failed to place city: %v", err)
+	}
+
+	// Draw the 8 with the epidemic first
+	cards := entities.GetEventCards()
+	order := g.Bank.EventCardOrder
+	for i, c := range order {
+		if cards[c].Event == entities.EventCardEpidemic && cards[c].Red+cards[c].White == 8 {
+			order[0], order[i] = order[i], order[0]
+		}
+	}
+
+	ct := entities.CardType(tile.Type)
+	bank := g.Bank.Hand.GetCardDeck(ct).Quantity
+	if err := g.RollDice(p, 0, 0); err != nil {
+		t.Fatalf("roll failed: %v", err)
+	}
+	if q := p.CurrentHand.GetCardDeck(ct).Quantity; q != 1 {
+		t.Fatalf("expected the city to produce one card, got %d", q)
+	}
+	if q := g.Bank.Hand.GetCardDeck(ct).Quantity; q != bank-1 {
+		t.Fatalf("expected the bank to give one card, got %d", bank-q)
+	}
+}
+
+func TestEventCardsLeaders(t *testing.T) {
+	g := newEventCardsTestGame(t, &noopStore{})
+	calls := make([]GoldCall, len(g.Players))
+	for i, p := range g.Players {
+		calls[i].Player = p
+	}
+
+	// Nobody gains without a knight
+	knights := func(p *entities.Player) int { return []int{0, 2, 2}[p.Order] }
+	g.giveEventCardLeaders(calls, func(p *entities.Player) int { return 0 })
+	for _, call := range calls {
+		if call.Quantity != 0 {
+			t.Fatal("expected no cards without knights")
+		}
+	}
+
+	g.giveEventCardLeaders(calls, knights)
+	if calls[0].Quantity != 0 || calls[1].Quantity != 1 || calls[2].Quantity != 1 {
+		t.Fatalf("expected the tied leaders to gain, got %v", calls)
+	}
+}
+
+func TestEventCardsCountHarbors(t *testing.T) {
+	g := newEventCardsTestGame(t, &noopStore{})
+	p := g.Players[0]
+	if g.countPlayerHarbors(p) != 0 {
+		t.Fatal("expected no harbors")
+	}
+
+	if err := p.BuildAtVertex(g.Ports[0].Vertices[0], entities.BTSettlement); err != nil {
+		t.Fatalf("failed to place settlement: %v", err)
+	}
+	if g.countPlayerHarbors(p) != 1 || g.countPlayerHarbors(g.Players[1]) != 0 {
+		t.Fatal("expected one harbor for the settlement")
+	}
+}
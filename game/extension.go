@@ -0,0 +1,53 @@
+package game
+
+import (
+	"fmt"
+	"sakura/entities"
+	"sakura/maps"
+)
+
+// MaxPlayers is the most players a game can seat
+const MaxPlayers = 6
+
+// Players a map is made for when it does not say
+const defaultMapMaxPlayers = 4
+
+// isExtensionGame checks if the game is played with the 5-6 player
+// extension rules
+func isExtensionGame(numPlayers uint16) bool {
+	return numPlayers >= entities.ExtensionPlayers
+}
+
+// getExtensionMapName returns the 5-6 player board of the mode
+func getExtensionMapName(mode entities.GameMode) string {
+	if mode.HasSeafarers() {
+		return maps.SeafarersHeadingForNewShoresExtension
+	}
+	return maps.BaseExtensionMapName
+}
+
+// ValidatePlayerCount checks the map of the settings is made for that many
+// players in the game mode
+func ValidatePlayerCount(settings entities.GameSettings, numPlayers uint16) error {
+	if numPlayers > MaxPlayers {
+		return fmt.Errorf("at most %d players can play", MaxPlayers)
+	}
+
+	defn := settings.MapDefn
+	if defn == nil {
+		defn = maps.GetBaseMap()
+	}
+	limit := defn.MaxPlayers
+	if limit == 0 {
+		limit = defaultMapMaxPlayers
+	}
+	if int(numPlayers) > limit {
+		return fmt.Errorf("map %s is for up to %d players, play %s instead", defn.Name, limit, getExtensionMapName(settings.Mode))
+	}
+
+	// The Seafarers boards have no room for the extension in the other modes
+	if isExtensionGame(numPlayers) && !settings.Mode.HasSeafarers() && defn.Scenario != nil && defn.Scenario.Expansion == "Seafarers" {
+		return fmt.Errorf("map %s needs Seafarers for %d players, play %s instead", defn.Name, numPlayers, getExtensionMapName(settings.Mode))
+	}
+	return nil
+}
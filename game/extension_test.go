@@ -0,0 +1,132 @@
+package game
+
+import (
+	"sakura/entities"
+	"sakura/maps"
+	"testing"
+)
+
+func extensionTestSettings(t *testing.T, mode entities.GameMode, mapName string) entities.GameSettings {
+	t.Helper()
+
+	defn := maps.GetMapByName(mapName)
+	if defn == nil {
+		t.Fatalf("map %q missing", mapName)
+	}
+	return entities.GameSettings{
+		Mode:          mode,
+		MapName:       mapName,
+		MapDefn:       defn,
+		VictoryPoints: 10,
+		Speed:         entities.NormalSpeed,
+	}
+}
+
+func newExtensionTestGame(t *testing.T, store Store, settings entities.GameSettings, players uint16) *Game {
+	t.Helper()
+
+	g := &Game{Store: store, Seed: 2, Settings: settings}
+	if _, err := g.Initialize("extension", players); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	stopTickerForTest(g)
+	return g
+}
+
+func TestExtensionBankAndDeck(t *testing.T) {
+	small := newExtensionTestGame(t, &noopStore{}, extensionTestSettings(t, entities.Base, maps.BaseExtensionMapName), 4)
+	big := newExtensionTestGame(t, &noopStore{}, extensionTestSettings(t, entities.Base, maps.BaseExtensionMapName), 6)
+
+	if q := small.Bank.Hand.GetCardDeck(entities.CardTypeWood).Quantity; q != entities.IQBaseResource {
+		t.Fatalf("expected %d wood for 4 players, got %d", entities.IQBaseResource, q)
+	}
+	if q := big.Bank.Hand.GetCardDeck(entities.CardTypeOre).Quantity; q != entities.IQExtensionResource {
+		t.Fatalf("expected %d ore for 6 players, got %d", entities.IQExtensionResource, q)
+	}
+	if n := len(small.Bank.DevelopmentCardOrder[0]); n != 25 {
+		t.Fatalf("expected 25 development cards for 4 players, got %d", n)
+	}
+	if n := len(big.Bank.DevelopmentCardOrder[0]); n != 34 {
+		t.Fatalf("expected 34 development cards for 6 players, got %d", n)
+	}
+	knights := 0
+	for _, c := range big.Bank.DevelopmentCardOrder[0] {
+		if c == entities.DevelopmentCardKnight {
+			knights++
+		}
+	}
+	if knights != int(entities.IQExtensionKnight) {
+		t.Fatalf("expected %d knights, got %d", entities.IQExtensionKnight, knights)
+	}
+
+	ck := newExtensionTestGame(t, &noopStore{}, extensionTestSettings(t, entities.CitiesAndKnights, maps.BaseExtensionMapName), 5)
+	if q := ck.Bank.Hand.GetCardDeck(entities.CardTypePaper).Quantity; q != entities.IQExtensionCKCommodity {
+		t.Fatalf("expected %d paper for 5 players, got %d", entities.IQExtensionCKCommodity, q)
+	}
+}
+
+func TestValidatePlayerCount(t *testing.T) {
+	accepted := []struct {
+		mode    entities.GameMode
+		mapName string
+		players uint16
+	}{
+		{entities.Base, maps.BaseMapName, 4},
+		{entities.Base, maps.BaseExtensionMapName, 6},
+		{entities.CitiesAndKnights, maps.BaseExtensionMapName, 5},
+		{entities.Seafarers, maps.SeafarersHeadingForNewShoresExtension, 6},
+		{entities.Seafarers, maps.SeafarersNewWorldVariant, 5},
+	}
+	for _, c := range accepted {
+		if err := ValidatePlayerCount(extensionTestSettings(t, c.mode, c.mapName), c.players); err != nil {
+			t.Fatalf("expected %d players on %q: %v", c.players, c.mapName, err)
+		}
+	}
+
+	rejected := []struct {
+		mode    entities.GameMode
+		mapName string
+		players uint16
+	}{
+		{entities.Base, maps.BaseMapName, 5},
+		{entities.CitiesAndKnights, maps.BaseMapName, 6},
+		{entities.Seafarers, maps.SeafarersHeadingForNewShores, 5},
+		{entities.Base, maps.SeafarersHeadingForNewShoresExtension, 5},
+		{entities.Base, maps.BaseExtensionMapName, 7},
+	}
+	for _, c := range rejected {
+		if err := ValidatePlayerCount(extensionTestSettings(t, c.mode, c.mapName), c.players); err == nil {
+			t.Fatalf("expected %d players on %q to be rejected", c.players, c.mapName)
+		}
+	}
+
+	g := &Game{Store: &noopStore{}, Settings: extensionTestSettings(t, entities.Base, maps.BaseMapName)}
+	if _, err := g.Initialize("extension", 5); err == nil {
+		t.Fatal("expected the base map to refuse 5 players")
+	}
+	if g.Initialized {
+		t.Fatal("expected a refused game to stay uninitialized")
+	}
+}
+
+func TestExtensionForcesSpecialBuild(t *testing.T) {
+	small := newExtensionTestGame(t, &noopStore{}, extensionTestSettings(t, entities.Base, maps.BaseExtensionMapName), 4)
+	if small.Settings.SpecialBuild {
+		t.Fatal("expected special building to stay optional for 4 players")
+	}
+
+	store := &memoryStore{}
+	big := newExtensionTestGame(t, store, extensionTestSettings(t, entities.Seafarers, maps.SeafarersHeadingForNewShoresExtension), 6)
+	if !big.Settings.SpecialBuild {
+		t.Fatal("expected special building for 6 players")
+	}
+	big.j.Flush()
+
+	resumed := newExtensionTestGame(t, &memoryStore{journal: store.journal}, extensionTestSettings(t, entities.Seafarers, maps.SeafarersHeadingForNewShoresExtension), 6)
+	if !resumed.Settings.SpecialBuild {
+		t.Fatal("expected special building after replay")
+	}
+	if q := resumed.Bank.Hand.GetCardDeck(entities.CardTypeWheat).Quantity; q != entities.IQExtensionResource {
+		t.Fatalf("expected the bigger bank after replay, got %d wheat", q)
+	}
+}
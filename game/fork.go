@@ -0,0 +1,132 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ForkSeat gives a seat of a forked game to another user. A username
+// ending with * seats a bot. Seats left empty keep the parent's player.
+type ForkSeat struct {
+	Id       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// SetupLen is the number of entries written while the board was set up.
+// A game can only be forked after its setup.
+func (r *Replay) SetupLen() int {
+	for i, e := range r.entries {
+		switch {
+		case e.Type <= JSetRobber, e.Type == JSetPirate:
+		default:
+			return i
+		}
+	}
+	return len(r.entries)
+}
+
+// Fork stores a new game whose journal is this journal up to the current
+// position, followed by a JForkedFrom entry and the changed seats. The
+// new game resumes like any restarted game once a hub loads it.
+func (r *Replay) Fork(store Store, id string, seats []ForkSeat) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if id == r.ID {
+		return errors.New("fork needs a new game id")
+	}
+	if r.position < r.SetupLen() {
+		return fmt.Errorf("cannot fork before entry %d, the board is not set up", r.SetupLen())
+	}
+	if len(seats) > len(r.g.Players) {
+		return fmt.Errorf("game has %d seats, got %d", len(r.g.Players), len(seats))
+	}
+	if exists, _ := store.CheckIfJournalExists(id); exists {
+		return errors.New("game already exists")
+	}
+
+	// Copy the stored entries as they are, so older formats still
+	// migrate the same way when the fork is played
+	byteEntries, err := store.ReadJournal(r.ID)
+	if err != nil {
+		return err
+	}
+	prefix := make([][]byte, r.position)
+	for _, b := range byteEntries {
+		var e JournalEntry
+		if err := msgpack.Unmarshal(b, &e); err != nil {
+			return err
+		}
+		if e.Index >= 1 && e.Index <= r.position && prefix[e.Index-1] == nil {
+			prefix[e.Index-1] = b
+		}
+	}
+	for i, b := range prefix {
+		if b == nil {
+			return fmt.Errorf("journal entry %d is missing", i+1)
+		}
+	}
+
+	extra := []JournalEntry{{Type: JForkedFrom, Fields: []interface{}{r.ID, r.position}}}
+	final := make([]ForkSeat, len(r.g.Players))
+	for i, p := range r.g.Players {
+		final[i] = ForkSeat{Id: p.Id, Username: p.Username}
+	}
+	for i, seat := range seats {
+		if seat.Username == "" {
+			continue
+		}
+		order := r.g.Players[i].Order
+		extra = append(extra,
+			JournalEntry{Type: JSetUsername, Fields: []interface{}{order, seat.Username}},
+			JournalEntry{Type: JSetId, Fields: []interface{}{order, seat.Id}},
+		)
+		final[i] = seat
+	}
+	for i := range extra {
+		extra[i].Index = r.position + i + 1
+		b, err := msgpack.Marshal(extra[i])
+		if err != nil {
+			return err
+		}
+		prefix = append(prefix, b)
+	}
+
+	settings, err := msgpack.Marshal(r.g.Settings)
+	if err != nil {
+		return err
+	}
+
+	if err := store.Init(id); err != nil {
+		return err
+	}
+	if err := store.WriteGameServer(id); err != nil {
+		return err
+	}
+	if err := store.WriteGameSettings(id, settings); err != nil {
+		return err
+	}
+	if err := store.WriteJournalEntries(id, prefix); err != nil {
+		return err
+	}
+
+	participantIds := make([]string, 0, len(final))
+	for _, seat := range final {
+		if seat.Id == "" || strings.HasSuffix(seat.Username, "*") {
+			continue
+		}
+		participantIds = append(participantIds, seat.Id)
+		store.WriteGameIdForUser(id, seat.Id, &r.g.Settings)
+	}
+	sort.Strings(participantIds)
+	if err := store.WriteGameParticipants(id, participantIds); err != nil {
+		return err
+	}
+
+	// Written last, a hub only resumes games with players
+	return store.WriteGamePlayers(id, int32(r.NumPlayers))
+}
@@ -0,0 +1,142 @@
+package game
+
+import (
+	"bytes"
+	"testing"
+)
+
+type forkTestStore struct {
+	noopStore
+	journals     map[string][][]byte
+	players      map[string]int
+	participants map[string][]string
+}
+
+func newForkTestStore() *forkTestStore {
+	return &forkTestStore{
+		journals:     map[string][][]byte{},
+		players:      map[string]int{},
+		participants: map[string][]string{},
+	}
+}
+
+func (s *forkTestStore) WriteJournalEntries(id string, entries [][]byte) error {
+	s.journals[id] = append(s.journals[id], entries...)
+	return nil
+}
+func (s *forkTestStore) ReadJournal(id string) ([][]byte, error) {
+	return s.journals[id], nil
+}
+func (s *forkTestStore) CheckIfJournalExists(id string) (bool, error) {
+	return len(s.journals[id]) > 0, nil
+}
+func (s *forkTestStore) WriteGamePlayers(id string, numPlayers int32) error {
+	s.players[id] = int(numPlayers)
+	return nil
+}
+func (s *forkTestStore) ReadGamePlayers(id string) (int, error) {
+	return s.players[id], nil
+}
+func (s *forkTestStore) WriteGameParticipants(id string, participantIds []string) error {
+	s.participants[id] = participantIds
+	return nil
+}
+
+func encodedForkSnapshot(t *testing.T, g *Game, journalIndex int) []byte {
+	t.Helper()
+	s := g.CreateSnapshot()
+	s.JournalIndex = journalIndex
+	s.ParentID = ""
+	s.ParentIndex = 0
+	b, err := EncodeSnapshot(s)
+	if err != nil {
+		t.Fatalf("failed to encode snapshot: %v", err)
+	}
+	return b
+}
+
+func TestForkResumesFromParentHistory(t *testing.T) {
+	live := playSnapshotTestGame(t, &memoryStore{}, 8)
+
+	store := newForkTestStore()
+	store.journals["parent"] = live.Store.(*memoryStore).journal
+	store.players["parent"] = 3
+
+	replay, err := NewReplay(store, "parent")
+	if err != nil {
+		t.Fatalf("failed to load replay: %v", err)
+	}
+
+	if err := replay.Seek(replay.SetupLen() - 1); err != nil {
+		t.Fatalf("seek failed: %v", err)
+	}
+	if err := replay.Fork(store, "early", nil); err == nil {
+		t.Fatal("expected fork before the board is set up to fail")
+	}
+
+	if err := replay.Seek(replay.Len()); err != nil {
+		t.Fatalf("seek failed: %v", err)
+	}
+	if err := replay.SeekTurn(4); err != nil {
+		t.Fatalf("seek turn failed: %v", err)
+	}
+	position := replay.Frame(false).Position
+
+	if err := replay.Fork(store, "parent", nil); err == nil {
+		t.Fatal("expected fork onto the parent id to fail")
+	}
+	if err := replay.Fork(store, "same", nil); err != nil {
+		t.Fatalf("fork failed: %v", err)
+	}
+	if err := replay.Fork(store, "same", nil); err == nil {
+		t.Fatal("expected fork onto an existing game to fail")
+	}
+
+	same := &Game{Store: store, Settings: snapshotTestSettings()}
+	if _, err := same.Initialize("same", 3); err != nil {
+		t.Fatalf("initialize fork failed: %v", err)
+	}
+	stopTickerForTest(same)
+
+	if same.ParentID != "parent" || same.ParentIndex != position {
+		t.Fatalf("expected parent reference parent@%d, got %s@%d", position, same.ParentID, same.ParentIndex)
+	}
+	if same.TurnCount != 4 {
+		t.Fatalf("expected fork at turn 4, got %d", same.TurnCount)
+	}
+	want := encodedForkSnapshot(t, replay.Game(), 0)
+	if got := encodedForkSnapshot(t, same, 0); !bytes.Equal(got, want) {
+		t.Fatal("forked game does not match the parent at the fork point")
+	}
+
+	// Seats can go to other users and bots
+	seats := []ForkSeat{{}, {Id: "r", Username: "Robo*"}, {Id: "d", Username: "dave"}}
+	if err := replay.Fork(store, "seats", seats); err != nil {
+		t.Fatalf("fork with seats failed: %v", err)
+	}
+	forked := &Game{Store: store, Settings: snapshotTestSettings()}
+	if _, err := forked.Initialize("seats", 3); err != nil {
+		t.Fatalf("initialize fork failed: %v", err)
+	}
+	stopTickerForTest(forked)
+
+	if kept := replay.Game().Players[0].Username; forked.Players[0].Username != kept {
+		t.Fatalf("expected %s to keep the first seat, got %s", kept, forked.Players[0].Username)
+	}
+	if forked.Players[1].Username != "Robo*" || !forked.Players[1].GetIsBot() {
+		t.Fatal("expected a bot in the second seat")
+	}
+	if forked.Players[2].Id != "d" || forked.Players[2].Username != "dave" {
+		t.Fatal("expected dave in the third seat")
+	}
+	participants := map[string]bool{}
+	for _, id := range store.participants["seats"] {
+		participants[id] = true
+	}
+	if !participants["d"] || participants["r"] || len(participants) != 2 {
+		t.Fatalf("expected the humans as participants, got %v", store.participants["seats"])
+	}
+	if store.players["seats"] != 3 {
+		t.Fatalf("expected 3 players stored, got %d", store.players["seats"])
+	}
+}
@@ -20,6 +20,9 @@ type (
 		Settings         entities.GameSettings
 		AdvancedSettings entities.AdvancedSettings
 
+		// Prices and piece limits of the mode, map and house rules
+		BuildRules *entities.BuildRules
+
 		DiceState     int
 		LastRollWhite int
 		LastRollRed   int
@@ -51,6 +54,29 @@ type (
 		SpecialBuildPhase   bool
 		SpecialBuildStarter *entities.Player
 
+		// Number of turns ended so far, used to schedule snapshots
+		TurnCount int
+
+		// Number of rounds of normal turns played so far
+		RoundCount int
+
+		// Player whose turn ends sends the robber back to the desert
+		RobberReturnAfter *entities.Player
+
+		// Ended turns between snapshots; zero uses DefaultSnapshotInterval
+		// and a negative value disables snapshots
+		SnapshotInterval int
+
+		// Seed of the game RNG, set before Initialize to replay a game
+		// with the same randomness. Zero picks a seed from the clock.
+		Seed       int64
+		rand       *rand.Rand
+		randSource *randomSource
+
+		// Game and journal index this game was forked from, if any
+		ParentID    string
+		ParentIndex int
+
 		Ticker       *time.Ticker
 		TickerPause  bool
 		Paused       bool
@@ -61,8 +87,14 @@ type (
 
 		DispCoordMap map[entities.Coordinate]entities.FloatCoordinate
 
-		j  Journal
-		ai AI
+		j    Journal
+		bots map[uint16]BotStrategy
+
+		// Set for games driven by an Engine instead of the ticker
+		engine *Engine
+
+		// Copies made by Clone send no messages and keep no journal
+		simulated bool
 
 		OfferCounter  int
 		CurrentOffers []*entities.TradeOffer
@@ -79,6 +111,14 @@ type (
 		ScenarioDesertRegionByTile map[entities.Coordinate]int
 		ScenarioDesertMainRegion   int
 		ScenarioDesertAwarded      map[*entities.Player]map[int]bool
+		ScenarioTribeMarkers       []*entities.TribeMarker
+		ScenarioTribeHarbors       map[*entities.Player][]entities.PortType
+		ScenarioClothVillages      []*entities.ClothVillage
+		ScenarioCloth              map[*entities.Player]int
+		ScenarioFortresses         []*entities.PirateFortress
+		ScenarioWarships           map[*entities.Edge]bool
+		ScenarioPirateRoute        []*entities.Tile
+		ScenarioWonders            []*entities.Wonder
 
 		mutex       sync.Mutex
 		ActionMutex sync.Mutex
@@ -93,6 +133,9 @@ type (
 		PlayerSecretStates []*entities.PlayerSecretState `msgpack:"pss"`
 		GameOver           bool                          `msgpack:"g"`
 		Winner             int                           `msgpack:"w"`
+		Seed               int64                         `msgpack:"sd"`
+		ParentID           string                        `msgpack:"pa,omitempty"`
+		ParentIndex        int                           `msgpack:"pi,omitempty"`
 	}
 
 	Store interface {
@@ -112,7 +155,9 @@ type (
 		WriteJournalEntries(id string, entries [][]byte) error
 		WriteGameState(id string, state []byte) error
 		WriteGameIdForUser(gameId, userId string, settings *entities.GameSettings) error
+		WriteGameSnapshot(id string, snapshot []byte) error
 		ReadJournal(id string) ([][]byte, error)
+		ReadGameSnapshot(id string) ([]byte, error)
 		ReadGamePlayers(id string) (int, error)
 		ReadUser(id string) (map[string]interface{}, error)
 		GetOfficalMapNames() []string
@@ -303,48 +348,37 @@ func (game *Game) Initialize(id string, numPlayers uint16) (*Game, error) {
 		}
 	}()
 
-	gameMode := game.Settings.Mode
-	if gameMode != entities.Base && gameMode != entities.CitiesAndKnights && gameMode != entities.Seafarers {
-		gameMode = entities.Base
-	}
-
-	game.Initialized = true
-	game.Mode = gameMode
-	game.ID = id
-
-	// Init
-	game.ai.g = game
-	game.j.g = game
-	game.j.Init()
+	resume := false
 	if val, err := game.Store.CheckIfJournalExists(id); err == nil && val {
-		game.j.playing = true // Prevent anything from being written during init if journal exists
+		resume = true
 	}
-	game.InitPhase = true
-
-	rand.Seed(time.Now().UnixNano())
-
-	game.TimerVals = timerValuesForSpeed(game.Settings.Speed)
+	if !resume {
+		if err := ValidatePlayerCount(game.Settings, numPlayers); err != nil {
+			return game, err
+		}
 
-	// Dice init
-	game.DiceState = 0
-	game.LastRollRed = 1
-	game.LastRollWhite = 1
-	if game.Settings.Mode == entities.CitiesAndKnights {
-		game.LastRollEvent = 4
+		// Special building is part of the 5-6 player rules
+		if isExtensionGame(numPlayers) {
+			game.Settings.SpecialBuild = true
+		}
 	}
-	game.NumPlayers = numPlayers
-
-	// Graph objects
-	game.InitGraph()
-	game.InitWithGameMode()
-	game.DiceStats = &entities.DiceStats{}
+	game.initState(id, numPlayers, resume)
 
 	// At this point, all data structures should be initialized
 	// Check if journal exists and start processing journal instead if yes
-	if game.j.playing {
-		game.j.playing = false
+	if resume {
 		game.InitGraph()
 		game.j.Play()
+		game.configureScenarioHooks()
+
+		// Entries appended from now on use the current format
+		if game.j.version != JournalFormatVersion {
+			game.j.WJournalFormat()
+		}
+		// Older journals have no seed, keep the one picked above
+		if !game.j.seeded {
+			game.j.WSetSeed()
+		}
 		game.startTicker()
 		return game, nil
 	}
@@ -353,6 +387,16 @@ func (game *Game) Initialize(id string, numPlayers uint16) (*Game, error) {
 	if game.Settings.MapDefn == nil {
 		game.Settings.MapDefn = maps.GetBaseMap()
 	}
+
+	// Every New World game rolls its own islands from the game seed
+	if sc := game.Settings.MapDefn.Scenario; sc != nil && sc.Key == maps.NewWorldScenarioKey {
+		defn, err := maps.GenerateNewWorldMap(maps.DefaultNewWorldOptions(int(numPlayers)), game.Seed)
+		if err != nil {
+			game.Initialized = false
+			return nil, err
+		}
+		game.Settings.MapDefn = defn
+	}
 	game.configureScenarioHooks()
 
 	// Initialize graph
@@ -370,8 +414,50 @@ func (game *Game) Initialize(id string, numPlayers uint16) (*Game, error) {
 	return game, nil
 }
 
+// initState sets up an empty game. A game that will be rebuilt from its
+// journal writes nothing while being set up.
+func (game *Game) initState(id string, numPlayers uint16, fromJournal bool) {
+	gameMode := game.Settings.Mode
+	if gameMode != entities.Base && gameMode != entities.CitiesAndKnights && gameMode != entities.Seafarers && gameMode != entities.SeafarersCitiesAndKnights {
+		gameMode = entities.Base
+	}
+
+	game.Initialized = true
+	game.Mode = gameMode
+	game.ID = id
+
+	// Init
+	game.j.g = game
+	game.j.Init()
+	game.seedRandom(game.Seed)
+	if fromJournal {
+		game.j.playing = true // Prevent anything from being written during init if journal exists
+	} else {
+		game.j.WJournalFormat()
+		game.j.WSetSeed()
+	}
+	game.InitPhase = true
+
+	game.TimerVals = timerValuesForSpeed(game.Settings.Speed)
+
+	// Dice init
+	game.DiceState = 0
+	game.LastRollRed = 1
+	game.LastRollWhite = 1
+	if game.Settings.Mode.HasCitiesAndKnights() {
+		game.LastRollEvent = 4
+	}
+	game.NumPlayers = numPlayers
+
+	// Graph objects
+	game.InitGraph()
+	game.InitWithGameMode()
+	game.DiceStats = &entities.DiceStats{}
+	game.j.playing = false
+}
+
 func (g *Game) startTicker() {
-	if g.Ticker != nil {
+	if g.Ticker != nil || g.engine != nil {
 		return
 	}
 
@@ -385,16 +471,25 @@ func (game *Game) InitWithGameMode() error {
 	game.j.WSetAdvancedSettings()
 	game.j.WSetGameSettings()
 
+	var mapRules *entities.BuildRules
+	if game.Settings.MapDefn != nil {
+		mapRules = game.Settings.MapDefn.BuildRules
+	}
+	game.BuildRules = entities.GetBuildRules(game.Mode, mapRules, game.Settings.BuildRules)
+
 	// Create players
 	players, err := entities.GetNewPlayers(game.Mode, game.NumPlayers)
 	if err != nil {
 		return err
 	}
+	for _, p := range players {
+		p.BuildablesLeft = game.BuildRules.GetPieces()
+	}
 	game.Players = players
 	game.CurrentPlayer = players[0]
 
 	// Init bank
-	game.Bank, _ = entities.GetNewBank(game.Mode)
+	game.Bank, _ = entities.GetNewBankForPlayers(game.Mode, game.NumPlayers, game.Rand())
 
 	// Extra points
 	game.ExtraVictoryPoints = &entities.ExtraVictoryPoints{}
@@ -405,8 +500,16 @@ func (game *Game) InitWithGameMode() error {
 	game.ScenarioLandHome = make(map[*entities.Player]map[int]bool)
 	game.ScenarioDesertRegionByTile = make(map[entities.Coordinate]int)
 	game.ScenarioDesertAwarded = make(map[*entities.Player]map[int]bool)
-
-	if game.Mode == entities.CitiesAndKnights {
+	game.ScenarioTribeMarkers = nil
+	game.ScenarioTribeHarbors = make(map[*entities.Player][]entities.PortType)
+	game.ScenarioClothVillages = nil
+	game.ScenarioCloth = make(map[*entities.Player]int)
+	game.ScenarioFortresses = nil
+	game.ScenarioWarships = make(map[*entities.Edge]bool)
+	game.ScenarioPirateRoute = nil
+	game.ScenarioWonders = nil
+
+	if game.Mode.HasCitiesAndKnights() {
 		// Merchant
 		game.Merchant = &entities.Merchant{}
 		game.MerchantFleets = [9]int{-1, 4, 4, 4, 4, 4, 4, 4, 4}
@@ -432,6 +535,10 @@ func (game *Game) InitWithGameMode() error {
 		game.j.WDevelopmentCardOrder(game.Bank.DevelopmentCardOrder[0], 0)
 	}
 
+	if game.usesEventCards() {
+		game.shuffleEventCards()
+	}
+
 	return nil
 }
 
@@ -474,7 +581,19 @@ func (g *Game) Terminate() {
 		}
 	}
 
-	g.j.Flush()
+	if err := g.j.Flush(); err != nil {
+		log.Println("journal entries lost on terminate:", err)
+	}
+}
+
+// JournalError reports why the game refuses actions because its history
+// cannot be stored, or nil if it can
+func (g *Game) JournalError() error {
+	return g.j.Err()
+}
+
+func (g *Game) JournalStats() JournalStats {
+	return g.j.Stats()
 }
 
 func (g *Game) HasPlayerPendingAction() bool {
@@ -524,9 +643,14 @@ func (g *Game) Tick() {
 		return
 	}
 
+	// Do not advance the game while its history cannot be stored
+	if g.j.Err() != nil {
+		return
+	}
+
 	g.CurrentPlayer.TimeLeft--
 	if g.CurrentPlayer.TimeLeft > 0 {
-		if g.ai.Tick() {
+		if g.tickBots() {
 			return
 		}
 
@@ -640,6 +764,12 @@ func (g *Game) SetId(p *entities.Player, id string) {
 	g.j.WSetId(p, id)
 }
 
+// SetBotLevel picks the strategy the seat plays with while it is a bot
+func (g *Game) SetBotLevel(p *entities.Player, level string) {
+	p.BotLevel = level
+	g.j.WSetBotLevel(p, level)
+}
+
 func (g *Game) SendError(err error, p *entities.Player) {
 	if err != nil {
 		p.SendMessage(&entities.Message{
@@ -649,13 +779,19 @@ func (g *Game) SendError(err error, p *entities.Player) {
 	}
 }
 
+// Lock takes the game mutex. Headless games only ever run on the
+// goroutine of their engine and skip it.
 func (g *Game) Lock() bool {
-	g.mutex.Lock()
+	if g.engine == nil {
+		g.mutex.Lock()
+	}
 	return g.Initialized
 }
 
 func (g *Game) Unlock() {
-	g.mutex.Unlock()
+	if g.engine == nil {
+		g.mutex.Unlock()
+	}
 }
 
 func (g *Game) GenerateStoreGameState() *StoreGameState {
@@ -665,6 +801,9 @@ func (g *Game) GenerateStoreGameState() *StoreGameState {
 		AdvancedSettings: g.AdvancedSettings,
 		NumPlayers:       g.NumPlayers,
 		GameOver:         g.GameOver,
+		Seed:             g.Seed,
+		ParentID:         g.ParentID,
+		ParentIndex:      g.ParentIndex,
 	}
 	storeGameState.PlayerStates = make([]*entities.PlayerState, 0)
 	storeGameState.PlayerSecretStates = make([]*entities.PlayerSecretState, 0)
@@ -2,7 +2,6 @@ package game
 
 import (
 	"log"
-	"math/rand"
 	"sakura/entities"
 	"strings"
 
@@ -15,7 +14,7 @@ func (g *Game) IsInitPhase() bool {
 
 func (g *Game) RunInitPhase() {
 	// g.simuateInit()
-	go g.startInitPhase()
+	g.spawn(g.startInitPhase)
 }
 
 func (g *Game) startInitPhase() {
@@ -66,7 +65,7 @@ func (g *Game) startInitPhase() {
 		}
 
 		build := func(g *Game, C entities.Coordinate) error {
-			if built >= len(g.Players) && g.Mode == entities.CitiesAndKnights {
+			if built >= len(g.Players) && g.Mode.HasCitiesAndKnights() {
 				return g.BuildCity(p, C)
 			} else {
 				return g.BuildSettlement(p, C)
@@ -75,7 +74,7 @@ func (g *Game) startInitPhase() {
 
 		err = build(g, C)
 		if err != nil {
-			C = g.ai.ChooseBestVertexSettlement(p, AllowedVertices).C
+			C = g.botStrategy(p).ChooseSetupSettlement(p, AllowedVertices).C
 			_ = build(g, C)
 		}
 		builtVertex, _ := g.Graph.GetVertex(C)
@@ -107,7 +106,7 @@ func (g *Game) startInitPhase() {
 						},
 					})
 
-					chosen := available[rand.Intn(len(available))]
+					chosen := available[g.Rand().Intn(len(available))]
 					if errGold == nil {
 						var cards []float64
 						if mapstructure.Decode(expGold, &cards) == nil && len(cards) == 9 {
@@ -145,7 +144,7 @@ func (g *Game) startInitPhase() {
 		}
 
 		msg := "Choose location for road"
-		if g.Mode == entities.Seafarers {
+		if g.Mode.HasSeafarers() {
 			msg = "Choose location for road/ship"
 		}
 		allowRoadEdges := make([]*entities.Edge, 0, len(allowedEdges))
@@ -188,7 +187,7 @@ func (g *Game) startInitPhase() {
 
 			canRoad := roadAllowed[target]
 			canShip := shipAllowed[target]
-			if g.Mode != entities.Seafarers {
+			if !g.Mode.HasSeafarers() {
 				return g.BuildRoad(p, target.C)
 			}
 
@@ -230,10 +229,10 @@ func (g *Game) startInitPhase() {
 
 		err = buildChosen(edge)
 		if err != nil {
-			if g.Mode == entities.Seafarers {
+			if g.Mode.HasSeafarers() {
 				_ = buildChosen(allowedEdges[0])
 			} else {
-				C = g.ai.ChooseBestEdgeRoad(p, allowedEdges).C
+				C = g.botStrategy(p).ChooseSetupRoad(p, allowedEdges).C
 				_ = g.BuildRoad(p, C)
 			}
 		}
@@ -261,7 +260,7 @@ func (g *Game) startInitPhase() {
 	g.j.WSetInitPhase(g.InitPhase)
 	g.SendPlayerSecret(g.CurrentPlayer)
 	g.BroadcastState()
-	g.ai.Reset()
+	g.resetBots()
 }
 
 func (g *Game) getInitEdgeChoices(
@@ -274,7 +273,7 @@ func (g *Game) getInitEdgeChoices(
 	}
 
 	shipAllowed := make(map[*entities.Edge]bool)
-	if g.Mode == entities.Seafarers {
+	if g.Mode.HasSeafarers() {
 		for _, e := range p.GetBuildLocationsShip(g.Graph) {
 			if g.IsSeaRobberBlockingEdge(e) {
 				continue
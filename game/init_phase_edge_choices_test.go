@@ -126,6 +126,10 @@ func TestSecondInitEdgeChoicesAreAnchoredToSecondSettlement(t *testing.T) {
 func TestInitEdgeChoicesExcludeShipOnPirateBlockedEdge(t *testing.T) {
 	g := buildInitSeafarersGame(t)
 	p := g.CurrentPlayer
+	// Start without a pirate so the edge is only blocked once it moves
+	if g.Pirate != nil {
+		g.Pirate.Tile = nil
+	}
 
 	var coastal *entities.Vertex
 	for _, v := range p.GetBuildLocationsSettlement(g.Graph, true, false) {
@@ -1,26 +1,78 @@
 package game
 
 import (
+	"errors"
+	"fmt"
 	"sakura/entities"
 	"log"
-	"sort"
+	"sync"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/vmihailenco/msgpack/v5"
 )
 
+const (
+	// Pending entries that trigger a flush from Write
+	JournalFlushThreshold = 256
+
+	// Pending entries at which the game stops accepting actions
+	JournalMaxPending = 4096
+
+	// Consecutive failed flushes after which the game stops accepting actions
+	JournalMaxFailures = 3
+
+	// Flushes slower than this, or entries pending longer than
+	// JournalLagWarning, are logged
+	JournalSlowFlush  = 2 * time.Second
+	JournalLagWarning = 30 * time.Second
+
+	journalRetryDelay    = 500 * time.Millisecond
+	journalMaxRetryDelay = 30 * time.Second
+)
+
 type (
 	JournalEntry struct {
 		Type   int           `msgpack:"t"`
 		Fields []interface{} `msgpack:"f"`
 		Index  int           `msgpack:"i"`
+
+		// State of the game RNG after the entry was written, only set
+		// when it changed since the previous entry
+		Rand uint64 `msgpack:"r,omitempty"`
 	}
 
 	Journal struct {
 		playing bool
 		g       *Game
-		pending chan []byte
 		index   int
+		version int
+
+		// RNG state stored with the last entry and whether the seed
+		// is in the journal
+		lastRand uint64
+		seeded   bool
+
+		// Encoded entries not stored yet. Entries are only removed
+		// once the store accepted them, so nothing is ever dropped.
+		pending [][]byte
+		oldest  time.Time
+		retryAt time.Time
+		err     error
+		stats   JournalStats
+
+		mutex      sync.Mutex
+		flushMutex sync.Mutex
+	}
+
+	JournalStats struct {
+		Pending             int
+		Written             uint64
+		Flushes             uint64
+		FailedFlushes       uint64
+		ConsecutiveFailures int
+		LastFlushDuration   time.Duration
+		OldestPendingAge    time.Duration
 	}
 
 	PortEntry struct {
@@ -28,47 +80,185 @@ type (
 		C     entities.EdgeCoordinate
 		Ratio int16
 	}
+
+	TribeMarkerEntry struct {
+		C    entities.EdgeCoordinate
+		Type entities.TribeMarkerType
+		Port entities.PortType
+	}
+
+	ClothVillageEntry struct {
+		C      entities.Coordinate
+		Number uint16
+		Cloth  int
+	}
+
+	FortressEntry struct {
+		C            entities.Coordinate
+		Owner        uint16
+		Strength     int
+		AttackedTurn int
+	}
+
+	WonderEntry struct {
+		Type  entities.WonderType
+		Owner int16
+		Stage int
+	}
 )
 
 func (j *Journal) Init() {
-	j.pending = make(chan []byte, 1024)
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.pending = nil
+	j.err = nil
+	j.lastRand = 0
+	j.seeded = false
+	j.stats = JournalStats{}
 }
 
-func (j *Journal) Flush() {
-	if len(j.pending) == 0 {
-		return
-	}
+// Flush stores all pending entries. On failure the entries stay pending
+// and are retried by the next flush.
+func (j *Journal) Flush() error {
+	j.flushMutex.Lock()
+	defer j.flushMutex.Unlock()
 
-	arr := make([][]byte, 0)
+	j.mutex.Lock()
+	batch := j.pending
+	oldest := j.oldest
+	j.mutex.Unlock()
 
-	for len(j.pending) > 0 {
-		arr = append(arr, <-j.pending)
+	if len(batch) == 0 {
+		return nil
 	}
 
-	err := j.g.Store.WriteJournalEntries(j.g.ID, arr)
+	start := time.Now()
+	err := j.g.Store.WriteJournalEntries(j.g.ID, batch)
+	took := time.Since(start)
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.stats.LastFlushDuration = took
 	if err != nil {
-		log.Println(err)
+		j.stats.FailedFlushes++
+		j.stats.ConsecutiveFailures++
+
+		delay := journalRetryDelay << (j.stats.ConsecutiveFailures - 1)
+		if delay > journalMaxRetryDelay || delay <= 0 {
+			delay = journalMaxRetryDelay
+		}
+		j.retryAt = time.Now().Add(delay)
+
+		log.Printf("journal flush failed for game %s (%d pending, attempt %d): %v",
+			j.g.ID, len(j.pending), j.stats.ConsecutiveFailures, err)
+		j.checkStalled()
+		return err
+	}
+
+	// Anything written while we were flushing stays pending
+	j.pending = append([][]byte(nil), j.pending[len(batch):]...)
+	j.oldest = time.Now()
+	j.retryAt = time.Time{}
+	j.stats.Flushes++
+	j.stats.Written += uint64(len(batch))
+	j.stats.ConsecutiveFailures = 0
+
+	if took > JournalSlowFlush {
+		log.Printf("journal flush for game %s took %v for %d entries", j.g.ID, took, len(batch))
+	}
+	if age := time.Since(oldest); age > JournalLagWarning {
+		log.Printf("journal for game %s was %v behind", j.g.ID, age.Round(time.Second))
+	}
+
+	if j.err != nil && len(j.pending) < JournalMaxPending {
+		log.Printf("journal for game %s caught up, accepting actions again", j.g.ID)
+		j.err = nil
+	}
+
+	return nil
+}
+
+// dropPending forgets entries that were written but not stored yet
+func (j *Journal) dropPending() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.pending = nil
+}
+
+// checkStalled marks the journal as failing once it can no longer keep
+// up. Must be called with the mutex held.
+func (j *Journal) checkStalled() {
+	if j.err != nil {
 		return
 	}
+
+	if len(j.pending) >= JournalMaxPending {
+		j.err = fmt.Errorf("journal has %d entries waiting to be stored", len(j.pending))
+	} else if j.stats.ConsecutiveFailures >= JournalMaxFailures {
+		j.err = fmt.Errorf("journal could not be stored %d times in a row", j.stats.ConsecutiveFailures)
+	}
+
+	if j.err != nil {
+		log.Printf("journal for game %s is stalled, refusing actions: %v", j.g.ID, j.err)
+	}
+}
+
+// Err returns why the journal cannot accept more history, if it cannot
+func (j *Journal) Err() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return j.err
+}
+
+func (j *Journal) Stats() JournalStats {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	stats := j.stats
+	stats.Pending = len(j.pending)
+	if len(j.pending) > 0 {
+		stats.OldestPendingAge = time.Since(j.oldest)
+	}
+	return stats
 }
 
 func (j *Journal) Write(v JournalEntry) {
-	if j.playing || !j.g.Initialized {
+	if j.playing || !j.g.Initialized || j.g.simulated {
 		return
 	}
 
-	j.index++
-	v.Index = j.index
+	v.Index = j.index + 1
+
+	randState := j.g.randomState()
+	if randState != j.lastRand {
+		v.Rand = randState
+	}
 
 	b, err := msgpack.Marshal(v)
 	if err != nil {
+		// Skipping the entry would leave the history behind the game
+		j.mutex.Lock()
+		j.err = fmt.Errorf("failed to encode journal entry %d: %v", v.Type, err)
+		j.mutex.Unlock()
 		log.Println(err)
 		return
 	}
+	j.index = v.Index
+	j.lastRand = randState
 
-	select {
-	case j.pending <- b:
-	default:
+	j.mutex.Lock()
+	if len(j.pending) == 0 {
+		j.oldest = time.Now()
+	}
+	j.pending = append(j.pending, b)
+	pressure := len(j.pending) >= JournalFlushThreshold && time.Now().After(j.retryAt)
+	j.checkStalled()
+	j.mutex.Unlock()
+
+	if pressure {
+		j.Flush()
 	}
 }
 
@@ -82,31 +272,74 @@ func (j *Journal) Play() {
 		return
 	}
 
-	entries := make([]JournalEntry, len(byteEntries))
+	entries, version, err := DecodeJournal(byteEntries)
+	if err != nil {
+		log.Println("failed to decode journal:", err)
+		return
+	}
+	j.version = version
+
+	// Start from the latest snapshot if there is one, so only the
+	// entries written after it need to be played
+	start := j.restoreSnapshot()
 
-	for i, e := range byteEntries {
-		err := msgpack.Unmarshal(e, &entries[i])
-		if err != nil {
-			log.Println("invalid line in journal, failed to play")
+	for i := range entries {
+		if entries[i].Index <= start {
+			continue
+		}
+
+		if err := j.playEntry(&entries[i]); err != nil {
+			log.Println(err)
 			return
 		}
 	}
 
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].Index < entries[j].Index
-	})
+	log.Println("Journal replay done")
+}
 
-	for i, e := range entries {
-		if e.Index != i+1 {
-			log.Println("missing entries in journal, failed to play")
-			return
-		}
+// playEntry applies the entry that follows the last played one
+func (j *Journal) playEntry(e *JournalEntry) error {
+	if e.Index != j.index+1 {
+		return errors.New("missing entries in journal, failed to play")
+	}
+
+	j.play(e)
+	j.index = e.Index
 
-		j.play(&e)
-		j.index = e.Index
+	// Continue the RNG from where the game left it
+	if e.Rand != 0 {
+		j.g.setRandomState(e.Rand)
+		j.lastRand = e.Rand
 	}
+	return nil
+}
 
-	log.Println("Journal replay done")
+// restoreSnapshot loads the stored snapshot and returns the index of the
+// last journal entry it covers. On any failure the game is reset so the
+// whole journal can be played instead.
+func (j *Journal) restoreSnapshot() int {
+	j.index = 0
+
+	b, err := j.g.Store.ReadGameSnapshot(j.g.ID)
+	if err != nil {
+		log.Println("error reading snapshot:", err)
+		return 0
+	}
+	if b == nil {
+		return 0
+	}
+
+	s, err := DecodeSnapshot(b)
+	if err == nil {
+		err = j.g.RestoreSnapshot(s)
+	}
+	if err != nil {
+		log.Println("error restoring snapshot, playing full journal:", err)
+		j.g.resetForReplay()
+		return 0
+	}
+
+	return s.JournalIndex
 }
 
 func (j *Journal) setNotPlaying() {
@@ -114,6 +347,7 @@ func (j *Journal) setNotPlaying() {
 }
 
 const (
+	JJournalFormat         = 1000
 	JCreateTile            = 1001
 	JGenVerticesTiles      = 1002
 	JSetTileType           = 1003
@@ -123,9 +357,20 @@ const (
 	JSetInitPhase          = 1007
 	JSetGameSettings       = 1008
 	JSetAdvancedSettings   = 1009
+	JSetSeed               = 1010
+	JForkedFrom            = 1011
+	JSetTribeMarkers       = 1012
+	JSetClothVillages      = 1013
+	JSetCloth              = 1014
+	JSetFortresses         = 1015
+	JSetWarship            = 1016
+	JSetWonders            = 1017
+	JEventCardOrder        = 1018
+	JEventCardCursor       = 1019
 
 	JSetRobber       = 1101
 	JSetPirate       = 1112
+	JSetRobberReturn = 1113
 	JVertexBuild     = 1102
 	JEdgeBuild       = 1103
 	JCityImprove     = 1104
@@ -149,10 +394,13 @@ const (
 
 	JSetUsername = 1401
 	JSetId       = 1402
+	JSetBotLevel = 1403
 )
 
 func (j *Journal) play(e *JournalEntry) {
 	switch e.Type {
+	case JJournalFormat:
+		// Only used while decoding
 	case JCreateTile:
 		j.PCreateTile(e)
 	case JGenVerticesTiles:
@@ -163,6 +411,8 @@ func (j *Journal) play(e *JournalEntry) {
 		j.PSetRobber(e)
 	case JSetPirate:
 		j.PSetPirate(e)
+	case JSetRobberReturn:
+		j.PSetRobberReturn(e)
 	case JVertexBuild:
 		j.PVertexBuild(e)
 	case JEdgeBuild:
@@ -203,6 +453,10 @@ func (j *Journal) play(e *JournalEntry) {
 		j.PDevelopmentCardOrder(e)
 	case JDevelopmentCardCursor:
 		j.PDevelopmentCardCursor(e)
+	case JEventCardOrder:
+		j.PEventCardOrder(e)
+	case JEventCardCursor:
+		j.PEventCardCursor(e)
 	case JSetPorts:
 		j.PSetPorts(e)
 	case JSetInitPhase:
@@ -211,13 +465,58 @@ func (j *Journal) play(e *JournalEntry) {
 		j.PSetUsername(e)
 	case JSetId:
 		j.PSetId(e)
+	case JSetBotLevel:
+		j.PSetBotLevel(e)
 	case JSetGameSettings:
 		j.PSetGameSettings(e)
 	case JSetAdvancedSettings:
 		j.PSetAdvancedSettings(e)
+	case JSetSeed:
+		j.PSetSeed(e)
+	case JForkedFrom:
+		j.PForkedFrom(e)
+	case JSetTribeMarkers:
+		j.PSetTribeMarkers(e)
+	case JSetClothVillages:
+		j.PSetClothVillages(e)
+	case JSetCloth:
+		j.PSetCloth(e)
+	case JSetFortresses:
+		j.PSetFortresses(e)
+	case JSetWarship:
+		j.PSetWarship(e)
+	case JSetWonders:
+		j.PSetWonders(e)
 	}
 }
 
+// WJournalFormat marks all following entries as written in the current
+// format version
+func (j *Journal) WJournalFormat() {
+	j.Write(JournalEntry{Type: JJournalFormat, Fields: []interface{}{JournalFormatVersion}})
+	j.version = JournalFormatVersion
+}
+
+// WSetSeed records the seed of the game RNG
+func (j *Journal) WSetSeed() {
+	j.Write(JournalEntry{Type: JSetSeed, Fields: []interface{}{j.g.Seed}})
+	j.seeded = true
+}
+
+func (j *Journal) PSetSeed(e *JournalEntry) {
+	var seed int64
+	mapstructure.Decode(e.Fields[0], &seed)
+	j.g.seedRandom(seed)
+	j.seeded = true
+}
+
+func (j *Journal) PForkedFrom(e *JournalEntry) {
+	var index int
+	mapstructure.Decode(e.Fields[1], &index)
+	j.g.ParentID = e.Fields[0].(string)
+	j.g.ParentIndex = index
+}
+
 func (j *Journal) WCreateTile(tile *entities.Tile, dispX float64) {
 	j.Write(JournalEntry{Type: JCreateTile, Fields: []interface{}{tile.Center, dispX, tile.Fog}})
 }
@@ -282,6 +581,16 @@ func (j *Journal) WSetPirate(tile *entities.Tile) {
 	j.Write(JournalEntry{Type: JSetPirate, Fields: []interface{}{tile.Center}})
 }
 
+func (j *Journal) PSetRobberReturn(e *JournalEntry) {
+	var order int
+	mapstructure.Decode(e.Fields[0], &order)
+	j.g.RobberReturnAfter = j.g.playerAtOrder(order)
+}
+
+func (j *Journal) WSetRobberReturn(p *entities.Player) {
+	j.Write(JournalEntry{Type: JSetRobberReturn, Fields: []interface{}{playerOrderOrNone(p)}})
+}
+
 func (j *Journal) PVertexBuild(e *JournalEntry) {
 	var C entities.Coordinate
 	var playerOrder uint16
@@ -291,10 +600,7 @@ func (j *Journal) PVertexBuild(e *JournalEntry) {
 	mapstructure.Decode(e.Fields[0], &C)
 	mapstructure.Decode(e.Fields[1], &playerOrder)
 	mapstructure.Decode(e.Fields[2], &bt)
-
-	if len(e.Fields) >= 4 {
-		mapstructure.Decode(e.Fields[3], &force)
-	}
+	mapstructure.Decode(e.Fields[3], &force)
 
 	vertex, _ := j.g.Graph.GetVertex(C)
 	if vertex == nil {
@@ -746,6 +1052,34 @@ func (j *Journal) PDevelopmentCardCursor(e *JournalEntry) {
 	j.g.Bank.DevelopmentCardCursor = cursor
 }
 
+func (j *Journal) WEventCardOrder(order []int) {
+	j.Write(JournalEntry{Type: JEventCardOrder, Fields: []interface{}{
+		order,
+	}})
+}
+
+func (j *Journal) PEventCardOrder(e *JournalEntry) {
+	var order []int
+	err := mapstructure.Decode(e.Fields[0], &order)
+	if err != nil {
+		return
+	}
+
+	j.g.Bank.EventCardOrder = order
+}
+
+func (j *Journal) WEventCardCursor(cursor int) {
+	j.Write(JournalEntry{Type: JEventCardCursor, Fields: []interface{}{
+		cursor,
+	}})
+}
+
+func (j *Journal) PEventCardCursor(e *JournalEntry) {
+	var cursor int
+	mapstructure.Decode(e.Fields[0], &cursor)
+	j.g.Bank.EventCardCursor = cursor
+}
+
 // Write a function to add journal entry for a port
 func (j *Journal) WSetPorts() {
 	portEntries := make([]interface{}, len(j.g.Ports))
@@ -760,6 +1094,104 @@ func (j *Journal) WSetPorts() {
 	j.Write(JournalEntry{Type: JSetPorts, Fields: portEntries})
 }
 
+func (j *Journal) WSetTribeMarkers() {
+	markerEntries := make([]interface{}, len(j.g.ScenarioTribeMarkers))
+	for i, m := range j.g.ScenarioTribeMarkers {
+		markerEntries[i] = interface{}(TribeMarkerEntry{C: m.Edge.C, Type: m.Type, Port: m.Port})
+	}
+
+	j.Write(JournalEntry{Type: JSetTribeMarkers, Fields: markerEntries})
+}
+
+func (j *Journal) PSetTribeMarkers(e *JournalEntry) {
+	markerEntries := make([]TribeMarkerEntry, len(e.Fields))
+	mapstructure.Decode(e.Fields, &markerEntries)
+	j.g.ScenarioTribeMarkers = j.g.restoreTribeMarkers(markerEntries)
+}
+
+func (j *Journal) WSetClothVillages() {
+	villageEntries := make([]interface{}, len(j.g.ScenarioClothVillages))
+	for i, v := range j.g.ScenarioClothVillages {
+		villageEntries[i] = interface{}(ClothVillageEntry{C: v.Tile.Center, Number: v.Number, Cloth: v.Cloth})
+	}
+
+	j.Write(JournalEntry{Type: JSetClothVillages, Fields: villageEntries})
+}
+
+func (j *Journal) PSetClothVillages(e *JournalEntry) {
+	villageEntries := make([]ClothVillageEntry, len(e.Fields))
+	mapstructure.Decode(e.Fields, &villageEntries)
+	j.g.ScenarioClothVillages = j.g.restoreClothVillages(villageEntries)
+}
+
+func (j *Journal) WSetCloth(p *entities.Player) {
+	j.Write(JournalEntry{Type: JSetCloth, Fields: []interface{}{
+		p.Order, j.g.ScenarioCloth[p],
+	}})
+}
+
+func (j *Journal) PSetCloth(e *JournalEntry) {
+	var playerOrder uint16
+	var cloth int
+	mapstructure.Decode(e.Fields[0], &playerOrder)
+	mapstructure.Decode(e.Fields[1], &cloth)
+	j.g.ScenarioCloth[j.g.Players[playerOrder]] = cloth
+}
+
+func (j *Journal) WSetFortresses() {
+	fortressEntries := make([]interface{}, len(j.g.ScenarioFortresses))
+	for i, f := range j.g.ScenarioFortresses {
+		fortressEntries[i] = interface{}(FortressEntry{C: f.Vertex.C, Owner: f.Owner, Strength: f.Strength, AttackedTurn: f.AttackedTurn})
+	}
+
+	j.Write(JournalEntry{Type: JSetFortresses, Fields: fortressEntries})
+}
+
+func (j *Journal) PSetFortresses(e *JournalEntry) {
+	fortressEntries := make([]FortressEntry, len(e.Fields))
+	mapstructure.Decode(e.Fields, &fortressEntries)
+	j.g.ScenarioFortresses = j.g.restoreFortresses(fortressEntries)
+}
+
+func (j *Journal) WSetWarship(e *entities.Edge, warship bool) {
+	j.Write(JournalEntry{Type: JSetWarship, Fields: []interface{}{
+		e.C, warship,
+	}})
+}
+
+func (j *Journal) PSetWarship(e *JournalEntry) {
+	var C entities.EdgeCoordinate
+	var warship bool
+	mapstructure.Decode(e.Fields[0], &C)
+	mapstructure.Decode(e.Fields[1], &warship)
+
+	edge, err := j.g.Graph.GetEdge(C)
+	if err != nil {
+		log.Println("Error setting warship:", err)
+		return
+	}
+	if warship {
+		j.g.ScenarioWarships[edge] = true
+	} else {
+		delete(j.g.ScenarioWarships, edge)
+	}
+}
+
+func (j *Journal) WSetWonders() {
+	wonderEntries := make([]interface{}, len(j.g.ScenarioWonders))
+	for i, w := range j.g.ScenarioWonders {
+		wonderEntries[i] = interface{}(WonderEntry{Type: w.Type, Owner: w.Owner, Stage: w.Stage})
+	}
+
+	j.Write(JournalEntry{Type: JSetWonders, Fields: wonderEntries})
+}
+
+func (j *Journal) PSetWonders(e *JournalEntry) {
+	wonderEntries := make([]WonderEntry, len(e.Fields))
+	mapstructure.Decode(e.Fields, &wonderEntries)
+	j.g.ScenarioWonders = j.g.restoreWonders(wonderEntries)
+}
+
 func (j *Journal) PSetPorts(e *JournalEntry) {
 	portEntries := make([]PortEntry, len(j.g.Ports))
 	mapstructure.Decode(e.Fields, &portEntries)
@@ -821,6 +1253,18 @@ func (j *Journal) PSetId(e *JournalEntry) {
 	j.g.Players[order].Id = id
 }
 
+func (j *Journal) WSetBotLevel(p *entities.Player, level string) {
+	j.Write(JournalEntry{Type: JSetBotLevel, Fields: []interface{}{
+		p.Order, level,
+	}})
+}
+
+func (j *Journal) PSetBotLevel(e *JournalEntry) {
+	order := e.Fields[0].(uint16)
+	level := e.Fields[1].(string)
+	j.g.SetBotLevel(j.g.Players[order], level)
+}
+
 func (j *Journal) WSetGameSettings() {
 	j.Write(JournalEntry{Type: JSetGameSettings, Fields: []interface{}{
 		j.g.Settings,
@@ -831,9 +1275,20 @@ func (j *Journal) PSetGameSettings(e *JournalEntry) {
 	var settings entities.GameSettings
 	mapstructure.Decode(e.Fields[0], &settings)
 
+	// The map definition is not journaled, keep the one we started with
+	if settings.MapDefn == nil {
+		settings.MapDefn = j.g.Settings.MapDefn
+	}
+
 	j.g.Settings = settings
+	if j.g.Settings.MapDefn == nil {
+		j.g.ensureMapDefn()
+	}
 	j.g.Mode = j.g.Settings.Mode
 	j.g.InitWithGameMode()
+
+	// Scenario rules played back with the builds need the hooks
+	j.g.configureScenarioHooks()
 }
 
 func (j *Journal) WSetAdvancedSettings() {
@@ -0,0 +1,265 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Current journal format. Bump this whenever a writer changes the fields
+// it stores, add the new schema below and a migration from the previous
+// version to journalMigrations.
+//
+// Versions:
+//  1. Journals without a format entry
+//  2. Format entries, vertex builds always carry the force flag
+const JournalFormatVersion = 2
+
+type (
+	JournalFieldKind int
+
+	JournalField struct {
+		Name string
+		Kind JournalFieldKind
+	}
+
+	// JournalSchema lists the fields of an entry type by position. If
+	// Repeated is set, the entry holds any number of the single field.
+	JournalSchema struct {
+		Name     string
+		Fields   []JournalField
+		Repeated bool
+	}
+)
+
+const (
+	JFInt JournalFieldKind = iota + 1
+	JFFloat
+	JFBool
+	JFString
+	JFObject
+	JFList
+)
+
+func jf(name string, kind JournalFieldKind) JournalField {
+	return JournalField{Name: name, Kind: kind}
+}
+
+// Schemas of the current format version
+var JournalSchemas = map[int]JournalSchema{
+	JJournalFormat: {Name: "JournalFormat", Fields: []JournalField{jf("version", JFInt)}},
+
+	JCreateTile:            {Name: "CreateTile", Fields: []JournalField{jf("center", JFObject), jf("dispX", JFFloat), jf("fog", JFBool)}},
+	JGenVerticesTiles:      {Name: "GenVerticesEdges"},
+	JSetTileType:           {Name: "SetTileType", Fields: []JournalField{jf("center", JFObject), jf("type", JFInt), jf("number", JFInt)}},
+	JDevelopmentCardOrder:  {Name: "DevelopmentCardOrder", Fields: []JournalField{jf("stack", JFInt), jf("order", JFList)}},
+	JDevelopmentCardCursor: {Name: "DevelopmentCardCursor", Fields: []JournalField{jf("cursor", JFInt)}},
+	JSetPorts:              {Name: "SetPorts", Fields: []JournalField{jf("port", JFObject)}, Repeated: true},
+	JSetInitPhase:          {Name: "SetInitPhase", Fields: []JournalField{jf("initPhase", JFBool)}},
+	JSetGameSettings:       {Name: "SetGameSettings", Fields: []JournalField{jf("settings", JFObject)}},
+	JSetAdvancedSettings:   {Name: "SetAdvancedSettings", Fields: []JournalField{jf("settings", JFObject)}},
+	JSetSeed:               {Name: "SetSeed", Fields: []JournalField{jf("seed", JFInt)}},
+	JForkedFrom:            {Name: "ForkedFrom", Fields: []JournalField{jf("parent", JFString), jf("index", JFInt)}},
+	JSetTribeMarkers:       {Name: "SetTribeMarkers", Fields: []JournalField{jf("marker", JFObject)}, Repeated: true},
+	JSetClothVillages:      {Name: "SetClothVillages", Fields: []JournalField{jf("village", JFObject)}, Repeated: true},
+	JSetCloth:              {Name: "SetCloth", Fields: []JournalField{jf("player", JFInt), jf("cloth", JFInt)}},
+	JSetFortresses:         {Name: "SetFortresses", Fields: []JournalField{jf("fortress", JFObject)}, Repeated: true},
+	JSetWarship:            {Name: "SetWarship", Fields: []JournalField{jf("c", JFObject), jf("warship", JFBool)}},
+	JSetWonders:            {Name: "SetWonders", Fields: []JournalField{jf("wonder", JFObject)}, Repeated: true},
+	JEventCardOrder:        {Name: "EventCardOrder", Fields: []JournalField{jf("order", JFList)}},
+	JEventCardCursor:       {Name: "EventCardCursor", Fields: []JournalField{jf("cursor", JFInt)}},
+
+	JSetRobber:       {Name: "SetRobber", Fields: []JournalField{jf("center", JFObject)}},
+	JSetPirate:       {Name: "SetPirate", Fields: []JournalField{jf("center", JFObject)}},
+	JSetRobberReturn: {Name: "SetRobberReturn", Fields: []JournalField{jf("player", JFInt)}},
+	JVertexBuild:     {Name: "VertexBuild", Fields: []JournalField{jf("c", JFObject), jf("player", JFInt), jf("type", JFInt), jf("force", JFBool)}},
+	JEdgeBuild:       {Name: "EdgeBuild", Fields: []JournalField{jf("c", JFObject), jf("player", JFInt), jf("type", JFInt)}},
+	JCityImprove:     {Name: "CityImprove", Fields: []JournalField{jf("player", JFInt), jf("cardType", JFInt), jf("level", JFInt)}},
+	JSetKnightActive: {Name: "SetKnightActive", Fields: []JournalField{jf("c", JFObject), jf("activated", JFBool), jf("canUse", JFBool)}},
+	JBuildWall:       {Name: "BuildWall", Fields: []JournalField{jf("c", JFObject), jf("player", JFInt)}},
+	JBuildMetropolis: {Name: "BuildMetropolis", Fields: []JournalField{jf("c", JFObject), jf("cardType", JFInt)}},
+	JMerchantFleet:   {Name: "MerchantFleet", Fields: []JournalField{jf("cardType", JFInt)}},
+	JMerchant:        {Name: "Merchant", Fields: []JournalField{jf("center", JFObject), jf("player", JFInt)}},
+	JGiveProgress:    {Name: "GiveProgress", Fields: []JournalField{jf("player", JFInt), jf("deckType", JFInt)}},
+	JMovePlacement:   {Name: "MovePlacement", Fields: []JournalField{jf("from", JFObject), jf("to", JFObject)}},
+
+	JEndTurn:       {Name: "EndTurn", Fields: []JournalField{jf("player", JFInt)}},
+	JRollDice:      {Name: "RollDice", Fields: []JournalField{jf("red", JFInt), jf("white", JFInt)}},
+	JRollEventDice: {Name: "RollEventDice", Fields: []JournalField{jf("roll", JFInt)}},
+	JSpecialBuild:  {Name: "SpecialBuild", Fields: []JournalField{jf("player", JFInt), jf("specialBuild", JFBool)}},
+
+	JUpdateCard:              {Name: "UpdateCard", Fields: []JournalField{jf("player", JFInt), jf("cardType", JFInt), jf("quantity", JFInt)}},
+	JUpdateResources:         {Name: "UpdateResources", Fields: []JournalField{jf("player", JFInt), jf("wood", JFInt), jf("brick", JFInt), jf("wool", JFInt), jf("wheat", JFInt), jf("ore", JFInt)}},
+	JUpdateDevelopmentCard:   {Name: "UpdateDevelopmentCard", Fields: []JournalField{jf("player", JFInt), jf("type", JFInt), jf("quantity", JFInt), jf("numUsed", JFInt), jf("canUse", JFBool)}},
+	JReinsertDevelopmentCard: {Name: "ReinsertDevelopmentCard", Fields: []JournalField{jf("player", JFInt), jf("type", JFInt)}},
+
+	JSetUsername: {Name: "SetUsername", Fields: []JournalField{jf("player", JFInt), jf("username", JFString)}},
+	JSetId:       {Name: "SetId", Fields: []JournalField{jf("player", JFInt), jf("id", JFString)}},
+	JSetBotLevel: {Name: "SetBotLevel", Fields: []JournalField{jf("player", JFInt), jf("level", JFString)}},
+}
+
+// journalMigrations upgrade an entry from the keyed version to the next one
+var journalMigrations = map[int]func(e *JournalEntry) error{
+	1: migrateJournalV1,
+}
+
+// Version 1 vertex builds written before the force flag existed only
+// had three fields
+func migrateJournalV1(e *JournalEntry) error {
+	if e.Type == JVertexBuild && len(e.Fields) == 3 {
+		e.Fields = append(e.Fields, false)
+	}
+	return nil
+}
+
+// MigrateJournalEntry upgrades an entry written in the given format
+// version to the current one
+func MigrateJournalEntry(e *JournalEntry, version int) error {
+	for v := version; v < JournalFormatVersion; v++ {
+		migrate, ok := journalMigrations[v]
+		if !ok {
+			return fmt.Errorf("no journal migration from version %d", v)
+		}
+		if err := migrate(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (k JournalFieldKind) matches(v interface{}) bool {
+	switch k {
+	case JFInt:
+		switch v.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return true
+		}
+	case JFFloat:
+		switch v.(type) {
+		case float32, float64:
+			return true
+		}
+	case JFBool:
+		_, ok := v.(bool)
+		return ok
+	case JFString:
+		_, ok := v.(string)
+		return ok
+	case JFObject:
+		_, ok := v.(map[string]interface{})
+		return ok
+	case JFList:
+		_, ok := v.([]interface{})
+		return ok || v == nil
+	}
+	return false
+}
+
+// Validate checks the entry fields against the schema
+func (s JournalSchema) Validate(e *JournalEntry) error {
+	if s.Repeated {
+		for i, v := range e.Fields {
+			if !s.Fields[0].Kind.matches(v) {
+				return fmt.Errorf("%s field %d (%s) has unexpected type %T", s.Name, i, s.Fields[0].Name, v)
+			}
+		}
+		return nil
+	}
+
+	if len(e.Fields) != len(s.Fields) {
+		return fmt.Errorf("%s has %d fields, expected %d", s.Name, len(e.Fields), len(s.Fields))
+	}
+	for i, f := range s.Fields {
+		if !f.Kind.matches(e.Fields[i]) {
+			return fmt.Errorf("%s field %d (%s) has unexpected type %T", s.Name, i, f.Name, e.Fields[i])
+		}
+	}
+	return nil
+}
+
+// DecodeJournal unmarshals stored entries, sorts them by index, drops
+// repeated indices and upgrades them to the current format. A format
+// entry applies to all entries after it, entries before the first one
+// are version 1. The returned version is the format the journal was last
+// written in.
+func DecodeJournal(byteEntries [][]byte) ([]JournalEntry, int, error) {
+	entries, version, bad := decodeJournal(byteEntries)
+	if len(bad) > 0 {
+		return nil, 0, bad[0].err
+	}
+	return entries, version, nil
+}
+
+// An entry that could not be decoded
+type journalDecodeError struct {
+	index int
+	err   error
+}
+
+// decodeJournal decodes entry by entry like DecodeJournal, but keeps
+// going past broken entries. Stored entries that do not unmarshal are
+// left out and reported at their position in the journal, entries that
+// do not migrate or validate are kept as they are and reported at their
+// index. Nothing after an unsupported format is migrated.
+func decodeJournal(byteEntries [][]byte) ([]JournalEntry, int, []journalDecodeError) {
+	var bad []journalDecodeError
+	entries := make([]JournalEntry, 0, len(byteEntries))
+	for i, b := range byteEntries {
+		var e JournalEntry
+		if err := msgpack.Unmarshal(b, &e); err != nil {
+			bad = append(bad, journalDecodeError{index: i + 1, err: errors.New("invalid line in journal")})
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Index < entries[j].Index
+	})
+
+	// A flush retried after a partial write can store entries twice
+	unique := entries[:0]
+	for _, e := range entries {
+		if len(unique) > 0 && unique[len(unique)-1].Index == e.Index {
+			continue
+		}
+		unique = append(unique, e)
+	}
+	entries = unique
+
+	version := 1
+	for i := range entries {
+		e := &entries[i]
+
+		if e.Type == JJournalFormat && len(e.Fields) == 1 {
+			if err := mapstructure.Decode(e.Fields[0], &version); err != nil {
+				bad = append(bad, journalDecodeError{index: e.Index, err: fmt.Errorf("invalid journal format entry %d", e.Index)})
+				break
+			}
+			if version < 1 || version > JournalFormatVersion {
+				bad = append(bad, journalDecodeError{index: e.Index, err: fmt.Errorf("unsupported journal format version %d", version)})
+				break
+			}
+			continue
+		}
+
+		if err := MigrateJournalEntry(e, version); err != nil {
+			bad = append(bad, journalDecodeError{index: e.Index, err: fmt.Errorf("entry %d: %v", e.Index, err)})
+			continue
+		}
+
+		schema, ok := JournalSchemas[e.Type]
+		if !ok {
+			bad = append(bad, journalDecodeError{index: e.Index, err: fmt.Errorf("entry %d: unknown journal entry type %d", e.Index, e.Type)})
+			continue
+		}
+		if err := schema.Validate(e); err != nil {
+			bad = append(bad, journalDecodeError{index: e.Index, err: fmt.Errorf("entry %d: %v", e.Index, err)})
+		}
+	}
+
+	return entries, version, bad
+}
@@ -0,0 +1,147 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func readFrozenJournal(t *testing.T, name string) [][]byte {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", "journals", name))
+	if err != nil {
+		t.Fatalf("failed to read frozen journal: %v", err)
+	}
+	var entries [][]byte
+	if err := msgpack.Unmarshal(b, &entries); err != nil {
+		t.Fatalf("failed to decode frozen journal: %v", err)
+	}
+	return entries
+}
+
+func encodeJournalEntry(t *testing.T, e JournalEntry) []byte {
+	t.Helper()
+	b, err := msgpack.Marshal(e)
+	if err != nil {
+		t.Fatalf("failed to encode entry: %v", err)
+	}
+	return b
+}
+
+// Both journals hold the same three player base game, eight turns in,
+// as written by the version 1 journal writers
+func TestFrozenJournalsV1Replay(t *testing.T) {
+	for _, name := range []string{"v1_base.msgpack", "v1_vertex_build_without_force.msgpack"} {
+		t.Run(name, func(t *testing.T) {
+			frozen := readFrozenJournal(t, name)
+			store := &memoryStore{journal: append([][]byte(nil), frozen...)}
+
+			g := &Game{Store: store, Settings: snapshotTestSettings()}
+			if _, err := g.Initialize("frozen", 3); err != nil {
+				t.Fatalf("initialize failed: %v", err)
+			}
+			stopTickerForTest(g)
+
+			if g.TurnCount != 8 || g.CurrentPlayer.Order != 2 || g.IsInitPhase() {
+				t.Fatalf("unexpected turn state: turns=%d current=%d init=%v", g.TurnCount, g.CurrentPlayer.Order, g.IsInitPhase())
+			}
+
+			expected := []struct {
+				vertices, edges int
+				cards           int16
+			}{{2, 2, 3}, {2, 2, 4}, {2, 4, 2}}
+			for i, p := range g.Players {
+				got := expected[i]
+				if len(p.VertexPlacements) != got.vertices || len(p.EdgePlacements) != got.edges || p.CurrentHand.GetCardCount() != got.cards {
+					t.Fatalf("player %d: got %d vertices, %d edges, %d cards", i,
+						len(p.VertexPlacements), len(p.EdgePlacements), p.CurrentHand.GetCardCount())
+				}
+			}
+			if g.Bank.Hand.GetCardCount() != 86 {
+				t.Fatalf("expected 86 cards in bank, got %d", g.Bank.Hand.GetCardCount())
+			}
+			if g.DiceStats.Rolls != [12]int{0, 1, 0, 1, 0, 1, 0, 3, 1, 1, 0, 0} {
+				t.Fatalf("unexpected dice stats %v", g.DiceStats.Rolls)
+			}
+
+			// Resuming the game marks the appended entries with the current
+			// format and records the seed the game continues with
+			g.j.Flush()
+			if len(store.journal) != len(frozen)+2 {
+				t.Fatalf("expected format and seed entries to be appended, got %d entries", len(store.journal))
+			}
+			entries, version, err := DecodeJournal(store.journal)
+			if err != nil || version != JournalFormatVersion {
+				t.Fatalf("expected upgraded journal at version %d, got %d err=%v", JournalFormatVersion, version, err)
+			}
+			if last := entries[len(entries)-1]; last.Type != JSetSeed {
+				t.Fatalf("expected seed entry last, got type %d", last.Type)
+			}
+		})
+	}
+}
+
+func TestNewJournalStartsWithFormat(t *testing.T) {
+	store := &memoryStore{}
+	g := &Game{Store: store, Settings: snapshotTestSettings()}
+	if _, err := g.Initialize("fresh", 2); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	stopTickerForTest(g)
+	g.j.Flush()
+
+	entries, version, err := DecodeJournal(store.journal)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if version != JournalFormatVersion || entries[0].Type != JJournalFormat || entries[0].Index != 1 {
+		t.Fatalf("expected format entry first, got type %d index %d version %d", entries[0].Type, entries[0].Index, version)
+	}
+}
+
+func TestDecodeJournalMigratesAndValidates(t *testing.T) {
+	c := map[string]interface{}{"X": 1, "Y": 2}
+
+	legacy := [][]byte{
+		encodeJournalEntry(t, JournalEntry{Type: JVertexBuild, Fields: []interface{}{c, 0, 1}, Index: 1}),
+	}
+	entries, version, err := DecodeJournal(legacy)
+	if err != nil || version != 1 {
+		t.Fatalf("expected version 1 journal, got %d err=%v", version, err)
+	}
+	if len(entries[0].Fields) != 4 || entries[0].Fields[3] != false {
+		t.Fatalf("expected force flag to be added, got %v", entries[0].Fields)
+	}
+
+	// Current version entries are not migrated again
+	current := [][]byte{
+		encodeJournalEntry(t, JournalEntry{Type: JJournalFormat, Fields: []interface{}{JournalFormatVersion}, Index: 1}),
+		encodeJournalEntry(t, JournalEntry{Type: JVertexBuild, Fields: []interface{}{c, 0, 1}, Index: 2}),
+	}
+	if _, _, err := DecodeJournal(current); err == nil {
+		t.Fatal("expected short vertex build to be rejected in current format")
+	}
+
+	wrongType := [][]byte{
+		encodeJournalEntry(t, JournalEntry{Type: JEndTurn, Fields: []interface{}{"0"}, Index: 1}),
+	}
+	if _, _, err := DecodeJournal(wrongType); err == nil {
+		t.Fatal("expected field of wrong type to be rejected")
+	}
+
+	unknown := [][]byte{
+		encodeJournalEntry(t, JournalEntry{Type: 999, Index: 1}),
+	}
+	if _, _, err := DecodeJournal(unknown); err == nil {
+		t.Fatal("expected unknown entry type to be rejected")
+	}
+
+	future := [][]byte{
+		encodeJournalEntry(t, JournalEntry{Type: JJournalFormat, Fields: []interface{}{JournalFormatVersion + 1}, Index: 1}),
+	}
+	if _, _, err := DecodeJournal(future); err == nil {
+		t.Fatal("expected newer journal format to be rejected")
+	}
+}
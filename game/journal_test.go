@@ -0,0 +1,167 @@
+package game
+
+import (
+	"errors"
+	"sakura/entities"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type flakyStore struct {
+	memoryStore
+	fail    bool
+	partial bool
+	calls   int
+}
+
+func (s *flakyStore) WriteJournalEntries(id string, entries [][]byte) error {
+	s.calls++
+	if s.partial {
+		// Store half of the batch before failing
+		s.memoryStore.WriteJournalEntries(id, entries[:len(entries)/2])
+		return errors.New("partial write")
+	}
+	if s.fail {
+		return errors.New("store unavailable")
+	}
+	return s.memoryStore.WriteJournalEntries(id, entries)
+}
+
+func newJournalTestGame(store Store) *Game {
+	g := &Game{ID: "journal", Store: store, Initialized: true}
+	g.j.g = g
+	g.j.Init()
+	return g
+}
+
+func checkContiguousJournal(t *testing.T, raw [][]byte, count int) {
+	t.Helper()
+	entries, _, err := DecodeJournal(raw)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if len(entries) != count {
+		t.Fatalf("expected %d entries, got %d", count, len(entries))
+	}
+	for i, e := range entries {
+		if e.Index != i+1 {
+			t.Fatalf("expected index %d, got %d", i+1, e.Index)
+		}
+	}
+}
+
+func TestJournalWriteFlushesUnderPressure(t *testing.T) {
+	store := &flakyStore{}
+	g := newJournalTestGame(store)
+
+	// Far more than the old channel could hold without a flush
+	for i := 0; i < 3000; i++ {
+		g.j.WEndTurn(&entities.Player{Order: uint16(i % 3)})
+	}
+
+	if len(store.journal) < 3000-JournalFlushThreshold {
+		t.Fatalf("expected pressure flushes, only %d entries stored", len(store.journal))
+	}
+	if err := g.j.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	checkContiguousJournal(t, store.journal, 3000)
+
+	if stats := g.JournalStats(); stats.Pending != 0 || stats.Written != 3000 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestJournalKeepsEntriesWhileStoreFails(t *testing.T) {
+	store := &flakyStore{fail: true}
+	g := newJournalTestGame(store)
+
+	for i := 0; i < 10; i++ {
+		g.j.WSetInitPhase(true)
+	}
+
+	for i := 0; i < JournalMaxFailures; i++ {
+		if g.JournalError() != nil {
+			t.Fatalf("journal stalled after %d failures", i)
+		}
+		if err := g.j.Flush(); err == nil {
+			t.Fatal("expected flush to fail")
+		}
+	}
+	if g.JournalError() == nil {
+		t.Fatal("expected game to refuse actions after repeated failures")
+	}
+	if stats := g.JournalStats(); stats.Pending != 10 || stats.FailedFlushes != uint64(JournalMaxFailures) {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	// Once the store recovers everything is written, in order, once
+	store.fail = false
+	g.j.WSetInitPhase(false)
+	if err := g.j.Flush(); err != nil {
+		t.Fatalf("flush failed after recovery: %v", err)
+	}
+	if g.JournalError() != nil {
+		t.Fatalf("expected journal to recover, got %v", g.JournalError())
+	}
+	checkContiguousJournal(t, store.journal, 11)
+}
+
+func TestJournalStallsWhenTooFarBehind(t *testing.T) {
+	store := &flakyStore{fail: true}
+	g := newJournalTestGame(store)
+
+	for i := 0; i < JournalMaxPending; i++ {
+		g.j.WSetInitPhase(true)
+	}
+
+	if g.JournalError() == nil {
+		t.Fatal("expected journal to stall with too many pending entries")
+	}
+	// Backoff keeps a failing store from being hit on every write
+	if store.calls > JournalMaxPending/JournalFlushThreshold {
+		t.Fatalf("expected flush retries to back off, got %d calls", store.calls)
+	}
+	if stats := g.JournalStats(); stats.Pending != JournalMaxPending {
+		t.Fatalf("expected all entries to be kept, got %d", stats.Pending)
+	}
+}
+
+func TestJournalRetryAfterPartialWrite(t *testing.T) {
+	store := &flakyStore{partial: true}
+	g := newJournalTestGame(store)
+
+	for i := 0; i < 6; i++ {
+		g.j.WRollDice(1, 2)
+	}
+	if err := g.j.Flush(); err == nil {
+		t.Fatal("expected partial write to fail")
+	}
+
+	store.partial = false
+	if err := g.j.Flush(); err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+	if len(store.journal) != 9 {
+		t.Fatalf("expected duplicated entries in store, got %d", len(store.journal))
+	}
+	checkContiguousJournal(t, store.journal, 6)
+}
+
+func TestJournalWriteRefusesUnencodableEntry(t *testing.T) {
+	g := newJournalTestGame(&flakyStore{})
+
+	g.j.Write(JournalEntry{Type: JSetInitPhase, Fields: []interface{}{make(chan int)}})
+	if g.JournalError() == nil {
+		t.Fatal("expected unencodable entry to stop the game")
+	}
+
+	// The failed entry does not use up an index
+	g.j.WSetInitPhase(true)
+	var e JournalEntry
+	msgpack.Unmarshal(g.j.pending[0], &e)
+	if e.Index != 1 {
+		t.Fatalf("expected index 1, got %d", e.Index)
+	}
+}
@@ -0,0 +1,213 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"sakura/entities"
+	"sort"
+	"strings"
+)
+
+type (
+	// JournalViolation is a broken invariant found at a journal entry
+	JournalViolation struct {
+		Index   int
+		Message string
+	}
+
+	// JournalReport is the result of verifying a stored journal
+	JournalReport struct {
+		Entries    []JournalEntry
+		Version    int
+		Played     int
+		Violations []JournalViolation
+	}
+)
+
+func (v JournalViolation) String() string {
+	return fmt.Sprintf("#%d: %s", v.Index, v.Message)
+}
+
+// FormatJournalEntry prints an entry with its type and field names
+func FormatJournalEntry(e *JournalEntry) string {
+	var sb strings.Builder
+	schema, ok := JournalSchemas[e.Type]
+	if ok {
+		fmt.Fprintf(&sb, "#%d %s", e.Index, schema.Name)
+	} else {
+		fmt.Fprintf(&sb, "#%d Unknown(%d)", e.Index, e.Type)
+	}
+
+	for i, f := range e.Fields {
+		name := fmt.Sprintf("%d", i)
+		switch {
+		case ok && schema.Repeated && len(schema.Fields) == 1:
+			name = fmt.Sprintf("%s[%d]", schema.Fields[0].Name, i)
+		case ok && i < len(schema.Fields):
+			name = schema.Fields[i].Name
+		}
+		fmt.Fprintf(&sb, " %s=%v", name, f)
+	}
+
+	if e.Rand != 0 {
+		fmt.Fprintf(&sb, " rand=%d", e.Rand)
+	}
+	return sb.String()
+}
+
+// VerifyJournal plays a stored journal into a fresh game and checks the
+// game after every entry. Gaps in the indexes and entries that do not
+// decode stop the replay.
+func VerifyJournal(store Store, id string) (*JournalReport, error) {
+	numPlayers, err := store.ReadGamePlayers(id)
+	if err != nil {
+		return nil, err
+	}
+	if numPlayers < 1 {
+		return nil, errors.New("game has no players")
+	}
+
+	byteEntries, err := store.ReadJournal(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Everything readable is reported, the replay stops at the first
+	// entry that is not
+	entries, version, bad := decodeJournal(byteEntries)
+	report := &JournalReport{Entries: entries, Version: version}
+	playable := len(entries)
+	for _, b := range bad {
+		report.Violations = append(report.Violations, JournalViolation{Index: b.index, Message: b.err.Error()})
+		for i, e := range entries[:playable] {
+			if e.Index >= b.index {
+				playable = i
+				break
+			}
+		}
+	}
+	for i, e := range entries[:playable] {
+		if e.Index != i+1 {
+			report.Violations = append(report.Violations, JournalViolation{
+				Index:   i + 1,
+				Message: fmt.Sprintf("missing entries %d to %d", i+1, e.Index-1),
+			})
+			playable = i
+			break
+		}
+	}
+
+	r := &Replay{
+		ID:         id,
+		NumPlayers: uint16(numPlayers),
+		store:      replayStore{store},
+		entries:    entries[:playable],
+	}
+	r.reset()
+
+	active := map[string]bool{}
+	for i := range r.entries {
+		e := &r.entries[i]
+		if err := r.playChecked(e); err != nil {
+			report.Violations = append(report.Violations, JournalViolation{Index: e.Index, Message: err.Error()})
+			break
+		}
+		r.position++
+		report.Played = r.position
+
+		// Cards move between bank and hands in separate entries, so
+		// totals only add up at the end of a turn
+		balanced := e.Type == JEndTurn || i == len(r.entries)-1
+		found := map[string]bool{}
+		for _, msg := range r.g.checkInvariants(balanced) {
+			found[msg] = true
+			if !active[msg] {
+				report.Violations = append(report.Violations, JournalViolation{Index: e.Index, Message: msg})
+			}
+		}
+		active = found
+	}
+
+	sort.SliceStable(report.Violations, func(i, j int) bool {
+		return report.Violations[i].Index < report.Violations[j].Index
+	})
+	return report, nil
+}
+
+// playChecked plays one entry, turning a panic into an error like a
+// failed restore would hit
+func (r *Replay) playChecked(e *JournalEntry) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("failed to play %s: %v", JournalSchemas[e.Type].Name, p)
+		}
+	}()
+	return r.g.j.playEntry(e)
+}
+
+// checkInvariants lists what is wrong with the game state. Card totals
+// are only compared with the initial bank if balanced is set.
+func (g *Game) checkInvariants(balanced bool) []string {
+	var res []string
+
+	checkHand := func(owner string, h *entities.Hand) {
+		if h == nil {
+			return
+		}
+		for _, t := range sortedCardTypes(h) {
+			if q := h.CardDeckMap[t].Quantity; q < 0 {
+				res = append(res, fmt.Sprintf("%s has %d cards of type %d", owner, q, t))
+			}
+		}
+		for t, d := range h.DevelopmentCardDeckMap {
+			if d.Quantity < 0 {
+				res = append(res, fmt.Sprintf("%s has %d development cards of type %d", owner, d.Quantity, t))
+			}
+		}
+	}
+
+	if g.Bank != nil {
+		checkHand("bank", g.Bank.Hand)
+	}
+	for _, p := range g.Players {
+		owner := fmt.Sprintf("player %d", p.Order)
+		checkHand(owner, p.CurrentHand)
+
+		types := make([]int, 0, len(p.BuildablesLeft))
+		for t := range p.BuildablesLeft {
+			types = append(types, int(t))
+		}
+		sort.Ints(types)
+		for _, t := range types {
+			if left := p.BuildablesLeft[entities.BuildableType(t)]; left < 0 {
+				res = append(res, fmt.Sprintf("%s built %d more of buildable %d than allowed", owner, -left, t))
+			}
+		}
+	}
+
+	if balanced && g.Bank != nil && g.Bank.Hand != nil {
+		for _, t := range sortedCardTypes(g.Bank.Hand) {
+			total := int(g.Bank.Hand.CardDeckMap[t].Quantity)
+			for _, p := range g.Players {
+				if d := p.CurrentHand.GetCardDeck(t); d != nil {
+					total += int(d.Quantity)
+				}
+			}
+			if want := int(entities.GetInitialCardQuantity(g.Mode, t, isExtensionGame(uint16(len(g.Players))))); total != want {
+				res = append(res, fmt.Sprintf("bank and hands hold %d cards of type %d, expected %d", total, t, want))
+			}
+		}
+	}
+
+	sort.Strings(res)
+	return res
+}
+
+func sortedCardTypes(h *entities.Hand) []entities.CardType {
+	types := make([]entities.CardType, 0, len(h.CardDeckMap))
+	for t := range h.CardDeckMap {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
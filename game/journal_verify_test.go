@@ -0,0 +1,97 @@
+package game
+
+import (
+	"sakura/entities"
+	"strings"
+	"testing"
+)
+
+func TestVerifyJournalReportsViolations(t *testing.T) {
+	live := playSnapshotTestGame(t, &memoryStore{}, 4)
+	live.MoveCards(-1, 0, entities.CardTypeWood, 3, true, false)
+	live.j.Flush()
+
+	store := &replayTestStore{}
+	store.journal = live.Store.(*memoryStore).journal
+
+	report, err := VerifyJournal(store, "snapshot")
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if len(report.Violations) != 0 {
+		t.Fatalf("expected a clean journal, got %v", report.Violations)
+	}
+	if report.Played != len(report.Entries) {
+		t.Fatalf("expected all %d entries played, got %d", len(report.Entries), report.Played)
+	}
+
+	// Cards taken from a hand without going back to the bank
+	live.j.WUpdateCard(live.Players[1], entities.CardTypeOre, -20)
+	live.j.Flush()
+	store.journal = live.Store.(*memoryStore).journal
+
+	report, err = VerifyJournal(store, "snapshot")
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	messages := make([]string, 0)
+	for _, v := range report.Violations {
+		if v.Index != len(report.Entries) {
+			t.Fatalf("expected violations at the last entry, got %s", v)
+		}
+		messages = append(messages, v.Message)
+	}
+	joined := strings.Join(messages, "\n")
+	if !strings.Contains(joined, "player 1 has -") || !strings.Contains(joined, "cards of type 5, expected") {
+		t.Fatalf("expected negative count and bank mismatch, got %v", messages)
+	}
+
+	// Gaps stop the replay
+	store.journal = append(append([][]byte{}, store.journal[:20]...), store.journal[21:]...)
+	report, err = VerifyJournal(store, "snapshot")
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].Index != 21 || report.Played != 20 {
+		t.Fatalf("expected a gap at entry 21, got %v after %d entries", report.Violations, report.Played)
+	}
+}
+
+func TestVerifyJournalReportsCorruptEntry(t *testing.T) {
+	live := playSnapshotTestGame(t, &memoryStore{}, 4)
+	live.j.Flush()
+
+	store := &replayTestStore{}
+	store.journal = append([][]byte{}, live.Store.(*memoryStore).journal...)
+	total := len(store.journal)
+	store.journal[20] = []byte{0xc1}
+
+	report, err := VerifyJournal(store, "snapshot")
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if len(report.Entries) != total-1 {
+		t.Fatalf("expected the other %d entries to be decoded, got %d", total-1, len(report.Entries))
+	}
+	if report.Entries[20].Index != 22 {
+		t.Fatalf("expected the entries after the corrupt one, got #%d", report.Entries[20].Index)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].Index != 21 || report.Played != 20 {
+		t.Fatalf("expected the replay to stop at entry 21, got %v after %d entries", report.Violations, report.Played)
+	}
+	if !strings.Contains(report.Violations[0].Message, "invalid line") {
+		t.Fatalf("unexpected violation %s", report.Violations[0])
+	}
+}
+
+func TestFormatJournalEntry(t *testing.T) {
+	e := JournalEntry{Type: JRollDice, Fields: []interface{}{2, 5}, Index: 7}
+	if got := FormatJournalEntry(&e); got != "#7 RollDice red=2 white=5" {
+		t.Fatalf("unexpected format %q", got)
+	}
+
+	e = JournalEntry{Type: 999, Fields: []interface{}{true}, Index: 8, Rand: 3}
+	if got := FormatJournalEntry(&e); got != "#8 Unknown(999) 0=true rand=3" {
+		t.Fatalf("unexpected format %q", got)
+	}
+}
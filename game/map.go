@@ -5,8 +5,6 @@ import (
 	"sakura/entities"
 	"log"
 	"math"
-	"math/rand"
-	"time"
 )
 
 const (
@@ -87,6 +85,10 @@ func generateMapInner(g *Game, defn *entities.MapDefinition) error {
 					g.Tiles[center].Fog = true
 				}
 				g.j.WCreateTile(g.Tiles[center], dispX)
+				// Created tiles are deserts when played back
+				if g.Tiles[center].Type == entities.TileTypeSea {
+					g.j.WSetTileType(g.Tiles[center])
+				}
 			}
 
 			x += 4
@@ -116,7 +118,9 @@ func (g *Game) addTile(center entities.Coordinate, dispX float64) {
 }
 
 func (g *Game) generateVertices() {
-	for _, tile := range g.Tiles {
+	// Walk tiles in a fixed order so the graph is identical on every rebuild
+	for _, center := range g.sortedTileCoordinates() {
+		tile := g.Tiles[center]
 		theta := math.Pi / 2
 
 		for _, c := range tile.GetVertexCoordinates() {
@@ -149,7 +153,8 @@ func (g *Game) addVertex(c entities.Coordinate) *entities.Vertex {
 }
 
 func (g *Game) generateEdges() {
-	for _, tile := range g.Tiles {
+	for _, center := range g.sortedTileCoordinates() {
+		tile := g.Tiles[center]
 		for i, c := range tile.GetEdgeCoordinates() {
 			edge := g.addEdge(c)
 			edge.Orientation = uint16(i)
@@ -182,7 +187,8 @@ func (g *Game) generatePorts() {
 
 	if len(g.Settings.MapDefn.PortCoordinates) > 0 {
 		for _, c := range g.Settings.MapDefn.PortCoordinates {
-			if e, err := g.Graph.GetEdge(c); err == nil && e.IsBeach {
+			// Given harbors may also be on the coast of an island
+			if e, err := g.Graph.GetEdge(c); err == nil && (e.IsBeach || e.IsWaterEdge() && e.IsLandEdge()) {
 				beachEdges = append(beachEdges, e)
 			}
 		}
@@ -202,14 +208,12 @@ func (g *Game) generatePorts() {
 		}
 	}
 
-	rand.Seed(time.Now().UnixNano())
-
 	for _, portType := range types {
 		if len(beachEdges) == 0 {
 			break
 		}
 
-		edge := beachEdges[rand.Intn(len(beachEdges))]
+		edge := beachEdges[g.Rand().Intn(len(beachEdges))]
 
 		vertex1, err1 := g.Graph.GetVertex(edge.C.C1)
 		vertex2, err2 := g.Graph.GetVertex(edge.C.C2)
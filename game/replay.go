@@ -0,0 +1,314 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"sakura/entities"
+	"sakura/maps"
+	"sync"
+	"time"
+)
+
+type (
+	// Replay rebuilds a game from its stored journal and can be moved to
+	// the state after any entry. The rebuilt game never writes anything
+	// and has no ticker or connected players.
+	Replay struct {
+		ID         string
+		NumPlayers uint16
+
+		store   Store
+		entries []JournalEntry
+		turns   []int // Position after each ended turn
+		rolls   []int // Position after each dice roll
+
+		g        *Game
+		position int
+		finished bool
+		players  map[string]bool
+		mutex    sync.Mutex
+	}
+
+	// ReplayFrame is the state of a replayed game at one position
+	ReplayFrame struct {
+		Position int `msgpack:"i"`
+		Length   int `msgpack:"n"`
+		Turn     int `msgpack:"t"`
+		Turns    int `msgpack:"tn"`
+		Roll     int `msgpack:"r"`
+		Rolls    int `msgpack:"rn"`
+
+		// Type of the last entry played. The entry itself, with its RNG
+		// state and hands, only comes with the secret states.
+		EntryType    int                          `msgpack:"et"`
+		EntryName    string                       `msgpack:"en"`
+		Entry        *JournalEntry                `msgpack:"e,omitempty"`
+		Dice         entities.DieRollState        `msgpack:"d"`
+		State        *entities.GameState          `msgpack:"gs"`
+		SecretStates []entities.PlayerSecretState `msgpack:"ss,omitempty"`
+
+		// Board as the live game sends it on connect
+		Tiles            []*entities.Tile           `msgpack:"bt"`
+		Ports            []*entities.Port           `msgpack:"bp"`
+		VertexPlacements []entities.VertexBuildable `msgpack:"bv"`
+		EdgePlacements   []entities.EdgeBuildable   `msgpack:"be"`
+	}
+
+	// replayStore hides all writes of a replayed game from the real store
+	replayStore struct {
+		Store
+	}
+)
+
+func (s replayStore) Init(id string) error                                     { return nil }
+func (s replayStore) CreateGameIfNotExists(id string) error                    { return nil }
+func (s replayStore) CreateGameStateIfNotExists(id string, state []byte) error { return nil }
+func (s replayStore) WriteGameServer(id string) error                          { return nil }
+func (s replayStore) WriteGameStarted(id string) error                         { return nil }
+func (s replayStore) WriteGameFinished(id string) error                        { return nil }
+func (s replayStore) WriteGameCompletedForUser(id string) error                { return nil }
+func (s replayStore) WriteGamePlayers(id string, numPlayers int32) error       { return nil }
+func (s replayStore) WriteGameParticipants(id string, participantIds []string) error {
+	return nil
+}
+func (s replayStore) WriteGamePrivacy(id string, private bool) error        { return nil }
+func (s replayStore) WriteGameSettings(id string, settings []byte) error    { return nil }
+func (s replayStore) WriteJournalEntries(id string, entries [][]byte) error { return nil }
+func (s replayStore) WriteGameState(id string, state []byte) error          { return nil }
+func (s replayStore) WriteGameSnapshot(id string, snapshot []byte) error    { return nil }
+func (s replayStore) TerminateGame(id string) error                         { return nil }
+func (s replayStore) WriteGameActivePlayers(id string, numPlayers int32, host string) error {
+	return nil
+}
+func (s replayStore) WriteGamePresence(id string, connectedPlayers int32, connectedHumans int32, host string, hostId string, lastHumanSeenAt *time.Time) error {
+	return nil
+}
+func (s replayStore) WriteGameIdForUser(gameId, userId string, settings *entities.GameSettings) error {
+	return nil
+}
+
+// NewReplay loads the journal of a game from the store. The replay starts
+// before the first entry.
+func NewReplay(store Store, id string) (*Replay, error) {
+	numPlayers, err := store.ReadGamePlayers(id)
+	if err != nil {
+		return nil, err
+	}
+	if numPlayers < 1 {
+		return nil, errors.New("game has no players")
+	}
+
+	byteEntries, err := store.ReadJournal(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(byteEntries) == 0 {
+		return nil, errors.New("game has no journal")
+	}
+
+	entries, _, err := DecodeJournal(byteEntries)
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range entries {
+		if e.Index != i+1 {
+			return nil, errors.New("missing entries in journal")
+		}
+	}
+
+	r := &Replay{
+		ID:         id,
+		NumPlayers: uint16(numPlayers),
+		store:      replayStore{store},
+		entries:    entries,
+	}
+	for i, e := range entries {
+		switch e.Type {
+		case JEndTurn:
+			r.turns = append(r.turns, i+1)
+		case JRollDice:
+			r.rolls = append(r.rolls, i+1)
+		}
+	}
+
+	// Play through once to learn how the game ended
+	r.reset()
+	if err := r.Seek(len(entries)); err != nil {
+		return nil, err
+	}
+	r.finished = r.g.GameOver
+	r.players = make(map[string]bool)
+	for _, p := range r.g.Players {
+		r.players[p.Id] = true
+	}
+	r.reset()
+
+	return r, nil
+}
+
+func (r *Replay) reset() {
+	g := &Game{Store: r.store}
+	g.initState(r.ID, r.NumPlayers, true)
+	g.InitGraph()
+	g.j.playing = true
+
+	r.g = g
+	r.position = 0
+}
+
+// Len is the number of entries in the journal
+func (r *Replay) Len() int {
+	return len(r.entries)
+}
+
+// Turns is the number of ended turns in the journal
+func (r *Replay) Turns() int {
+	return len(r.turns)
+}
+
+// Rolls is the number of dice rolls in the journal
+func (r *Replay) Rolls() int {
+	return len(r.rolls)
+}
+
+// Seek moves to the state after the given number of entries. Moving
+// backwards rebuilds the game from the start of the journal.
+func (r *Replay) Seek(position int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if position < 0 || position > len(r.entries) {
+		return fmt.Errorf("position %d out of range 0-%d", position, len(r.entries))
+	}
+
+	if position < r.position {
+		r.reset()
+	}
+	for r.position < position {
+		if err := r.g.j.playEntry(&r.entries[r.position]); err != nil {
+			return err
+		}
+		r.position++
+	}
+
+	r.g.ensureMapDefn()
+	r.g.configureScenarioHooks()
+	return nil
+}
+
+// SeekTurn moves to the start of a turn. Turn 0 is the start of the game
+// and turn n starts right after the n-th ended turn.
+func (r *Replay) SeekTurn(turn int) error {
+	if turn < 0 || turn > len(r.turns) {
+		return fmt.Errorf("turn %d out of range 0-%d", turn, len(r.turns))
+	}
+	if turn == 0 {
+		return r.Seek(0)
+	}
+	return r.Seek(r.turns[turn-1])
+}
+
+// SeekRoll moves to right after the n-th dice roll, starting at 1
+func (r *Replay) SeekRoll(roll int) error {
+	if roll < 1 || roll > len(r.rolls) {
+		return fmt.Errorf("roll %d out of range 1-%d", roll, len(r.rolls))
+	}
+	return r.Seek(r.rolls[roll-1])
+}
+
+// Finished tells if the journal ends with the game over
+func (r *Replay) Finished() bool {
+	return r.finished
+}
+
+// HasPlayer tells if the user played in the game
+func (r *Replay) HasPlayer(id string) bool {
+	return id != "" && r.players[id]
+}
+
+// Game is the replayed game at the current position. It must only be read.
+func (r *Replay) Game() *Game {
+	return r.g
+}
+
+// Frame describes the current position, with the secret states of all
+// players and the last entry if requested
+func (r *Replay) Frame(secrets bool) *ReplayFrame {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	f := &ReplayFrame{
+		Position: r.position,
+		Length:   len(r.entries),
+		Turn:     countUpTo(r.turns, r.position),
+		Turns:    len(r.turns),
+		Roll:     countUpTo(r.rolls, r.position),
+		Rolls:    len(r.rolls),
+		Dice: entities.DieRollState{
+			RedRoll:   r.g.LastRollRed,
+			WhiteRoll: r.g.LastRollWhite,
+			EventRoll: r.g.LastRollEvent,
+		},
+		State: r.g.GetGameState(),
+	}
+	if r.position > 0 {
+		e := r.entries[r.position-1]
+		f.EntryType = e.Type
+		if schema, ok := JournalSchemas[e.Type]; ok {
+			f.EntryName = schema.Name
+		}
+		if secrets {
+			f.Entry = &e
+		}
+	}
+
+	for _, t := range r.g.sortedTiles() {
+		// Unexplored tiles stay hidden, as they were to the players
+		if t.Fog {
+			t = &entities.Tile{Center: t.Center, Fog: t.Fog}
+		}
+		f.Tiles = append(f.Tiles, t)
+	}
+	f.Ports = r.g.Ports
+	for _, p := range r.g.Players {
+		f.VertexPlacements = append(f.VertexPlacements, p.VertexPlacements...)
+		f.EdgePlacements = append(f.EdgePlacements, p.EdgePlacements...)
+	}
+
+	if secrets {
+		for _, p := range r.g.Players {
+			f.SecretStates = append(f.SecretStates, r.g.GetPlayerSecretState(p))
+		}
+	}
+
+	return f
+}
+
+// countUpTo counts the positions in the sorted list at or before pos
+func countUpTo(positions []int, pos int) int {
+	n := 0
+	for _, p := range positions {
+		if p > pos {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// ensureMapDefn loads the map definition named in the settings, which is
+// not part of the journal
+func (g *Game) ensureMapDefn() {
+	if g.Settings.MapDefn != nil && g.Settings.MapDefn.Name == g.Settings.MapName {
+		return
+	}
+
+	defn := g.Store.GetMap(g.Settings.MapName)
+	if defn == nil {
+		defn = maps.GetMapByName(g.Settings.MapName)
+	}
+	if defn == nil {
+		defn = maps.GetBaseMap()
+	}
+	g.Settings.MapDefn = defn
+}
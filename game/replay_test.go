@@ -0,0 +1,99 @@
+package game
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type replayTestStore struct {
+	memoryStore
+	writes int
+}
+
+func (s *replayTestStore) ReadGamePlayers(id string) (int, error) {
+	return 3, nil
+}
+func (s *replayTestStore) WriteJournalEntries(id string, entries [][]byte) error {
+	s.writes++
+	return nil
+}
+func (s *replayTestStore) WriteGameSnapshot(id string, snapshot []byte) error {
+	s.writes++
+	return nil
+}
+
+func TestReplaySeekMatchesLiveGame(t *testing.T) {
+	live := playSnapshotTestGame(t, &memoryStore{}, 8)
+	store := &replayTestStore{}
+	store.journal = live.Store.(*memoryStore).journal
+
+	replay, err := NewReplay(store, "snapshot")
+	if err != nil {
+		t.Fatalf("failed to load replay: %v", err)
+	}
+	if replay.Len() != len(store.journal) {
+		t.Fatalf("expected %d entries, got %d", len(store.journal), replay.Len())
+	}
+	if replay.Turns() != 8 || replay.Rolls() != 8 {
+		t.Fatalf("expected 8 turns and rolls, got %d and %d", replay.Turns(), replay.Rolls())
+	}
+	if replay.Finished() {
+		t.Fatal("expected unfinished game")
+	}
+
+	if err := replay.Seek(replay.Len()); err != nil {
+		t.Fatalf("seek to end failed: %v", err)
+	}
+	want := encodedSnapshot(t, live)
+	if got := encodedSnapshot(t, replay.Game()); !bytes.Equal(got, want) {
+		t.Fatal("replay at the end does not match live game")
+	}
+
+	// Seeking back rebuilds the same history
+	if err := replay.SeekTurn(3); err != nil {
+		t.Fatalf("seek turn failed: %v", err)
+	}
+	frame := replay.Frame(true)
+	if frame.Turn != 3 || frame.Roll != 3 {
+		t.Fatalf("expected turn 3 and roll 3, got %d and %d", frame.Turn, frame.Roll)
+	}
+	if frame.Entry == nil || frame.Entry.Type != JEndTurn {
+		t.Fatal("expected the frame to end on an ended turn")
+	}
+	if len(frame.SecretStates) != 3 {
+		t.Fatalf("expected 3 secret states, got %d", len(frame.SecretStates))
+	}
+	if _, err := msgpack.Marshal(frame); err != nil {
+		t.Fatalf("failed to encode frame: %v", err)
+	}
+	turnState := encodedSnapshot(t, replay.Game())
+
+	if err := replay.SeekRoll(4); err != nil {
+		t.Fatalf("seek roll failed: %v", err)
+	}
+	frame = replay.Frame(false)
+	if frame.EntryType != JRollDice || frame.EntryName == "" {
+		t.Fatal("expected the frame to end on a dice roll")
+	}
+	if frame.Entry != nil || frame.SecretStates != nil {
+		t.Fatal("expected no raw entry without secrets")
+	}
+	if err := replay.SeekTurn(3); err != nil {
+		t.Fatalf("seek turn failed: %v", err)
+	}
+	if !bytes.Equal(encodedSnapshot(t, replay.Game()), turnState) {
+		t.Fatal("seeking back does not rebuild the same state")
+	}
+
+	if err := replay.SeekTurn(9); err == nil {
+		t.Fatal("expected error seeking past the last turn")
+	}
+	if err := replay.SeekRoll(0); err == nil {
+		t.Fatal("expected error seeking roll 0")
+	}
+	if store.writes != 0 {
+		t.Fatalf("expected replay to write nothing, got %d writes", store.writes)
+	}
+}
@@ -0,0 +1,80 @@
+package game
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// randomSource is a splitmix64 generator. Its whole state is a single
+// number, so the position of a game's RNG can be written to the journal
+// and snapshots and restored exactly.
+type randomSource struct {
+	state uint64
+	mutex sync.Mutex
+}
+
+func (s *randomSource) Seed(seed int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state = uint64(seed)
+}
+
+func (s *randomSource) Uint64() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (s *randomSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+func (s *randomSource) State() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.state
+}
+
+func (s *randomSource) SetState(state uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state = state
+}
+
+// seedRandom starts the game RNG from the given seed. A zero seed picks
+// one from the clock.
+func (g *Game) seedRandom(seed int64) {
+	for seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	g.Seed = seed
+	g.randSource = &randomSource{}
+	g.randSource.Seed(seed)
+	g.rand = rand.New(g.randSource)
+}
+
+// Rand returns the RNG of the game. Every random choice that can change
+// the game must use it, so the same seed and the same player inputs
+// always produce the same game.
+func (g *Game) Rand() *rand.Rand {
+	if g.rand == nil {
+		g.seedRandom(g.Seed)
+	}
+	return g.rand
+}
+
+func (g *Game) randomState() uint64 {
+	g.Rand()
+	return g.randSource.State()
+}
+
+func (g *Game) setRandomState(state uint64) {
+	g.Rand()
+	g.randSource.SetState(state)
+}
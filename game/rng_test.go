@@ -0,0 +1,107 @@
+package game
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func newSeededTestGame(t *testing.T, seed int64) (*Game, *memoryStore) {
+	t.Helper()
+
+	store := &memoryStore{}
+	g := &Game{Store: store, Settings: snapshotTestSettings(), Seed: seed}
+	if _, err := g.Initialize("seeded", 3); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	stopTickerForTest(g)
+	return g, store
+}
+
+func TestSameSeedGeneratesSameGame(t *testing.T) {
+	a, storeA := newSeededTestGame(t, 42)
+	b, storeB := newSeededTestGame(t, 42)
+
+	if !bytes.Equal(encodedSnapshot(t, a), encodedSnapshot(t, b)) {
+		t.Fatal("expected games with the same seed to have the same board and decks")
+	}
+
+	a.j.Flush()
+	b.j.Flush()
+	if len(storeA.journal) != len(storeB.journal) {
+		t.Fatalf("expected identical journals, got %d and %d entries", len(storeA.journal), len(storeB.journal))
+	}
+	for i := range storeA.journal {
+		if !bytes.Equal(storeA.journal[i], storeB.journal[i]) {
+			t.Fatalf("journals differ at entry %d", i+1)
+		}
+	}
+
+	// Steals and dice keep matching
+	for i := 0; i < 20; i++ {
+		ca := a.Bank.Hand.ChooseRandomCardType(a.Rand())
+		cb := b.Bank.Hand.ChooseRandomCardType(b.Rand())
+		if *ca != *cb {
+			t.Fatalf("card choice %d differs: %d and %d", i, *ca, *cb)
+		}
+		if a.Rand().Intn(6) != b.Rand().Intn(6) {
+			t.Fatalf("roll %d differs", i)
+		}
+	}
+
+	c, _ := newSeededTestGame(t, 43)
+	same := true
+	for coord, tile := range a.Tiles {
+		if c.Tiles[coord].Type != tile.Type || c.Tiles[coord].Number != tile.Number {
+			same = false
+			break
+		}
+	}
+	if same {
+		t.Fatal("expected a different seed to generate a different board")
+	}
+}
+
+func TestSeedIsJournaledAndStored(t *testing.T) {
+	g, store := newSeededTestGame(t, 7)
+	g.j.Flush()
+
+	entries, _, err := DecodeJournal(store.journal)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if entries[1].Type != JSetSeed {
+		t.Fatalf("expected seed entry after the format entry, got type %d", entries[1].Type)
+	}
+
+	var state StoreGameState
+	b, _ := msgpack.Marshal(g.GenerateStoreGameState())
+	if err := msgpack.Unmarshal(b, &state); err != nil || state.Seed != 7 {
+		t.Fatalf("expected seed 7 in stored game state, got %d err=%v", state.Seed, err)
+	}
+}
+
+func TestRandomStateContinuesAfterRestore(t *testing.T) {
+	for _, useSnapshot := range []bool{false, true} {
+		store := &memoryStore{}
+		live := playSnapshotTestGame(t, store, 8)
+
+		// Draws since the last entry are written with the next one
+		live.Rand().Int63()
+		live.j.WSetInitPhase(false)
+		live.j.Flush()
+
+		store.skipSnapshot = !useSnapshot
+		restored := restoreSnapshotTestGame(t, store)
+
+		if restored.Seed != live.Seed {
+			t.Fatalf("snapshot=%v: expected seed %d, got %d", useSnapshot, live.Seed, restored.Seed)
+		}
+		for i := 0; i < 10; i++ {
+			if want, got := live.Rand().Int63(), restored.Rand().Int63(); want != got {
+				t.Fatalf("snapshot=%v: draw %d differs after restore", useSnapshot, i)
+			}
+		}
+	}
+}
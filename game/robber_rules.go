@@ -0,0 +1,94 @@
+package game
+
+import "sakura/entities"
+
+// Players with at most this many public points are safe from the friendly robber
+const FriendlyRobberPoints = 2
+
+// robberProtects checks if the friendly robber keeps away from the player
+func (g *Game) robberProtects(p *entities.Player) bool {
+	return g.Settings.Advanced && g.AdvancedSettings.FriendlyRobber &&
+		p != g.CurrentPlayer && g.GetVictoryPoints(p, true) <= FriendlyRobberPoints
+}
+
+// robberProtectsTile checks if the robber or pirate may not go to the tile
+// because of the friendly robber. The desert is always open.
+func (g *Game) robberProtectsTile(t *entities.Tile) bool {
+	if t.Type == entities.TileTypeDesert {
+		return false
+	}
+
+	if g.Mode.HasSeafarers() && t.Type == entities.TileTypeSea {
+		for _, ec := range t.GetEdgeCoordinates() {
+			e, err := g.Graph.GetEdge(ec)
+			if err != nil || e == nil || e.Placement == nil || e.Placement.GetType() != entities.BTShip {
+				continue
+			}
+			if g.robberProtects(e.Placement.GetOwner()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, vp := range g.Graph.GetTilePlacements(t) {
+		if vp.GetType() != entities.BTSettlement && vp.GetType() != entities.BTCity {
+			continue
+		}
+		if g.robberProtects(vp.GetOwner()) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterRobberTiles leaves out the tiles the friendly robber protects,
+// unless that leaves no tile at all
+func (g *Game) filterRobberTiles(tiles []*entities.Tile) []*entities.Tile {
+	allowed := make([]*entities.Tile, 0, len(tiles))
+	for _, t := range tiles {
+		if !g.robberProtectsTile(t) {
+			allowed = append(allowed, t)
+		}
+	}
+	if len(allowed) == 0 {
+		return tiles
+	}
+	return allowed
+}
+
+// robberHeld checks if a 7 leaves the robber alone in this round
+func (g *Game) robberHeld() bool {
+	return g.Settings.Advanced && g.RoundCount+1 < g.AdvancedSettings.RobberFromRound
+}
+
+// setRobberReturn sends the robber back to the desert when the turn of
+// the player ends
+func (g *Game) setRobberReturn(p *entities.Player) {
+	if !g.Settings.Advanced || !g.AdvancedSettings.RobberReturnsToDesert {
+		return
+	}
+	g.RobberReturnAfter = p
+	g.j.WSetRobberReturn(p)
+}
+
+// returnRobberAfterTurn puts the robber back on the desert if the turn
+// of the player was the one it waited for
+func (g *Game) returnRobberAfterTurn(p *entities.Player) {
+	if g.RobberReturnAfter == nil || g.RobberReturnAfter != p {
+		return
+	}
+	g.RobberReturnAfter = nil
+	g.j.WSetRobberReturn(nil)
+
+	if g.Robber.Tile != nil && g.Robber.Tile.Type == entities.TileTypeDesert {
+		return
+	}
+	for _, t := range g.sortedTiles() {
+		if !t.Fog && t.Type == entities.TileTypeDesert {
+			g.Robber.Move(t)
+			g.j.WSetRobber(t)
+			return
+		}
+	}
+}
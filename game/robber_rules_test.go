@@ -0,0 +1,154 @@
+package game
+
+import (
+	"sakura/entities"
+	"testing"
+)
+
+func newRobberRulesTestGame(t *testing.T, store Store, advanced entities.AdvancedSettings) *Game {
+	t.Helper()
+
+	settings := snapshotTestSettings()
+	settings.Advanced = true
+	g := &Game{Store: store, Settings: settings, AdvancedSettings: advanced}
+	if _, err := g.Initialize("robber-rules", 3); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	stopTickerForTest(g)
+	g.InitPhase = false
+	return g
+}
+
+// settleRobberRulesTestPlayer builds settlements for the player on free
+// vertices and returns the tile next to the first one
+func settleRobberRulesTestPlayer(t *testing.T, g *Game, p *entities.Player, n int) *entities.Tile {
+	t.Helper()
+
+	var tile *entities.Tile
+	for i := 0; i < n; i++ {
+		vertices := p.GetBuildLocationsSettlement(g.Graph, true, false)
+		for _, v := range vertices {
+			if tile != nil || len(v.AdjacentTiles) < 3 || v.AdjacentTiles[0].Type == entities.TileTypeDesert {
+				continue
+			}
+			tile = v.AdjacentTiles[0]
+		}
+		if len(vertices) == 0 {
+			t.Fatalf("no settlement location for player %d", p.Order)
+		}
+		v := vertices[0]
+		if i == 0 {
+			for _, c := range vertices {
+				if len(c.AdjacentTiles) > 0 && c.AdjacentTiles[0] == tile {
+					v = c
+					break
+				}
+			}
+		}
+		if err := p.BuildAtVertex(v, entities.BTSettlement); err != nil {
+			t.Fatalf("failed to place settlement: %v", err)
+		}
+	}
+	return tile
+}
+
+func TestFriendlyRobberProtectsSmallPlayers(t *testing.T) {
+	g := newRobberRulesTestGame(t, &noopStore{}, entities.AdvancedSettings{FriendlyRobber: true})
+	small, big := g.Players[1], g.Players[2]
+
+	smallTile := settleRobberRulesTestPlayer(t, g, small, 2)
+	settleRobberRulesTestPlayer(t, g, big, 3)
+	small.CurrentHand.UpdateResources(1, 1, 1, 1, 1)
+
+	if !g.robberProtects(small) || g.robberProtects(big) || g.robberProtects(g.CurrentPlayer) {
+		t.Fatal("expected only the player with 2 points to be protected")
+	}
+	if !g.robberProtectsTile(smallTile) {
+		t.Fatal("expected the tile of the protected player to be closed")
+	}
+
+	tiles := g.filterRobberTiles(g.sortedTiles())
+	for _, tile := range tiles {
+		if tile == smallTile {
+			t.Fatal("expected the protected tile to be left out")
+		}
+	}
+	if robber := g.botStrategy(g.CurrentPlayer).ChooseRobberTile(g.CurrentPlayer, g.sortedTiles()); g.robberProtectsTile(robber) {
+		t.Fatal("expected the AI to keep the robber off protected tiles")
+	}
+
+	if err := g.StealCardAtTile(smallTile); err != nil {
+		t.Fatalf("steal failed: %v", err)
+	}
+	if small.CurrentHand.GetCardCount() != 5 {
+		t.Fatal("expected nothing to be stolen from the protected player")
+	}
+}
+
+func TestNoRobberUntilRound(t *testing.T) {
+	g := newRobberRulesTestGame(t, &noopStore{}, entities.AdvancedSettings{RobberFromRound: 2})
+
+	if _, err := g.RollDiceWith(3, 4); err != nil {
+		t.Fatalf("roll failed: %v", err)
+	}
+	if g.DiceState != 1 || g.HasPlayerPendingAction() {
+		t.Fatal("expected the early 7 to do nothing")
+	}
+
+	for _, p := range g.Players {
+		g.DiceState = 1
+		if err := g.EndTurn(p); err != nil {
+			t.Fatalf("end turn failed: %v", err)
+		}
+	}
+	if g.RoundCount != 1 || g.robberHeld() {
+		t.Fatalf("expected the robber to be back in round 2, got %d rounds", g.RoundCount)
+	}
+
+	g.AdvancedSettings.RobberFromRound = 3
+	g.AdvancedSettings.RerollEarly7 = true
+	if _, err := g.RollDiceWith(3, 4); err != nil {
+		t.Fatalf("roll failed: %v", err)
+	}
+	if g.DiceState != 0 {
+		t.Fatal("expected the early 7 to be rolled again")
+	}
+}
+
+func TestRobberReturnsToDesert(t *testing.T) {
+	store := &memoryStore{skipSnapshot: true}
+	g := newRobberRulesTestGame(t, store, entities.AdvancedSettings{RobberReturnsToDesert: true})
+	desert := g.Robber.Tile
+	victim := g.Players[1]
+
+	for _, tile := range g.sortedTiles() {
+		if tile.Type != entities.TileTypeDesert && tile.Type != entities.TileTypeSea {
+			g.Robber.Move(tile)
+			g.j.WSetRobber(tile)
+			break
+		}
+	}
+	g.setRobberReturn(victim)
+	g.j.Flush()
+
+	resumed := newRobberRulesTestGame(t, &memoryStore{journal: store.journal}, entities.AdvancedSettings{RobberReturnsToDesert: true})
+	if resumed.RobberReturnAfter != resumed.Players[1] {
+		t.Fatal("expected the robber to wait for the victim after replay")
+	}
+
+	g.DiceState = 1
+	if err := g.EndTurn(g.Players[0]); err != nil {
+		t.Fatalf("end turn failed: %v", err)
+	}
+	if g.Robber.Tile == desert {
+		t.Fatal("expected the robber to stay until the victim played")
+	}
+
+	g.DiceState = 1
+	if err := g.EndTurn(victim); err != nil {
+		t.Fatalf("end turn failed: %v", err)
+	}
+	if g.Robber.Tile != desert || g.RobberReturnAfter != nil {
+		t.Fatal("expected the robber back on the desert")
+	}
+}
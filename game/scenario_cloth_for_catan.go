@@ -0,0 +1,172 @@
+package game
+
+import "sakura/entities"
+
+// Number tokens of the villages, one each
+var clothVillageNumbers = []uint16{2, 3, 4, 5, 6, 8, 9, 10, 11, 12}
+
+const (
+	// Cloth every village starts with
+	clothVillageSupply = 5
+
+	// The game ends once this many villages have no cloth left
+	clothExhaustedVillagesToEnd = 3
+)
+
+func (g *Game) configureClothForCatanHooks() {
+	awayFromVillages := func(g *Game, p *entities.Player, allowed []*entities.Vertex) []*entities.Vertex {
+		filtered := make([]*entities.Vertex, 0, len(allowed))
+		for _, v := range allowed {
+			if !g.isClothVillageVertex(v) {
+				filtered = append(filtered, v)
+			}
+		}
+		return filtered
+	}
+
+	// Nobody may settle on the islands of the villages
+	g.ScenarioHooks.FilterInitVertices = awayFromVillages
+	g.ScenarioHooks.FilterSettlementVertices = awayFromVillages
+
+	g.ScenarioHooks.OnDiceRolled = func(g *Game, roll int) {
+		g.produceCloth(roll)
+	}
+
+	g.ScenarioHooks.VictoryEvaluator = func(g *Game) *entities.Player {
+		if g.GetVictoryPoints(g.CurrentPlayer, false) >= g.getScenarioVictoryTarget() {
+			return g.CurrentPlayer
+		}
+		if g.countExhaustedClothVillages() >= clothExhaustedVillagesToEnd {
+			return g.getClothForCatanLeader()
+		}
+		return nil
+	}
+}
+
+// placeClothVillages puts a village with a shuffled number token on every
+// desert of the map
+func (g *Game) placeClothVillages() {
+	numbers := append([]uint16(nil), clothVillageNumbers...)
+	g.Rand().Shuffle(len(numbers), func(i, j int) {
+		numbers[i], numbers[j] = numbers[j], numbers[i]
+	})
+
+	g.ScenarioClothVillages = make([]*entities.ClothVillage, 0, len(numbers))
+	for _, t := range g.sortedTiles() {
+		if t.Type != entities.TileTypeDesert || len(g.ScenarioClothVillages) == len(numbers) {
+			continue
+		}
+		g.ScenarioClothVillages = append(g.ScenarioClothVillages, &entities.ClothVillage{
+			Tile:   t,
+			Number: numbers[len(g.ScenarioClothVillages)],
+			Cloth:  clothVillageSupply,
+		})
+	}
+
+	g.j.WSetClothVillages()
+}
+
+// isClothVillageVertex checks if the vertex lies on a village
+func (g *Game) isClothVillageVertex(v *entities.Vertex) bool {
+	for _, village := range g.ScenarioClothVillages {
+		for _, t := range v.AdjacentTiles {
+			if t == village.Tile {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isConnectedToClothVillage checks if the player has a ship along the
+// tile of the village
+func (g *Game) isConnectedToClothVillage(p *entities.Player, village *entities.ClothVillage) bool {
+	for _, ep := range p.EdgePlacements {
+		if ep.GetType() != entities.BTShip {
+			continue
+		}
+		for _, t := range ep.GetLocation().AdjacentTiles {
+			if t == village.Tile {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// produceCloth gives one cloth of every village with the rolled number to
+// each connected player, starting with the current player, while the
+// supply of the village lasts
+func (g *Game) produceCloth(roll int) {
+	changed := make(map[*entities.Player]bool)
+	for _, village := range g.ScenarioClothVillages {
+		if village.Number != uint16(roll) {
+			continue
+		}
+
+		for i := range g.Players {
+			p := g.Players[(int(g.CurrentPlayer.Order)+i)%len(g.Players)]
+			if village.Cloth == 0 {
+				break
+			}
+			if !g.isConnectedToClothVillage(p, village) {
+				continue
+			}
+
+			village.Cloth--
+			g.ScenarioCloth[p]++
+			changed[p] = true
+		}
+	}
+
+	if len(changed) == 0 {
+		return
+	}
+
+	g.j.WSetClothVillages()
+	for _, p := range g.Players {
+		if changed[p] {
+			g.j.WSetCloth(p)
+		}
+	}
+}
+
+func (g *Game) countExhaustedClothVillages() int {
+	count := 0
+	for _, village := range g.ScenarioClothVillages {
+		if village.Cloth == 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// getClothForCatanLeader returns the player with the most victory points
+// once the villages run out. Ties go to the player with more cloth, then
+// to the player closest in turn order to the current player.
+func (g *Game) getClothForCatanLeader() *entities.Player {
+	var leader *entities.Player
+	leaderVP := 0
+	for i := range g.Players {
+		p := g.Players[(int(g.CurrentPlayer.Order)+i)%len(g.Players)]
+		vp := g.GetVictoryPoints(p, false)
+		if leader == nil || vp > leaderVP || (vp == leaderVP && g.ScenarioCloth[p] > g.ScenarioCloth[leader]) {
+			leader = p
+			leaderVP = vp
+		}
+	}
+	return leader
+}
+
+// restoreClothVillages looks up the tiles of stored villages
+func (g *Game) restoreClothVillages(entries []ClothVillageEntry) []*entities.ClothVillage {
+	villages := make([]*entities.ClothVillage, 0, len(entries))
+	for _, ve := range entries {
+		t, ok := g.Tiles[ve.C]
+		if !ok {
+			continue
+		}
+		villages = append(villages, &entities.ClothVillage{Tile: t, Number: ve.Number, Cloth: ve.Cloth})
+	}
+	return villages
+}
@@ -1,9 +1,7 @@
 package game
 
 import (
-	"math/rand"
 	"sakura/entities"
-	"time"
 )
 
 func (g *Game) configureFogIslandsHooks() {}
@@ -16,6 +14,14 @@ func (g *Game) finalizeScenarioSetup() {
 	switch g.Settings.MapDefn.Scenario.Key {
 	case "seafarers_fog_islands":
 		g.initializeFogIslandsStacks()
+	case "seafarers_forgotten_tribe":
+		g.placeForgottenTribeMarkers()
+	case "seafarers_cloth_for_catan":
+		g.placeClothVillages()
+	case "seafarers_pirate_islands":
+		g.placePirateFortresses()
+	case "seafarers_wonders_of_catan":
+		g.placeWonders()
 	}
 }
 
@@ -23,7 +29,7 @@ func (g *Game) initializeFogIslandsStacks() {
 	g.ScenarioFogTileStack = nil
 	g.ScenarioFogNumberStack = nil
 
-	for _, tile := range g.Tiles {
+	for _, tile := range g.sortedTiles() {
 		if !tile.Fog {
 			continue
 		}
@@ -38,11 +44,10 @@ func (g *Game) initializeFogIslandsStacks() {
 		}
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(g.ScenarioFogTileStack), func(i, j int) {
+	g.Rand().Shuffle(len(g.ScenarioFogTileStack), func(i, j int) {
 		g.ScenarioFogTileStack[i], g.ScenarioFogTileStack[j] = g.ScenarioFogTileStack[j], g.ScenarioFogTileStack[i]
 	})
-	rand.Shuffle(len(g.ScenarioFogNumberStack), func(i, j int) {
+	g.Rand().Shuffle(len(g.ScenarioFogNumberStack), func(i, j int) {
 		g.ScenarioFogNumberStack[i], g.ScenarioFogNumberStack[j] = g.ScenarioFogNumberStack[j], g.ScenarioFogNumberStack[i]
 	})
 }
@@ -0,0 +1,244 @@
+package game
+
+import "sakura/entities"
+
+// Rewards handed out by the forgotten tribe, one marker each
+var forgottenTribeMarkers = []entities.TribeMarker{
+	{Type: entities.TribeMarkerVictoryPoint},
+	{Type: entities.TribeMarkerVictoryPoint},
+	{Type: entities.TribeMarkerVictoryPoint},
+	{Type: entities.TribeMarkerVictoryPoint},
+	{Type: entities.TribeMarkerVictoryPoint},
+	{Type: entities.TribeMarkerVictoryPoint},
+	{Type: entities.TribeMarkerVictoryPoint},
+	{Type: entities.TribeMarkerVictoryPoint},
+	{Type: entities.TribeMarkerDevelopmentCard},
+	{Type: entities.TribeMarkerDevelopmentCard},
+	{Type: entities.TribeMarkerDevelopmentCard},
+	{Type: entities.TribeMarkerDevelopmentCard},
+	{Type: entities.TribeMarkerHarbor, Port: entities.PortTypeAny},
+	{Type: entities.TribeMarkerHarbor, Port: entities.PortTypeWood},
+	{Type: entities.TribeMarkerHarbor, Port: entities.PortTypeBrick},
+	{Type: entities.TribeMarkerHarbor, Port: entities.PortTypeWool},
+	{Type: entities.TribeMarkerHarbor, Port: entities.PortTypeWheat},
+	{Type: entities.TribeMarkerHarbor, Port: entities.PortTypeOre},
+}
+
+func (g *Game) configureForgottenTribeHooks() {
+	onMainIsland := func(g *Game, p *entities.Player, allowed []*entities.Vertex) []*entities.Vertex {
+		g.ensureScenarioLandRegions()
+		if g.ScenarioLandMainRegion == 0 {
+			return allowed
+		}
+
+		filtered := make([]*entities.Vertex, 0, len(allowed))
+		for _, v := range allowed {
+			if g.scenarioVertexTouchesLandRegion(v, g.ScenarioLandMainRegion) {
+				filtered = append(filtered, v)
+			}
+		}
+		return filtered
+	}
+
+	// The islands of the tribe can only be visited by ship
+	g.ScenarioHooks.FilterInitVertices = onMainIsland
+	g.ScenarioHooks.FilterSettlementVertices = onMainIsland
+
+	g.ScenarioHooks.OnEdgeBuilt = func(g *Game, p *entities.Player, e *entities.Edge) {
+		g.claimForgottenTribeMarker(p, e)
+		g.placeForgottenTribeHarbors(p)
+	}
+
+	g.ScenarioHooks.VictoryEvaluator = func(g *Game) *entities.Player {
+		if g.GetVictoryPoints(g.CurrentPlayer, false) >= g.getForgottenTribeVictoryTarget() {
+			return g.CurrentPlayer
+		}
+		return nil
+	}
+}
+
+// getForgottenTribeVictoryTarget is the target of the scenario metadata.
+// The markers hand out enough points that the lobby setting is ignored.
+func (g *Game) getForgottenTribeVictoryTarget() int {
+	if g.Settings.MapDefn != nil &&
+		g.Settings.MapDefn.Scenario != nil &&
+		g.Settings.MapDefn.Scenario.VictoryPoints > 0 {
+		// Cities & Knights needs 2 more points than the scenario
+		if g.Mode == entities.SeafarersCitiesAndKnights {
+			return g.Settings.MapDefn.Scenario.VictoryPoints + 2
+		}
+		return g.Settings.MapDefn.Scenario.VictoryPoints
+	}
+	return g.getScenarioVictoryTarget()
+}
+
+// placeForgottenTribeMarkers puts the shuffled markers on sea edges along
+// the islands of the tribe
+func (g *Game) placeForgottenTribeMarkers() {
+	g.ensureScenarioLandRegions()
+
+	candidates := make([]*entities.Edge, 0)
+	for _, e := range g.Edges {
+		if e.IsWaterEdge() && g.isForgottenTribeEdge(e) {
+			candidates = append(candidates, e)
+		}
+	}
+	entities.SortEdges(candidates)
+
+	markers := append([]entities.TribeMarker(nil), forgottenTribeMarkers...)
+	g.Rand().Shuffle(len(markers), func(i, j int) {
+		markers[i], markers[j] = markers[j], markers[i]
+	})
+
+	g.ScenarioTribeMarkers = make([]*entities.TribeMarker, 0, len(markers))
+	for _, m := range markers {
+		if len(candidates) == 0 {
+			break
+		}
+
+		i := g.Rand().Intn(len(candidates))
+		m.Edge = candidates[i]
+		candidates = append(candidates[:i], candidates[i+1:]...)
+
+		marker := m
+		g.ScenarioTribeMarkers = append(g.ScenarioTribeMarkers, &marker)
+	}
+
+	g.j.WSetTribeMarkers()
+}
+
+// isForgottenTribeEdge checks if the edge lies along land outside the
+// main island
+func (g *Game) isForgottenTribeEdge(e *entities.Edge) bool {
+	for _, t := range e.AdjacentTiles {
+		if rid, ok := g.ScenarioLandRegionByTile[t.Center]; ok && rid != g.ScenarioLandMainRegion {
+			return true
+		}
+	}
+	return false
+}
+
+// claimForgottenTribeMarker hands the marker on the edge to the owner of
+// the ship built there
+func (g *Game) claimForgottenTribeMarker(p *entities.Player, e *entities.Edge) {
+	if p == nil || e == nil || e.Placement == nil || e.Placement.GetType() != entities.BTShip {
+		return
+	}
+
+	var marker *entities.TribeMarker
+	for i, m := range g.ScenarioTribeMarkers {
+		if m.Edge == e {
+			marker = m
+			g.ScenarioTribeMarkers = append(g.ScenarioTribeMarkers[:i:i], g.ScenarioTribeMarkers[i+1:]...)
+			break
+		}
+	}
+	if marker == nil {
+		return
+	}
+
+	switch marker.Type {
+	case entities.TribeMarkerVictoryPoint:
+		g.ScenarioBonusVP[p]++
+	case entities.TribeMarkerDevelopmentCard:
+		// Once the stack is empty the marker is lost
+		if g.Bank.DevelopmentCardCursor < len(g.Bank.DevelopmentCardOrder[0]) {
+			g.drawDevelopmentCard(p)
+		}
+	case entities.TribeMarkerHarbor:
+		g.ScenarioTribeHarbors[p] = append(g.ScenarioTribeHarbors[p], marker.Port)
+	}
+
+	g.SendPlayerSecret(p)
+	g.BroadcastState()
+}
+
+// placeForgottenTribeHarbors puts the harbors a player got from the tribe
+// next to their settlements and cities. Harbors without a free coast wait
+// for the next road or ship the player builds.
+func (g *Game) placeForgottenTribeHarbors(p *entities.Player) {
+	placed := false
+	for len(g.ScenarioTribeHarbors[p]) > 0 {
+		edge := g.getForgottenTribeHarborEdge(p)
+		if edge == nil {
+			break
+		}
+
+		portType := g.ScenarioTribeHarbors[p][0]
+		g.ScenarioTribeHarbors[p] = g.ScenarioTribeHarbors[p][1:]
+
+		v1, _ := g.Graph.GetVertex(edge.C.C1)
+		v2, _ := g.Graph.GetVertex(edge.C.C2)
+		ratio := 2
+		if portType == entities.PortTypeAny {
+			ratio = 3
+		}
+
+		port := &entities.Port{
+			Type:     portType,
+			Vertices: []*entities.Vertex{v1, v2},
+			Edge:     edge,
+			Ratio:    int16(ratio),
+		}
+		g.Ports = append(g.Ports, port)
+		placed = true
+
+		// Same message as the ports sent when joining
+		g.BroadcastMessage(&entities.Message{Type: "i-p", Data: port})
+	}
+
+	if placed {
+		g.j.WSetPorts()
+		g.SendPlayerSecret(p)
+	}
+}
+
+// getForgottenTribeHarborEdge returns the first coast of the main island
+// next to a settlement or city of the player without a harbor nearby
+func (g *Game) getForgottenTribeHarborEdge(p *entities.Player) *entities.Edge {
+	g.ensureScenarioLandRegions()
+
+	taken := make(map[*entities.Vertex]bool)
+	for _, port := range g.Ports {
+		for _, v := range port.Vertices {
+			taken[v] = true
+		}
+	}
+
+	edges := make([]*entities.Edge, 0)
+	for _, vp := range p.VertexPlacements {
+		bt := vp.GetType()
+		if bt != entities.BTSettlement && bt != entities.BTCity {
+			continue
+		}
+		for _, e := range g.Graph.GetAdjacentVertexEdges(vp.GetLocation()) {
+			if e.IsWaterEdge() && e.IsLandEdge() && g.scenarioEdgeTouchesLandRegion(e, g.ScenarioLandMainRegion) {
+				edges = append(edges, e)
+			}
+		}
+	}
+	entities.SortEdges(edges)
+
+	for _, e := range edges {
+		v1, err1 := g.Graph.GetVertex(e.C.C1)
+		v2, err2 := g.Graph.GetVertex(e.C.C2)
+		if err1 != nil || err2 != nil || taken[v1] || taken[v2] {
+			continue
+		}
+		return e
+	}
+	return nil
+}
+
+// restoreTribeMarkers looks up the edges of stored markers
+func (g *Game) restoreTribeMarkers(entries []TribeMarkerEntry) []*entities.TribeMarker {
+	markers := make([]*entities.TribeMarker, 0, len(entries))
+	for _, me := range entries {
+		e, err := g.Graph.GetEdge(me.C)
+		if err != nil {
+			continue
+		}
+		markers = append(markers, &entities.TribeMarker{Edge: e, Type: me.Type, Port: me.Port})
+	}
+	return markers
+}
@@ -34,7 +34,7 @@ func (g *Game) configureHeadingForNewShoresHooks() {
 	}
 
 	g.ScenarioHooks.OnSettlementBuilt = func(g *Game, p *entities.Player, v *entities.Vertex) {
-		g.applyScenarioOuterIslandSettlementBonus(p, v)
+		g.applyScenarioOuterIslandSettlementBonus(p, v, 2)
 	}
 }
 
@@ -60,7 +60,7 @@ func (g *Game) ensureScenarioLandRegions() {
 
 	regionID := 0
 	sizeByRegion := make(map[int]int)
-	for _, tile := range g.Tiles {
+	for _, tile := range g.sortedTiles() {
 		if !isLandTile(tile) {
 			continue
 		}
@@ -103,7 +103,7 @@ func (g *Game) ensureScenarioLandRegions() {
 	}
 }
 
-func (g *Game) applyScenarioOuterIslandSettlementBonus(p *entities.Player, v *entities.Vertex) {
+func (g *Game) applyScenarioOuterIslandSettlementBonus(p *entities.Player, v *entities.Vertex, bonus int) {
 	if p == nil || v == nil {
 		return
 	}
@@ -133,7 +133,7 @@ func (g *Game) applyScenarioOuterIslandSettlementBonus(p *entities.Player, v *en
 	}
 
 	g.ScenarioLandAwarded[p][awardedRegion] = true
-	g.ScenarioBonusVP[p] += 2
+	g.ScenarioBonusVP[p] += bonus
 }
 
 func (g *Game) scenarioVertexTouchesLandRegion(v *entities.Vertex, regionID int) bool {
@@ -3,12 +3,21 @@ package game
 import "sakura/entities"
 
 type ScenarioHookSet struct {
-	FilterInitVertices func(g *Game, p *entities.Player, allowed []*entities.Vertex) []*entities.Vertex
-	FilterInitEdges    func(g *Game, p *entities.Player, allowed []*entities.Edge) []*entities.Edge
-	OnSettlementBuilt  func(g *Game, p *entities.Player, v *entities.Vertex)
-	OnTurnStart        func(g *Game, p *entities.Player)
-	OnDiceRolled       func(g *Game, roll int)
-	VictoryEvaluator   func(g *Game) *entities.Player
+	FilterInitVertices       func(g *Game, p *entities.Player, allowed []*entities.Vertex) []*entities.Vertex
+	FilterInitEdges          func(g *Game, p *entities.Player, allowed []*entities.Edge) []*entities.Edge
+	FilterSettlementVertices func(g *Game, p *entities.Player, allowed []*entities.Vertex) []*entities.Vertex
+	OnSettlementBuilt        func(g *Game, p *entities.Player, v *entities.Vertex)
+	OnEdgeBuilt              func(g *Game, p *entities.Player, e *entities.Edge)
+	OnTurnStart              func(g *Game, p *entities.Player)
+	OnDiceRolled             func(g *Game, roll int)
+	VictoryEvaluator         func(g *Game) *entities.Player
+
+	// Knights do something else than moving the robber
+	PlayKnight  func(g *Game, p *entities.Player, dry bool) error
+	OnShipMoved func(g *Game, p *entities.Player, from *entities.Edge, to *entities.Edge)
+
+	// The robber and pirate stay where they are on a 7
+	NoRobber bool
 }
 
 func (g *Game) configureScenarioHooks() {
@@ -18,7 +27,7 @@ func (g *Game) configureScenarioHooks() {
 	}
 
 	switch g.Settings.MapDefn.Scenario.Key {
-	case "seafarers_heading_for_new_shores":
+	case "seafarers_heading_for_new_shores", "seafarers_heading_for_new_shores_extension":
 		g.configureHeadingForNewShoresHooks()
 	case "seafarers_four_islands":
 		g.configureFourIslandsHooks()
@@ -26,6 +35,14 @@ func (g *Game) configureScenarioHooks() {
 		g.configureFogIslandsHooks()
 	case "seafarers_through_the_desert":
 		g.configureThroughDesertHooks()
+	case "seafarers_forgotten_tribe":
+		g.configureForgottenTribeHooks()
+	case "seafarers_cloth_for_catan":
+		g.configureClothForCatanHooks()
+	case "seafarers_pirate_islands":
+		g.configurePirateIslandsHooks()
+	case "seafarers_wonders_of_catan":
+		g.configureWondersOfCatanHooks()
 	}
 }
 
@@ -43,12 +60,39 @@ func (g *Game) applyInitEdgeScenarioHooks(p *entities.Player, allowed []*entitie
 	return g.ScenarioHooks.FilterInitEdges(g, p, allowed)
 }
 
+func (g *Game) applySettlementScenarioHooks(p *entities.Player, allowed []*entities.Vertex) []*entities.Vertex {
+	if g.ScenarioHooks.FilterSettlementVertices == nil {
+		return allowed
+	}
+	return g.ScenarioHooks.FilterSettlementVertices(g, p, allowed)
+}
+
+// GetBuildLocationsSettlement lists where a player can build a settlement
+// after the init phase, leaving out places the scenario forbids
+func (g *Game) GetBuildLocationsSettlement(p *entities.Player) []*entities.Vertex {
+	return g.applySettlementScenarioHooks(p, p.GetBuildLocationsSettlement(g.Graph, false, false))
+}
+
 func (g *Game) onScenarioSettlementBuilt(p *entities.Player, v *entities.Vertex) {
 	if g.ScenarioHooks.OnSettlementBuilt != nil {
 		g.ScenarioHooks.OnSettlementBuilt(g, p, v)
 	}
 }
 
+// onScenarioEdgeBuilt runs once a road or ship is on the edge and in the
+// journal, so anything the hook journals is played after the build
+func (g *Game) onScenarioEdgeBuilt(p *entities.Player, e *entities.Edge) {
+	if g.ScenarioHooks.OnEdgeBuilt != nil {
+		g.ScenarioHooks.OnEdgeBuilt(g, p, e)
+	}
+}
+
+func (g *Game) onScenarioShipMoved(p *entities.Player, from *entities.Edge, to *entities.Edge) {
+	if g.ScenarioHooks.OnShipMoved != nil {
+		g.ScenarioHooks.OnShipMoved(g, p, from, to)
+	}
+}
+
 func (g *Game) onScenarioTurnStart(p *entities.Player) {
 	if g.ScenarioHooks.OnTurnStart != nil {
 		g.ScenarioHooks.OnTurnStart(g, p)
@@ -68,6 +112,10 @@ func (g *Game) getScenarioVictoryTarget() int {
 	if g.Settings.MapDefn != nil &&
 		g.Settings.MapDefn.Scenario != nil &&
 		g.Settings.MapDefn.Scenario.VictoryPoints > 0 {
+		// Cities & Knights needs 2 more points than the scenario
+		if g.Mode == entities.SeafarersCitiesAndKnights {
+			return g.Settings.MapDefn.Scenario.VictoryPoints + 2
+		}
 		return g.Settings.MapDefn.Scenario.VictoryPoints
 	}
 	return g.Settings.VictoryPoints
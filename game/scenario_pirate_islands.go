@@ -0,0 +1,459 @@
+package game
+
+import (
+	"errors"
+	"math"
+	"sakura/entities"
+	"sort"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	// Won attacks needed to conquer a fortress
+	pirateFortressStrength = 3
+
+	// Ships next to the fortress lost with an attack
+	pirateFortressShipsLost = 2
+)
+
+func (g *Game) configurePirateIslandsHooks() {
+	awayFromFortresses := func(g *Game, p *entities.Player, allowed []*entities.Vertex) []*entities.Vertex {
+		filtered := make([]*entities.Vertex, 0, len(allowed))
+		for _, v := range allowed {
+			if !g.isPirateIslandVertex(v) {
+				filtered = append(filtered, v)
+			}
+		}
+		return filtered
+	}
+
+	// The islands of the pirates can only be taken by conquering them
+	g.ScenarioHooks.FilterInitVertices = awayFromFortresses
+	g.ScenarioHooks.FilterSettlementVertices = awayFromFortresses
+
+	g.ScenarioHooks.NoRobber = true
+	g.ScenarioHooks.OnDiceRolled = func(g *Game, roll int) {
+		g.sailPirateFleet()
+	}
+
+	g.ScenarioHooks.PlayKnight = func(g *Game, p *entities.Player, dry bool) error {
+		return g.buildWarship(p, dry)
+	}
+
+	g.ScenarioHooks.OnShipMoved = func(g *Game, p *entities.Player, from *entities.Edge, to *entities.Edge) {
+		if g.ScenarioWarships[from] {
+			delete(g.ScenarioWarships, from)
+			g.ScenarioWarships[to] = true
+			g.j.WSetWarship(from, false)
+			g.j.WSetWarship(to, true)
+		}
+	}
+
+	// Enough points only win with the own fortress conquered
+	g.ScenarioHooks.VictoryEvaluator = func(g *Game) *entities.Player {
+		p := g.CurrentPlayer
+		if g.GetVictoryPoints(p, false) < g.getScenarioVictoryTarget() {
+			return nil
+		}
+		if f := g.getPirateFortress(p); f != nil && f.Strength > 0 {
+			return nil
+		}
+		return p
+	}
+}
+
+// placePirateFortresses gives every player a fortress on one of the
+// islands of the pirates and puts the pirate fleet on its route
+func (g *Game) placePirateFortresses() {
+	g.ensureScenarioLandRegions()
+
+	// Middle of the main island, fortresses face it
+	var cx, cy, n float64
+	for c, rid := range g.ScenarioLandRegionByTile {
+		if rid == g.ScenarioLandMainRegion {
+			cx += float64(c.X)
+			cy += float64(c.Y)
+			n++
+		}
+	}
+	if n > 0 {
+		cx /= n
+		cy /= n
+	}
+
+	g.ScenarioFortresses = make([]*entities.PirateFortress, 0)
+	for _, t := range g.sortedTiles() {
+		if len(g.ScenarioFortresses) == len(g.Players) {
+			break
+		}
+		if rid, ok := g.ScenarioLandRegionByTile[t.Center]; !ok || rid == g.ScenarioLandMainRegion || t.Type != entities.TileTypeDesert {
+			continue
+		}
+
+		var closest *entities.Vertex
+		closestDist := 0.0
+		for _, c := range t.GetVertexCoordinates() {
+			v, err := g.Graph.GetVertex(c)
+			if err != nil {
+				continue
+			}
+			dist := math.Pow(float64(c.X)-cx, 2) + math.Pow(float64(c.Y)-cy, 2)
+			if closest == nil || dist < closestDist {
+				closest = v
+				closestDist = dist
+			}
+		}
+		if closest == nil {
+			continue
+		}
+
+		g.ScenarioFortresses = append(g.ScenarioFortresses, &entities.PirateFortress{
+			Vertex:   closest,
+			Owner:    uint16(len(g.ScenarioFortresses)),
+			Strength: pirateFortressStrength,
+		})
+	}
+	g.j.WSetFortresses()
+
+	if route := g.getPirateRoute(); len(route) > 0 && g.Pirate != nil {
+		g.Pirate.Move(route[0])
+		g.j.WSetPirate(route[0])
+	}
+}
+
+// getPirateRoute returns the sea tiles around the main island, clockwise.
+// The pirate fleet sails along them.
+func (g *Game) getPirateRoute() []*entities.Tile {
+	if g.ScenarioPirateRoute != nil {
+		return g.ScenarioPirateRoute
+	}
+	g.ensureScenarioLandRegions()
+
+	var cx, cy, n float64
+	for c, rid := range g.ScenarioLandRegionByTile {
+		if rid == g.ScenarioLandMainRegion {
+			cx += float64(c.X)
+			cy += float64(c.Y)
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	cx /= n
+	cy /= n
+
+	route := make([]*entities.Tile, 0)
+	for _, t := range g.sortedTiles() {
+		if t.Type != entities.TileTypeSea {
+			continue
+		}
+		for _, c := range g.scenarioNeighborCenters(t.Center) {
+			if rid, ok := g.ScenarioLandRegionByTile[c]; ok && rid == g.ScenarioLandMainRegion {
+				route = append(route, t)
+				break
+			}
+		}
+	}
+
+	angle := func(t *entities.Tile) float64 {
+		return math.Atan2(float64(t.Center.Y)-cy, float64(t.Center.X)-cx)
+	}
+	sort.SliceStable(route, func(i, j int) bool {
+		return angle(route[i]) < angle(route[j])
+	})
+
+	g.ScenarioPirateRoute = route
+	return route
+}
+
+// sailPirateFleet moves the pirate fleet along its route by the lower die.
+// The fleet then attacks with the higher die, after the cards of the roll
+// are handed out.
+func (g *Game) sailPirateFleet() {
+	route := g.getPirateRoute()
+	if len(route) == 0 || g.Pirate == nil {
+		return
+	}
+
+	pos := 0
+	for i, t := range route {
+		if t == g.Pirate.Tile {
+			pos = i
+			break
+		}
+	}
+
+	steps, strength := g.LastRollRed, g.LastRollWhite
+	if steps > strength {
+		steps, strength = strength, steps
+	}
+
+	tile := route[(pos+steps)%len(route)]
+	g.Pirate.Move(tile)
+	g.j.WSetPirate(tile)
+
+	// Cards taken are in the journal
+	if !g.j.playing {
+		g.spawn(func() { g.PirateFleetAttack(tile, strength) })
+	}
+}
+
+// PirateFleetAttack takes a resource from every player with a settlement
+// or city next to the pirate fleet and fewer warships than its strength
+func (g *Game) PirateFleetAttack(tile *entities.Tile, strength int) {
+	g.ActionMutex.Lock()
+	defer g.ActionMutex.Unlock()
+
+	defer g.Unlock()
+	if !g.Lock() {
+		return
+	}
+
+	if !g.Initialized || g.j.playing {
+		return
+	}
+
+	attacked := make(map[*entities.Player]bool)
+	for _, vp := range g.Graph.GetTilePlacements(tile) {
+		if vp.GetType() != entities.BTSettlement && vp.GetType() != entities.BTCity {
+			continue
+		}
+		attacked[vp.GetOwner()] = true
+	}
+
+	for i := range g.Players {
+		p := g.Players[(int(g.CurrentPlayer.Order)+i)%len(g.Players)]
+		if !attacked[p] || g.countWarships(p) >= strength {
+			continue
+		}
+
+		cardType := p.CurrentHand.ChooseRandomCardType(g.Rand())
+		if cardType != nil {
+			g.MoveCards(int(p.Order), -1, *cardType, 1, true, false)
+			g.SendPlayerSecret(p)
+		}
+	}
+}
+
+// isPirateIslandVertex checks if the vertex lies on an island with a
+// fortress of the pirates
+func (g *Game) isPirateIslandVertex(v *entities.Vertex) bool {
+	for _, f := range g.ScenarioFortresses {
+		for _, ft := range f.Vertex.AdjacentTiles {
+			if ft.Type != entities.TileTypeDesert {
+				continue
+			}
+			for _, t := range v.AdjacentTiles {
+				if t == ft {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (g *Game) getPirateFortress(p *entities.Player) *entities.PirateFortress {
+	for _, f := range g.ScenarioFortresses {
+		if f.Owner == p.Order {
+			return f
+		}
+	}
+	return nil
+}
+
+func (g *Game) countWarships(p *entities.Player) int {
+	count := 0
+	for _, ep := range p.EdgePlacements {
+		if ep.GetType() == entities.BTShip && g.ScenarioWarships[ep.GetLocation()] {
+			count++
+		}
+	}
+	return count
+}
+
+// getWarshipCoordinates lists the edges of all warships in a fixed order
+func (g *Game) getWarshipCoordinates() []entities.EdgeCoordinate {
+	edges := make([]*entities.Edge, 0, len(g.ScenarioWarships))
+	for e := range g.ScenarioWarships {
+		edges = append(edges, e)
+	}
+	entities.SortEdges(edges)
+
+	coords := make([]entities.EdgeCoordinate, len(edges))
+	for i, e := range edges {
+		coords[i] = e.C
+	}
+	return coords
+}
+
+// buildWarship lets the player turn one of their ships into a warship
+func (g *Game) buildWarship(p *entities.Player, dry bool) error {
+	ships := make([]*entities.Edge, 0)
+	for _, ep := range p.EdgePlacements {
+		if ep.GetType() == entities.BTShip && !g.ScenarioWarships[ep.GetLocation()] {
+			ships = append(ships, ep.GetLocation())
+		}
+	}
+	entities.SortEdges(ships)
+	if len(ships) == 0 {
+		return errors.New("no ship to turn into a warship")
+	}
+
+	if dry {
+		return nil
+	}
+
+	exp, err := g.BlockForAction(p, g.TimerVals.DevCardNonTurnStatePlaceRobber, &entities.PlayerAction{
+		Type:    entities.PlayerActionTypeChooseWarship,
+		Message: "Choose ship to turn into a warship",
+		Data:    entities.PlayerActionChooseEdge{Allowed: ships},
+	})
+	if err != nil {
+		return err
+	}
+
+	ship := ships[0]
+	var c entities.EdgeCoordinate
+	if exp != nil && mapstructure.Decode(exp, &c) == nil {
+		for _, e := range ships {
+			if (e.C.C1 == c.C1 && e.C.C2 == c.C2) || (e.C.C1 == c.C2 && e.C.C2 == c.C1) {
+				ship = e
+				break
+			}
+		}
+	}
+
+	g.ScenarioWarships[ship] = true
+	g.j.WSetWarship(ship, true)
+	g.BroadcastState()
+	return nil
+}
+
+// CanAttackPirateFortress checks if the player can attack their fortress
+// now. It takes a ship next to the fortress, after rolling the dice, once
+// every turn.
+func (g *Game) CanAttackPirateFortress(p *entities.Player) error {
+	f := g.getPirateFortress(p)
+	if f == nil || f.Strength == 0 {
+		return errors.New("no fortress to attack")
+	}
+	if g.CurrentPlayer != p || g.DiceState != 1 || g.InitPhase {
+		return errors.New("can only attack after rolling the dice")
+	}
+	if f.AttackedTurn == g.TurnCount+1 {
+		return errors.New("already attacked this turn")
+	}
+	if len(g.getFortressShips(p, f)) == 0 {
+		return errors.New("no ship next to the fortress")
+	}
+	return nil
+}
+
+// getFortressShips returns the ships of the player next to the fortress
+func (g *Game) getFortressShips(p *entities.Player, f *entities.PirateFortress) []*entities.Edge {
+	ships := make([]*entities.Edge, 0)
+	for _, e := range g.Graph.GetAdjacentVertexEdges(f.Vertex) {
+		if e.Placement != nil && e.Placement.GetOwner() == p && e.Placement.GetType() == entities.BTShip {
+			ships = append(ships, e)
+		}
+	}
+	entities.SortEdges(ships)
+	return ships
+}
+
+// AttackPirateFortress rolls a die for the pirates against the warships of
+// the player. More warships take one strength off the fortress, fewer lose
+// the ships next to it.
+func (g *Game) AttackPirateFortress(p *entities.Player) error {
+	if err := g.EnsureCurrentPlayer(p); err != nil {
+		return err
+	}
+
+	g.ActionMutex.Lock()
+	defer g.ActionMutex.Unlock()
+
+	if err := g.CanAttackPirateFortress(p); err != nil {
+		return err
+	}
+
+	f := g.getPirateFortress(p)
+	roll := g.Rand().Intn(6) + 1
+	warships := g.countWarships(p)
+	f.AttackedTurn = g.TurnCount + 1
+
+	if warships > roll {
+		f.Strength--
+		if f.Strength == 0 {
+			g.conquerPirateFortress(p, f)
+		}
+	} else if warships < roll {
+		ships := g.getFortressShips(p, f)
+		for i, e := range ships {
+			if i == pirateFortressShipsLost {
+				break
+			}
+			if g.ScenarioWarships[e] {
+				delete(g.ScenarioWarships, e)
+				g.j.WSetWarship(e, false)
+			}
+			e.RemovePlacement()
+			p.BuildablesLeft[entities.BTShip]++
+			g.j.WEdgeBuild(e)
+			g.BroadcastMessage(&entities.Message{
+				Type: entities.MessageTypeEdgePlacementRem,
+				Data: &entities.Road{Owner: p, Location: e, Type: entities.BTShip},
+			})
+		}
+	}
+	g.j.WSetFortresses()
+
+	g.BroadcastMessage(&entities.Message{
+		Type: entities.MessageTypeFortressAttack,
+		Data: &entities.FortressAttack{Player: p.Order, Warships: warships, Roll: roll, Won: warships > roll},
+	})
+
+	g.SetExtraVictoryPoints()
+	g.SendPlayerSecret(p)
+	g.BroadcastState()
+	g.CheckForVictory()
+	return nil
+}
+
+// conquerPirateFortress turns the fortress into a settlement of the player
+func (g *Game) conquerPirateFortress(p *entities.Player, f *entities.PirateFortress) {
+	if f.Vertex.Placement != nil {
+		return
+	}
+	if err := p.BuildAtVertex(f.Vertex, entities.BTSettlement); err != nil {
+		return
+	}
+	if _, ok := p.BuildablesLeft[entities.BTSettlement]; ok && p.BuildablesLeft[entities.BTSettlement] > 0 {
+		p.BuildablesLeft[entities.BTSettlement]--
+	}
+	g.j.WVertexBuild(f.Vertex, true)
+	g.BroadcastMessage(&entities.Message{
+		Type: entities.MessageTypeVertexPlacement,
+		Data: f.Vertex.Placement,
+	})
+}
+
+// restoreFortresses looks up the vertices of stored fortresses
+func (g *Game) restoreFortresses(entries []FortressEntry) []*entities.PirateFortress {
+	fortresses := make([]*entities.PirateFortress, 0, len(entries))
+	for _, fe := range entries {
+		v, err := g.Graph.GetVertex(fe.C)
+		if err != nil {
+			continue
+		}
+		fortresses = append(fortresses, &entities.PirateFortress{
+			Vertex:       v,
+			Owner:        fe.Owner,
+			Strength:     fe.Strength,
+			AttackedTurn: fe.AttackedTurn,
+		})
+	}
+	return fortresses
+}
@@ -63,7 +63,7 @@ func (g *Game) ensureThroughDesertRegions() {
 
 	regionID := 0
 	sizeByRegion := make(map[int]int)
-	for _, tile := range g.Tiles {
+	for _, tile := range g.sortedTiles() {
 		if !isLandRegionTile(tile) {
 			continue
 		}
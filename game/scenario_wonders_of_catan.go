@@ -0,0 +1,290 @@
+package game
+
+import (
+	"errors"
+	"sakura/entities"
+)
+
+type wonderDefinition struct {
+	Type entities.WonderType
+
+	// Resources paid for every stage, in CardType order
+	Cost [5]int
+
+	// Requirement checks if the player may claim the wonder
+	Requirement func(g *Game, p *entities.Player) error
+}
+
+var wonderDefinitions = []wonderDefinition{
+	{
+		Type: entities.WonderGreatWall,
+		Cost: [5]int{0, 3, 0, 1, 1},
+		Requirement: func(g *Game, p *entities.Player) error {
+			for _, vp := range p.VertexPlacements {
+				if vp.GetType() != entities.BTSettlement && vp.GetType() != entities.BTCity {
+					continue
+				}
+				for _, t := range vp.GetLocation().AdjacentTiles {
+					if t != nil && t.Type == entities.TileTypeDesert {
+						return nil
+					}
+				}
+			}
+			return errors.New("needs a settlement or city next to a desert")
+		},
+	},
+	{
+		Type: entities.WonderGreatBridge,
+		Cost: [5]int{2, 2, 0, 0, 1},
+		Requirement: func(g *Game, p *entities.Player) error {
+			if g.ExtraVictoryPoints.LongestRoadHolder != p {
+				return errors.New("needs the longest road")
+			}
+			return nil
+		},
+	},
+	{
+		Type: entities.WonderLighthouse,
+		Cost: [5]int{1, 0, 2, 0, 2},
+		Requirement: func(g *Game, p *entities.Player) error {
+			for _, port := range g.Ports {
+				for _, v := range port.Vertices {
+					if v.Placement != nil && v.Placement.GetOwner() == p && v.Placement.GetType() == entities.BTCity {
+						return nil
+					}
+				}
+			}
+			return errors.New("needs a city on a harbor")
+		},
+	},
+	{
+		Type: entities.WonderMonument,
+		Cost: [5]int{0, 1, 0, 2, 2},
+		Requirement: func(g *Game, p *entities.Player) error {
+			cities := 0
+			for _, vp := range p.VertexPlacements {
+				if vp.GetType() == entities.BTCity {
+					cities++
+				}
+			}
+			if cities < 2 {
+				return errors.New("needs two cities")
+			}
+			return nil
+		},
+	},
+	{
+		Type: entities.WonderTheater,
+		Cost: [5]int{1, 0, 2, 2, 0},
+		Requirement: func(g *Game, p *entities.Player) error {
+			g.ensureScenarioLandRegions()
+			for _, vp := range p.VertexPlacements {
+				if vp.GetType() != entities.BTSettlement && vp.GetType() != entities.BTCity {
+					continue
+				}
+				for _, t := range vp.GetLocation().AdjacentTiles {
+					if t == nil {
+						continue
+					}
+					if rid, ok := g.ScenarioLandRegionByTile[t.Center]; ok && rid != g.ScenarioLandMainRegion {
+						return nil
+					}
+				}
+			}
+			return errors.New("needs a settlement on a small island")
+		},
+	},
+}
+
+func getWonderDefinition(t entities.WonderType) *wonderDefinition {
+	for i := range wonderDefinitions {
+		if wonderDefinitions[i].Type == t {
+			return &wonderDefinitions[i]
+		}
+	}
+	return nil
+}
+
+func (g *Game) configureWondersOfCatanHooks() {
+	// Same start as Heading for New Shores, with one point for the islands
+	g.configureHeadingForNewShoresHooks()
+	g.ScenarioHooks.OnSettlementBuilt = func(g *Game, p *entities.Player, v *entities.Vertex) {
+		g.applyScenarioOuterIslandSettlementBonus(p, v, 1)
+	}
+
+	// A finished wonder wins right away. With enough points, only the most
+	// stages built wins.
+	g.ScenarioHooks.VictoryEvaluator = func(g *Game) *entities.Player {
+		p := g.CurrentPlayer
+		w := g.getPlayerWonder(p)
+		if w != nil && w.Stage >= entities.WonderStages {
+			return p
+		}
+
+		if w == nil || w.Stage == 0 || g.GetVictoryPoints(p, false) < g.getScenarioVictoryTarget() {
+			return nil
+		}
+		for _, other := range g.ScenarioWonders {
+			if other != w && other.Owner >= 0 && other.Stage >= w.Stage {
+				return nil
+			}
+		}
+		return p
+	}
+}
+
+// placeWonders lays out the unclaimed wonders
+func (g *Game) placeWonders() {
+	g.ScenarioWonders = make([]*entities.Wonder, len(wonderDefinitions))
+	for i, d := range wonderDefinitions {
+		g.ScenarioWonders[i] = &entities.Wonder{Type: d.Type, Owner: -1}
+	}
+	g.j.WSetWonders()
+}
+
+func (g *Game) getWonder(t entities.WonderType) *entities.Wonder {
+	for _, w := range g.ScenarioWonders {
+		if w.Type == t {
+			return w
+		}
+	}
+	return nil
+}
+
+// getPlayerWonder returns the wonder claimed by the player, if any
+func (g *Game) getPlayerWonder(p *entities.Player) *entities.Wonder {
+	for _, w := range g.ScenarioWonders {
+		if w.Owner >= 0 && uint16(w.Owner) == p.Order {
+			return w
+		}
+	}
+	return nil
+}
+
+// ensureWonderTurn checks the player can act on wonders now
+func (g *Game) ensureWonderTurn(p *entities.Player) error {
+	if len(g.ScenarioWonders) == 0 {
+		return errors.New("no wonders in this game")
+	}
+	if g.CurrentPlayer != p || g.DiceState != 1 || g.InitPhase {
+		return errors.New("can only build wonders after rolling the dice")
+	}
+	return nil
+}
+
+// CanClaimWonder checks if the player can claim the wonder. Every player
+// builds only one wonder and every wonder has one builder.
+func (g *Game) CanClaimWonder(p *entities.Player, t entities.WonderType) error {
+	if err := g.ensureWonderTurn(p); err != nil {
+		return err
+	}
+	w, d := g.getWonder(t), getWonderDefinition(t)
+	if w == nil || d == nil {
+		return errors.New("no such wonder")
+	}
+	if w.Owner >= 0 {
+		return errors.New("wonder already claimed")
+	}
+	if g.getPlayerWonder(p) != nil {
+		return errors.New("already building a wonder")
+	}
+	return d.Requirement(g, p)
+}
+
+// getClaimableWonders lists the wonders the player can claim now
+func (g *Game) getClaimableWonders(p *entities.Player) []entities.WonderType {
+	types := make([]entities.WonderType, 0)
+	for _, w := range g.ScenarioWonders {
+		if g.CanClaimWonder(p, w.Type) == nil {
+			types = append(types, w.Type)
+		}
+	}
+	return types
+}
+
+// ClaimWonder makes the player the builder of the wonder
+func (g *Game) ClaimWonder(p *entities.Player, t entities.WonderType) error {
+	if err := g.EnsureCurrentPlayer(p); err != nil {
+		return err
+	}
+
+	g.ActionMutex.Lock()
+	defer g.ActionMutex.Unlock()
+
+	if err := g.CanClaimWonder(p, t); err != nil {
+		return err
+	}
+
+	g.getWonder(t).Owner = int16(p.Order)
+	g.j.WSetWonders()
+
+	g.BroadcastState()
+	return nil
+}
+
+// CanBuildWonderStage checks if the player can pay for the next stage of
+// their wonder
+func (g *Game) CanBuildWonderStage(p *entities.Player) error {
+	if err := g.ensureWonderTurn(p); err != nil {
+		return err
+	}
+	w := g.getPlayerWonder(p)
+	if w == nil {
+		return errors.New("no wonder claimed")
+	}
+	if w.Stage >= entities.WonderStages {
+		return errors.New("wonder already finished")
+	}
+
+	if !g.IsCreativeMode() {
+		d := getWonderDefinition(w.Type)
+		for i, q := range d.Cost {
+			if int(p.CurrentHand.GetCardDeck(entities.CardType(i+1)).Quantity) < q {
+				return errors.New("not enough cards")
+			}
+		}
+	}
+	return nil
+}
+
+// BuildWonderStage pays for and builds the next stage of the wonder of
+// the player
+func (g *Game) BuildWonderStage(p *entities.Player) error {
+	if err := g.EnsureCurrentPlayer(p); err != nil {
+		return err
+	}
+
+	g.ActionMutex.Lock()
+	defer g.ActionMutex.Unlock()
+
+	if err := g.CanBuildWonderStage(p); err != nil {
+		return err
+	}
+
+	w := g.getPlayerWonder(p)
+	if !g.IsCreativeMode() {
+		d := getWonderDefinition(w.Type)
+		for i, q := range d.Cost {
+			if q > 0 {
+				g.MoveCards(int(p.Order), -1, entities.CardType(i+1), q, true, false)
+			}
+		}
+	}
+
+	w.Stage++
+	g.j.WSetWonders()
+
+	g.SendPlayerSecret(p)
+	g.BroadcastState()
+	g.CheckForVictory()
+	return nil
+}
+
+// restoreWonders rebuilds stored wonders
+func (g *Game) restoreWonders(entries []WonderEntry) []*entities.Wonder {
+	wonders := make([]*entities.Wonder, len(entries))
+	for i, we := range entries {
+		wonders[i] = &entities.Wonder{Type: we.Type, Owner: we.Owner, Stage: we.Stage}
+	}
+	return wonders
+}
@@ -0,0 +1,191 @@
+package game
+
+import (
+	"sakura/entities"
+	"sakura/maps"
+	"testing"
+)
+
+func combinedTestSettings(t *testing.T, mapName string) entities.GameSettings {
+	t.Helper()
+
+	defn := maps.GetMapByName(mapName)
+	if defn == nil {
+		t.Fatalf("map %q missing", mapName)
+	}
+	return entities.GameSettings{
+		Mode:    entities.SeafarersCitiesAndKnights,
+		MapName: mapName,
+		MapDefn: defn,
+		Speed:   entities.NormalSpeed,
+	}
+}
+
+func newCombinedTestGame(t *testing.T) *Game {
+	t.Helper()
+
+	g := &Game{Store: &noopStore{}, Settings: combinedTestSettings(t, maps.SeafarersHeadingForNewShores)}
+	if _, err := g.Initialize("combined", 3); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	stopTickerForTest(g)
+	return g
+}
+
+// placeCombinedTestShip builds a coastal settlement with a ship leading away
+// from it and returns the ship and its open end
+func placeCombinedTestShip(t *testing.T, g *Game, p *entities.Player) (*entities.Edge, *entities.Vertex) {
+	t.Helper()
+
+	for _, v := range p.GetBuildLocationsSettlement(g.Graph, true, false) {
+		if !v.HasAdjacentSea() {
+			continue
+		}
+		if err := p.BuildAtVertex(v, entities.BTSettlement); err != nil {
+			t.Fatalf("failed to place settlement: %v", err)
+		}
+		for _, e := range p.GetBuildLocationsShip(g.Graph) {
+			end, _ := g.Graph.GetVertex(e.C.C1)
+			if end == v {
+				end, _ = g.Graph.GetVertex(e.C.C2)
+			}
+			if end == nil || end.Placement != nil {
+				continue
+			}
+			if err := p.BuildAtEdge(e, entities.BTShip); err != nil {
+				t.Fatalf("failed to place ship: %v", err)
+			}
+			return e, end
+		}
+		_ = v.RemovePlacement()
+	}
+	t.Fatal("no coastal settlement with a free ship edge")
+	return nil, nil
+}
+
+func TestSeafarersCitiesAndKnightsSetup(t *testing.T) {
+	g := newCombinedTestGame(t)
+
+	if g.Pirate.Tile != nil {
+		t.Fatal("expected the pirate to wait for the first barbarian attack")
+	}
+	if g.Robber.Tile == nil {
+		t.Fatal("expected the robber on the board")
+	}
+	if len(g.Bank.DevelopmentCardOrder) != 3 {
+		t.Fatalf("expected the three progress stacks, got %d", len(g.Bank.DevelopmentCardOrder))
+	}
+	if got := g.getScenarioVictoryTarget(); got != 16 {
+		t.Fatalf("expected the scenario target plus 2, got %d", got)
+	}
+	if g.GetBarbarianStrength() < 0 {
+		t.Fatal("expected barbarians in the combined mode")
+	}
+
+	g.BarbarianPosition = 1
+	g.MoveBarbarian()
+	if g.NumBarbarianAttacks != 1 {
+		t.Fatalf("expected a barbarian attack, got %d", g.NumBarbarianAttacks)
+	}
+	if g.Pirate.Tile == nil || g.Pirate.Tile.Type != entities.TileTypeSea {
+		t.Fatal("expected the pirate at sea after the first attack")
+	}
+}
+
+func TestSeafarersCitiesAndKnightsShipKeepsKnightConnected(t *testing.T) {
+	g := newCombinedTestGame(t)
+	g.InitPhase = false
+	p := g.CurrentPlayer
+
+	ship, end := placeCombinedTestShip(t, g, p)
+	if len(g.GetMovableShips(p)) != 1 {
+		t.Fatal("expected the open ship to be movable")
+	}
+
+	if err := p.BuildAtVertex(end, entities.BTKnight1); err != nil {
+		t.Fatalf("failed to place knight: %v", err)
+	}
+	for _, e := range g.GetMovableShips(p) {
+		if e == ship {
+			t.Fatal("expected the ship under a knight to stay")
+		}
+	}
+}
+
+func TestSeafarersCitiesAndKnightsKnightChasesPirate(t *testing.T) {
+	g := newCombinedTestGame(t)
+	g.InitPhase = false
+	g.DiceState = 1
+	p := g.CurrentPlayer
+
+	_, end := placeCombinedTestShip(t, g, p)
+	if err := p.BuildAtVertex(end, entities.BTKnight1); err != nil {
+		t.Fatalf("failed to place knight: %v", err)
+	}
+	k := end.Placement.(*entities.Knight)
+	k.Activated = true
+	k.CanUse = true
+
+	// Keep the robber away from the knight
+	for _, tile := range g.sortedTiles() {
+		near := false
+		for _, adj := range end.AdjacentTiles {
+			near = near || adj == tile
+		}
+		if tile.Type != entities.TileTypeSea && !near {
+			g.Robber.Move(tile)
+			break
+		}
+	}
+
+	if err := g.KnightChaseRobber(p, true); err == nil {
+		t.Fatal("expected no pirate to chase before the barbarians came")
+	}
+
+	for _, tile := range end.AdjacentTiles {
+		if tile.Type == entities.TileTypeSea {
+			g.Pirate.Move(tile)
+			break
+		}
+	}
+	if err := g.KnightChaseRobber(p, true); err != nil {
+		t.Fatalf("expected the knight to chase the pirate: %v", err)
+	}
+}
+
+func TestSeafarersCitiesAndKnightsBotGame(t *testing.T) {
+	e, err := NewEngine(combinedTestSettings(t, maps.SeafarersHeadingForNewShores), 1, []string{"a*", "b*", "c*"})
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	if _, err := e.Start(); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	g := e.Game()
+
+	for steps := 0; !g.GameOver; steps++ {
+		if steps > 20000 {
+			t.Fatalf("game stuck at turn %d", g.TurnCount)
+		}
+		acted, _, err := e.Step()
+		if err != nil {
+			t.Fatalf("step failed: %v", err)
+		}
+		if !acted {
+			t.Fatalf("bot %d did nothing on its turn", g.CurrentPlayer.Order)
+		}
+	}
+
+	if g.NumBarbarianAttacks == 0 || g.Pirate.Tile == nil {
+		t.Fatal("expected the barbarians to attack and bring the pirate")
+	}
+	winner := false
+	for _, p := range g.Players {
+		if g.GetVictoryPoints(p, false) >= 16 {
+			winner = true
+		}
+	}
+	if !winner {
+		t.Fatal("expected a winner with the combined victory target")
+	}
+}
@@ -0,0 +1,199 @@
+package game
+
+import (
+	"sakura/entities"
+	"sakura/maps"
+	"testing"
+)
+
+func newClothForCatanTestGame(t *testing.T, store Store) *Game {
+	t.Helper()
+
+	defn := maps.GetMapByName(maps.SeafarersClothForCatan)
+	if defn == nil {
+		t.Fatal("cloth for catan map definition missing")
+	}
+
+	g := &Game{
+		Store: store,
+		Seed:  3,
+		Settings: entities.GameSettings{
+			Mode:          entities.Seafarers,
+			MapName:       maps.SeafarersClothForCatan,
+			MapDefn:       defn,
+			VictoryPoints: 14,
+			Speed:         entities.NormalSpeed,
+		},
+	}
+	if _, err := g.Initialize("seafarers-cloth-for-catan", 3); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	stopTickerForTest(g)
+	return g
+}
+
+// connectToClothVillage puts a ship of the player along the village
+func connectToClothVillage(t *testing.T, g *Game, p *entities.Player, village *entities.ClothVillage) {
+	t.Helper()
+	for _, e := range g.Edges {
+		for _, tile := range e.AdjacentTiles {
+			if tile == village.Tile && e.Placement == nil {
+				if err := p.BuildAtEdge(e, entities.BTShip); err != nil {
+					t.Fatalf("failed to place ship: %v", err)
+				}
+				return
+			}
+		}
+	}
+	t.Fatal("no free edge along the village")
+}
+
+func rollClothVillage(t *testing.T, g *Game, village *entities.ClothVillage) {
+	t.Helper()
+	red := int(village.Number) - 1
+	if red > 6 {
+		red = 6
+	}
+	g.DiceState = 0
+	if _, err := g.RollDiceWith(red, int(village.Number)-red); err != nil {
+		t.Fatalf("roll failed: %v", err)
+	}
+}
+
+func TestSeafarersClothForCatanInitialize(t *testing.T) {
+	g := newClothForCatanTestGame(t, &noopStore{})
+
+	if g.Settings.MapDefn.Scenario == nil || g.Settings.MapDefn.Scenario.Placeholder {
+		t.Fatal("expected non-placeholder scenario metadata on cloth for catan map")
+	}
+	if len(g.ScenarioClothVillages) != len(clothVillageNumbers) {
+		t.Fatalf("expected %d villages, got %d", len(clothVillageNumbers), len(g.ScenarioClothVillages))
+	}
+
+	numbers := make(map[uint16]bool)
+	for _, v := range g.ScenarioClothVillages {
+		if v.Tile.Type != entities.TileTypeDesert || v.Tile.Number != 0 {
+			t.Fatalf("expected village at %v on a desert without a land number", v.Tile.Center)
+		}
+		if v.Cloth != clothVillageSupply || numbers[v.Number] {
+			t.Fatalf("expected a full supply and a number of its own at %v", v.Tile.Center)
+		}
+		numbers[v.Number] = true
+	}
+
+	p := g.CurrentPlayer
+	raw := p.GetBuildLocationsSettlement(g.Graph, true, false)
+	filtered := g.applyInitVertexScenarioHooks(p, raw)
+	if len(filtered) == 0 || len(filtered) == len(raw) {
+		t.Fatalf("expected villages to be left out, got %d of %d vertices", len(filtered), len(raw))
+	}
+	for _, v := range filtered {
+		if g.isClothVillageVertex(v) {
+			t.Fatal("found init vertex on a village")
+		}
+	}
+}
+
+func TestClothForCatanProduction(t *testing.T) {
+	g := newClothForCatanTestGame(t, &noopStore{})
+	g.InitPhase = false
+	p0, p1, p2 := g.Players[0], g.Players[1], g.Players[2]
+
+	village := g.ScenarioClothVillages[0]
+	connectToClothVillage(t, g, p0, village)
+	connectToClothVillage(t, g, p1, village)
+
+	vp := g.GetVictoryPoints(p0, true)
+	rollClothVillage(t, g, village)
+	if g.ScenarioCloth[p0] != 1 || g.ScenarioCloth[p1] != 1 || g.ScenarioCloth[p2] != 0 {
+		t.Fatalf("expected cloth for connected players only, got %d %d %d",
+			g.ScenarioCloth[p0], g.ScenarioCloth[p1], g.ScenarioCloth[p2])
+	}
+	if village.Cloth != clothVillageSupply-2 {
+		t.Fatalf("expected the supply to shrink by 2, got %d", village.Cloth)
+	}
+	if got := g.GetPlayerState(p0).Cloth; got != 1 {
+		t.Fatalf("expected cloth in the player state, got %d", got)
+	}
+
+	rollClothVillage(t, g, village)
+	if got := g.GetVictoryPoints(p0, true); got != vp+1 {
+		t.Fatalf("expected 1 VP for 2 cloth, got %d more", got-vp)
+	}
+
+	// One cloth left goes to the current player first
+	g.CurrentPlayer = p1
+	rollClothVillage(t, g, village)
+	if village.Cloth != 0 || g.ScenarioCloth[p1] != 3 || g.ScenarioCloth[p0] != 2 {
+		t.Fatalf("expected the last cloth to go to the current player, got %d %d", g.ScenarioCloth[p0], g.ScenarioCloth[p1])
+	}
+}
+
+func TestClothForCatanEndsWhenVillagesRunOut(t *testing.T) {
+	g := newClothForCatanTestGame(t, &noopStore{})
+	g.InitPhase = false
+	p0, p1 := g.Players[0], g.Players[1]
+	g.CurrentPlayer = p0
+
+	g.ScenarioCloth[p1] = 4
+	g.ScenarioClothVillages[0].Cloth = 0
+	g.ScenarioClothVillages[1].Cloth = 0
+	if winner := g.getScenarioVictoryWinner(); winner != nil {
+		t.Fatal("expected the game to go on with 2 villages out of cloth")
+	}
+
+	g.ScenarioClothVillages[2].Cloth = 0
+	if winner := g.getScenarioVictoryWinner(); winner != p1 {
+		t.Fatal("expected the player with the most VP to win once 3 villages run out")
+	}
+
+	// Ties go to the player with more cloth
+	g.ScenarioCloth[p0] = 1
+	g.ScenarioBonusVP[p0] = 2
+	if winner := g.getScenarioVictoryWinner(); winner != p1 {
+		t.Fatal("expected a tie to go to the player with more cloth")
+	}
+}
+
+func TestClothForCatanClothSurvivesReplay(t *testing.T) {
+	store := &memoryStore{}
+	live := newClothForCatanTestGame(t, store)
+	live.InitPhase = false
+	p := live.Players[0]
+
+	village := live.ScenarioClothVillages[0]
+	connectToClothVillage(t, live, p, village)
+	rollClothVillage(t, live, village)
+	live.j.Flush()
+
+	resumed := newClothForCatanTestGame(t, &memoryStore{journal: store.journal})
+	if got := resumed.ScenarioCloth[resumed.Players[0]]; got != 1 {
+		t.Fatalf("expected the cloth of the journal, got %d", got)
+	}
+	if len(resumed.ScenarioClothVillages) != len(live.ScenarioClothVillages) {
+		t.Fatalf("expected %d villages after replay, got %d", len(live.ScenarioClothVillages), len(resumed.ScenarioClothVillages))
+	}
+	for i, v := range live.ScenarioClothVillages {
+		r := resumed.ScenarioClothVillages[i]
+		if r.Tile.Center != v.Tile.Center || r.Number != v.Number || r.Cloth != v.Cloth {
+			t.Fatalf("village %d differs after replay", i)
+		}
+	}
+	for c, tile := range live.Tiles {
+		if resumed.Tiles[c].Type != tile.Type {
+			t.Fatalf("tile %v differs after replay", c)
+		}
+	}
+
+	s, err := DecodeSnapshot(encodedSnapshot(t, live))
+	if err != nil {
+		t.Fatalf("failed to decode snapshot: %v", err)
+	}
+	fresh := newClothForCatanTestGame(t, &noopStore{})
+	if err := fresh.RestoreSnapshot(s); err != nil {
+		t.Fatalf("failed to restore snapshot: %v", err)
+	}
+	if fresh.ScenarioCloth[fresh.Players[0]] != 1 || fresh.ScenarioClothVillages[0].Cloth != village.Cloth {
+		t.Fatal("expected cloth to survive a snapshot")
+	}
+}
@@ -0,0 +1,319 @@
+package game
+
+import (
+	"math/rand"
+	"sakura/entities"
+	"sakura/maps"
+	"testing"
+)
+
+func newForgottenTribeTestGame(t *testing.T, store Store) *Game {
+	t.Helper()
+
+	defn := maps.GetMapByName(maps.SeafarersForgottenTribe)
+	if defn == nil {
+		t.Fatal("forgotten tribe map definition missing")
+	}
+
+	g := &Game{
+		Store: store,
+		Seed:  5,
+		Settings: entities.GameSettings{
+			Mode:          entities.Seafarers,
+			MapName:       maps.SeafarersForgottenTribe,
+			MapDefn:       defn,
+			VictoryPoints: 10,
+			Speed:         entities.NormalSpeed,
+		},
+	}
+	if _, err := g.Initialize("seafarers-forgotten-tribe", 2); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	stopTickerForTest(g)
+	return g
+}
+
+func forgottenTribeMarkerOfType(g *Game, mt entities.TribeMarkerType) *entities.TribeMarker {
+	for _, m := range g.ScenarioTribeMarkers {
+		if m.Type == mt {
+			return m
+		}
+	}
+	return nil
+}
+
+func TestSeafarersForgottenTribeInitialize(t *testing.T) {
+	g := newForgottenTribeTestGame(t, &noopStore{})
+
+	if g.Settings.MapDefn.Scenario == nil || g.Settings.MapDefn.Scenario.Placeholder {
+		t.Fatal("expected non-placeholder scenario metadata on forgotten tribe map")
+	}
+	if target := g.getForgottenTribeVictoryTarget(); target != 13 {
+		t.Fatalf("expected scenario victory target 13 from metadata, got %d", target)
+	}
+	if len(g.Ports) != 0 {
+		t.Fatalf("expected no harbors before the tribe hands them out, got %d", len(g.Ports))
+	}
+
+	if len(g.ScenarioTribeMarkers) != len(forgottenTribeMarkers) {
+		t.Fatalf("expected %d tribe markers, got %d", len(forgottenTribeMarkers), len(g.ScenarioTribeMarkers))
+	}
+	seen := make(map[*entities.Edge]bool)
+	for _, m := range g.ScenarioTribeMarkers {
+		if seen[m.Edge] {
+			t.Fatal("expected one marker per edge")
+		}
+		seen[m.Edge] = true
+		if !m.Edge.IsWaterEdge() || !g.isForgottenTribeEdge(m.Edge) {
+			t.Fatalf("marker at %v is not on a sea edge of the tribe", m.Edge.C)
+		}
+	}
+}
+
+func TestForgottenTribeSettlementsOnlyOnMainIsland(t *testing.T) {
+	g := newForgottenTribeTestGame(t, &noopStore{})
+	p := g.CurrentPlayer
+
+	raw := p.GetBuildLocationsSettlement(g.Graph, true, false)
+	filtered := g.applyInitVertexScenarioHooks(p, raw)
+	if len(filtered) == 0 || len(filtered) == len(raw) {
+		t.Fatalf("expected tribe islands to be left out, got %d of %d vertices", len(filtered), len(raw))
+	}
+	for _, v := range filtered {
+		if !g.scenarioVertexTouchesLandRegion(v, g.ScenarioLandMainRegion) {
+			t.Fatal("found init vertex outside the main island")
+		}
+	}
+
+	// A ship next to the tribe does not allow settling there
+	marker := g.ScenarioTribeMarkers[0]
+	if err := p.BuildAtEdge(marker.Edge, entities.BTShip); err != nil {
+		t.Fatalf("failed to place ship: %v", err)
+	}
+	if len(p.GetBuildLocationsSettlement(g.Graph, false, false)) == 0 {
+		t.Fatal("expected the ship to reach a vertex of the tribe")
+	}
+	if got := g.GetBuildLocationsSettlement(p); len(got) != 0 {
+		t.Fatalf("expected no settlement locations on the tribe islands, got %d", len(got))
+	}
+}
+
+func TestForgottenTribeMarkerRewards(t *testing.T) {
+	g := newForgottenTribeTestGame(t, &noopStore{})
+	g.InitPhase = false
+	p := g.CurrentPlayer
+
+	vp := forgottenTribeMarkerOfType(g, entities.TribeMarkerVictoryPoint)
+	dev := forgottenTribeMarkerOfType(g, entities.TribeMarkerDevelopmentCard)
+	harbor := forgottenTribeMarkerOfType(g, entities.TribeMarkerHarbor)
+	if vp == nil || dev == nil || harbor == nil {
+		t.Fatal("expected a marker of every type")
+	}
+	left := len(g.ScenarioTribeMarkers)
+
+	claim := func(m *entities.TribeMarker) {
+		t.Helper()
+		if err := p.BuildAtEdge(m.Edge, entities.BTShip); err != nil {
+			t.Fatalf("failed to place ship: %v", err)
+		}
+		g.onScenarioEdgeBuilt(p, m.Edge)
+	}
+
+	claim(vp)
+	if got := g.ScenarioBonusVP[p]; got != 1 {
+		t.Fatalf("expected +1 VP from the marker, got %d", got)
+	}
+	g.onScenarioEdgeBuilt(p, vp.Edge)
+	if got := g.ScenarioBonusVP[p]; got != 1 {
+		t.Fatalf("expected a marker to be taken only once, got %d VP", got)
+	}
+
+	cards := p.CurrentHand.GetDevelopmentCardCount()
+	claim(dev)
+	if got := p.CurrentHand.GetDevelopmentCardCount(); got != cards+1 {
+		t.Fatalf("expected a development card from the marker, got %d cards", got)
+	}
+
+	// Without a settlement on the coast the harbor waits
+	claim(harbor)
+	if len(g.Ports) != 0 || len(g.ScenarioTribeHarbors[p]) != 1 {
+		t.Fatalf("expected the harbor to wait for a coast, got %d ports", len(g.Ports))
+	}
+
+	var coast *entities.Vertex
+	for _, v := range g.applyInitVertexScenarioHooks(p, p.GetBuildLocationsSettlement(g.Graph, true, false)) {
+		for _, e := range g.Graph.GetAdjacentVertexEdges(v) {
+			if e.IsWaterEdge() && e.IsLandEdge() {
+				coast = v
+			}
+		}
+		if coast != nil {
+			break
+		}
+	}
+	if coast == nil {
+		t.Fatal("expected a coastal vertex on the main island")
+	}
+	if err := p.BuildAtVertex(coast, entities.BTSettlement); err != nil {
+		t.Fatalf("failed to place settlement: %v", err)
+	}
+
+	road := g.Graph.GetAdjacentVertexEdges(coast)[0]
+	if err := p.BuildAtEdge(road, entities.BTShip); err != nil {
+		t.Fatalf("failed to place ship: %v", err)
+	}
+	g.onScenarioEdgeBuilt(p, road)
+	if len(g.Ports) != 1 || len(g.ScenarioTribeHarbors[p]) != 0 {
+		t.Fatalf("expected the waiting harbor to be placed, got %d ports", len(g.Ports))
+	}
+	port := g.Ports[0]
+	if port.Type != harbor.Port || (port.Vertices[0] != coast && port.Vertices[1] != coast) {
+		t.Fatal("expected the harbor next to the settlement")
+	}
+
+	if got := len(g.ScenarioTribeMarkers); got != left-3 {
+		t.Fatalf("expected 3 markers taken, %d left", got)
+	}
+}
+
+func TestForgottenTribeMarkersSurviveResume(t *testing.T) {
+	store := &memoryStore{}
+	live := newForgottenTribeTestGame(t, store)
+	live.InitPhase = false
+	live.j.WSetInitPhase(false)
+	p := live.CurrentPlayer
+
+	// A settlement where the ship can start from, then the ship to the
+	// marker built like any other
+	m := forgottenTribeMarkerOfType(live, entities.TribeMarkerVictoryPoint)
+	start, _ := live.Graph.GetVertex(m.Edge.C.C1)
+	if err := p.BuildAtVertex(start, entities.BTSettlement); err != nil {
+		t.Fatalf("failed to place settlement: %v", err)
+	}
+	p.BuildablesLeft[entities.BTSettlement]--
+	live.j.WVertexBuild(start, true)
+
+	if _, err := live.RollDiceWith(2, 3); err != nil {
+		t.Fatalf("roll failed: %v", err)
+	}
+	cost, _ := live.BuildRules.GetCost(entities.BTShip)
+	for ct, q := range cost {
+		if q > 0 {
+			live.MoveCards(-1, int(p.Order), entities.CardType(ct), q, true, false)
+		}
+	}
+	if err := live.BuildShip(p, m.Edge.C); err != nil {
+		t.Fatalf("failed to build ship: %v", err)
+	}
+	if live.ScenarioBonusVP[p] != 1 || len(live.ScenarioTribeMarkers) != len(forgottenTribeMarkers)-1 {
+		t.Fatal("expected the ship to take the marker")
+	}
+	live.j.Flush()
+
+	resumed := newForgottenTribeTestGame(t, &memoryStore{journal: store.journal})
+	if e, _ := resumed.Graph.GetEdge(m.Edge.C); e.Placement == nil || e.Placement.GetType() != entities.BTShip {
+		t.Fatal("expected the ship to be built again from the journal")
+	}
+	if len(resumed.ScenarioTribeMarkers) != len(live.ScenarioTribeMarkers) {
+		t.Fatalf("expected %d markers after resume, got %d", len(live.ScenarioTribeMarkers), len(resumed.ScenarioTribeMarkers))
+	}
+	for i, m := range live.ScenarioTribeMarkers {
+		r := resumed.ScenarioTribeMarkers[i]
+		if r.Edge.C != m.Edge.C || r.Type != m.Type || r.Port != m.Port {
+			t.Fatalf("marker %d differs after resume", i)
+		}
+	}
+	for _, r := range resumed.ScenarioTribeMarkers {
+		if r.Edge.C == m.Edge.C {
+			t.Fatal("expected the taken marker to be gone after resume")
+		}
+	}
+	if got := resumed.ScenarioBonusVP[resumed.Players[p.Order]]; got != 1 {
+		t.Fatalf("expected the VP of the marker after resume, got %d", got)
+	}
+
+	s, err := DecodeSnapshot(encodedSnapshot(t, live))
+	if err != nil {
+		t.Fatalf("failed to decode snapshot: %v", err)
+	}
+	if err := resumed.RestoreSnapshot(s); err != nil {
+		t.Fatalf("failed to restore snapshot: %v", err)
+	}
+	if len(resumed.ScenarioTribeMarkers) != len(live.ScenarioTribeMarkers) || resumed.ScenarioBonusVP[resumed.Players[p.Order]] != 1 {
+		t.Fatal("expected the taken marker to stay taken after restoring a snapshot")
+	}
+}
+
+func TestSeafarersForgottenTribeThirteenVPIgnoresLobbySetting(t *testing.T) {
+	defn := maps.GetMapByName(maps.SeafarersForgottenTribe)
+	bank, err := entities.GetNewBank(entities.Seafarers, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("failed to create bank: %v", err)
+	}
+
+	p0, _ := entities.NewPlayer(entities.Seafarers, "p0", "p0", 0)
+	p1, _ := entities.NewPlayer(entities.Seafarers, "p1", "p1", 1)
+	g := &Game{
+		Store:              &noopStore{},
+		Bank:               bank,
+		Settings:           entities.GameSettings{Mode: entities.Seafarers, MapDefn: defn, VictoryPoints: 10},
+		Players:            []*entities.Player{p0, p1},
+		CurrentPlayer:      p0,
+		ExtraVictoryPoints: &entities.ExtraVictoryPoints{},
+		ScenarioBonusVP: map[*entities.Player]int{
+			p0: 12,
+		},
+	}
+	g.configureScenarioHooks()
+
+	g.CheckForVictory()
+	if g.GameOver {
+		t.Fatal("expected no winner below the 13 VP scenario target")
+	}
+
+	g.ScenarioBonusVP[p0] = 13
+	g.CheckForVictory()
+	if !g.GameOver {
+		t.Fatal("expected game over when current player reaches 13 VP")
+	}
+}
+
+func TestSeafarersForgottenTribeCitiesAndKnightsTarget(t *testing.T) {
+	defn := maps.GetMapByName(maps.SeafarersForgottenTribe)
+	bank, err := entities.GetNewBank(entities.SeafarersCitiesAndKnights, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("failed to create bank: %v", err)
+	}
+
+	p0, _ := entities.NewPlayer(entities.SeafarersCitiesAndKnights, "p0", "p0", 0)
+	p1, _ := entities.NewPlayer(entities.SeafarersCitiesAndKnights, "p1", "p1", 1)
+	g := &Game{
+		Store:              &noopStore{},
+		Bank:               bank,
+		Mode:               entities.SeafarersCitiesAndKnights,
+		Settings:           entities.GameSettings{Mode: entities.SeafarersCitiesAndKnights, MapDefn: defn, VictoryPoints: 10},
+		Merchant:           &entities.Merchant{},
+		Players:            []*entities.Player{p0, p1},
+		CurrentPlayer:      p0,
+		ExtraVictoryPoints: &entities.ExtraVictoryPoints{},
+		ScenarioBonusVP: map[*entities.Player]int{
+			p0: 14,
+		},
+	}
+	g.configureScenarioHooks()
+
+	if target := g.getForgottenTribeVictoryTarget(); target != 15 {
+		t.Fatalf("expected Cities & Knights to add 2 to the 13 VP target, got %d", target)
+	}
+
+	g.CheckForVictory()
+	if g.GameOver {
+		t.Fatal("expected no winner below the 15 VP target")
+	}
+
+	g.ScenarioBonusVP[p0] = 15
+	g.CheckForVictory()
+	if !g.GameOver {
+		t.Fatal("expected game over when current player reaches 15 VP")
+	}
+}
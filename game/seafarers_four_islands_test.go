@@ -1,6 +1,7 @@
 package game
 
 import (
+	"math/rand"
 	"sakura/entities"
 	"sakura/maps"
 	"testing"
@@ -347,7 +348,7 @@ func TestSeafarersThroughDesertFourteenVPWinsOnCurrentPlayersTurn(t *testing.T)
 	if defn == nil {
 		t.Fatal("through the desert map definition missing")
 	}
-	bank, err := entities.GetNewBank(entities.Seafarers)
+	bank, err := entities.GetNewBank(entities.Seafarers, rand.New(rand.NewSource(1)))
 	if err != nil {
 		t.Fatalf("failed to create bank: %v", err)
 	}
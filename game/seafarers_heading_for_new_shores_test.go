@@ -63,10 +63,10 @@ func TestHeadingForNewShoresBonusIsPerIslandPerPlayer(t *testing.T) {
 		},
 	}
 
-	g.applyScenarioOuterIslandSettlementBonus(p0, outerIslandVertex)
-	g.applyScenarioOuterIslandSettlementBonus(p0, outerIslandVertex)
-	g.applyScenarioOuterIslandSettlementBonus(p0, secondOuterIslandVertex)
-	g.applyScenarioOuterIslandSettlementBonus(p1, outerIslandVertex)
+	g.applyScenarioOuterIslandSettlementBonus(p0, outerIslandVertex, 2)
+	g.applyScenarioOuterIslandSettlementBonus(p0, outerIslandVertex, 2)
+	g.applyScenarioOuterIslandSettlementBonus(p0, secondOuterIslandVertex, 2)
+	g.applyScenarioOuterIslandSettlementBonus(p1, outerIslandVertex, 2)
 
 	if got := g.ScenarioBonusVP[p0]; got != 4 {
 		t.Fatalf("expected p0 to earn 4 bonus VP across two islands, got %d", got)
@@ -0,0 +1,81 @@
+package game
+
+import (
+	"sakura/entities"
+	"sakura/maps"
+	"testing"
+)
+
+func newNewWorldTestGame(t *testing.T, seed int64, players uint16) *Game {
+	t.Helper()
+
+	defn := maps.GetMapByName(maps.SeafarersNewWorldVariant)
+	if defn == nil {
+		t.Fatal("new world map definition missing")
+	}
+	g := &Game{Store: &noopStore{}, Seed: seed, Settings: entities.GameSettings{
+		Mode:          entities.Seafarers,
+		MapName:       maps.SeafarersNewWorldVariant,
+		MapDefn:       defn,
+		VictoryPoints: 12,
+		Speed:         entities.NormalSpeed,
+	}}
+	if _, err := g.Initialize("seafarers-new-world", players); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	stopTickerForTest(g)
+	return g
+}
+
+func TestSeafarersNewWorldRollsMapFromSeed(t *testing.T) {
+	g := newNewWorldTestGame(t, 5, 3)
+
+	opts := maps.DefaultNewWorldOptions(3)
+	if len(g.Ports) != opts.Harbors {
+		t.Fatalf("expected %d harbors, got %d", opts.Harbors, len(g.Ports))
+	}
+	for _, port := range g.Ports {
+		if !port.Edge.IsWaterEdge() || !port.Edge.IsLandEdge() {
+			t.Fatalf("expected harbor %v on a coast", port.Edge.C)
+		}
+	}
+
+	gold := 0
+	for _, tile := range g.Tiles {
+		if tile.Type == entities.TileTypeGold {
+			gold++
+		}
+	}
+	if gold != opts.GoldFields {
+		t.Fatalf("expected %d gold fields, got %d", opts.GoldFields, gold)
+	}
+
+	layout := func(g *Game) map[entities.Coordinate]bool {
+		land := make(map[entities.Coordinate]bool)
+		for c, tile := range g.Tiles {
+			if tile.Type != entities.TileTypeSea {
+				land[c] = true
+			}
+		}
+		return land
+	}
+	same, other := layout(newNewWorldTestGame(t, 5, 3)), layout(newNewWorldTestGame(t, 6, 3))
+	first := layout(g)
+	differs := len(first) != len(other)
+	for c := range first {
+		if !same[c] {
+			t.Fatal("expected the same seed to roll the same islands")
+		}
+		if !other[c] {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Fatal("expected another seed to roll other islands")
+	}
+
+	big := newNewWorldTestGame(t, 5, 6)
+	if len(big.Tiles) <= len(g.Tiles) {
+		t.Fatal("expected a bigger archipelago for more players")
+	}
+}
@@ -0,0 +1,354 @@
+package game
+
+import (
+	"sakura/entities"
+	"sakura/maps"
+	"testing"
+)
+
+func pirateIslandsTestSettings(t *testing.T) entities.GameSettings {
+	t.Helper()
+
+	defn := maps.GetMapByName(maps.SeafarersPirateIslands)
+	if defn == nil {
+		t.Fatal("pirate islands map definition missing")
+	}
+	return entities.GameSettings{
+		Mode:          entities.Seafarers,
+		MapName:       maps.SeafarersPirateIslands,
+		MapDefn:       defn,
+		VictoryPoints: 10,
+		Speed:         entities.NormalSpeed,
+	}
+}
+
+func newPirateIslandsTestGame(t *testing.T, store Store) *Game {
+	t.Helper()
+
+	g := &Game{Store: store, Seed: 3, Settings: pirateIslandsTestSettings(t)}
+	if _, err := g.Initialize("seafarers-pirate-islands", 3); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	stopTickerForTest(g)
+	return g
+}
+
+// placeShipsNextToFortress puts ships of the player on the free edges at
+// the fortress
+func placeShipsNextToFortress(t *testing.T, g *Game, p *entities.Player, f *entities.PirateFortress) []*entities.Edge {
+	t.Helper()
+	ships := make([]*entities.Edge, 0)
+	for _, e := range g.Graph.GetAdjacentVertexEdges(f.Vertex) {
+		if e.Placement == nil && e.IsWaterEdge() {
+			if err := p.BuildAtEdge(e, entities.BTShip); err != nil {
+				t.Fatalf("failed to place ship: %v", err)
+			}
+			ships = append(ships, e)
+		}
+	}
+	if len(ships) == 0 {
+		t.Fatal("no free edge at the fortress")
+	}
+	entities.SortEdges(ships)
+	return ships
+}
+
+// giveWarships puts count warships of the player out at sea
+func giveWarships(t *testing.T, g *Game, p *entities.Player, count int) {
+	t.Helper()
+	edges := make([]*entities.Edge, 0, len(g.Edges))
+	for _, e := range g.Edges {
+		edges = append(edges, e)
+	}
+	entities.SortEdges(edges)
+
+	for _, e := range edges {
+		if count == 0 {
+			return
+		}
+		if e.Placement != nil || !e.IsWaterEdge() || e.IsLandEdge() {
+			continue
+		}
+		if err := p.BuildAtEdge(e, entities.BTShip); err != nil {
+			t.Fatalf("failed to place ship: %v", err)
+		}
+		g.ScenarioWarships[e] = true
+		count--
+	}
+	t.Fatal("not enough open sea for warships")
+}
+
+func TestSeafarersPirateIslandsInitialize(t *testing.T) {
+	g := newPirateIslandsTestGame(t, &noopStore{})
+
+	if g.Settings.MapDefn.Scenario == nil || g.Settings.MapDefn.Scenario.Placeholder {
+		t.Fatal("expected non-placeholder scenario metadata on pirate islands map")
+	}
+	if len(g.ScenarioFortresses) != len(g.Players) {
+		t.Fatalf("expected a fortress for each of %d players, got %d", len(g.Players), len(g.ScenarioFortresses))
+	}
+	for i, f := range g.ScenarioFortresses {
+		if int(f.Owner) != i || f.Strength != pirateFortressStrength {
+			t.Fatalf("expected fortress %d of player %d at full strength", i, f.Owner)
+		}
+		if !g.isPirateIslandVertex(f.Vertex) {
+			t.Fatalf("expected fortress %d on a pirate island", i)
+		}
+	}
+
+	route := g.getPirateRoute()
+	if len(route) == 0 || g.Pirate == nil || g.Pirate.Tile != route[0] {
+		t.Fatal("expected the pirate fleet at the start of its route")
+	}
+	for _, tile := range route {
+		if tile.Type != entities.TileTypeSea {
+			t.Fatalf("expected the route to stay at sea, got %v", tile.Center)
+		}
+	}
+
+	p := g.CurrentPlayer
+	raw := p.GetBuildLocationsSettlement(g.Graph, true, false)
+	filtered := g.applyInitVertexScenarioHooks(p, raw)
+	if len(filtered) == 0 || len(filtered) == len(raw) {
+		t.Fatalf("expected pirate islands to be left out, got %d of %d vertices", len(filtered), len(raw))
+	}
+	for _, v := range filtered {
+		if g.isPirateIslandVertex(v) {
+			t.Fatal("found init vertex on a pirate island")
+		}
+	}
+}
+
+func TestPirateIslandsFleetSailsInsteadOfRobber(t *testing.T) {
+	e, err := NewEngine(pirateIslandsTestSettings(t), 5, []string{"a*", "b*", "c*"})
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	if _, err := e.Start(); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	g := e.Game()
+	route := g.getPirateRoute()
+	robber := g.Robber.Tile
+
+	pos := 0
+	for i, tile := range route {
+		if tile == g.Pirate.Tile {
+			pos = i
+		}
+	}
+
+	if _, err := e.Do(g.CurrentPlayer.Order, func(g *Game, p *entities.Player) error {
+		return g.RollDice(p, 3, 4)
+	}); err != nil {
+		t.Fatalf("roll failed: %v", err)
+	}
+	if g.Pirate.Tile != route[(pos+3)%len(route)] {
+		t.Fatal("expected the pirate fleet to sail 3 tiles on its route")
+	}
+	if g.Robber.Tile != robber || g.HasPlayerPendingAction() {
+		t.Fatal("expected no robber to move on a 7")
+	}
+}
+
+func TestPirateIslandsFleetAttack(t *testing.T) {
+	g := newPirateIslandsTestGame(t, &noopStore{})
+	g.InitPhase = false
+	p0, p1 := g.Players[0], g.Players[1]
+
+	// Settle both players on the coast of one tile of the route
+	tile := g.getPirateRoute()[0]
+	for _, v := range g.Vertices {
+		if v.Placement != nil {
+			continue
+		}
+		touches, land := false, false
+		for _, t := range v.AdjacentTiles {
+			touches = touches || t == tile
+			land = land || t.Type != entities.TileTypeSea
+		}
+		if !touches || !land {
+			continue
+		}
+		owner := p0
+		if len(g.Graph.GetTilePlacements(tile)) > 0 {
+			owner = p1
+		}
+		if err := owner.BuildAtVertex(v, entities.BTSettlement); err != nil {
+			t.Fatalf("failed to place settlement: %v", err)
+		}
+		if owner == p1 {
+			break
+		}
+	}
+
+	g.MoveCards(-1, int(p0.Order), entities.CardTypeWood, 2, false, false)
+	g.MoveCards(-1, int(p1.Order), entities.CardTypeWood, 2, false, false)
+	giveWarships(t, g, p1, 4)
+
+	g.PirateFleetAttack(tile, 4)
+	if got := p0.CurrentHand.GetCardCount(); got != 1 {
+		t.Fatalf("expected the fleet to take a card, got %d left", got)
+	}
+	if got := p1.CurrentHand.GetCardCount(); got != 2 {
+		t.Fatalf("expected enough warships to fend off the fleet, got %d left", got)
+	}
+}
+
+func TestPirateIslandsKnightBuildsWarship(t *testing.T) {
+	e, err := NewEngine(pirateIslandsTestSettings(t), 5, []string{"alice", "bob*", "carol*"})
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	if _, err := e.Start(); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	for len(e.Decisions()) > 0 {
+		if _, err := e.Answer(0, answerForTest(e.Decisions()[0])); err != nil {
+			t.Fatalf("answer failed: %v", err)
+		}
+	}
+	g := e.Game()
+
+	var ships []*entities.Edge
+	if _, err := e.Do(0, func(g *Game, p *entities.Player) error {
+		ships = placeShipsNextToFortress(t, g, p, g.getPirateFortress(p))
+		deck := p.CurrentHand.GetDevelopmentCardDeck(entities.DevelopmentCardKnight)
+		deck.Quantity = 1
+		deck.CanUse = true
+		return nil
+	}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	if _, err := e.Do(0, func(g *Game, p *entities.Player) error {
+		return g.UseDevelopmentCard(p, entities.DevelopmentCardKnight)
+	}); err != nil {
+		t.Fatalf("knight failed: %v", err)
+	}
+	decisions := e.Decisions()
+	if len(decisions) != 1 || decisions[0].Action.Type != entities.PlayerActionTypeChooseWarship {
+		t.Fatalf("expected a warship to be chosen, got %v", decisions)
+	}
+
+	// Answers run the command again on a restored game
+	chosen := ships[len(ships)-1].C
+	if _, err := e.Answer(0, chosen); err != nil {
+		t.Fatalf("answer failed: %v", err)
+	}
+	if ship, err := g.Graph.GetEdge(chosen); err != nil || !g.ScenarioWarships[ship] || g.countWarships(g.Players[0]) != 1 {
+		t.Fatal("expected the chosen ship to become a warship")
+	}
+	if g.HasPlayerPendingAction() {
+		t.Fatal("expected the knight not to move the robber")
+	}
+}
+
+func TestPirateIslandsAttackFortress(t *testing.T) {
+	g := newPirateIslandsTestGame(t, &noopStore{})
+	g.InitPhase = false
+	g.DiceState = 1
+	p := g.CurrentPlayer
+	f := g.getPirateFortress(p)
+
+	if err := g.CanAttackPirateFortress(p); err == nil {
+		t.Fatal("expected no attack without a ship at the fortress")
+	}
+
+	// Without warships every attack is lost along with the ships
+	ships := placeShipsNextToFortress(t, g, p, f)
+	left := p.BuildablesLeft[entities.BTShip]
+	if err := g.AttackPirateFortress(p); err != nil {
+		t.Fatalf("attack failed: %v", err)
+	}
+	if f.Strength != pirateFortressStrength || ships[0].Placement != nil || ships[1].Placement != nil {
+		t.Fatal("expected a lost attack to cost the ships at the fortress")
+	}
+	if p.BuildablesLeft[entities.BTShip] != left+pirateFortressShipsLost {
+		t.Fatalf("expected %d ships back, got %d", pirateFortressShipsLost, p.BuildablesLeft[entities.BTShip]-left)
+	}
+	if err := g.AttackPirateFortress(p); err == nil {
+		t.Fatal("expected only one attack every turn")
+	}
+
+	// More warships than any die wins every attack
+	g.ScenarioBonusVP[p] = g.getScenarioVictoryTarget()
+	giveWarships(t, g, p, 7)
+	for i := 0; i < pirateFortressStrength; i++ {
+		if winner := g.getScenarioVictoryWinner(); winner != nil {
+			t.Fatal("expected no winner before conquering the fortress")
+		}
+		g.TurnCount++
+		if err := g.AttackPirateFortress(p); err != nil {
+			t.Fatalf("attack failed: %v", err)
+		}
+		if f.Strength != pirateFortressStrength-i-1 {
+			t.Fatalf("expected strength %d after a won attack, got %d", pirateFortressStrength-i-1, f.Strength)
+		}
+	}
+
+	if f.Vertex.Placement == nil || f.Vertex.Placement.GetOwner() != p || f.Vertex.Placement.GetType() != entities.BTSettlement {
+		t.Fatal("expected the conquered fortress to become a settlement")
+	}
+	if winner := g.getScenarioVictoryWinner(); winner != p {
+		t.Fatal("expected the player to win with the fortress conquered")
+	}
+}
+
+func TestPirateIslandsStateSurvivesReplay(t *testing.T) {
+	store := &memoryStore{}
+	live := newPirateIslandsTestGame(t, store)
+	live.InitPhase = false
+	live.DiceState = 1
+	p := live.CurrentPlayer
+
+	ships := placeShipsNextToFortress(t, live, p, live.getPirateFortress(p))
+	live.ScenarioWarships[ships[0]] = true
+	live.j.WSetWarship(ships[0], true)
+	giveWarships(t, live, p, 7)
+	for e := range live.ScenarioWarships {
+		live.j.WSetWarship(e, true)
+	}
+	if err := live.AttackPirateFortress(p); err != nil {
+		t.Fatalf("attack failed: %v", err)
+	}
+	live.j.Flush()
+
+	check := func(g *Game, how string) {
+		t.Helper()
+		if len(g.ScenarioFortresses) != len(live.ScenarioFortresses) {
+			t.Fatalf("expected %d fortresses %s, got %d", len(live.ScenarioFortresses), how, len(g.ScenarioFortresses))
+		}
+		for i, f := range live.ScenarioFortresses {
+			r := g.ScenarioFortresses[i]
+			if r.Vertex.C != f.Vertex.C || r.Owner != f.Owner || r.Strength != f.Strength || r.AttackedTurn != f.AttackedTurn {
+				t.Fatalf("fortress %d differs %s", i, how)
+			}
+		}
+		want, got := live.getWarshipCoordinates(), g.getWarshipCoordinates()
+		if len(want) != len(got) {
+			t.Fatalf("expected %d warships %s, got %d", len(want), how, len(got))
+		}
+		for i := range want {
+			if want[i] != got[i] {
+				t.Fatalf("warship %d differs %s", i, how)
+			}
+		}
+	}
+
+	resumed := newPirateIslandsTestGame(t, &memoryStore{journal: store.journal})
+	check(resumed, "after replay")
+	if resumed.ScenarioHooks.PlayKnight == nil || !resumed.ScenarioHooks.NoRobber {
+		t.Fatal("expected the scenario hooks after replay")
+	}
+
+	s, err := DecodeSnapshot(encodedSnapshot(t, live))
+	if err != nil {
+		t.Fatalf("failed to decode snapshot: %v", err)
+	}
+	fresh := newPirateIslandsTestGame(t, &noopStore{})
+	if err := fresh.RestoreSnapshot(s); err != nil {
+		t.Fatalf("failed to restore snapshot: %v", err)
+	}
+	check(fresh, "after a snapshot")
+}
@@ -2,6 +2,7 @@ package game
 
 import (
 	"errors"
+	"math/rand"
 	"sakura/entities"
 	"sakura/maps"
 	"testing"
@@ -54,9 +55,15 @@ func (s *noopStore) WriteGameState(id string, state []byte) error {
 func (s *noopStore) WriteGameIdForUser(gameId, userId string, settings *entities.GameSettings) error {
 	return nil
 }
+func (s *noopStore) WriteGameSnapshot(id string, snapshot []byte) error {
+	return nil
+}
 func (s *noopStore) ReadJournal(id string) ([][]byte, error) {
 	return nil, nil
 }
+func (s *noopStore) ReadGameSnapshot(id string) ([]byte, error) {
+	return nil, nil
+}
 func (s *noopStore) ReadGamePlayers(id string) (int, error) {
 	return 0, errors.New("not found")
 }
@@ -466,7 +473,7 @@ func TestSeafarersCoastalRoadAllowed(t *testing.T) {
 }
 
 func TestRevealFogAdjacentToEdgeAwardsResource(t *testing.T) {
-	bank, err := entities.GetNewBank(entities.Seafarers)
+	bank, err := entities.GetNewBank(entities.Seafarers, rand.New(rand.NewSource(1)))
 	if err != nil {
 		t.Fatalf("failed to create bank: %v", err)
 	}
@@ -512,7 +519,7 @@ func TestRevealFogAdjacentToEdgeAwardsResource(t *testing.T) {
 }
 
 func TestRevealFogAdjacentToEdgeSeaDiscoveryGivesNoReward(t *testing.T) {
-	bank, err := entities.GetNewBank(entities.Seafarers)
+	bank, err := entities.GetNewBank(entities.Seafarers, rand.New(rand.NewSource(1)))
 	if err != nil {
 		t.Fatalf("failed to create bank: %v", err)
 	}
@@ -0,0 +1,245 @@
+package game
+
+import (
+	"sakura/entities"
+	"sakura/maps"
+	"testing"
+)
+
+func wondersOfCatanTestSettings(t *testing.T) entities.GameSettings {
+	t.Helper()
+
+	defn := maps.GetMapByName(maps.SeafarersWondersOfCatan)
+	if defn == nil {
+		t.Fatal("wonders of catan map definition missing")
+	}
+	return entities.GameSettings{
+		Mode:          entities.Seafarers,
+		MapName:       maps.SeafarersWondersOfCatan,
+		MapDefn:       defn,
+		VictoryPoints: 10,
+		Speed:         entities.NormalSpeed,
+	}
+}
+
+func newWondersOfCatanTestGame(t *testing.T, store Store) *Game {
+	t.Helper()
+
+	g := &Game{Store: store, Seed: 3, Settings: wondersOfCatanTestSettings(t)}
+	if _, err := g.Initialize("seafarers-wonders-of-catan", 3); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	stopTickerForTest(g)
+	g.InitPhase = false
+	g.DiceState = 1
+	return g
+}
+
+// buildNextToDesert puts a settlement of the player next to a desert
+func buildNextToDesert(t *testing.T, g *Game, p *entities.Player) {
+	t.Helper()
+	for _, tile := range g.sortedTiles() {
+		if tile.Type != entities.TileTypeDesert {
+			continue
+		}
+		for _, c := range tile.GetVertexCoordinates() {
+			v, err := g.Graph.GetVertex(c)
+			if err == nil && v.Placement == nil {
+				if err := p.BuildAtVertex(v, entities.BTSettlement); err != nil {
+					t.Fatalf("failed to build settlement: %v", err)
+				}
+				return
+			}
+		}
+	}
+	t.Fatal("no free vertex next to a desert")
+}
+
+func TestSeafarersWondersOfCatanInitialize(t *testing.T) {
+	g := newWondersOfCatanTestGame(t, &noopStore{})
+
+	if g.Settings.MapDefn.Scenario == nil || g.Settings.MapDefn.Scenario.Placeholder {
+		t.Fatal("expected non-placeholder scenario metadata on wonders of catan map")
+	}
+	if len(g.ScenarioWonders) != len(wonderDefinitions) {
+		t.Fatalf("expected %d wonders, got %d", len(wonderDefinitions), len(g.ScenarioWonders))
+	}
+	for _, w := range g.ScenarioWonders {
+		if w.Owner != -1 || w.Stage != 0 {
+			t.Fatalf("expected wonder %d unclaimed", w.Type)
+		}
+	}
+
+	g.ensureScenarioLandRegions()
+	regions := make(map[int]bool)
+	for _, rid := range g.ScenarioLandRegionByTile {
+		regions[rid] = true
+	}
+	if len(regions) < 2 {
+		t.Fatal("expected small islands next to the main island")
+	}
+
+	p := g.CurrentPlayer
+	raw := p.GetBuildLocationsSettlement(g.Graph, true, false)
+	filtered := g.applyInitVertexScenarioHooks(p, raw)
+	if len(filtered) == 0 || len(filtered) == len(raw) {
+		t.Fatalf("expected the small islands to be left out, got %d of %d vertices", len(filtered), len(raw))
+	}
+}
+
+func TestWondersOfCatanClaimWonder(t *testing.T) {
+	g := newWondersOfCatanTestGame(t, &noopStore{})
+	p := g.CurrentPlayer
+	other := g.Players[(int(p.Order)+1)%len(g.Players)]
+
+	if err := g.CanClaimWonder(p, entities.WonderGreatWall); err == nil {
+		t.Fatal("expected no great wall without a building next to a desert")
+	}
+	if err := g.CanClaimWonder(p, entities.WonderGreatBridge); err == nil {
+		t.Fatal("expected no great bridge without the longest road")
+	}
+
+	buildNextToDesert(t, g, p)
+	g.ExtraVictoryPoints.LongestRoadHolder = p
+	if types := g.getClaimableWonders(p); len(types) != 2 {
+		t.Fatalf("expected two claimable wonders, got %v", types)
+	}
+	if err := g.ClaimWonder(p, entities.WonderGreatWall); err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+	if w := g.getPlayerWonder(p); w == nil || w.Type != entities.WonderGreatWall {
+		t.Fatal("expected the player to build the great wall")
+	}
+	if err := g.CanClaimWonder(p, entities.WonderGreatBridge); err == nil {
+		t.Fatal("expected only one wonder for every player")
+	}
+
+	state := g.GetPlayerState(p)
+	if state.Wonder != entities.WonderGreatWall || state.WonderStage != 0 {
+		t.Fatal("expected the wonder in the player state")
+	}
+
+	// Nobody else can build a claimed wonder
+	g.CurrentPlayer = other
+	buildNextToDesert(t, g, other)
+	if err := g.CanClaimWonder(other, entities.WonderGreatWall); err == nil {
+		t.Fatal("expected a claimed wonder to be taken")
+	}
+}
+
+func TestWondersOfCatanBuildStages(t *testing.T) {
+	g := newWondersOfCatanTestGame(t, &noopStore{})
+	p := g.CurrentPlayer
+
+	if err := g.CanBuildWonderStage(p); err == nil {
+		t.Fatal("expected no stage without a wonder")
+	}
+	buildNextToDesert(t, g, p)
+	if err := g.ClaimWonder(p, entities.WonderGreatWall); err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+	if err := g.CanBuildWonderStage(p); err == nil {
+		t.Fatal("expected no stage without the cards")
+	}
+
+	cost := getWonderDefinition(entities.WonderGreatWall).Cost
+	for stage := 1; stage <= entities.WonderStages; stage++ {
+		p.CurrentHand.UpdateResources(cost[0], cost[1], cost[2], cost[3], cost[4])
+		if err := g.BuildWonderStage(p); err != nil {
+			t.Fatalf("stage %d failed: %v", stage, err)
+		}
+		if w := g.getPlayerWonder(p); w.Stage != stage {
+			t.Fatalf("expected stage %d, got %d", stage, w.Stage)
+		}
+		if p.CurrentHand.GetCardCount() != 0 {
+			t.Fatalf("expected the stage to cost all cards, %d left", p.CurrentHand.GetCardCount())
+		}
+	}
+
+	p.CurrentHand.UpdateResources(cost[0], cost[1], cost[2], cost[3], cost[4])
+	if err := g.CanBuildWonderStage(p); err == nil {
+		t.Fatal("expected no stage after the wonder is finished")
+	}
+}
+
+func TestWondersOfCatanVictory(t *testing.T) {
+	g := newWondersOfCatanTestGame(t, &noopStore{})
+	p := g.CurrentPlayer
+	other := g.Players[(int(p.Order)+1)%len(g.Players)]
+
+	buildNextToDesert(t, g, p)
+	if err := g.ClaimWonder(p, entities.WonderGreatWall); err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+	g.getWonder(entities.WonderGreatBridge).Owner = int16(other.Order)
+
+	// Enough points need the most stages
+	g.ScenarioBonusVP[p] = g.getScenarioVictoryTarget()
+	if winner := g.getScenarioVictoryWinner(); winner != nil {
+		t.Fatal("expected no winner without a stage built")
+	}
+	g.getPlayerWonder(p).Stage = 2
+	g.getPlayerWonder(other).Stage = 2
+	if winner := g.getScenarioVictoryWinner(); winner != nil {
+		t.Fatal("expected no winner with tied stages")
+	}
+	g.getPlayerWonder(p).Stage = 3
+	if winner := g.getScenarioVictoryWinner(); winner != p {
+		t.Fatal("expected the player with the most stages to win")
+	}
+
+	// A finished wonder wins without the points
+	g.ScenarioBonusVP[p] = 0
+	if winner := g.getScenarioVictoryWinner(); winner != nil {
+		t.Fatal("expected no winner without enough points")
+	}
+	g.getPlayerWonder(p).Stage = entities.WonderStages
+	if winner := g.getScenarioVictoryWinner(); winner != p {
+		t.Fatal("expected the finished wonder to win")
+	}
+}
+
+func TestWondersOfCatanStateSurvivesReplay(t *testing.T) {
+	store := &memoryStore{}
+	live := newWondersOfCatanTestGame(t, store)
+	p := live.CurrentPlayer
+
+	buildNextToDesert(t, live, p)
+	if err := live.ClaimWonder(p, entities.WonderGreatWall); err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+	cost := getWonderDefinition(entities.WonderGreatWall).Cost
+	p.CurrentHand.UpdateResources(cost[0], cost[1], cost[2], cost[3], cost[4])
+	if err := live.BuildWonderStage(p); err != nil {
+		t.Fatalf("stage failed: %v", err)
+	}
+	live.j.Flush()
+
+	check := func(g *Game, how string) {
+		t.Helper()
+		if len(g.ScenarioWonders) != len(live.ScenarioWonders) {
+			t.Fatalf("expected %d wonders %s, got %d", len(live.ScenarioWonders), how, len(g.ScenarioWonders))
+		}
+		for i, w := range live.ScenarioWonders {
+			if *g.ScenarioWonders[i] != *w {
+				t.Fatalf("wonder %d differs %s", i, how)
+			}
+		}
+	}
+
+	resumed := newWondersOfCatanTestGame(t, &memoryStore{journal: store.journal})
+	check(resumed, "after replay")
+	if resumed.ScenarioHooks.VictoryEvaluator == nil {
+		t.Fatal("expected the scenario hooks after replay")
+	}
+
+	s, err := DecodeSnapshot(encodedSnapshot(t, live))
+	if err != nil {
+		t.Fatalf("failed to decode snapshot: %v", err)
+	}
+	fresh := newWondersOfCatanTestGame(t, &noopStore{})
+	if err := fresh.RestoreSnapshot(s); err != nil {
+		t.Fatalf("failed to restore snapshot: %v", err)
+	}
+	check(fresh, "after a snapshot")
+}
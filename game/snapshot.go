@@ -0,0 +1,865 @@
+package game
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"sakura/entities"
+	"sort"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	// Version of the snapshot encoding, bumped on incompatible changes
+	SnapshotVersion = 2
+
+	// Number of ended turns between two snapshots
+	DefaultSnapshotInterval = 10
+)
+
+type (
+	// GameSnapshot is a self-contained copy of everything the journal
+	// would otherwise rebuild. A game restored from a snapshot only
+	// needs to replay the journal entries after JournalIndex.
+	GameSnapshot struct {
+		Version      int `msgpack:"v"`
+		JournalIndex int `msgpack:"i"`
+		TurnCount    int `msgpack:"tc"`
+		RoundCount   int `msgpack:"rc,omitempty"`
+
+		Seed      int64  `msgpack:"sd"`
+		RandState uint64 `msgpack:"rs"`
+
+		ParentID    string `msgpack:"pa,omitempty"`
+		ParentIndex int    `msgpack:"pi,omitempty"`
+
+		Settings         entities.GameSettings     `msgpack:"s"`
+		AdvancedSettings entities.AdvancedSettings `msgpack:"as"`
+		MapDefn          *entities.MapDefinition   `msgpack:"md"`
+		Mode             entities.GameMode         `msgpack:"m"`
+		NumPlayers       uint16                    `msgpack:"n"`
+
+		DiceState     int                `msgpack:"ds"`
+		LastRollWhite int                `msgpack:"lw"`
+		LastRollRed   int                `msgpack:"lr"`
+		LastRollEvent int                `msgpack:"le"`
+		DiceStats     entities.DiceStats `msgpack:"st"`
+
+		CurrentPlayer       uint16 `msgpack:"c"`
+		InitPhase           bool   `msgpack:"ip"`
+		GameOver            bool   `msgpack:"go"`
+		SpecialBuildPhase   bool   `msgpack:"sb"`
+		SpecialBuildStarter int    `msgpack:"sbs"`
+		OfferCounter        int    `msgpack:"oc"`
+		RobberReturnAfter   int    `msgpack:"rra"`
+
+		BarbarianPosition   int    `msgpack:"bp"`
+		NumBarbarianAttacks int    `msgpack:"ba"`
+		MerchantFleets      [9]int `msgpack:"mf"`
+
+		Tiles    []SnapshotTile        `msgpack:"t"`
+		Ports    []PortEntry           `msgpack:"po"`
+		Robber   *entities.Coordinate  `msgpack:"r"`
+		Pirate   *entities.Coordinate  `msgpack:"pr"`
+		Merchant *entities.Coordinate  `msgpack:"me"`
+		Trader   int                   `msgpack:"mo"`
+		Bank     SnapshotBank          `msgpack:"b"`
+		Players  []SnapshotPlayer      `msgpack:"p"`
+		ExtraVP  SnapshotExtraVP       `msgpack:"x"`
+		Scenario SnapshotScenarioState `msgpack:"sc"`
+	}
+
+	SnapshotTile struct {
+		C      entities.Coordinate `msgpack:"c"`
+		DispX  float64             `msgpack:"x"`
+		Type   entities.TileType   `msgpack:"t"`
+		Number uint16              `msgpack:"n"`
+		Fog    bool                `msgpack:"f"`
+	}
+
+	SnapshotVertexPlacement struct {
+		C          entities.Coordinate    `msgpack:"c"`
+		Type       entities.BuildableType `msgpack:"t"`
+		Metropolis entities.CardType      `msgpack:"m"`
+		Wall       bool                   `msgpack:"w"`
+		Activated  bool                   `msgpack:"a"`
+		CanUse     bool                   `msgpack:"u"`
+	}
+
+	SnapshotEdgePlacement struct {
+		C    entities.EdgeCoordinate `msgpack:"c"`
+		Type entities.BuildableType  `msgpack:"t"`
+	}
+
+	SnapshotHand struct {
+		Cards            []entities.CardDeck            `msgpack:"c"`
+		DevelopmentCards []entities.DevelopmentCardDeck `msgpack:"d"`
+	}
+
+	SnapshotBank struct {
+		Hand                  SnapshotHand                   `msgpack:"h"`
+		DevelopmentCardOrder  []SnapshotDevelopmentCardStack `msgpack:"o"`
+		DevelopmentCardCursor int                            `msgpack:"c"`
+		EventCardOrder        []int                          `msgpack:"eo,omitempty"`
+		EventCardCursor       int                            `msgpack:"ec,omitempty"`
+	}
+
+	SnapshotPlayer struct {
+		Id                   string                       `msgpack:"id"`
+		Username             string                       `msgpack:"u"`
+		Color                string                       `msgpack:"c"`
+		Hand                 SnapshotHand                 `msgpack:"h"`
+		Vertices             []SnapshotVertexPlacement    `msgpack:"v"`
+		Edges                []SnapshotEdgePlacement      `msgpack:"e"`
+		BuildablesLeft       []SnapshotValue              `msgpack:"b"`
+		Improvements         []SnapshotValue              `msgpack:"i"`
+		UsingDevCard         entities.DevelopmentCardType `msgpack:"ud"`
+		ChoosingProgressCard bool                         `msgpack:"cp"`
+		LongestRoad          int                          `msgpack:"lr"`
+		SpecialBuild         bool                         `msgpack:"sb"`
+		ShipMoved            bool                         `msgpack:"sm"`
+		ShipsBuiltThisTurn   []entities.EdgeCoordinate    `msgpack:"st"`
+		BotLevel             string                       `msgpack:"bl,omitempty"`
+	}
+
+	SnapshotExtraVP struct {
+		LongestRoadHolder       int             `msgpack:"lr"`
+		LargestArmyHolder       int             `msgpack:"la"`
+		LargestArmyCount        int16           `msgpack:"lc"`
+		AvailableDefenderPoints int             `msgpack:"ad"`
+		DefenderPoints          []int           `msgpack:"dp"`
+		Metropolis              []SnapshotValue `msgpack:"m"`
+		PrinterHolder           int             `msgpack:"ph"`
+		ConstitutionHolder      int             `msgpack:"ch"`
+	}
+
+	// Maps are stored as key sorted lists, since the encoder only
+	// orders keys of a few map types
+	SnapshotValue struct {
+		Key   int `msgpack:"k"`
+		Value int `msgpack:"v"`
+	}
+
+	SnapshotDevelopmentCardStack struct {
+		Stack entities.CardType              `msgpack:"s"`
+		Order []entities.DevelopmentCardType `msgpack:"o"`
+	}
+
+	SnapshotRegionSet struct {
+		Player  uint16 `msgpack:"p"`
+		Regions []int  `msgpack:"r"`
+	}
+
+	SnapshotRegion struct {
+		C      entities.Coordinate `msgpack:"c"`
+		Region int                 `msgpack:"r"`
+	}
+
+	SnapshotScenarioState struct {
+		BonusVP            []SnapshotValue           `msgpack:"vp"`
+		LandRegionByTile   []SnapshotRegion          `msgpack:"lr"`
+		LandMainRegion     int                       `msgpack:"lm"`
+		LandAwarded        []SnapshotRegionSet       `msgpack:"la"`
+		LandHome           []SnapshotRegionSet       `msgpack:"lh"`
+		FogTileStack       []entities.TileType       `msgpack:"ft"`
+		FogNumberStack     []uint16                  `msgpack:"fn"`
+		DesertRegionByTile []SnapshotRegion          `msgpack:"dr"`
+		DesertMainRegion   int                       `msgpack:"dm"`
+		DesertAwarded      []SnapshotRegionSet       `msgpack:"da"`
+		TribeMarkers       []TribeMarkerEntry        `msgpack:"tm"`
+		TribeHarbors       []SnapshotHarbors         `msgpack:"th"`
+		ClothVillages      []ClothVillageEntry       `msgpack:"cv"`
+		Cloth              []SnapshotValue           `msgpack:"cl"`
+		Fortresses         []FortressEntry           `msgpack:"pf"`
+		Warships           []entities.EdgeCoordinate `msgpack:"ws"`
+		Wonders            []WonderEntry             `msgpack:"wd"`
+	}
+
+	SnapshotHarbors struct {
+		Player uint16              `msgpack:"p"`
+		Ports  []entities.PortType `msgpack:"t"`
+	}
+)
+
+func playerOrderOrNone(p *entities.Player) int {
+	if p == nil {
+		return -1
+	}
+	return int(p.Order)
+}
+
+func (g *Game) playerAtOrder(order int) *entities.Player {
+	if order < 0 || order >= len(g.Players) {
+		return nil
+	}
+	return g.Players[order]
+}
+
+func snapshotHand(h *entities.Hand) SnapshotHand {
+	sh := SnapshotHand{
+		Cards:            make([]entities.CardDeck, 0, len(h.CardDeckMap)),
+		DevelopmentCards: make([]entities.DevelopmentCardDeck, 0, len(h.DevelopmentCardDeckMap)),
+	}
+	for _, deck := range h.CardDeckMap {
+		sh.Cards = append(sh.Cards, *deck)
+	}
+	for _, deck := range h.DevelopmentCardDeckMap {
+		sh.DevelopmentCards = append(sh.DevelopmentCards, *deck)
+	}
+	sort.Slice(sh.Cards, func(i, j int) bool {
+		return sh.Cards[i].Type < sh.Cards[j].Type
+	})
+	sort.Slice(sh.DevelopmentCards, func(i, j int) bool {
+		return sh.DevelopmentCards[i].Type < sh.DevelopmentCards[j].Type
+	})
+	return sh
+}
+
+func restoreHand(h *entities.Hand, sh SnapshotHand) {
+	for _, deck := range sh.Cards {
+		d := deck
+		h.CardDeckMap[deck.Type] = &d
+	}
+	for _, deck := range sh.DevelopmentCards {
+		d := deck
+		h.DevelopmentCardDeckMap[deck.Type] = &d
+	}
+}
+
+func snapshotRegions(regions map[entities.Coordinate]int) []SnapshotRegion {
+	res := make([]SnapshotRegion, 0, len(regions))
+	for c, r := range regions {
+		res = append(res, SnapshotRegion{C: c, Region: r})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return lessCoordinate(res[i].C, res[j].C)
+	})
+	return res
+}
+
+func sortSnapshotValues(values []SnapshotValue) []SnapshotValue {
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Key < values[j].Key
+	})
+	return values
+}
+
+func snapshotRegionSets(sets map[*entities.Player]map[int]bool) []SnapshotRegionSet {
+	res := make([]SnapshotRegionSet, 0, len(sets))
+	for p, set := range sets {
+		regions := make([]int, 0, len(set))
+		for r, ok := range set {
+			if ok {
+				regions = append(regions, r)
+			}
+		}
+		sort.Ints(regions)
+		res = append(res, SnapshotRegionSet{Player: p.Order, Regions: regions})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Player < res[j].Player
+	})
+	return res
+}
+
+func (g *Game) restoreRegionSets(sets []SnapshotRegionSet) map[*entities.Player]map[int]bool {
+	res := make(map[*entities.Player]map[int]bool)
+	for _, set := range sets {
+		regions := set.Regions
+		p := g.playerAtOrder(int(set.Player))
+		if p == nil {
+			continue
+		}
+		res[p] = make(map[int]bool)
+		for _, r := range regions {
+			res[p][r] = true
+		}
+	}
+	return res
+}
+
+// CreateSnapshot captures the current game state. The caller must hold
+// the game lock.
+func (g *Game) CreateSnapshot() *GameSnapshot {
+	s := &GameSnapshot{
+		Version:      SnapshotVersion,
+		JournalIndex: g.j.index,
+		TurnCount:    g.TurnCount,
+		RoundCount:   g.RoundCount,
+
+		Seed:      g.Seed,
+		RandState: g.randomState(),
+
+		ParentID:    g.ParentID,
+		ParentIndex: g.ParentIndex,
+
+		Settings:         g.Settings,
+		AdvancedSettings: g.AdvancedSettings,
+		MapDefn:          g.Settings.MapDefn,
+		Mode:             g.Mode,
+		NumPlayers:       g.NumPlayers,
+
+		DiceState:     g.DiceState,
+		LastRollWhite: g.LastRollWhite,
+		LastRollRed:   g.LastRollRed,
+		LastRollEvent: g.LastRollEvent,
+
+		CurrentPlayer:       g.CurrentPlayer.Order,
+		InitPhase:           g.InitPhase,
+		GameOver:            g.GameOver,
+		SpecialBuildPhase:   g.SpecialBuildPhase,
+		SpecialBuildStarter: playerOrderOrNone(g.SpecialBuildStarter),
+		OfferCounter:        g.OfferCounter,
+		RobberReturnAfter:   playerOrderOrNone(g.RobberReturnAfter),
+
+		BarbarianPosition:   g.BarbarianPosition,
+		NumBarbarianAttacks: g.NumBarbarianAttacks,
+		MerchantFleets:      g.MerchantFleets,
+		Trader:              -1,
+	}
+	s.Settings.MapDefn = nil
+
+	if g.DiceStats != nil {
+		s.DiceStats = *g.DiceStats
+	}
+
+	// Board
+	for _, c := range g.sortedTileCoordinates() {
+		tile := g.Tiles[c]
+		s.Tiles = append(s.Tiles, SnapshotTile{
+			C:      c,
+			DispX:  g.DispCoordMap[c].X,
+			Type:   tile.Type,
+			Number: tile.Number,
+			Fog:    tile.Fog,
+		})
+	}
+
+	for _, port := range g.Ports {
+		s.Ports = append(s.Ports, PortEntry{Type: port.Type, C: port.Edge.C, Ratio: port.Ratio})
+	}
+
+	if g.Robber != nil && g.Robber.Tile != nil {
+		c := g.Robber.Tile.Center
+		s.Robber = &c
+	}
+	if g.Pirate != nil && g.Pirate.Tile != nil {
+		c := g.Pirate.Tile.Center
+		s.Pirate = &c
+	}
+	if g.Merchant != nil {
+		if g.Merchant.Tile != nil {
+			c := g.Merchant.Tile.Center
+			s.Merchant = &c
+		}
+		s.Trader = playerOrderOrNone(g.Merchant.Owner)
+	}
+
+	// Bank
+	s.Bank = SnapshotBank{
+		Hand:                  snapshotHand(g.Bank.Hand),
+		DevelopmentCardOrder:  make([]SnapshotDevelopmentCardStack, 0, len(g.Bank.DevelopmentCardOrder)),
+		DevelopmentCardCursor: g.Bank.DevelopmentCardCursor,
+		EventCardOrder:        g.Bank.EventCardOrder,
+		EventCardCursor:       g.Bank.EventCardCursor,
+	}
+	for stack, order := range g.Bank.DevelopmentCardOrder {
+		s.Bank.DevelopmentCardOrder = append(s.Bank.DevelopmentCardOrder, SnapshotDevelopmentCardStack{
+			Stack: stack,
+			Order: append([]entities.DevelopmentCardType{}, order...),
+		})
+	}
+	sort.Slice(s.Bank.DevelopmentCardOrder, func(i, j int) bool {
+		return s.Bank.DevelopmentCardOrder[i].Stack < s.Bank.DevelopmentCardOrder[j].Stack
+	})
+
+	// Players
+	for _, p := range g.Players {
+		sp := SnapshotPlayer{
+			Id:                   p.Id,
+			Username:             p.Username,
+			Color:                p.Color,
+			Hand:                 snapshotHand(p.CurrentHand),
+			Vertices:             make([]SnapshotVertexPlacement, 0, len(p.VertexPlacements)),
+			Edges:                make([]SnapshotEdgePlacement, 0, len(p.EdgePlacements)),
+			BuildablesLeft:       make([]SnapshotValue, 0, len(p.BuildablesLeft)),
+			Improvements:         make([]SnapshotValue, 0, len(p.Improvements)),
+			UsingDevCard:         p.UsingDevCard,
+			ChoosingProgressCard: p.ChoosingProgressCard,
+			LongestRoad:          p.LongestRoad,
+			SpecialBuild:         p.SpecialBuild,
+			ShipMoved:            p.ShipMoved,
+			ShipsBuiltThisTurn:   make([]entities.EdgeCoordinate, 0),
+			BotLevel:             p.BotLevel,
+		}
+
+		for _, vp := range p.VertexPlacements {
+			svp := SnapshotVertexPlacement{C: vp.GetLocation().C, Type: vp.GetType()}
+			switch placement := vp.(type) {
+			case *entities.City:
+				svp.Metropolis = placement.Metropolis
+				svp.Wall = placement.Wall
+			case *entities.Knight:
+				svp.Activated = placement.Activated
+				svp.CanUse = placement.CanUse
+			}
+			sp.Vertices = append(sp.Vertices, svp)
+		}
+		for _, ep := range p.EdgePlacements {
+			sp.Edges = append(sp.Edges, SnapshotEdgePlacement{C: ep.GetLocation().C, Type: ep.GetType()})
+		}
+		for t, left := range p.BuildablesLeft {
+			sp.BuildablesLeft = append(sp.BuildablesLeft, SnapshotValue{Key: int(t), Value: left})
+		}
+		for ct, level := range p.Improvements {
+			sp.Improvements = append(sp.Improvements, SnapshotValue{Key: ct, Value: level})
+		}
+		sortSnapshotValues(sp.BuildablesLeft)
+		sortSnapshotValues(sp.Improvements)
+		for e, built := range p.ShipsBuiltThisTurn {
+			if built {
+				sp.ShipsBuiltThisTurn = append(sp.ShipsBuiltThisTurn, e.C)
+			}
+		}
+		sort.Slice(sp.ShipsBuiltThisTurn, func(i, j int) bool {
+			return lessEdgeCoordinate(sp.ShipsBuiltThisTurn[i], sp.ShipsBuiltThisTurn[j])
+		})
+
+		s.Players = append(s.Players, sp)
+	}
+
+	// Extra victory points
+	evp := g.ExtraVictoryPoints
+	s.ExtraVP = SnapshotExtraVP{
+		LongestRoadHolder:       playerOrderOrNone(evp.LongestRoadHolder),
+		LargestArmyHolder:       playerOrderOrNone(evp.LargestArmyHolder),
+		LargestArmyCount:        evp.LargestArmyCount,
+		AvailableDefenderPoints: evp.AvailableDefenderPoints,
+		PrinterHolder:           playerOrderOrNone(evp.PrinterHolder),
+		ConstitutionHolder:      playerOrderOrNone(evp.ConstitutionHolder),
+	}
+	if evp.DefenderPoints != nil {
+		s.ExtraVP.DefenderPoints = make([]int, len(evp.DefenderPoints))
+		for i, p := range evp.DefenderPoints {
+			s.ExtraVP.DefenderPoints[i] = playerOrderOrNone(p)
+		}
+	}
+	if evp.Metropolis != nil {
+		s.ExtraVP.Metropolis = make([]SnapshotValue, 0, len(evp.Metropolis))
+		for ct, p := range evp.Metropolis {
+			s.ExtraVP.Metropolis = append(s.ExtraVP.Metropolis, SnapshotValue{Key: int(ct), Value: playerOrderOrNone(p)})
+		}
+		sortSnapshotValues(s.ExtraVP.Metropolis)
+	}
+
+	// Scenario state
+	s.Scenario = SnapshotScenarioState{
+		BonusVP:            make([]SnapshotValue, 0, len(g.ScenarioBonusVP)),
+		LandRegionByTile:   snapshotRegions(g.ScenarioLandRegionByTile),
+		LandMainRegion:     g.ScenarioLandMainRegion,
+		LandAwarded:        snapshotRegionSets(g.ScenarioLandAwarded),
+		LandHome:           snapshotRegionSets(g.ScenarioLandHome),
+		FogTileStack:       append([]entities.TileType{}, g.ScenarioFogTileStack...),
+		FogNumberStack:     append([]uint16{}, g.ScenarioFogNumberStack...),
+		DesertRegionByTile: snapshotRegions(g.ScenarioDesertRegionByTile),
+		DesertMainRegion:   g.ScenarioDesertMainRegion,
+		DesertAwarded:      snapshotRegionSets(g.ScenarioDesertAwarded),
+	}
+	for p, vp := range g.ScenarioBonusVP {
+		if vp != 0 {
+			s.Scenario.BonusVP = append(s.Scenario.BonusVP, SnapshotValue{Key: int(p.Order), Value: vp})
+		}
+	}
+	sortSnapshotValues(s.Scenario.BonusVP)
+	for _, m := range g.ScenarioTribeMarkers {
+		s.Scenario.TribeMarkers = append(s.Scenario.TribeMarkers, TribeMarkerEntry{C: m.Edge.C, Type: m.Type, Port: m.Port})
+	}
+	for _, v := range g.ScenarioClothVillages {
+		s.Scenario.ClothVillages = append(s.Scenario.ClothVillages, ClothVillageEntry{C: v.Tile.Center, Number: v.Number, Cloth: v.Cloth})
+	}
+	for p, cloth := range g.ScenarioCloth {
+		if cloth != 0 {
+			s.Scenario.Cloth = append(s.Scenario.Cloth, SnapshotValue{Key: int(p.Order), Value: cloth})
+		}
+	}
+	sortSnapshotValues(s.Scenario.Cloth)
+	for _, f := range g.ScenarioFortresses {
+		s.Scenario.Fortresses = append(s.Scenario.Fortresses, FortressEntry{C: f.Vertex.C, Owner: f.Owner, Strength: f.Strength, AttackedTurn: f.AttackedTurn})
+	}
+	s.Scenario.Warships = g.getWarshipCoordinates()
+	for _, w := range g.ScenarioWonders {
+		s.Scenario.Wonders = append(s.Scenario.Wonders, WonderEntry{Type: w.Type, Owner: w.Owner, Stage: w.Stage})
+	}
+	for _, p := range g.Players {
+		if ports := g.ScenarioTribeHarbors[p]; len(ports) > 0 {
+			s.Scenario.TribeHarbors = append(s.Scenario.TribeHarbors, SnapshotHarbors{
+				Player: p.Order,
+				Ports:  append([]entities.PortType{}, ports...),
+			})
+		}
+	}
+
+	return s
+}
+
+// RestoreSnapshot replaces the game state with the snapshot. Nothing is
+// written to the journal while restoring.
+func (g *Game) RestoreSnapshot(s *GameSnapshot) error {
+	if s == nil {
+		return errors.New("no snapshot given")
+	}
+	if s.Version != SnapshotVersion {
+		return errors.New("unsupported snapshot version")
+	}
+	if int(s.NumPlayers) != len(s.Players) || s.NumPlayers == 0 {
+		return errors.New("snapshot player count mismatch")
+	}
+
+	playing := g.j.playing
+	g.j.playing = true
+	defer func() { g.j.playing = playing }()
+
+	g.Settings = s.Settings
+	g.Settings.MapDefn = s.MapDefn
+	g.AdvancedSettings = s.AdvancedSettings
+	g.Mode = s.Mode
+	g.NumPlayers = s.NumPlayers
+
+	g.InitGraph()
+	if err := g.InitWithGameMode(); err != nil {
+		return err
+	}
+
+	// Board
+	for _, st := range s.Tiles {
+		g.addTile(st.C, st.DispX)
+		tile := g.Tiles[st.C]
+		tile.Type = st.Type
+		tile.Number = st.Number
+		tile.Fog = st.Fog
+	}
+	g.generateVertices()
+	g.generateEdges()
+
+	g.Ports = make([]*entities.Port, 0, len(s.Ports))
+	for _, pe := range s.Ports {
+		edge, err := g.Graph.GetEdge(pe.C)
+		if err != nil {
+			return err
+		}
+		vertex1, _ := g.Graph.GetVertex(edge.C.C1)
+		vertex2, _ := g.Graph.GetVertex(edge.C.C2)
+		g.Ports = append(g.Ports, &entities.Port{
+			Type:     pe.Type,
+			Ratio:    pe.Ratio,
+			Edge:     edge,
+			Vertices: []*entities.Vertex{vertex1, vertex2},
+		})
+	}
+
+	if s.Robber != nil {
+		g.Robber.Tile = g.Tiles[*s.Robber]
+	}
+	if s.Pirate != nil {
+		g.Pirate.Tile = g.Tiles[*s.Pirate]
+	}
+
+	// Bank
+	restoreHand(g.Bank.Hand, s.Bank.Hand)
+	g.Bank.DevelopmentCardOrder = make(map[entities.CardType][]entities.DevelopmentCardType)
+	for _, stack := range s.Bank.DevelopmentCardOrder {
+		g.Bank.DevelopmentCardOrder[stack.Stack] = append([]entities.DevelopmentCardType{}, stack.Order...)
+	}
+	g.Bank.DevelopmentCardCursor = s.Bank.DevelopmentCardCursor
+	g.Bank.EventCardOrder = s.Bank.EventCardOrder
+	g.Bank.EventCardCursor = s.Bank.EventCardCursor
+
+	// Players
+	for i, sp := range s.Players {
+		p := g.Players[i]
+		p.Id = sp.Id
+		g.SetUsername(p, sp.Username)
+		p.Color = sp.Color
+		p.BotLevel = sp.BotLevel
+		restoreHand(p.CurrentHand, sp.Hand)
+
+		for _, svp := range sp.Vertices {
+			vertex, err := g.Graph.GetVertex(svp.C)
+			if err != nil {
+				return err
+			}
+			if err := p.BuildAtVertex(vertex, svp.Type); err != nil {
+				return err
+			}
+			switch placement := vertex.Placement.(type) {
+			case *entities.City:
+				placement.Metropolis = svp.Metropolis
+				placement.Wall = svp.Wall
+			case *entities.Knight:
+				placement.Activated = svp.Activated
+				placement.CanUse = svp.CanUse
+			}
+		}
+		for _, sep := range sp.Edges {
+			edge, err := g.Graph.GetEdge(sep.C)
+			if err != nil {
+				return err
+			}
+			if err := p.BuildAtEdge(edge, sep.Type); err != nil {
+				return err
+			}
+		}
+
+		p.BuildablesLeft = make(map[entities.BuildableType]int)
+		for _, v := range sp.BuildablesLeft {
+			p.BuildablesLeft[entities.BuildableType(v.Key)] = v.Value
+		}
+		p.Improvements = make(map[int]int)
+		for _, v := range sp.Improvements {
+			p.Improvements[v.Key] = v.Value
+		}
+		p.UsingDevCard = sp.UsingDevCard
+		p.ChoosingProgressCard = sp.ChoosingProgressCard
+		p.LongestRoad = sp.LongestRoad
+		p.SpecialBuild = sp.SpecialBuild
+		p.ShipMoved = sp.ShipMoved
+		for _, ec := range sp.ShipsBuiltThisTurn {
+			if edge, err := g.Graph.GetEdge(ec); err == nil {
+				p.ShipsBuiltThisTurn[edge] = true
+			}
+		}
+	}
+
+	if g.Merchant != nil {
+		if s.Merchant != nil {
+			g.Merchant.Tile = g.Tiles[*s.Merchant]
+		}
+		g.Merchant.Owner = g.playerAtOrder(s.Trader)
+	}
+
+	// Extra victory points
+	evp := g.ExtraVictoryPoints
+	evp.LongestRoadHolder = g.playerAtOrder(s.ExtraVP.LongestRoadHolder)
+	evp.LargestArmyHolder = g.playerAtOrder(s.ExtraVP.LargestArmyHolder)
+	evp.LargestArmyCount = s.ExtraVP.LargestArmyCount
+	evp.AvailableDefenderPoints = s.ExtraVP.AvailableDefenderPoints
+	evp.PrinterHolder = g.playerAtOrder(s.ExtraVP.PrinterHolder)
+	evp.ConstitutionHolder = g.playerAtOrder(s.ExtraVP.ConstitutionHolder)
+	evp.DefenderPoints = nil
+	if s.ExtraVP.DefenderPoints != nil {
+		evp.DefenderPoints = make([]*entities.Player, len(s.ExtraVP.DefenderPoints))
+		for i, order := range s.ExtraVP.DefenderPoints {
+			evp.DefenderPoints[i] = g.playerAtOrder(order)
+		}
+	}
+	evp.Metropolis = nil
+	if s.ExtraVP.Metropolis != nil {
+		evp.Metropolis = make(map[entities.CardType]*entities.Player)
+		for _, v := range s.ExtraVP.Metropolis {
+			evp.Metropolis[entities.CardType(v.Key)] = g.playerAtOrder(v.Value)
+		}
+	}
+
+	// Scenario state
+	g.ScenarioBonusVP = make(map[*entities.Player]int)
+	for _, v := range s.Scenario.BonusVP {
+		if p := g.playerAtOrder(v.Key); p != nil {
+			g.ScenarioBonusVP[p] = v.Value
+		}
+	}
+	g.ScenarioLandRegionByTile = make(map[entities.Coordinate]int)
+	for _, r := range s.Scenario.LandRegionByTile {
+		g.ScenarioLandRegionByTile[r.C] = r.Region
+	}
+	g.ScenarioLandMainRegion = s.Scenario.LandMainRegion
+	g.ScenarioLandAwarded = g.restoreRegionSets(s.Scenario.LandAwarded)
+	g.ScenarioLandHome = g.restoreRegionSets(s.Scenario.LandHome)
+	g.ScenarioFogTileStack = append([]entities.TileType(nil), s.Scenario.FogTileStack...)
+	g.ScenarioFogNumberStack = append([]uint16(nil), s.Scenario.FogNumberStack...)
+	g.ScenarioDesertRegionByTile = make(map[entities.Coordinate]int)
+	for _, r := range s.Scenario.DesertRegionByTile {
+		g.ScenarioDesertRegionByTile[r.C] = r.Region
+	}
+	g.ScenarioDesertMainRegion = s.Scenario.DesertMainRegion
+	g.ScenarioDesertAwarded = g.restoreRegionSets(s.Scenario.DesertAwarded)
+	g.ScenarioTribeMarkers = g.restoreTribeMarkers(s.Scenario.TribeMarkers)
+	g.ScenarioTribeHarbors = make(map[*entities.Player][]entities.PortType)
+	for _, h := range s.Scenario.TribeHarbors {
+		if p := g.playerAtOrder(int(h.Player)); p != nil {
+			g.ScenarioTribeHarbors[p] = append([]entities.PortType(nil), h.Ports...)
+		}
+	}
+	g.ScenarioClothVillages = g.restoreClothVillages(s.Scenario.ClothVillages)
+	g.ScenarioCloth = make(map[*entities.Player]int)
+	for _, v := range s.Scenario.Cloth {
+		if p := g.playerAtOrder(v.Key); p != nil {
+			g.ScenarioCloth[p] = v.Value
+		}
+	}
+	g.ScenarioFortresses = g.restoreFortresses(s.Scenario.Fortresses)
+	g.ScenarioWarships = make(map[*entities.Edge]bool)
+	for _, c := range s.Scenario.Warships {
+		if e, err := g.Graph.GetEdge(c); err == nil {
+			g.ScenarioWarships[e] = true
+		}
+	}
+	g.ScenarioPirateRoute = nil
+	g.ScenarioWonders = g.restoreWonders(s.Scenario.Wonders)
+
+	// Turn state
+	g.DiceState = s.DiceState
+	g.LastRollWhite = s.LastRollWhite
+	g.LastRollRed = s.LastRollRed
+	g.LastRollEvent = s.LastRollEvent
+	stats := s.DiceStats
+	g.DiceStats = &stats
+
+	g.CurrentPlayer = g.playerAtOrder(int(s.CurrentPlayer))
+	if g.CurrentPlayer == nil {
+		return errors.New("snapshot has invalid current player")
+	}
+	g.InitPhase = s.InitPhase
+	g.GameOver = s.GameOver
+	g.SpecialBuildPhase = s.SpecialBuildPhase
+	g.SpecialBuildStarter = g.playerAtOrder(s.SpecialBuildStarter)
+	g.OfferCounter = s.OfferCounter
+	g.BarbarianPosition = s.BarbarianPosition
+	g.NumBarbarianAttacks = s.NumBarbarianAttacks
+	g.MerchantFleets = s.MerchantFleets
+	g.TurnCount = s.TurnCount
+	g.RoundCount = s.RoundCount
+	g.RobberReturnAfter = g.playerAtOrder(s.RobberReturnAfter)
+	g.ParentID = s.ParentID
+	g.ParentIndex = s.ParentIndex
+
+	// Snapshots from before the seed was stored leave the RNG as is
+	if s.Seed != 0 {
+		g.seedRandom(s.Seed)
+		g.setRandomState(s.RandState)
+		g.j.lastRand = s.RandState
+		g.j.seeded = true
+	}
+
+	g.j.index = s.JournalIndex
+	g.configureScenarioHooks()
+
+	return nil
+}
+
+// resetForReplay puts the game back into the state Initialize leaves it
+// in before the journal is played
+func (g *Game) resetForReplay() {
+	g.InitGraph()
+	g.InitWithGameMode()
+
+	g.DiceState = 0
+	g.LastRollRed = 1
+	g.LastRollWhite = 1
+	g.LastRollEvent = 0
+	if g.Settings.Mode.HasCitiesAndKnights() {
+		g.LastRollEvent = 4
+	}
+	g.DiceStats = &entities.DiceStats{}
+
+	g.InitPhase = true
+	g.GameOver = false
+	g.SpecialBuildPhase = false
+	g.SpecialBuildStarter = nil
+	g.OfferCounter = 0
+	g.TurnCount = 0
+	g.RoundCount = 0
+	g.RobberReturnAfter = nil
+	g.ParentID = ""
+	g.ParentIndex = 0
+	g.ScenarioFogTileStack = nil
+	g.ScenarioFogNumberStack = nil
+	g.j.index = 0
+}
+
+// EncodeSnapshot serializes a snapshot with sorted map keys so that
+// equal states always produce equal bytes
+func EncodeSnapshot(s *GameSnapshot) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetSortMapKeys(true)
+	if err := enc.Encode(s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func DecodeSnapshot(b []byte) (*GameSnapshot, error) {
+	var s GameSnapshot
+	if err := msgpack.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// writeSnapshotIfDue stores a snapshot every SnapshotInterval ended turns.
+// The journal is flushed first so that the snapshot never points past
+// entries that are not stored yet; if that fails no snapshot is taken.
+func (g *Game) writeSnapshotIfDue() {
+	if g.j.playing || !g.Initialized || g.Store == nil {
+		return
+	}
+
+	interval := g.SnapshotInterval
+	if interval == 0 {
+		interval = DefaultSnapshotInterval
+	}
+	if interval < 0 || g.TurnCount%interval != 0 {
+		return
+	}
+
+	if err := g.j.Flush(); err != nil {
+		return
+	}
+
+	b, err := EncodeSnapshot(g.CreateSnapshot())
+	if err != nil {
+		log.Println("error encoding snapshot:", err)
+		return
+	}
+
+	if err := g.Store.WriteGameSnapshot(g.ID, b); err != nil {
+		log.Println("error writing snapshot:", err)
+	}
+}
+
+func lessCoordinate(a, b entities.Coordinate) bool {
+	if a.X != b.X {
+		return a.X < b.X
+	}
+	return a.Y < b.Y
+}
+
+func lessEdgeCoordinate(a, b entities.EdgeCoordinate) bool {
+	if a.C1 != b.C1 {
+		return lessCoordinate(a.C1, b.C1)
+	}
+	return lessCoordinate(a.C2, b.C2)
+}
+
+// sortedTileCoordinates lists the tile centers in a stable order, so that
+// anything derived by walking the tiles comes out the same on every run
+func (g *Game) sortedTileCoordinates() []entities.Coordinate {
+	coords := make([]entities.Coordinate, 0, len(g.Tiles))
+	for c := range g.Tiles {
+		coords = append(coords, c)
+	}
+	sort.Slice(coords, func(i, j int) bool {
+		return lessCoordinate(coords[i], coords[j])
+	})
+	return coords
+}
+
+// sortedTiles lists the tiles in the order of sortedTileCoordinates
+func (g *Game) sortedTiles() []*entities.Tile {
+	tiles := make([]*entities.Tile, 0, len(g.Tiles))
+	for _, c := range g.sortedTileCoordinates() {
+		tiles = append(tiles, g.Tiles[c])
+	}
+	return tiles
+}
@@ -0,0 +1,292 @@
+package game
+
+import (
+	"bytes"
+	"sakura/entities"
+	"sakura/maps"
+	"testing"
+)
+
+type memoryStore struct {
+	noopStore
+	journal      [][]byte
+	snapshot     []byte
+	skipSnapshot bool
+}
+
+func (s *memoryStore) WriteJournalEntries(id string, entries [][]byte) error {
+	for _, e := range entries {
+		s.journal = append(s.journal, append([]byte(nil), e...))
+	}
+	return nil
+}
+func (s *memoryStore) ReadJournal(id string) ([][]byte, error) {
+	return s.journal, nil
+}
+func (s *memoryStore) CheckIfJournalExists(id string) (bool, error) {
+	return len(s.journal) > 0, nil
+}
+func (s *memoryStore) WriteGameSnapshot(id string, snapshot []byte) error {
+	s.snapshot = append([]byte(nil), snapshot...)
+	return nil
+}
+func (s *memoryStore) ReadGameSnapshot(id string) ([]byte, error) {
+	if s.skipSnapshot {
+		return nil, nil
+	}
+	return s.snapshot, nil
+}
+
+func snapshotTestSettings() entities.GameSettings {
+	return entities.GameSettings{
+		Mode:          entities.Base,
+		MapName:       maps.BaseMapName,
+		MapDefn:       maps.GetBaseMap(),
+		VictoryPoints: 10,
+		Speed:         entities.NormalSpeed,
+	}
+}
+
+func encodedSnapshot(t *testing.T, g *Game) []byte {
+	t.Helper()
+	b, err := EncodeSnapshot(g.CreateSnapshot())
+	if err != nil {
+		t.Fatalf("failed to encode snapshot: %v", err)
+	}
+	return b
+}
+
+func playSnapshotTestGame(t *testing.T, store *memoryStore, turns int) *Game {
+	t.Helper()
+
+	g := &Game{Store: store, Settings: snapshotTestSettings(), SnapshotInterval: 3}
+	if _, err := g.Initialize("snapshot", 3); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	stopTickerForTest(g)
+
+	for _, p := range g.Players {
+		for round := 0; round < 2; round++ {
+			vertices := p.GetBuildLocationsSettlement(g.Graph, true, false)
+			if len(vertices) == 0 {
+				t.Fatalf("no settlement location for player %d", p.Order)
+			}
+			if err := g.BuildSettlement(p, vertices[0].C); err != nil {
+				t.Fatalf("failed to build initial settlement: %v", err)
+			}
+			edges := p.GetBuildLocationsRoad(g.Graph, true)
+			if len(edges) == 0 {
+				t.Fatalf("no road location for player %d", p.Order)
+			}
+			if err := g.BuildRoad(p, edges[0].C); err != nil {
+				t.Fatalf("failed to build initial road: %v", err)
+			}
+		}
+	}
+	g.InitPhase = false
+	g.j.WSetInitPhase(false)
+
+	rolls := [][2]int{{2, 4}, {3, 5}, {1, 1}, {6, 3}, {4, 4}, {5, 5}, {2, 6}, {1, 3}}
+	for turn := 0; turn < turns; turn++ {
+		p := g.CurrentPlayer
+		roll := rolls[turn%len(rolls)]
+		if _, err := g.RollDiceWith(roll[0], roll[1]); err != nil {
+			t.Fatalf("roll failed on turn %d: %v", turn, err)
+		}
+
+		// Build wherever resources allow, failures are fine
+		if edges := p.GetBuildLocationsRoad(g.Graph, false); len(edges) > 0 {
+			g.BuildRoad(p, edges[0].C)
+		}
+		if vertices := p.GetBuildLocationsCity(g.Graph); len(vertices) > 0 {
+			g.BuildCity(p, vertices[0].C)
+		}
+
+		if err := g.EndTurn(p); err != nil {
+			t.Fatalf("end turn failed on turn %d: %v", turn, err)
+		}
+	}
+	g.j.Flush()
+
+	return g
+}
+
+func restoreSnapshotTestGame(t *testing.T, store *memoryStore) *Game {
+	t.Helper()
+
+	g := &Game{Store: store, Settings: snapshotTestSettings()}
+	if _, err := g.Initialize("snapshot", 3); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	stopTickerForTest(g)
+	return g
+}
+
+func TestSnapshotRestoreMatchesFullReplay(t *testing.T) {
+	store := &memoryStore{}
+	live := playSnapshotTestGame(t, store, 8)
+
+	if store.snapshot == nil {
+		t.Fatal("expected a snapshot to be written")
+	}
+	stored, err := DecodeSnapshot(store.snapshot)
+	if err != nil {
+		t.Fatalf("failed to decode stored snapshot: %v", err)
+	}
+	if stored.TurnCount != 6 {
+		t.Fatalf("expected latest snapshot at turn 6, got %d", stored.TurnCount)
+	}
+	if stored.JournalIndex >= len(store.journal) {
+		t.Fatalf("expected journal entries after the snapshot")
+	}
+
+	want := encodedSnapshot(t, live)
+
+	store.skipSnapshot = true
+	replayed := restoreSnapshotTestGame(t, store)
+	if got := encodedSnapshot(t, replayed); !bytes.Equal(got, want) {
+		t.Fatal("full journal replay does not match live game")
+	}
+
+	store.skipSnapshot = false
+	restored := restoreSnapshotTestGame(t, store)
+	if got := encodedSnapshot(t, restored); !bytes.Equal(got, want) {
+		t.Fatal("snapshot restore does not match live game")
+	}
+	if restored.TurnCount != live.TurnCount {
+		t.Fatalf("expected turn count %d, got %d", live.TurnCount, restored.TurnCount)
+	}
+}
+
+func TestSnapshotRestoreMatchesFullReplayCitiesAndKnights(t *testing.T) {
+	settings := snapshotTestSettings()
+	settings.Mode = entities.CitiesAndKnights
+	settings.VictoryPoints = 13
+	newGame := func(store *memoryStore) *Game {
+		t.Helper()
+		g := &Game{Store: store, Settings: settings, SnapshotInterval: 3}
+		if _, err := g.Initialize("snapshot", 3); err != nil {
+			t.Fatalf("initialize failed: %v", err)
+		}
+		stopTickerForTest(g)
+		return g
+	}
+
+	store := &memoryStore{}
+	live := newGame(store)
+	for _, p := range live.Players {
+		for round := 0; round < 2; round++ {
+			vertices := p.GetBuildLocationsSettlement(live.Graph, true, false)
+			if err := live.BuildSettlement(p, vertices[0].C); err != nil {
+				t.Fatalf("failed to build initial settlement: %v", err)
+			}
+			edges := p.GetBuildLocationsRoad(live.Graph, true)
+			if err := live.BuildRoad(p, edges[0].C); err != nil {
+				t.Fatalf("failed to build initial road: %v", err)
+			}
+		}
+	}
+	live.InitPhase = false
+	live.j.WSetInitPhase(false)
+
+	give := func(p *entities.Player, cost entities.BuildCost) {
+		for ct, q := range cost {
+			if q > 0 {
+				live.MoveCards(-1, int(p.Order), entities.CardType(ct), q, true, false)
+			}
+		}
+	}
+
+	rolls := [][2]int{{2, 4}, {3, 5}, {1, 1}, {6, 3}, {4, 4}, {5, 5}, {2, 6}, {1, 3}}
+	for turn := 0; turn < 8; turn++ {
+		p := live.CurrentPlayer
+		roll := rolls[turn%len(rolls)]
+		if _, err := live.RollDiceWith(roll[0], roll[1]); err != nil {
+			t.Fatalf("roll failed on turn %d: %v", turn, err)
+		}
+		// Every other turn moves the barbarians, short of an attack
+		if turn%2 == 0 {
+			live.RollEventDiceWith(4)
+		} else {
+			live.RollEventDiceWith(2)
+		}
+
+		if turn < len(live.Players) {
+			cost, _ := live.BuildRules.GetCost(entities.BTCity)
+			give(p, cost)
+			if err := live.BuildCity(p, p.GetBuildLocationsCity(live.Graph)[0].C); err != nil {
+				t.Fatalf("failed to build city: %v", err)
+			}
+
+			paper, _ := live.BuildRules.GetImprovementCost(0)
+			give(p, entities.BuildCost{entities.CardTypePaper: paper})
+			if err := live.BuildCityImprovement(p, entities.CardTypePaper); err != nil {
+				t.Fatalf("failed to improve city: %v", err)
+			}
+
+			cost, _ = live.BuildRules.GetCost(entities.BTKnight1)
+			give(p, cost)
+			v := p.GetBuildLocationsKnight(live.Graph, false)[0]
+			if err := live.BuildKnight(p, v.C); err != nil {
+				t.Fatalf("failed to build knight: %v", err)
+			}
+			give(p, live.BuildRules.GetActivateKnightCost())
+			if err := live.ActivateKnight(p, v.C); err != nil {
+				t.Fatalf("failed to activate knight: %v", err)
+			}
+		}
+
+		if err := live.EndTurn(p); err != nil {
+			t.Fatalf("end turn failed on turn %d: %v", turn, err)
+		}
+	}
+	live.j.Flush()
+
+	if live.BarbarianPosition == 7 {
+		t.Fatal("expected the barbarians to have moved")
+	}
+	if store.snapshot == nil {
+		t.Fatal("expected a snapshot to be written")
+	}
+	stored, err := DecodeSnapshot(store.snapshot)
+	if err != nil {
+		t.Fatalf("failed to decode stored snapshot: %v", err)
+	}
+	if stored.JournalIndex >= len(store.journal) {
+		t.Fatalf("expected journal entries after the snapshot")
+	}
+
+	want := encodedSnapshot(t, live)
+
+	store.skipSnapshot = true
+	replayed := newGame(store)
+	if got := encodedSnapshot(t, replayed); !bytes.Equal(got, want) {
+		t.Fatal("full journal replay does not match live game")
+	}
+
+	store.skipSnapshot = false
+	restored := newGame(store)
+	if got := encodedSnapshot(t, restored); !bytes.Equal(got, want) {
+		t.Fatal("snapshot restore does not match full replay")
+	}
+	if restored.GetBarbarianKnights() != len(restored.Players) {
+		t.Fatalf("expected an active knight of every player, got strength %d", restored.GetBarbarianKnights())
+	}
+	for _, p := range restored.Players {
+		if p.Improvements[int(entities.CardTypePaper)] != 1 {
+			t.Fatalf("expected the improvement of player %d", p.Order)
+		}
+	}
+}
+
+func TestSnapshotRestoreFallsBackToFullReplay(t *testing.T) {
+	store := &memoryStore{}
+	live := playSnapshotTestGame(t, store, 4)
+	want := encodedSnapshot(t, live)
+
+	store.snapshot = []byte{0xc1}
+	restored := restoreSnapshotTestGame(t, store)
+	if got := encodedSnapshot(t, restored); !bytes.Equal(got, want) {
+		t.Fatal("replay after a corrupt snapshot does not match live game")
+	}
+}
@@ -57,11 +57,17 @@ func (g *Game) GetGameState() *entities.GameState {
 		BarbarianPosition: g.BarbarianPosition,
 		BarbarianStrength: g.GetBarbarianStrength(),
 		BarbarianKnights:  g.GetBarbarianKnights(),
+
+		TribeMarkers:  g.ScenarioTribeMarkers,
+		ClothVillages: g.ScenarioClothVillages,
+		Fortresses:    g.ScenarioFortresses,
+		Warships:      g.getWarshipCoordinates(),
+		Wonders:       g.ScenarioWonders,
 	}
 }
 
 func (g *Game) GetBankDevelopmentRemaining() int {
-	if g.Mode == entities.CitiesAndKnights {
+	if g.Mode.HasCitiesAndKnights() {
 		return len(g.Bank.DevelopmentCardOrder[entities.CardTypePaper]) +
 			len(g.Bank.DevelopmentCardOrder[entities.CardTypeCloth]) +
 			len(g.Bank.DevelopmentCardOrder[entities.CardTypeCoin])
@@ -77,12 +83,18 @@ func (g *Game) GetBankDevelopmentRemaining() int {
 
 func (g *Game) GetPlayerState(p *entities.Player) *entities.PlayerState {
 	knights := int16(-1)
-	if g.Mode == entities.Base || g.Mode == entities.Seafarers {
+	if !g.Mode.HasCitiesAndKnights() {
 		knights = p.CurrentHand.DevelopmentCardDeckMap[entities.DevelopmentCardKnight].NumUsed
-	} else if g.Mode == entities.CitiesAndKnights {
+	} else {
 		knights = int16(p.GetActivatedKnightStrength())
 	}
 
+	var wonder entities.WonderType
+	wonderStage := 0
+	if w := g.getPlayerWonder(p); w != nil {
+		wonder, wonderStage = w.Type, w.Stage
+	}
+
 	return &entities.PlayerState{
 		Id:                  p.Id,
 		Username:            p.Username,
@@ -100,8 +112,12 @@ func (g *Game) GetPlayerState(p *entities.Player) *entities.PlayerState {
 		Improvements:        p.Improvements,
 		DiscardLimit:        g.GetDiscardLimit(p),
 		IsBot:               p.GetIsBot(),
+		BotLevel:            p.BotLevel,
 		HasLongestRoad:      g.ExtraVictoryPoints.LongestRoadHolder == p,
 		HasLargestArmy:      g.ExtraVictoryPoints.LargestArmyHolder == p,
+		Cloth:               g.ScenarioCloth[p],
+		Wonder:              wonder,
+		WonderStage:         wonderStage,
 	}
 }
 
@@ -119,29 +135,33 @@ func (g *Game) GetPlayerSecretState(p *entities.Player) entities.PlayerSecretSta
 
 	actions := entities.AllowedActionsMap{
 		BuildSettlement: !busy && g.ensureCanBuild(p, entities.BTSettlement) == nil &&
-			len(p.GetBuildLocationsSettlement(g.Graph, false, false)) > 0,
+			len(g.GetBuildLocationsSettlement(p)) > 0,
 		BuildCity: !busy && g.ensureCanBuild(p, entities.BTCity) == nil &&
 			len(p.GetBuildLocationsCity(g.Graph)) > 0,
 		BuildRoad: !busy && g.ensureCanBuild(p, entities.BTRoad) == nil &&
 			len(p.GetBuildLocationsRoad(g.Graph, false)) > 0,
 		BuildShip: !busy && g.ensureCanBuild(p, entities.BTShip) == nil &&
 			len(p.GetBuildLocationsShip(g.Graph)) > 0,
-		MoveShip: !commonBusy && !g.IsInitPhase() && g.DiceState == 0 && g.Mode == entities.Seafarers &&
+		MoveShip: !commonBusy && !g.IsInitPhase() && g.DiceState == 0 && g.Mode.HasSeafarers() &&
 			!p.ShipMoved && len(g.GetMovableShips(p)) > 0,
 		BuyDevelopmentCard: !busy &&
-			(g.IsCreativeMode() || p.CanBuyDevelopmentCard()),
+			(g.IsCreativeMode() || p.CanBuyDevelopmentCard(g.BuildRules)),
 		Trade:   !busy && !g.SpecialBuildPhase,
 		EndTurn: !busy && g.CanEndTurn() == nil,
 
 		BuildKnight: !busy && (g.ensureCanBuild(p, entities.BTKnight1) == nil ||
 			g.ensureCanBuild(p, entities.BTKnight2) == nil ||
 			g.ensureCanBuild(p, entities.BTKnight3) == nil),
-		ActivateKnight: !busy && (g.IsCreativeMode() || p.CurrentHand.HasResources(0, 0, 0, 1, 0)) &&
+		ActivateKnight: !busy && (g.IsCreativeMode() || p.CurrentHand.HasCost(g.BuildRules.GetActivateKnightCost())) &&
 			len(p.GetActivateLocationsKnight(g.Graph)) > 0,
 		RobberKnight: !busy && !g.SpecialBuildPhase && g.KnightChaseRobber(p, true) == nil,
 		MoveKnight:   !busy && !g.SpecialBuildPhase && g.KnightMove(p, true) == nil,
 		BuildWall:    !busy && g.ensureCanBuild(p, entities.BTWall) == nil && len(p.GetBuildLocationsWall(g.Graph)) > 0,
 
+		AttackFortress: !busy && g.CanAttackPirateFortress(p) == nil,
+		ClaimWonder:    !busy && len(g.getClaimableWonders(p)) > 0,
+		BuildWonder:    !busy && g.CanBuildWonderStage(p) == nil,
+
 		ImprovePaper: p.ChoosingProgressCard || (!busy || p.UsingDevCard == entities.ProgressPaperCrane) && (g.CanBuildImprovement(p, entities.CardTypePaper) == nil),
 		ImproveCloth: p.ChoosingProgressCard || (!busy || p.UsingDevCard == entities.ProgressPaperCrane) && (g.CanBuildImprovement(p, entities.CardTypeCloth) == nil),
 		ImproveCoin:  p.ChoosingProgressCard || (!busy || p.UsingDevCard == entities.ProgressPaperCrane) && (g.CanBuildImprovement(p, entities.CardTypeCoin) == nil),
@@ -212,7 +232,7 @@ func (g *Game) SetExtraVictoryPoints() {
 	}
 
 	// Largest Army
-	if g.Mode == entities.Base || g.Mode == entities.Seafarers {
+	if !g.Mode.HasCitiesAndKnights() {
 		for _, p := range g.Players {
 			deck := p.CurrentHand.DevelopmentCardDeckMap[entities.DevelopmentCardKnight]
 			if deck != nil && deck.NumUsed >= 3 && deck.NumUsed > g.ExtraVictoryPoints.LargestArmyCount {
@@ -250,7 +270,12 @@ func (g *Game) GetVictoryPoints(p *entities.Player, public bool) int {
 		victoryPoints += g.ScenarioBonusVP[p]
 	}
 
-	if g.Mode == entities.CitiesAndKnights {
+	// Cloth, 1 VP for every 2
+	if g.ScenarioCloth != nil {
+		victoryPoints += g.ScenarioCloth[p] / 2
+	}
+
+	if g.Mode.HasCitiesAndKnights() {
 		// Defender
 		for _, dp := range g.ExtraVictoryPoints.DefenderPoints {
 			if dp == p {
@@ -318,7 +343,7 @@ func (g *Game) CheckForVictory() {
 			ps.VictoryPoints = g.GetVictoryPoints(p, false)
 			message.Players = append(message.Players, ps)
 
-			if g.Mode == entities.CitiesAndKnights {
+			if g.Mode.HasCitiesAndKnights() {
 				ev := int16(0)
 				if g.ExtraVictoryPoints.ConstitutionHolder == p {
 					ev++
@@ -333,7 +358,8 @@ func (g *Game) CheckForVictory() {
 			}
 
 			if firstCheck && !p.GetIsBot() {
-				go g.Store.WriteGameCompletedForUser(p.Id)
+				id := p.Id
+				g.spawn(func() { g.Store.WriteGameCompletedForUser(id) })
 			}
 		}
 
@@ -1,6 +1,7 @@
 package game
 
 import (
+	"math/rand"
 	"sakura/entities"
 	"testing"
 	"time"
@@ -13,7 +14,7 @@ func buildGameForTimerStateTest(t *testing.T) *Game {
 	if err != nil {
 		t.Fatalf("GetNewPlayers failed: %v", err)
 	}
-	bank, err := entities.GetNewBank(entities.Base)
+	bank, err := entities.GetNewBank(entities.Base, rand.New(rand.NewSource(1)))
 	if err != nil {
 		t.Fatalf("GetNewBank failed: %v", err)
 	}
@@ -343,6 +343,48 @@ func (ds *MangoStore) ReadJournal(id string) ([][]byte, error) {
 	return journalBytes, nil
 }
 
+func (ds *MangoStore) WriteGameSnapshot(id string, snapshot []byte) error {
+	db := GetDatabase()
+	collection := db.Collection(GamesTable)
+	_, err := collection.UpdateOne(
+		context.TODO(),
+		bson.D{primitive.E{Key: "id", Value: id}},
+		bson.D{
+			primitive.E{Key: "$set",
+				Value: bson.M{
+					"snapshot":  snapshot,
+					"updatedAt": time.Now(),
+				},
+			},
+		},
+	)
+	return err
+}
+
+func (ds *MangoStore) ReadGameSnapshot(id string) ([]byte, error) {
+	db := GetDatabase()
+	collection := db.Collection(GamesTable)
+
+	var m map[string]interface{}
+	err := collection.FindOne(
+		context.TODO(),
+		bson.D{primitive.E{Key: "id", Value: id}},
+		&options.FindOneOptions{
+			Projection: bson.M{"snapshot": 1},
+		},
+	).Decode(&m)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, ok := m["snapshot"].(primitive.Binary)
+	if !ok {
+		return nil, nil
+	}
+
+	return snapshot.Data, nil
+}
+
 func (ds *MangoStore) ReadGamePlayers(id string) (int, error) {
 	db := GetDatabase()
 	collection := db.Collection(GamesTable)
@@ -658,3 +700,38 @@ func (ds *MangoStore) GetMap(name string) *entities.MapDefinition {
 	}
 	return ans
 }
+
+// WriteMap stores a map definition under its name. Official maps are
+// listed to every user, the rest only to (or excluding) their creator.
+func (ds *MangoStore) WriteMap(creator string, official bool, defn *entities.MapDefinition) error {
+	if defn == nil || defn.Name == "" {
+		return errors.New("map must have a name")
+	}
+
+	// GetMap reads the definition back through json
+	marshal, err := json.Marshal(defn)
+	if err != nil {
+		return err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(marshal, &m); err != nil {
+		return err
+	}
+
+	db := GetDatabase()
+	collection := db.Collection(MapsTable)
+
+	upsert := true
+	_, err = collection.UpdateOne(
+		context.TODO(),
+		bson.D{primitive.E{Key: "name", Value: defn.Name}},
+		bson.D{primitive.E{Key: "$set", Value: bson.M{
+			"name":     defn.Name,
+			"creator":  creator,
+			"official": official,
+			"map":      m,
+		}}},
+		&options.UpdateOptions{Upsert: &upsert},
+	)
+	return err
+}
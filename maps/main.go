@@ -7,6 +7,7 @@ import (
 
 const (
 	BaseMapName                  = "Base"
+	BaseExtensionMapName         = "Base - 5-6 Players"
 	SeafarersHeadingForNewShores = "Seafarers - Heading for New Shores"
 )
 
@@ -20,6 +21,10 @@ func GetMapByName(name string) *entities.MapDefinition {
 	switch name {
 	case BaseMapName:
 		return GetBaseMap()
+	case BaseExtensionMapName:
+		var defn entities.MapDefinition
+		json.Unmarshal([]byte(baseBig), &defn)
+		return &defn
 	case SeafarersHeadingForNewShores:
 		var defn entities.MapDefinition
 		json.Unmarshal([]byte(seafarersHeadingForNewShores), &defn)
@@ -40,26 +45,36 @@ func GetMapByName(name string) *entities.MapDefinition {
 func GetOfficialMapNames() []string {
 	return []string{
 		BaseMapName,
+		BaseExtensionMapName,
 		SeafarersHeadingForNewShores,
+		SeafarersHeadingForNewShoresExtension,
 		SeafarersFourIslands,
 		SeafarersFogIslands,
 		SeafarersThroughDesert,
+		SeafarersForgottenTribe,
+		SeafarersClothForCatan,
+		SeafarersPirateIslands,
+		SeafarersWondersOfCatan,
+		SeafarersNewWorldVariant,
 	}
 }
 
 const baseMap = `{"name":"Base","order":[false,true,false,true,false],"ports":[6,6,6,6,1,2,3,4,5],"port_coordinates":[{"C1":{"X":2,"Y":8},"C2":{"X":2,"Y":6}},{"C1":{"X":4,"Y":2},"C2":{"X":6,"Y":0}},{"C1":{"X":10,"Y":0},"C2":{"X":12,"Y":2}},{"C1":{"X":16,"Y":4},"C2":{"X":18,"Y":6}},{"C1":{"X":20,"Y":10},"C2":{"X":20,"Y":12}},{"C1":{"X":18,"Y":16},"C2":{"X":16,"Y":18}},{"C1":{"X":12,"Y":20},"C2":{"X":10,"Y":22}},{"C1":{"X":6,"Y":22},"C2":{"X":4,"Y":20}},{"C1":{"X":2,"Y":16},"C2":{"X":2,"Y":14}}],"numbers":[2,3,3,4,4,5,5,6,6,8,8,9,9,10,10,11,11,12],"tiles":[0,1,1,1,1,2,2,2,3,3,3,3,4,4,4,4,5,5,5],"map":[[8,9,9,9,8],[9,9,9,9,8],[9,9,9,9,9],[9,9,9,9,8],[8,9,9,9,8]]}`
 
 const baseBig = `{
-		"name": "Base Big",
+		"name": "Base - 5-6 Players",
+		"max_players": 6,
 		"order": [true, false, true, false, true, false, true],
-		"ports": [6, 6, 6, 6, 1, 2, 3, 4, 5],
+		"ports": [6, 6, 6, 6, 6, 1, 2, 3, 3, 4, 5],
 		"port_coordinates": [
 			{ "C1": {"X": 6, "Y": 30}, "C2": {"X": 4, "Y": 28} },
 			{ "C1": {"X": 2, "Y": 24}, "C2": {"X": 2, "Y": 22} },
 			{ "C1": {"X": -2, "Y": 16}, "C2": {"X": -2, "Y": 14} },
 			{ "C1": {"X": 2, "Y": 6}, "C2": {"X": 4, "Y": 4} },
+			{ "C1": {"X": 8, "Y": 2}, "C2": {"X": 10, "Y": 0} },
 			{ "C1": {"X": 14, "Y": 0}, "C2": {"X": 16, "Y": 2} },
 			{ "C1": {"X": 18, "Y": 8}, "C2": {"X": 20, "Y": 10} },
+			{ "C1": {"X": 22, "Y": 14}, "C2": {"X": 22, "Y": 16} },
 			{ "C1": {"X": 20, "Y": 18}, "C2": {"X": 20, "Y": 20} },
 			{ "C1": {"X": 18, "Y": 24}, "C2": {"X": 16, "Y": 26} },
 			{ "C1": {"X": 12, "Y": 28}, "C2": {"X": 10, "Y": 30} }
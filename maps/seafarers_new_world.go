@@ -0,0 +1,353 @@
+package maps
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sakura/entities"
+)
+
+const NewWorldScenarioKey = "seafarers_new_world_variant"
+
+// NewWorldOptions is the configuration of a random New World archipelago.
+// Unset islands, land ratio and harbors are filled in from the player
+// count.
+type NewWorldOptions struct {
+	Players int `json:"players"`
+
+	// Number of separate islands
+	Islands int `json:"islands,omitempty"`
+
+	// Share of the tiles inside the frame that are land
+	LandRatio float64 `json:"land_ratio,omitempty"`
+
+	GoldFields int `json:"gold_fields,omitempty"`
+	Harbors    int `json:"harbors,omitempty"`
+}
+
+// Attempts at laying out the islands before giving up
+const newWorldAttempts = 50
+
+// DefaultNewWorldOptions returns the options for a New World game with
+// the given number of players
+func DefaultNewWorldOptions(players int) NewWorldOptions {
+	if players < 2 {
+		players = 2
+	} else if players > 6 {
+		players = 6
+	}
+
+	if players > 4 {
+		return NewWorldOptions{Players: players, Islands: 5, LandRatio: 0.45, GoldFields: 3, Harbors: 11}
+	}
+	return NewWorldOptions{Players: players, Islands: 4, LandRatio: 0.5, GoldFields: 2, Harbors: 9}
+}
+
+// withDefaults fills in unset options
+func (o NewWorldOptions) withDefaults() NewWorldOptions {
+	d := DefaultNewWorldOptions(o.Players)
+	if o.Players == 0 {
+		o.Players = d.Players
+	}
+	if o.Islands == 0 {
+		o.Islands = d.Islands
+	}
+	if o.LandRatio == 0 {
+		o.LandRatio = d.LandRatio
+	}
+	if o.Harbors == 0 {
+		o.Harbors = d.Harbors
+	}
+	return o
+}
+
+// frame returns the rows and columns of the map, sea border included
+func (o NewWorldOptions) frame() (int, int) {
+	if o.Players > 4 {
+		return 10, 11
+	}
+	return 8, 9
+}
+
+func (o NewWorldOptions) landTiles() int {
+	rows, cols := o.frame()
+	return int(math.Round(float64((rows-2)*(cols-2)) * o.LandRatio))
+}
+
+// Validate checks the options can produce a playable map
+func (o NewWorldOptions) Validate() error {
+	o = o.withDefaults()
+	if o.Players < 2 || o.Players > 6 {
+		return errors.New("new world needs 2 to 6 players")
+	}
+	if o.LandRatio < 0.3 || o.LandRatio > 0.6 {
+		return errors.New("land ratio must be between 0.3 and 0.6")
+	}
+
+	land := o.landTiles()
+	if o.Islands < 1 || o.Islands > land/3 {
+		return errors.New("every island needs at least 3 tiles")
+	}
+	if o.GoldFields < 0 || o.GoldFields > land/4 {
+		return errors.New("too many gold fields")
+	}
+	if o.Harbors < o.Islands || o.Harbors > 2*o.Islands+6 {
+		return errors.New("need one harbor for every island, and not too many")
+	}
+	return nil
+}
+
+// GenerateNewWorldMap rolls a random archipelago. The same options and
+// seed always give the same map.
+func GenerateNewWorldMap(opts NewWorldOptions, seed int64) (*entities.MapDefinition, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	opts = opts.withDefaults()
+	r := rand.New(rand.NewSource(seed))
+
+	rows, cols := opts.frame()
+	order := make([]bool, rows)
+	for i := range order {
+		order[i] = i%2 == 1
+	}
+	centers := newWorldCenters(order, cols)
+
+	var islands [][]newWorldCell
+	for attempt := 0; islands == nil; attempt++ {
+		if attempt == newWorldAttempts {
+			return nil, errors.New("could not lay out the islands")
+		}
+		islands = layoutNewWorldIslands(r, opts, rows, cols, centers)
+	}
+
+	grid := make([][]int, rows)
+	for i := range grid {
+		grid[i] = make([]int, cols)
+		for j := range grid[i] {
+			grid[i][j] = int(entities.TileTypeSea)
+		}
+	}
+
+	land := make([]newWorldCell, 0)
+	for _, island := range islands {
+		for _, c := range island {
+			grid[c.Row][c.Col] = int(entities.TileTypeRandom)
+			land = append(land, c)
+		}
+	}
+
+	// Gold fields on random land tiles
+	r.Shuffle(len(land), func(i, j int) { land[i], land[j] = land[j], land[i] })
+	for _, c := range land[:opts.GoldFields] {
+		grid[c.Row][c.Col] = int(entities.TileTypeGold)
+	}
+
+	// One desert for the robber, resources for the rest
+	resources := []entities.TileType{
+		entities.TileTypeWood,
+		entities.TileTypeBrick,
+		entities.TileTypeWool,
+		entities.TileTypeWheat,
+		entities.TileTypeOre,
+	}
+	randomTiles := []entities.TileType{entities.TileTypeDesert}
+	offset := r.Intn(len(resources))
+	for i := 1; i < len(land)-opts.GoldFields; i++ {
+		randomTiles = append(randomTiles, resources[(offset+i)%len(resources)])
+	}
+
+	baseNumbers := []uint16{2, 3, 3, 4, 4, 5, 5, 6, 6, 8, 8, 9, 9, 10, 10, 11, 11, 12}
+	numbers := make([]uint16, len(land)-1)
+	for i := range numbers {
+		numbers[i] = baseNumbers[i%len(baseNumbers)]
+	}
+
+	ports := make([]entities.PortType, opts.Harbors)
+	for i := range ports {
+		if i < len(resources) {
+			ports[i] = entities.PortType(resources[i])
+		} else {
+			ports[i] = entities.PortTypeAny
+		}
+	}
+
+	return &entities.MapDefinition{
+		Name:            SeafarersNewWorldVariant,
+		Order:           order,
+		Ports:           ports,
+		PortCoordinates: newWorldHarbors(r, islands, centers, grid, opts.Harbors),
+		Numbers:         numbers,
+		RandomTiles:     randomTiles,
+		Map:             grid,
+		MaxPlayers:      6,
+		Scenario: &entities.ScenarioMetadata{
+			Expansion:       "Seafarers",
+			Key:             NewWorldScenarioKey,
+			Title:           SeafarersNewWorldVariant,
+			Placeholder:     false,
+			VictoryPoints:   12,
+			VictoryRuleText: "If you have 12 or more VPs at any point during your turn, you win.",
+		},
+	}, nil
+}
+
+type newWorldCell struct {
+	Row int
+	Col int
+}
+
+// newWorldCenters returns the tile centers of the map the way the game
+// lays out its rows
+func newWorldCenters(order []bool, cols int) [][]entities.Coordinate {
+	centers := make([][]entities.Coordinate, len(order))
+	startX, startY := 2, 3
+	for i, odd := range order {
+		centers[i] = make([]entities.Coordinate, cols)
+		for j := range centers[i] {
+			centers[i][j] = entities.Coordinate{X: startX + 4*j, Y: startY}
+		}
+		if odd {
+			startX -= 2
+		} else {
+			startX += 2
+		}
+		startY += 4
+	}
+	return centers
+}
+
+// newWorldNeighbors lists the cells around a center, in the order of the
+// edges of a tile
+func newWorldNeighbors(c entities.Coordinate) []entities.Coordinate {
+	return []entities.Coordinate{
+		{X: c.X + 2, Y: c.Y - 4},
+		{X: c.X + 4, Y: c.Y},
+		{X: c.X + 2, Y: c.Y + 4},
+		{X: c.X - 2, Y: c.Y + 4},
+		{X: c.X - 4, Y: c.Y},
+		{X: c.X - 2, Y: c.Y - 4},
+	}
+}
+
+// layoutNewWorldIslands grows the islands from random seeds, keeping sea
+// between them. It returns nil if an island could not reach its size.
+func layoutNewWorldIslands(r *rand.Rand, opts NewWorldOptions, rows, cols int, centers [][]entities.Coordinate) [][]newWorldCell {
+	byCenter := make(map[entities.Coordinate]newWorldCell)
+	interior := make([]newWorldCell, 0)
+	for i := 1; i < rows-1; i++ {
+		for j := 1; j < cols-1; j++ {
+			byCenter[centers[i][j]] = newWorldCell{Row: i, Col: j}
+			interior = append(interior, newWorldCell{Row: i, Col: j})
+		}
+	}
+
+	owner := make(map[newWorldCell]int)
+	touchesOther := func(c newWorldCell, island int) bool {
+		for _, n := range newWorldNeighbors(centers[c.Row][c.Col]) {
+			if nc, ok := byCenter[n]; ok {
+				if o, taken := owner[nc]; taken && o != island {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	land := opts.landTiles()
+	islands := make([][]newWorldCell, opts.Islands)
+	for i := range islands {
+		size := land / opts.Islands
+		if i < land%opts.Islands {
+			size++
+		}
+
+		seeds := make([]newWorldCell, 0)
+		for _, c := range interior {
+			if _, taken := owner[c]; !taken && !touchesOther(c, i+1) {
+				seeds = append(seeds, c)
+			}
+		}
+		if len(seeds) == 0 {
+			return nil
+		}
+		start := seeds[r.Intn(len(seeds))]
+		owner[start] = i + 1
+		islands[i] = []newWorldCell{start}
+
+		for len(islands[i]) < size {
+			frontier := make([]newWorldCell, 0)
+			seen := make(map[newWorldCell]bool)
+			for _, c := range islands[i] {
+				for _, n := range newWorldNeighbors(centers[c.Row][c.Col]) {
+					nc, ok := byCenter[n]
+					if !ok || seen[nc] {
+						continue
+					}
+					seen[nc] = true
+					if _, taken := owner[nc]; !taken && !touchesOther(nc, i+1) {
+						frontier = append(frontier, nc)
+					}
+				}
+			}
+			if len(frontier) == 0 {
+				return nil
+			}
+			next := frontier[r.Intn(len(frontier))]
+			owner[next] = i + 1
+			islands[i] = append(islands[i], next)
+		}
+	}
+	return islands
+}
+
+// newWorldHarbors picks coastal edges for the harbors, going round the
+// islands so that every island gets at least one
+func newWorldHarbors(r *rand.Rand, islands [][]newWorldCell, centers [][]entities.Coordinate, grid [][]int, count int) []entities.EdgeCoordinate {
+	isSea := make(map[entities.Coordinate]bool)
+	for i, row := range grid {
+		for j, t := range row {
+			if t == int(entities.TileTypeSea) {
+				isSea[centers[i][j]] = true
+			}
+		}
+	}
+
+	coasts := make([][]entities.EdgeCoordinate, len(islands))
+	for i, island := range islands {
+		for _, c := range island {
+			center := centers[c.Row][c.Col]
+			tile := entities.Tile{Center: center}
+			edges := tile.GetEdgeCoordinates()
+			for k, n := range newWorldNeighbors(center) {
+				if isSea[n] {
+					coasts[i] = append(coasts[i], edges[k])
+				}
+			}
+		}
+		r.Shuffle(len(coasts[i]), func(a, b int) { coasts[i][a], coasts[i][b] = coasts[i][b], coasts[i][a] })
+	}
+
+	used := make(map[entities.Coordinate]bool)
+	harbors := make([]entities.EdgeCoordinate, 0, count)
+	for found := true; found && len(harbors) < count; {
+		found = false
+		for i := range coasts {
+			if len(harbors) == count {
+				break
+			}
+			for len(coasts[i]) > 0 {
+				e := coasts[i][0]
+				coasts[i] = coasts[i][1:]
+				if used[e.C1] || used[e.C2] {
+					continue
+				}
+				used[e.C1], used[e.C2] = true, true
+				harbors = append(harbors, e)
+				found = true
+				break
+			}
+		}
+	}
+	return harbors
+}
@@ -0,0 +1,155 @@
+package maps
+
+import (
+	"reflect"
+	"sakura/entities"
+	"testing"
+)
+
+// newWorldIslands groups the land tiles of the map into islands
+func newWorldIslands(defn *entities.MapDefinition) map[entities.Coordinate]int {
+	centers := newWorldCenters(defn.Order, len(defn.Map[0]))
+	isLand := make(map[entities.Coordinate]bool)
+	for i, row := range defn.Map {
+		for j, t := range row {
+			if t != int(entities.TileTypeSea) {
+				isLand[centers[i][j]] = true
+			}
+		}
+	}
+
+	island := make(map[entities.Coordinate]int)
+	count := 0
+	for i, row := range defn.Map {
+		for j := range row {
+			c := centers[i][j]
+			if !isLand[c] || island[c] != 0 {
+				continue
+			}
+			count++
+			stack := []entities.Coordinate{c}
+			for len(stack) > 0 {
+				curr := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if island[curr] != 0 {
+					continue
+				}
+				island[curr] = count
+				for _, n := range newWorldNeighbors(curr) {
+					if isLand[n] && island[n] == 0 {
+						stack = append(stack, n)
+					}
+				}
+			}
+		}
+	}
+	return island
+}
+
+func TestGenerateNewWorldMapLayout(t *testing.T) {
+	for _, players := range []int{3, 4, 5, 6} {
+		opts := DefaultNewWorldOptions(players)
+		for seed := int64(1); seed <= 20; seed++ {
+			defn, err := GenerateNewWorldMap(opts, seed)
+			if err != nil {
+				t.Fatalf("%d players, seed %d: %v", players, seed, err)
+			}
+			if defn.Scenario == nil || defn.Scenario.Key != NewWorldScenarioKey || defn.Scenario.Placeholder {
+				t.Fatal("expected new world scenario metadata")
+			}
+
+			rows, cols := opts.frame()
+			if len(defn.Map) != rows || len(defn.Order) != rows || len(defn.Map[0]) != cols {
+				t.Fatalf("expected a %dx%d frame for %d players", rows, cols, players)
+			}
+			for j := 0; j < cols; j++ {
+				if defn.Map[0][j] != int(entities.TileTypeSea) || defn.Map[rows-1][j] != int(entities.TileTypeSea) {
+					t.Fatal("expected sea around the map")
+				}
+			}
+
+			land, gold := 0, 0
+			for _, row := range defn.Map {
+				for _, tt := range row {
+					if tt != int(entities.TileTypeSea) {
+						land++
+					}
+					if tt == int(entities.TileTypeGold) {
+						gold++
+					}
+				}
+			}
+			if land != opts.landTiles() || gold != opts.GoldFields {
+				t.Fatalf("expected %d land and %d gold, got %d and %d", opts.landTiles(), opts.GoldFields, land, gold)
+			}
+			if len(defn.RandomTiles) != land-gold || len(defn.Numbers) != land-1 {
+				t.Fatalf("expected tiles and numbers for every land tile, got %d and %d", len(defn.RandomTiles), len(defn.Numbers))
+			}
+
+			islands := newWorldIslands(defn)
+			harbored := make(map[int]bool)
+			used := make(map[entities.Coordinate]bool)
+			for _, e := range defn.PortCoordinates {
+				if used[e.C1] || used[e.C2] {
+					t.Fatal("expected harbors not to share a corner")
+				}
+				used[e.C1], used[e.C2] = true, true
+				for c, id := range islands {
+					tile := entities.Tile{Center: c}
+					for _, te := range tile.GetEdgeCoordinates() {
+						if te == e {
+							harbored[id] = true
+						}
+					}
+				}
+			}
+			count := 0
+			for _, id := range islands {
+				if id > count {
+					count = id
+				}
+			}
+			if count != opts.Islands {
+				t.Fatalf("expected %d islands, got %d", opts.Islands, count)
+			}
+			if len(harbored) != count {
+				t.Fatalf("expected a harbor on each of %d islands, got %d", count, len(harbored))
+			}
+		}
+	}
+}
+
+func TestGenerateNewWorldMapIsSeeded(t *testing.T) {
+	opts := DefaultNewWorldOptions(4)
+	a, _ := GenerateNewWorldMap(opts, 7)
+	b, _ := GenerateNewWorldMap(opts, 7)
+	c, _ := GenerateNewWorldMap(opts, 8)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("expected the same seed to give the same map")
+	}
+	if reflect.DeepEqual(a.Map, c.Map) {
+		t.Fatal("expected another seed to give another map")
+	}
+}
+
+func TestNewWorldOptionsValidate(t *testing.T) {
+	if err := (NewWorldOptions{Players: 4}).Validate(); err != nil {
+		t.Fatalf("expected defaults to be valid: %v", err)
+	}
+
+	bad := []NewWorldOptions{
+		{Players: 7},
+		{Players: 4, LandRatio: 0.9},
+		{Players: 4, Islands: 20},
+		{Players: 4, GoldFields: 30},
+		{Players: 4, Islands: 4, Harbors: 2},
+	}
+	for _, opts := range bad {
+		if err := opts.Validate(); err == nil {
+			t.Fatalf("expected %+v to be rejected", opts)
+		}
+		if _, err := GenerateNewWorldMap(opts, 1); err == nil {
+			t.Fatalf("expected no map for %+v", opts)
+		}
+	}
+}
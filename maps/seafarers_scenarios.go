@@ -1,36 +1,29 @@
 package maps
 
-import (
-	"encoding/json"
-	"sakura/entities"
-)
+import "sakura/entities"
 
 const (
-	SeafarersFourIslands     = "Seafarers - The Four Islands"
-	SeafarersFogIslands      = "Seafarers - The Fog Islands"
-	SeafarersThroughDesert   = "Seafarers - Through the Desert"
-	SeafarersForgottenTribe  = "Seafarers - The Forgotten Tribe"
-	SeafarersClothForCatan   = "Seafarers - Cloth for Catan"
-	SeafarersPirateIslands   = "Seafarers - The Pirate Islands"
-	SeafarersWondersOfCatan  = "Seafarers - The Wonders of Catan"
-	SeafarersNewWorldVariant = "Seafarers - New World Variant"
+	SeafarersFourIslands                  = "Seafarers - The Four Islands"
+	SeafarersHeadingForNewShoresExtension = "Seafarers - Heading for New Shores (5-6 Players)"
+	SeafarersFogIslands                   = "Seafarers - The Fog Islands"
+	SeafarersThroughDesert                = "Seafarers - Through the Desert"
+	SeafarersForgottenTribe               = "Seafarers - The Forgotten Tribe"
+	SeafarersClothForCatan                = "Seafarers - Cloth for Catan"
+	SeafarersPirateIslands                = "Seafarers - The Pirate Islands"
+	SeafarersWondersOfCatan               = "Seafarers - The Wonders of Catan"
+	SeafarersNewWorldVariant              = "Seafarers - New World Variant"
 )
 
 // ScenarioStubMapNames lists Phase-1 scenario stubs that are wired for data-model
 // and map-loading integration but not yet full rules parity.
 func ScenarioStubMapNames() []string {
-	return []string{
-		SeafarersForgottenTribe,
-		SeafarersClothForCatan,
-		SeafarersPirateIslands,
-		SeafarersWondersOfCatan,
-		SeafarersNewWorldVariant,
-	}
+	return []string{}
 }
 
 func getSeafarersScenarioMapByName(name string) *entities.MapDefinition {
-	var key string
 	switch name {
+	case SeafarersHeadingForNewShoresExtension:
+		return getSeafarersHeadingForNewShoresExtensionMap()
 	case SeafarersFourIslands:
 		return getSeafarersFourIslandsMap()
 	case SeafarersFogIslands:
@@ -38,42 +31,111 @@ func getSeafarersScenarioMapByName(name string) *entities.MapDefinition {
 	case SeafarersThroughDesert:
 		return getSeafarersThroughDesertMap()
 	case SeafarersForgottenTribe:
-		key = "seafarers_forgotten_tribe"
+		return getSeafarersForgottenTribeMap()
 	case SeafarersClothForCatan:
-		key = "seafarers_cloth_for_catan"
+		return getSeafarersClothForCatanMap()
 	case SeafarersPirateIslands:
-		key = "seafarers_pirate_islands"
+		return getSeafarersPirateIslandsMap()
 	case SeafarersWondersOfCatan:
-		key = "seafarers_wonders_of_catan"
+		return getSeafarersWondersOfCatanMap()
 	case SeafarersNewWorldVariant:
-		key = "seafarers_new_world_variant"
+		// Every game rolls its own archipelago, this one stands in for it
+		// in the lobby
+		defn, err := GenerateNewWorldMap(DefaultNewWorldOptions(4), 1)
+		if err != nil {
+			return nil
+		}
+		return defn
 	default:
 		return nil
 	}
-
-	// Phase-1 placeholder: use a valid Seafarers layout while full scenario maps
-	// and rules are implemented incrementally.
-	defn := getSeafarersHeadingForNewShoresTemplate()
-	if defn == nil {
-		return nil
-	}
-	defn.Name = name
-	defn.Scenario = &entities.ScenarioMetadata{
-		Expansion:       "Seafarers",
-		Key:             key,
-		Title:           name,
-		Placeholder:     true,
-		VictoryRuleText: "Scenario-specific victory condition pending implementation.",
-	}
-	return defn
 }
 
-func getSeafarersHeadingForNewShoresTemplate() *entities.MapDefinition {
-	var defn entities.MapDefinition
-	if err := json.Unmarshal([]byte(seafarersHeadingForNewShores), &defn); err != nil {
-		return nil
+func getSeafarersHeadingForNewShoresExtensionMap() *entities.MapDefinition {
+	sea, land, desert := int(entities.TileTypeSea), int(entities.TileTypeRandom), int(entities.TileTypeDesert)
+
+	return &entities.MapDefinition{
+		Name:  SeafarersHeadingForNewShoresExtension,
+		Order: []bool{false, true, false, true, false, true, false, true, false},
+		Ports: []entities.PortType{
+			entities.PortTypeAny,
+			entities.PortTypeAny,
+			entities.PortTypeAny,
+			entities.PortTypeAny,
+			entities.PortTypeAny,
+			entities.PortTypeWood,
+			entities.PortTypeBrick,
+			entities.PortTypeWool,
+			entities.PortTypeWool,
+			entities.PortTypeWheat,
+			entities.PortTypeOre,
+		},
+		Numbers: []uint16{
+			2, 2, 3, 3, 3, 3, 4, 4, 4, 4, 4, 5, 5, 5, 5, 6, 6, 6, 6,
+			8, 8, 8, 8, 9, 9, 9, 9, 10, 10, 10, 10, 10, 11, 11, 11, 11, 12, 12,
+		},
+		// 28 random land tiles on the main island and 10 on the small ones
+		RandomTiles: []entities.TileType{
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeBrick,
+			entities.TileTypeBrick,
+			entities.TileTypeBrick,
+			entities.TileTypeBrick,
+			entities.TileTypeBrick,
+			entities.TileTypeBrick,
+			entities.TileTypeBrick,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeOre,
+			entities.TileTypeOre,
+			entities.TileTypeOre,
+			entities.TileTypeOre,
+			entities.TileTypeOre,
+			entities.TileTypeOre,
+			entities.TileTypeOre,
+		},
+		Map: [][]int{
+			{sea, sea, sea, sea, sea, sea, sea, sea, sea, sea, sea},
+			{sea, land, land, land, land, sea, sea, land, land, sea, sea},
+			{sea, land, land, land, land, land, sea, sea, land, sea, sea},
+			{sea, land, land, desert, land, land, land, sea, sea, sea, sea},
+			{sea, land, land, land, land, desert, land, sea, sea, land, sea},
+			{sea, land, land, land, land, land, sea, sea, land, land, sea},
+			{sea, land, land, land, land, sea, sea, sea, sea, land, sea},
+			{sea, sea, sea, sea, sea, land, land, land, sea, sea, sea},
+			{sea, sea, sea, sea, sea, sea, sea, sea, sea, sea, sea},
+		},
+		MaxPlayers: 6,
+		Scenario: &entities.ScenarioMetadata{
+			Expansion:       "Seafarers",
+			Key:             "seafarers_heading_for_new_shores_extension",
+			Title:           SeafarersHeadingForNewShoresExtension,
+			Placeholder:     false,
+			VictoryPoints:   14,
+			VictoryRuleText: "If you have 14 or more VPs at any point during your turn, you win.",
+		},
 	}
-	return &defn
 }
 
 func getSeafarersFourIslandsMap() *entities.MapDefinition {
@@ -210,6 +272,238 @@ func getSeafarersThroughDesertMap() *entities.MapDefinition {
 	}
 }
 
+func getSeafarersForgottenTribeMap() *entities.MapDefinition {
+	return &entities.MapDefinition{
+		Name:  SeafarersForgottenTribe,
+		Order: []bool{false, true, false, true, false, true, false},
+		// No harbors at the start, the forgotten tribe hands them out.
+		Ports:   []entities.PortType{},
+		Numbers: []uint16{2, 3, 4, 5, 6, 8, 9, 10, 11, 12},
+		// 10 random land tiles on the main island. The deserts around it are
+		// the islands of the forgotten tribe, which nobody may settle.
+		RandomTiles: []entities.TileType{
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeBrick,
+			entities.TileTypeBrick,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeOre,
+			entities.TileTypeOre,
+		},
+		Map: [][]int{
+			{int(entities.TileTypeNone), int(entities.TileTypeDesert), int(entities.TileTypeSea), int(entities.TileTypeSea), int(entities.TileTypeDesert), int(entities.TileTypeSea), int(entities.TileTypeNone)},
+			{int(entities.TileTypeDesert), int(entities.TileTypeSea), int(entities.TileTypeSea), int(entities.TileTypeSea), int(entities.TileTypeSea), int(entities.TileTypeSea), int(entities.TileTypeDesert)},
+			{int(entities.TileTypeSea), int(entities.TileTypeSea), int(entities.TileTypeRandom), int(entities.TileTypeRandom), int(entities.TileTypeRandom), int(entities.TileTypeSea), int(entities.TileTypeSea)},
+			{int(entities.TileTypeDesert), int(entities.TileTypeSea), int(entities.TileTypeRandom), int(entities.TileTypeRandom), int(entities.TileTypeRandom), int(entities.TileTypeRandom), int(entities.TileTypeSea)},
+			{int(entities.TileTypeSea), int(entities.TileTypeSea), int(entities.TileTypeRandom), int(entities.TileTypeRandom), int(entities.TileTypeRandom), int(entities.TileTypeSea), int(entities.TileTypeSea)},
+			{int(entities.TileTypeDesert), int(entities.TileTypeSea), int(entities.TileTypeSea), int(entities.TileTypeSea), int(entities.TileTypeSea), int(entities.TileTypeSea), int(entities.TileTypeDesert)},
+			{int(entities.TileTypeNone), int(entities.TileTypeDesert), int(entities.TileTypeSea), int(entities.TileTypeSea), int(entities.TileTypeDesert), int(entities.TileTypeSea), int(entities.TileTypeNone)},
+		},
+		Scenario: &entities.ScenarioMetadata{
+			Expansion:       "Seafarers",
+			Key:             "seafarers_forgotten_tribe",
+			Title:           SeafarersForgottenTribe,
+			Placeholder:     false,
+			VictoryPoints:   13,
+			VictoryRuleText: "If you have 13 or more VPs at any point during your turn, you win.",
+		},
+	}
+}
+
+func getSeafarersClothForCatanMap() *entities.MapDefinition {
+	none, sea, land, village := int(entities.TileTypeNone), int(entities.TileTypeSea), int(entities.TileTypeRandom), int(entities.TileTypeDesert)
+
+	return &entities.MapDefinition{
+		Name:  SeafarersClothForCatan,
+		Order: []bool{false, true, false, true, false, true, false, true, false, true, false},
+		Ports: []entities.PortType{
+			entities.PortTypeAny,
+			entities.PortTypeAny,
+			entities.PortTypeAny,
+			entities.PortTypeWood,
+			entities.PortTypeBrick,
+			entities.PortTypeWool,
+			entities.PortTypeWheat,
+			entities.PortTypeOre,
+		},
+		Numbers: []uint16{2, 3, 3, 4, 4, 5, 5, 6, 6, 8, 8, 9, 9, 10, 10, 11, 11, 12},
+		// 18 random land tiles split between the two main islands
+		RandomTiles: []entities.TileType{
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeBrick,
+			entities.TileTypeBrick,
+			entities.TileTypeBrick,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeOre,
+			entities.TileTypeOre,
+			entities.TileTypeOre,
+		},
+		// Every desert is a village, in two rows between the main islands
+		Map: [][]int{
+			{none, sea, sea, sea, sea, sea, sea, sea, none},
+			{sea, sea, land, land, land, land, land, sea, sea},
+			{sea, sea, land, land, land, land, sea, sea, sea},
+			{sea, sea, sea, sea, sea, sea, sea, sea, sea},
+			{village, sea, village, sea, village, sea, village, sea, village},
+			{sea, sea, sea, sea, sea, sea, sea, sea, sea},
+			{village, sea, village, sea, village, sea, village, sea, village},
+			{sea, sea, sea, sea, sea, sea, sea, sea, sea},
+			{sea, sea, sea, land, land, land, land, sea, sea},
+			{sea, sea, land, land, land, land, land, sea, sea},
+			{none, sea, sea, sea, sea, sea, sea, sea, none},
+		},
+		Scenario: &entities.ScenarioMetadata{
+			Expansion:       "Seafarers",
+			Key:             "seafarers_cloth_for_catan",
+			Title:           SeafarersClothForCatan,
+			Placeholder:     false,
+			VictoryPoints:   14,
+			VictoryRuleText: "If you have 14 or more VPs at any point during your turn, you win. Once 3 villages run out of cloth, the player with the most VPs wins.",
+		},
+	}
+}
+
+func getSeafarersPirateIslandsMap() *entities.MapDefinition {
+	sea, land, fortress := int(entities.TileTypeSea), int(entities.TileTypeRandom), int(entities.TileTypeDesert)
+
+	return &entities.MapDefinition{
+		Name:  SeafarersPirateIslands,
+		Order: []bool{false, true, false, true, false, true, false},
+		Ports: []entities.PortType{
+			entities.PortTypeAny,
+			entities.PortTypeAny,
+			entities.PortTypeWood,
+			entities.PortTypeBrick,
+			entities.PortTypeWool,
+			entities.PortTypeWheat,
+			entities.PortTypeOre,
+		},
+		Numbers: []uint16{2, 3, 3, 4, 4, 5, 5, 6, 6, 8, 8, 9, 9, 10, 10, 11, 11, 12},
+		// 18 random land tiles on the main island
+		RandomTiles: []entities.TileType{
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeBrick,
+			entities.TileTypeBrick,
+			entities.TileTypeBrick,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeOre,
+			entities.TileTypeOre,
+			entities.TileTypeOre,
+		},
+		// The deserts on the right are the islands of the pirate fortresses,
+		// one for each player. The pirate fleet sails around the main island.
+		Map: [][]int{
+			{sea, sea, sea, sea, sea, sea, sea, fortress},
+			{sea, land, land, land, sea, sea, sea, sea},
+			{sea, land, land, land, land, sea, sea, fortress},
+			{sea, land, land, land, land, sea, sea, sea},
+			{sea, land, land, land, land, sea, sea, fortress},
+			{sea, land, land, land, sea, sea, sea, sea},
+			{sea, sea, sea, sea, sea, sea, sea, fortress},
+		},
+		Scenario: &entities.ScenarioMetadata{
+			Expansion:       "Seafarers",
+			Key:             "seafarers_pirate_islands",
+			Title:           SeafarersPirateIslands,
+			Placeholder:     false,
+			VictoryPoints:   10,
+			VictoryRuleText: "If you have 10 or more VPs at any point during your turn and have conquered your pirate fortress, you win.",
+		},
+	}
+}
+
+func getSeafarersWondersOfCatanMap() *entities.MapDefinition {
+	sea, land, desert := int(entities.TileTypeSea), int(entities.TileTypeRandom), int(entities.TileTypeDesert)
+
+	return &entities.MapDefinition{
+		Name:  SeafarersWondersOfCatan,
+		Order: []bool{false, true, false, true, false, true, false, true, false},
+		Ports: []entities.PortType{
+			entities.PortTypeAny,
+			entities.PortTypeAny,
+			entities.PortTypeAny,
+			entities.PortTypeWood,
+			entities.PortTypeBrick,
+			entities.PortTypeWool,
+			entities.PortTypeWheat,
+			entities.PortTypeOre,
+		},
+		Numbers: []uint16{2, 3, 3, 3, 4, 4, 4, 5, 5, 5, 6, 6, 6, 8, 8, 8, 9, 9, 9, 10, 10, 10, 11, 11, 12},
+		// 19 random land tiles on the main island and 6 on the small ones
+		RandomTiles: []entities.TileType{
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeWood,
+			entities.TileTypeBrick,
+			entities.TileTypeBrick,
+			entities.TileTypeBrick,
+			entities.TileTypeBrick,
+			entities.TileTypeBrick,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWool,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeWheat,
+			entities.TileTypeOre,
+			entities.TileTypeOre,
+			entities.TileTypeOre,
+			entities.TileTypeOre,
+			entities.TileTypeOre,
+		},
+		// The Great Wall is built next to the deserts of the main island,
+		// the Theater on the small islands
+		Map: [][]int{
+			{sea, sea, sea, sea, sea, sea, sea, sea, sea},
+			{sea, land, land, land, sea, sea, sea, land, sea},
+			{sea, land, land, land, land, sea, sea, land, sea},
+			{sea, land, desert, land, land, land, sea, sea, sea},
+			{sea, land, land, land, desert, land, sea, sea, sea},
+			{sea, land, land, land, land, sea, sea, land, sea},
+			{sea, sea, sea, sea, sea, sea, sea, land, sea},
+			{sea, sea, land, land, sea, sea, sea, sea, sea},
+			{sea, sea, sea, sea, sea, sea, sea, sea, sea},
+		},
+		Scenario: &entities.ScenarioMetadata{
+			Expansion:       "Seafarers",
+			Key:             "seafarers_wonders_of_catan",
+			Title:           SeafarersWondersOfCatan,
+			Placeholder:     false,
+			VictoryPoints:   10,
+			VictoryRuleText: "Finish your wonder to win. If you have 10 or more VPs at any point during your turn and the most stages of a wonder, you win.",
+		},
+	}
+}
+
 func GetSeafarersScenarioCatalog() []*entities.ScenarioMetadata {
 	makeMeta := func(key, title string, placeholder bool, victoryPoints int, victoryText string) *entities.ScenarioMetadata {
 		if victoryText == "" {
@@ -230,10 +524,11 @@ func GetSeafarersScenarioCatalog() []*entities.ScenarioMetadata {
 		makeMeta("seafarers_four_islands", SeafarersFourIslands, false, 13, "If you have 13 or more VPs at any point during your turn, you win."),
 		makeMeta("seafarers_fog_islands", SeafarersFogIslands, false, 12, "If you have 12 or more VPs at any point during your turn, you win."),
 		makeMeta("seafarers_through_the_desert", SeafarersThroughDesert, false, 14, "If you have 14 or more VPs at any point during your turn, you win."),
-		makeMeta("seafarers_forgotten_tribe", SeafarersForgottenTribe, true, 0, ""),
-		makeMeta("seafarers_cloth_for_catan", SeafarersClothForCatan, true, 0, ""),
-		makeMeta("seafarers_pirate_islands", SeafarersPirateIslands, true, 0, ""),
-		makeMeta("seafarers_wonders_of_catan", SeafarersWondersOfCatan, true, 0, ""),
-		makeMeta("seafarers_new_world_variant", SeafarersNewWorldVariant, true, 0, ""),
+		makeMeta("seafarers_forgotten_tribe", SeafarersForgottenTribe, false, 13, "If you have 13 or more VPs at any point during your turn, you win."),
+		makeMeta("seafarers_cloth_for_catan", SeafarersClothForCatan, false, 14, "If you have 14 or more VPs at any point during your turn, you win. Once 3 villages run out of cloth, the player with the most VPs wins."),
+		makeMeta("seafarers_pirate_islands", SeafarersPirateIslands, false, 10, "If you have 10 or more VPs at any point during your turn and have conquered your pirate fortress, you win."),
+		makeMeta("seafarers_wonders_of_catan", SeafarersWondersOfCatan, false, 10, "Finish your wonder to win. If you have 10 or more VPs at any point during your turn and the most stages of a wonder, you win."),
+		makeMeta(NewWorldScenarioKey, SeafarersNewWorldVariant, false, 12, "If you have 12 or more VPs at any point during your turn, you win."),
+		makeMeta("seafarers_heading_for_new_shores_extension", SeafarersHeadingForNewShoresExtension, false, 14, "If you have 14 or more VPs at any point during your turn, you win."),
 	}
 }
@@ -4,8 +4,8 @@ import "testing"
 
 func TestScenarioStubMapNamesCount(t *testing.T) {
 	stubs := ScenarioStubMapNames()
-	if len(stubs) != 5 {
-		t.Fatalf("expected 5 seafarers scenario stubs, got %d", len(stubs))
+	if len(stubs) != 0 {
+		t.Fatalf("expected no seafarers scenario stubs, got %d", len(stubs))
 	}
 }
 
@@ -27,8 +27,8 @@ func TestGetMapByNameSeafarersStubHasScenarioMetadata(t *testing.T) {
 
 func TestGetSeafarersScenarioCatalog(t *testing.T) {
 	catalog := GetSeafarersScenarioCatalog()
-	if len(catalog) != 9 {
-		t.Fatalf("expected 9 seafarers catalog entries, got %d", len(catalog))
+	if len(catalog) != 10 {
+		t.Fatalf("expected 10 seafarers catalog entries, got %d", len(catalog))
 	}
 
 	if catalog[0].Title != SeafarersHeadingForNewShores || catalog[0].Placeholder || catalog[0].VictoryPoints != 14 {
@@ -43,6 +43,24 @@ func TestGetSeafarersScenarioCatalog(t *testing.T) {
 	if catalog[3].Title != SeafarersThroughDesert || catalog[3].Placeholder || catalog[3].VictoryPoints != 14 {
 		t.Fatalf("expected fourth catalog entry to be non-placeholder through the desert")
 	}
+	if catalog[4].Title != SeafarersForgottenTribe || catalog[4].Placeholder || catalog[4].VictoryPoints != 13 {
+		t.Fatalf("expected fifth catalog entry to be non-placeholder forgotten tribe")
+	}
+	if catalog[5].Title != SeafarersClothForCatan || catalog[5].Placeholder || catalog[5].VictoryPoints != 14 {
+		t.Fatalf("expected sixth catalog entry to be non-placeholder cloth for catan")
+	}
+	if catalog[6].Title != SeafarersPirateIslands || catalog[6].Placeholder || catalog[6].VictoryPoints != 10 {
+		t.Fatalf("expected seventh catalog entry to be non-placeholder pirate islands")
+	}
+	if catalog[7].Title != SeafarersWondersOfCatan || catalog[7].Placeholder || catalog[7].VictoryPoints != 10 {
+		t.Fatalf("expected eighth catalog entry to be non-placeholder wonders of catan")
+	}
+	if catalog[8].Title != SeafarersNewWorldVariant || catalog[8].Placeholder || catalog[8].VictoryPoints != 12 {
+		t.Fatalf("expected ninth catalog entry to be non-placeholder new world")
+	}
+	if catalog[9].Title != SeafarersHeadingForNewShoresExtension || catalog[9].Placeholder || catalog[9].VictoryPoints != 14 {
+		t.Fatalf("expected tenth catalog entry to be non-placeholder heading for new shores for 5-6 players")
+	}
 }
 
 func TestGetMapByNameSeafarersFourIslandsIsPlayable(t *testing.T) {
@@ -84,14 +102,106 @@ func TestGetMapByNameSeafarersThroughDesertIsPlayable(t *testing.T) {
 	}
 }
 
+func TestGetMapByNameSeafarersForgottenTribeIsPlayable(t *testing.T) {
+	defn := GetMapByName(SeafarersForgottenTribe)
+	if defn == nil {
+		t.Fatal("expected forgotten tribe map")
+	}
+	if defn.Scenario == nil || defn.Scenario.Placeholder {
+		t.Fatal("expected forgotten tribe map to be non-placeholder with scenario metadata")
+	}
+	if defn.Scenario.VictoryPoints != 13 {
+		t.Fatalf("expected forgotten tribe victory points override 13, got %d", defn.Scenario.VictoryPoints)
+	}
+}
+
+func TestGetMapByNameSeafarersClothForCatanIsPlayable(t *testing.T) {
+	defn := GetMapByName(SeafarersClothForCatan)
+	if defn == nil {
+		t.Fatal("expected cloth for catan map")
+	}
+	if defn.Scenario == nil || defn.Scenario.Placeholder {
+		t.Fatal("expected cloth for catan map to be non-placeholder with scenario metadata")
+	}
+	if defn.Scenario.VictoryPoints != 14 {
+		t.Fatalf("expected cloth for catan victory points override 14, got %d", defn.Scenario.VictoryPoints)
+	}
+}
+
+func TestGetMapByNameSeafarersPirateIslandsIsPlayable(t *testing.T) {
+	defn := GetMapByName(SeafarersPirateIslands)
+	if defn == nil {
+		t.Fatal("expected pirate islands map")
+	}
+	if defn.Scenario == nil || defn.Scenario.Placeholder {
+		t.Fatal("expected pirate islands map to be non-placeholder with scenario metadata")
+	}
+	if defn.Scenario.VictoryPoints != 10 {
+		t.Fatalf("expected pirate islands victory points override 10, got %d", defn.Scenario.VictoryPoints)
+	}
+}
+
+func TestGetMapByNameSeafarersWondersOfCatanIsPlayable(t *testing.T) {
+	defn := GetMapByName(SeafarersWondersOfCatan)
+	if defn == nil {
+		t.Fatal("expected wonders of catan map")
+	}
+	if defn.Scenario == nil || defn.Scenario.Placeholder {
+		t.Fatal("expected wonders of catan map to be non-placeholder with scenario metadata")
+	}
+	if defn.Scenario.VictoryPoints != 10 {
+		t.Fatalf("expected wonders of catan victory points override 10, got %d", defn.Scenario.VictoryPoints)
+	}
+}
+
+func TestGetMapByNameSeafarersNewWorldIsPlayable(t *testing.T) {
+	defn := GetMapByName(SeafarersNewWorldVariant)
+	if defn == nil {
+		t.Fatal("expected new world map")
+	}
+	if defn.Scenario == nil || defn.Scenario.Placeholder {
+		t.Fatal("expected new world map to be non-placeholder with scenario metadata")
+	}
+	if defn.Scenario.VictoryPoints != 12 {
+		t.Fatalf("expected new world victory points override 12, got %d", defn.Scenario.VictoryPoints)
+	}
+}
+
+func TestExtensionMapsSeatSixPlayers(t *testing.T) {
+	for _, name := range []string{BaseExtensionMapName, SeafarersHeadingForNewShoresExtension, SeafarersNewWorldVariant} {
+		defn := GetMapByName(name)
+		if defn == nil {
+			t.Fatalf("expected %q map", name)
+		}
+		if defn.MaxPlayers != 6 {
+			t.Fatalf("expected %q to seat 6 players, got %d", name, defn.MaxPlayers)
+		}
+	}
+	if GetBaseMap().MaxPlayers != 0 {
+		t.Fatal("expected the base map to keep the default player limit")
+	}
+
+	defn := GetMapByName(BaseExtensionMapName)
+	if len(defn.Ports) != 11 || len(defn.PortCoordinates) != 11 {
+		t.Fatalf("expected 11 harbors on the 5-6 player board, got %d", len(defn.Ports))
+	}
+}
+
 func TestOfficialMapNamesIncludeCurrentSeafarersSet(t *testing.T) {
 	names := GetOfficialMapNames()
 	required := map[string]bool{
-		BaseMapName:                  false,
-		SeafarersHeadingForNewShores: false,
-		SeafarersFourIslands:         false,
-		SeafarersFogIslands:          false,
-		SeafarersThroughDesert:       false,
+		BaseMapName:                           false,
+		BaseExtensionMapName:                  false,
+		SeafarersHeadingForNewShores:          false,
+		SeafarersHeadingForNewShoresExtension: false,
+		SeafarersFourIslands:                  false,
+		SeafarersFogIslands:                   false,
+		SeafarersThroughDesert:                false,
+		SeafarersForgottenTribe:               false,
+		SeafarersClothForCatan:                false,
+		SeafarersPirateIslands:                false,
+		SeafarersWondersOfCatan:               false,
+		SeafarersNewWorldVariant:              false,
 	}
 
 	for _, n := range names {
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sakura/entities"
+	"sync/atomic"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Seconds a prompt or the turn of an agent waits on it before the
+// built-in AI plays the seat
+const MAX_STALLED_AGENT_SEC = 15
+
+// Agents are programs that play a seat. They connect to /agent with the
+// token and game id a player uses for /socket, and talk in JSON text
+// frames instead of msgpack. The agent gets every message the web client
+// gets, with the same keys, and sends the commands the web client sends.
+func (s *Server) agentHandler(w http.ResponseWriter, r *http.Request) {
+	gameId := r.URL.Query().Get("id")
+
+	if hub, ok := s.hubs.Load(gameId); ok {
+		StartAgent(hub.(*WsHub), w, r)
+	} else {
+		RejectWs(w, r, http.StatusNotFound, "E738: Game not found. Try refresing this page.")
+	}
+}
+
+// agentCommand turns a JSON command of an agent into the msgpack the web
+// client sends
+func agentCommand(message []byte) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(message))
+	d.UseNumber()
+
+	var msg map[string]interface{}
+	if err := d.Decode(&msg); err != nil {
+		return nil, err
+	}
+	return msgpack.Marshal(fromJSONValue(msg))
+}
+
+// fromJSONValue makes whole numbers integers, like the web client sends
+func fromJSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = fromJSONValue(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = fromJSONValue(e)
+		}
+	}
+	return v
+}
+
+// agentMessage turns a msgpack message for the web client into JSON
+func agentMessage(message []byte) ([]byte, error) {
+	d := msgpack.NewDecoder(bytes.NewReader(message))
+	d.SetMapDecoder(func(d *msgpack.Decoder) (interface{}, error) {
+		return d.DecodeUntypedMap()
+	})
+
+	v, err := d.DecodeInterface()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(toJSONValue(v))
+}
+
+// toJSONValue writes the keys of maps as strings, the only keys JSON has
+func toJSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = toJSONValue(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = toJSONValue(e)
+		}
+	}
+	return v
+}
+
+// agentActive takes the seat back from the built-in AI when the agent
+// sends something
+func (c *WsClient) agentActive() {
+	atomic.StoreInt32(&c.agentStalled, 0)
+	c.Player.ResetInactivity()
+}
+
+// checkAgents hands the seats of stalled agents to the built-in AI.
+// Expects a locked game. Returns whether any seat changed hands.
+func (h *WsHub) checkAgents(tickerPeriod int) bool {
+	changed := false
+	h.Clients.Range(func(key interface{}, value interface{}) bool {
+		c := key.(*WsClient)
+		p := c.Player
+		if !c.Agent || p.GetIsBot() {
+			return true
+		}
+
+		waiting := p.PendingAction != nil ||
+			(h.Game.CurrentPlayer == p && !h.Game.GameOver && !h.Game.Paused)
+		if !waiting {
+			atomic.StoreInt32(&c.agentStalled, 0)
+			return true
+		}
+
+		if atomic.AddInt32(&c.agentStalled, int32(tickerPeriod)) > MAX_STALLED_AGENT_SEC {
+			p.SetIsBot(true)
+			changed = true
+		}
+		return true
+	})
+	return changed
+}
+
+// sendAgentError tells the agent its command could not be read
+func (c *WsClient) sendAgentError(err error) {
+	c.Player.SendMessage(&entities.Message{
+		Type: entities.MessageTypeError,
+		Data: "invalid command: " + err.Error(),
+	})
+}
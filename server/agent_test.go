@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"reflect"
+	"sakura/entities"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestAgentCommandSendsIntegers(t *testing.T) {
+	raw, err := agentCommand([]byte(`{"l":"g","t":"ar","ar_data":{"C":{"X":2,"Y":-4},"f":0.5}}`))
+	if err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+
+	var msg map[string]interface{}
+	if err := msgpack.Unmarshal(raw, &msg); err != nil {
+		t.Fatalf("failed to decode msgpack: %v", err)
+	}
+	data := msg["ar_data"].(map[string]interface{})
+	c := data["C"].(map[string]interface{})
+	if _, ok := c["X"].(int64); !ok {
+		t.Fatalf("expected an integer coordinate, got %T", c["X"])
+	}
+	if data["f"] != 0.5 {
+		t.Fatalf("expected the fraction to stay, got %v", data["f"])
+	}
+
+	if _, err := agentCommand([]byte(`{"t":`)); err == nil {
+		t.Fatal("expected broken JSON to fail")
+	}
+}
+
+func TestAgentMessageWritesStringKeys(t *testing.T) {
+	raw, err := msgpack.Marshal(map[string]interface{}{
+		"t":    "s",
+		"data": map[int]interface{}{3: []int{1, 2}},
+	})
+	if err != nil {
+		t.Fatalf("failed to encode msgpack: %v", err)
+	}
+
+	out, err := agentMessage(raw)
+	if err != nil {
+		t.Fatalf("message failed: %v", err)
+	}
+	var msg map[string]interface{}
+	if err := json.Unmarshal(out, &msg); err != nil {
+		t.Fatalf("expected JSON, got %s", out)
+	}
+	want := map[string]interface{}{"3": []interface{}{1.0, 2.0}}
+	if !reflect.DeepEqual(msg["data"], want) {
+		t.Fatalf("unexpected data: %v", msg["data"])
+	}
+}
+
+func TestStalledAgentFallsBackToBot(t *testing.T) {
+	ws, player := newGameWsClient(t, entities.Base)
+	ws.Agent = true
+	hub := ws.Hub
+	hub.Clients.Store(ws, true)
+
+	for elapsed := 0; elapsed < MAX_STALLED_AGENT_SEC; elapsed += 5 {
+		if hub.checkAgents(5) {
+			t.Fatalf("expected the agent to keep its seat after %ds", elapsed+5)
+		}
+	}
+	if !hub.checkAgents(5) || !player.GetIsBot() {
+		t.Fatal("expected the built-in AI to take the seat of a stalled agent")
+	}
+
+	ws.agentActive()
+	if player.GetIsBot() {
+		t.Fatal("expected the agent to take its seat back")
+	}
+	if hub.checkAgents(5) {
+		t.Fatal("expected the stall count to start again")
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sakura/game"
+
+	"github.com/gorilla/mux"
+	"github.com/mitchellh/mapstructure"
+)
+
+// handleExport sends a game as an archive file to one of its players
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	var userId string
+	mapstructure.Decode(r.Context().Value(ContextKey("id")), &userId)
+
+	id := mux.Vars(r)["id"]
+	a, err := game.ExportGame(s.store, id)
+	if err != nil {
+		log.Println("export error:", id, err)
+		WriteJson(w, http.StatusNotFound, map[string]string{"error": "Game not available"})
+		return
+	}
+
+	if !isArchivePlayer(a, userId) {
+		WriteJson(w, http.StatusForbidden, map[string]string{"error": "Only players of the game can export it"})
+		return
+	}
+
+	b, err := game.EncodeArchive(a)
+	if err != nil {
+		log.Println("export error:", id, err)
+		WriteJson(w, http.StatusInternalServerError, map[string]string{"error": "Could not export game"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+id+".sakura\"")
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// handleImport stores an uploaded archive as a new game. The id is taken
+// from the gameId query parameter or generated. Archives are not signed,
+// so anyone can claim to be a player of one: the endpoint is off unless
+// ALLOW_GAME_IMPORT is set, and even then only takes archives the caller
+// plays in.
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	if os.Getenv("ALLOW_GAME_IMPORT") != "true" {
+		WriteJson(w, http.StatusForbidden, map[string]string{"error": "Importing games is disabled on this server"})
+		return
+	}
+
+	var userId string
+	mapstructure.Decode(r.Context().Value(ContextKey("id")), &userId)
+	if userId == "" {
+		WriteJson(w, http.StatusUnauthorized, map[string]string{"error": "Sign in to import a game"})
+		return
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r.Body, game.MaxArchiveSize+1))
+	if err != nil || len(b) > game.MaxArchiveSize {
+		WriteJson(w, http.StatusBadRequest, map[string]string{"error": "Invalid archive"})
+		return
+	}
+	a, err := game.DecodeArchive(b)
+	if err != nil {
+		WriteJson(w, http.StatusBadRequest, map[string]string{"error": "Invalid archive: " + err.Error()})
+		return
+	}
+	if !isArchivePlayer(a, userId) {
+		WriteJson(w, http.StatusForbidden, map[string]string{"error": "Only players of the game can import it"})
+		return
+	}
+
+	gameId := r.URL.Query().Get("gameId")
+	if gameId == "" {
+		gameId, err = GenerateRandomString(4)
+		if err != nil {
+			WriteJson(w, http.StatusInternalServerError, map[string]string{"error": "Could not generate game ID"})
+			return
+		}
+	}
+	if len(gameId) > 4 {
+		WriteJson(w, http.StatusInternalServerError, map[string]string{"error": "Game ID must be 4 characters or less"})
+		return
+	}
+	if _, ok := s.hubs.Load(gameId); ok {
+		WriteJson(w, http.StatusConflict, map[string]string{"error": "Game already exists"})
+		return
+	}
+
+	if err := game.ImportGame(s.store, a, gameId, userId); err != nil {
+		log.Println("import error:", gameId, err)
+		WriteJson(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Finished games are only viewed through the replay endpoints
+	if !a.State.GameOver {
+		s.NewWsHub(gameId)
+	}
+	WriteJson(w, http.StatusOK, map[string]string{"id": gameId})
+}
+
+// isArchivePlayer checks if the user played the archived game
+func isArchivePlayer(a *game.GameArchive, userId string) bool {
+	for _, p := range a.Players {
+		if userId != "" && p.Id == userId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sakura/game"
+	"testing"
+)
+
+func TestImportOnlyForPlayersWhenEnabled(t *testing.T) {
+	b, err := game.EncodeArchive(&game.GameArchive{
+		Version: game.ArchiveVersion,
+		State:   &game.StoreGameState{},
+		Players: []game.ArchivePlayer{{Order: 0, Id: "player-a"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to encode archive: %v", err)
+	}
+
+	s := &Server{}
+	request := func(id string) int {
+		r := httptest.NewRequest("POST", "/games/import", bytes.NewReader(b))
+		if id != "" {
+			r = r.WithContext(context.WithValue(r.Context(), ContextKey("id"), id))
+		}
+		w := httptest.NewRecorder()
+		s.handleImport(w, r)
+		return w.Code
+	}
+
+	t.Setenv("ALLOW_GAME_IMPORT", "")
+	if code := request("player-a"); code != http.StatusForbidden {
+		t.Fatalf("expected import to be off by default, got %d", code)
+	}
+
+	t.Setenv("ALLOW_GAME_IMPORT", "true")
+	if code := request(""); code != http.StatusUnauthorized {
+		t.Fatalf("expected anonymous import to be refused, got %d", code)
+	}
+	if code := request("stranger"); code != http.StatusForbidden {
+		t.Fatalf("expected others to be refused the archive, got %d", code)
+	}
+}
@@ -9,13 +9,18 @@ import (
 	"github.com/google/uuid"
 )
 
-func (hub *WsHub) StartBot() error {
+// StartBot seats a bot playing with the strategy of the level
+func (hub *WsHub) StartBot(level string) error {
 	botname := randomdata.SillyName() + "*"
 
 	if hub.terminating {
 		return errors.New("hub is terminating")
 	}
 
+	if !entities.IsBotLevel(level) {
+		return errors.New("unknown bot level")
+	}
+
 	playerNumber := hub.DisconnectOtherClients(botname, "Remove duplicate bot.")
 	if playerNumber < 0 || playerNumber >= 6 || playerNumber >= hub.Game.Settings.MaxPlayers {
 		return errors.New("too many players to add bot")
@@ -30,6 +35,7 @@ func (hub *WsHub) StartBot() error {
 	if err != nil {
 		return err
 	}
+	player.BotLevel = level
 	client.Player = player
 
 	if hub.Game.Initialized {
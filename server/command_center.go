@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 )
 
 const (
@@ -41,7 +42,7 @@ Commands: !help, !embargo, !toggle, !stats
 
 	Stats allows you to view different stats pertaining to the current game.
 
-	Available stats are: dice
+	Available stats are: dice, journal
 
 	Type "!stats [stat]" to view the stat.
 `
@@ -187,5 +188,21 @@ func processStats(cmd []string, ws *WsClient) (string, error) {
 		return output, nil
 	}
 
+	if stat == "journal" {
+		stats := g.JournalStats()
+		output := "\n\n"
+		output += fmt.Sprintf("Pending: %d\n", stats.Pending)
+		output += fmt.Sprintf("Written: %d in %d flushes\n", stats.Written, stats.Flushes)
+		output += fmt.Sprintf("Failed flushes: %d (%d in a row)\n", stats.FailedFlushes, stats.ConsecutiveFailures)
+		output += fmt.Sprintf("Last flush: %v\n", stats.LastFlushDuration)
+		if stats.Pending > 0 {
+			output += fmt.Sprintf("Oldest pending: %v\n", stats.OldestPendingAge.Round(time.Second))
+		}
+		if err := g.JournalError(); err != nil {
+			output += fmt.Sprintf("Stalled: %v\n", err)
+		}
+		return output, nil
+	}
+
 	return "", errors.New("invalid stat")
 }
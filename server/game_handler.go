@@ -26,6 +26,15 @@ func (ws *WsClient) dispatchGameCommand(msg map[string]interface{}) {
 		}
 	}
 
+	if err := ws.Hub.Game.JournalError(); err != nil {
+		switch msg["t"] {
+		case "i", "r":
+		default:
+			ws.Hub.Game.SendError(errors.New("game history cannot be saved right now, try again shortly"), ws.Player)
+			return
+		}
+	}
+
 	switch msg["t"] {
 	case "i": // Init
 		ws.sendInitMessage()
@@ -161,11 +170,13 @@ func (ws *WsClient) handleInfoRequestCommand(msg map[string]interface{}) {
 }
 
 func (ws *WsClient) sendInitMessage() {
-	// Game settings
+	// Game settings, with the prices and pieces the game plays with
 	// This MUST be the first message sent to the client
+	settings := ws.Hub.Game.Settings
+	settings.BuildRules = ws.Hub.Game.BuildRules
 	ws.Player.SendMessage(&entities.Message{
 		Type: "i-st",
-		Data: ws.Hub.Game.Settings,
+		Data: settings,
 	})
 
 	// Mapping
@@ -63,6 +63,12 @@ func (ws *WsClient) handleSeafarersBuildOrBuyCommand(msg map[string]interface{})
 		ws.handleBuildShip()
 	case "ms": // Move ship
 		ws.handleMoveShip()
+	case "af": // Attack pirate fortress
+		ws.handleAttackFortress()
+	case "wc": // Claim wonder
+		ws.handleClaimWonder(msg)
+	case "wb": // Wonder stage
+		ws.handleBuildWonder()
 	default:
 		return false
 	}
@@ -70,8 +76,8 @@ func (ws *WsClient) handleSeafarersBuildOrBuyCommand(msg map[string]interface{})
 }
 
 func (ws *WsClient) handleBuildSettlement() {
-	vertices := ws.Player.GetBuildLocationsSettlement(ws.Hub.Game.Graph, false, false)
-	if len(vertices) == 0 || (!ws.Hub.Game.IsCreativeMode() && ws.Player.CanBuild(entities.BTSettlement) != nil) {
+	vertices := ws.Hub.Game.GetBuildLocationsSettlement(ws.Player)
+	if len(vertices) == 0 || (!ws.Hub.Game.IsCreativeMode() && ws.Player.CanBuild(entities.BTSettlement, ws.Hub.Game.BuildRules) != nil) {
 		ws.Hub.Game.SendError(errors.New("nowhere to build or cannot build"), ws.Player)
 		return
 	}
@@ -85,7 +91,7 @@ func (ws *WsClient) handleBuildSettlement() {
 
 func (ws *WsClient) handleBuildCity() {
 	vertices := ws.Player.GetBuildLocationsCity(ws.Hub.Game.Graph)
-	if len(vertices) == 0 || (!ws.Hub.Game.IsCreativeMode() && ws.Player.CanBuild(entities.BTCity) != nil) {
+	if len(vertices) == 0 || (!ws.Hub.Game.IsCreativeMode() && ws.Player.CanBuild(entities.BTCity, ws.Hub.Game.BuildRules) != nil) {
 		ws.Hub.Game.SendError(errors.New("nowhere to build or cannot build"), ws.Player)
 		return
 	}
@@ -99,7 +105,7 @@ func (ws *WsClient) handleBuildCity() {
 
 func (ws *WsClient) handleBuildRoad() {
 	edges := ws.Player.GetBuildLocationsRoad(ws.Hub.Game.Graph, false)
-	if len(edges) == 0 || (!ws.Hub.Game.IsCreativeMode() && ws.Player.CanBuild(entities.BTRoad) != nil) {
+	if len(edges) == 0 || (!ws.Hub.Game.IsCreativeMode() && ws.Player.CanBuild(entities.BTRoad, ws.Hub.Game.BuildRules) != nil) {
 		ws.Hub.Game.SendError(errors.New("nowhere to build or cannot build"), ws.Player)
 		return
 	}
@@ -112,23 +118,23 @@ func (ws *WsClient) handleBuildRoad() {
 }
 
 func (ws *WsClient) handleBuyDevelopmentCard() {
-	if ws.Hub.Game.Mode == entities.CitiesAndKnights {
+	if ws.Hub.Game.Mode.HasCitiesAndKnights() {
 		return
 	}
 	ws.Hub.Game.SendError(ws.Hub.Game.BuyDevelopmentCard(ws.Player), ws.Player)
 }
 
 func (ws *WsClient) handleBuildKnight() {
-	if ws.Hub.Game.Mode != entities.CitiesAndKnights {
+	if !ws.Hub.Game.Mode.HasCitiesAndKnights() {
 		return
 	}
 
 	vertices := ws.Player.GetBuildLocationsKnight(ws.Hub.Game.Graph, true)
 	if len(vertices) == 0 ||
 		(!ws.Hub.Game.IsCreativeMode() &&
-			ws.Player.CanBuild(entities.BTKnight1) != nil &&
-			ws.Player.CanBuild(entities.BTKnight2) != nil &&
-			ws.Player.CanBuild(entities.BTKnight3) != nil) {
+			ws.Player.CanBuild(entities.BTKnight1, ws.Hub.Game.BuildRules) != nil &&
+			ws.Player.CanBuild(entities.BTKnight2, ws.Hub.Game.BuildRules) != nil &&
+			ws.Player.CanBuild(entities.BTKnight3, ws.Hub.Game.BuildRules) != nil) {
 		ws.Hub.Game.SendError(errors.New("nowhere to build or cannot build"), ws.Player)
 		return
 	}
@@ -141,7 +147,7 @@ func (ws *WsClient) handleBuildKnight() {
 }
 
 func (ws *WsClient) handleActivateKnight() {
-	if ws.Hub.Game.Mode != entities.CitiesAndKnights {
+	if !ws.Hub.Game.Mode.HasCitiesAndKnights() {
 		return
 	}
 
@@ -159,21 +165,21 @@ func (ws *WsClient) handleActivateKnight() {
 }
 
 func (ws *WsClient) handleKnightRobber() {
-	if ws.Hub.Game.Mode != entities.CitiesAndKnights {
+	if !ws.Hub.Game.Mode.HasCitiesAndKnights() {
 		return
 	}
 	ws.Hub.Game.SendError(ws.Hub.Game.KnightChaseRobber(ws.Player, false), ws.Player)
 }
 
 func (ws *WsClient) handleKnightMove() {
-	if ws.Hub.Game.Mode != entities.CitiesAndKnights {
+	if !ws.Hub.Game.Mode.HasCitiesAndKnights() {
 		return
 	}
 	ws.Hub.Game.SendError(ws.Hub.Game.KnightMove(ws.Player, false), ws.Player)
 }
 
 func (ws *WsClient) handleCityImprovement(msg map[string]interface{}) {
-	if ws.Hub.Game.Mode != entities.CitiesAndKnights {
+	if !ws.Hub.Game.Mode.HasCitiesAndKnights() {
 		return
 	}
 
@@ -186,12 +192,12 @@ func (ws *WsClient) handleCityImprovement(msg map[string]interface{}) {
 }
 
 func (ws *WsClient) handleBuildWall() {
-	if ws.Hub.Game.Mode != entities.CitiesAndKnights {
+	if !ws.Hub.Game.Mode.HasCitiesAndKnights() {
 		return
 	}
 
 	vertices := ws.Player.GetBuildLocationsWall(ws.Hub.Game.Graph)
-	if len(vertices) == 0 || (!ws.Hub.Game.IsCreativeMode() && ws.Player.CanBuild(entities.BTWall) != nil) {
+	if len(vertices) == 0 || (!ws.Hub.Game.IsCreativeMode() && ws.Player.CanBuild(entities.BTWall, ws.Hub.Game.BuildRules) != nil) {
 		ws.Hub.Game.SendError(errors.New("nowhere to build or cannot build"), ws.Player)
 		return
 	}
@@ -204,12 +210,12 @@ func (ws *WsClient) handleBuildWall() {
 }
 
 func (ws *WsClient) handleBuildShip() {
-	if ws.Hub.Game.Mode != entities.Seafarers {
+	if !ws.Hub.Game.Mode.HasSeafarers() {
 		return
 	}
 
 	edges := ws.Player.GetBuildLocationsShip(ws.Hub.Game.Graph)
-	if len(edges) == 0 || (!ws.Hub.Game.IsCreativeMode() && ws.Player.CanBuild(entities.BTShip) != nil) {
+	if len(edges) == 0 || (!ws.Hub.Game.IsCreativeMode() && ws.Player.CanBuild(entities.BTShip, ws.Hub.Game.BuildRules) != nil) {
 		ws.Hub.Game.SendError(errors.New("nowhere to build or cannot build"), ws.Player)
 		return
 	}
@@ -222,12 +228,39 @@ func (ws *WsClient) handleBuildShip() {
 }
 
 func (ws *WsClient) handleMoveShip() {
-	if ws.Hub.Game.Mode != entities.Seafarers {
+	if !ws.Hub.Game.Mode.HasSeafarers() {
 		return
 	}
 	ws.Hub.Game.SendError(ws.Hub.Game.MoveShipInteractive(ws.Player), ws.Player)
 }
 
+func (ws *WsClient) handleAttackFortress() {
+	if !ws.Hub.Game.Mode.HasSeafarers() {
+		return
+	}
+	ws.Hub.Game.SendError(ws.Hub.Game.AttackPirateFortress(ws.Player), ws.Player)
+}
+
+func (ws *WsClient) handleClaimWonder(msg map[string]interface{}) {
+	if !ws.Hub.Game.Mode.HasSeafarers() {
+		return
+	}
+
+	var wt entities.WonderType
+	if err := mapstructure.Decode(msg["wt"], &wt); err != nil {
+		ws.Hub.Game.SendError(err, ws.Player)
+		return
+	}
+	ws.Hub.Game.SendError(ws.Hub.Game.ClaimWonder(ws.Player, wt), ws.Player)
+}
+
+func (ws *WsClient) handleBuildWonder() {
+	if !ws.Hub.Game.Mode.HasSeafarers() {
+		return
+	}
+	ws.Hub.Game.SendError(ws.Hub.Game.BuildWonderStage(ws.Player), ws.Player)
+}
+
 func (ws *WsClient) handleUseDevelopmentCard(msg map[string]interface{}) {
 	var dcType entities.DevelopmentCardType
 	if err := mapstructure.Decode(msg["dct"], &dcType); err != nil {
@@ -67,9 +67,15 @@ func (s *testGameStore) WriteGameState(id string, state []byte) error {
 func (s *testGameStore) WriteGameIdForUser(gameId, userId string, settings *entities.GameSettings) error {
 	return nil
 }
+func (s *testGameStore) WriteGameSnapshot(id string, snapshot []byte) error {
+	return nil
+}
 func (s *testGameStore) ReadJournal(id string) ([][]byte, error) {
 	return nil, nil
 }
+func (s *testGameStore) ReadGameSnapshot(id string) ([]byte, error) {
+	return nil, nil
+}
 func (s *testGameStore) ReadGamePlayers(id string) (int, error) {
 	return 0, errors.New("not found")
 }
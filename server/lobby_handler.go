@@ -4,6 +4,7 @@ import (
 	"log"
 	"math/rand"
 	"sakura/entities"
+	"sakura/game"
 	"sakura/maps"
 	"sort"
 	"sync/atomic"
@@ -53,7 +54,7 @@ func (ws *WsClient) handleLobby(msg map[string]interface{}) {
 			return
 		}
 		for i := 0; i < 3; i++ {
-			ws.Hub.StartBot()
+			ws.Hub.StartBot(entities.BotLevelNormal)
 		}
 		ws.Hub.Game.Settings.Private = false
 		ws.Hub.Game.Settings.EnableKarma = false
@@ -122,7 +123,12 @@ func (ws *WsClient) handleLobby(msg map[string]interface{}) {
 			return
 		}
 
-		err := ws.Hub.StartBot()
+		level := entities.BotLevelNormal
+		if msg["level"] != nil {
+			mapstructure.Decode(msg["level"], &level)
+		}
+
+		err := ws.Hub.StartBot(level)
 		if err != nil {
 			ws.sendLobbyMessage(&entities.Message{
 				Type: entities.MessageTypeError,
@@ -188,6 +194,14 @@ func (ws *WsClient) handleLobby(msg map[string]interface{}) {
 			return
 		}
 
+		if err := game.ValidatePlayerCount(ws.Hub.Game.Settings, uint16(numPlayers)); err != nil {
+			ws.sendLobbyMessage(&entities.Message{
+				Type: entities.MessageTypeError,
+				Data: err.Error(),
+			})
+			return
+		}
+
 		gameId := ws.Hub.Game.ID
 		if p, err := ws.Hub.Game.Store.ReadGamePlayers(gameId); err == nil && p > 0 {
 			if numPlayers > int32(p) {
@@ -244,6 +258,9 @@ func startGame(gameId string, numPlayers int32, hub *WsHub) {
 		for i, cp := range clientPlayers {
 			hub.Game.SetUsername(hub.Game.Players[playerOrder[i]], cp.Username)
 			hub.Game.SetId(hub.Game.Players[playerOrder[i]], cp.Id)
+			if cp.BotLevel != "" {
+				hub.Game.SetBotLevel(hub.Game.Players[playerOrder[i]], cp.BotLevel)
+			}
 			cp.Order = uint16(playerOrder[i])
 			hub.Game.Store.WriteGameIdForUser(gameId, cp.Id, &hub.Game.Settings)
 		}
@@ -305,6 +322,7 @@ func (h *WsHub) GetLobbyPlayersMessage() *entities.Message {
 			Ready:         c.Ready,
 			GamesStarted:  c.GamesStarted,
 			GamesFinished: c.GamesFinished,
+			BotLevel:      p.BotLevel,
 		})
 		return true
 	})
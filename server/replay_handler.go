@@ -0,0 +1,287 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sakura/entities"
+	"sakura/game"
+	"strconv"
+	"time"
+
+	"github.com/Pallinder/go-randomdata"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/mitchellh/mapstructure"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	// Time a replay socket may stay open without a request
+	replayIdleTimeout = 10 * time.Minute
+)
+
+// ReplaySeek is a request to move a replay to another position
+type ReplaySeek struct {
+	// One of entry, turn or roll
+	By      string `msgpack:"by"`
+	N       int    `msgpack:"n"`
+	Secrets bool   `msgpack:"s"`
+}
+
+// ForkRequest creates a new game from the history of another one. The
+// fork point is given with Entry or Turn and defaults to the end.
+type ForkRequest struct {
+	GameId string            `json:"gameId"`
+	Entry  *int              `json:"entry"`
+	Turn   *int              `json:"turn"`
+	Seats  []ForkSeatRequest `json:"seats"`
+}
+
+// ForkSeatRequest fills one seat of a fork with a user or a new bot.
+// An empty seat keeps the player of the parent game.
+type ForkSeatRequest struct {
+	Id  string `json:"id"`
+	Bot bool   `json:"bot"`
+}
+
+var errReplaySecrets = errors.New("secret states are only available once the game is over")
+
+// canWatchReplay checks that the game is over or the user played in it
+func canWatchReplay(replay *game.Replay, r *http.Request) bool {
+	var userId string
+	mapstructure.Decode(r.Context().Value(ContextKey("id")), &userId)
+	return replay.Finished() || replay.HasPlayer(userId)
+}
+
+// seekReplay moves the replay as asked and builds the frame there
+func seekReplay(replay *game.Replay, req ReplaySeek) (*game.ReplayFrame, error) {
+	if req.Secrets && !replay.Finished() {
+		return nil, errReplaySecrets
+	}
+
+	var err error
+	switch req.By {
+	case "", "entry":
+		err = replay.Seek(req.N)
+	case "turn":
+		err = replay.SeekTurn(req.N)
+	case "roll":
+		err = replay.SeekRoll(req.N)
+	default:
+		err = fmt.Errorf("unknown seek type %s", req.By)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return replay.Frame(req.Secrets), nil
+}
+
+// handleReplay serves a single frame of a stored game. The position is
+// given with one of entry, turn or roll and defaults to the end.
+func (s *Server) handleReplay(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	replay, err := game.NewReplay(s.store, id)
+	if err != nil {
+		log.Println("replay load error:", id, err)
+		WriteJson(w, http.StatusNotFound, map[string]string{"error": "Replay not available"})
+		return
+	}
+	if !canWatchReplay(replay, r) {
+		WriteJson(w, http.StatusForbidden, map[string]string{"error": "Only players can watch a game before it is over"})
+		return
+	}
+
+	q := r.URL.Query()
+	req := ReplaySeek{By: "entry", N: replay.Len()}
+	for _, by := range []string{"entry", "turn", "roll"} {
+		if v := q.Get(by); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				WriteJson(w, http.StatusBadRequest, map[string]string{"error": "Invalid " + by})
+				return
+			}
+			req = ReplaySeek{By: by, N: n}
+			break
+		}
+	}
+	req.Secrets = q.Get("secrets") == "1" || q.Get("secrets") == "true"
+
+	frame, err := seekReplay(replay, req)
+	if err == errReplaySecrets {
+		WriteJson(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		WriteJson(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	WriteMsgpack(w, http.StatusOK, frame)
+}
+
+// replaySocketHandler steps through a stored game over a websocket. The
+// first frame is the start of the game, after which every ReplaySeek
+// is answered with a frame or an error.
+func (s *Server) replaySocketHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	replay, err := game.NewReplay(s.store, id)
+	if err != nil {
+		log.Println("replay load error:", id, err)
+		RejectWs(w, r, http.StatusNotFound, "E750: Replay not available.")
+		return
+	}
+	if !canWatchReplay(replay, r) {
+		RejectWs(w, r, http.StatusForbidden, "E751: Only players can watch a game before it is over.")
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(maxMessageSize)
+
+	send := func(msg entities.Message) bool {
+		serialized, err := msgpack.Marshal(msg)
+		if err != nil {
+			log.Println("replay encode error:", id, err)
+			return false
+		}
+		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		return conn.WriteMessage(websocket.BinaryMessage, serialized) == nil
+	}
+
+	if !send(entities.Message{Type: entities.MessageTypeReplayFrame, Data: replay.Frame(false)}) {
+		return
+	}
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(replayIdleTimeout))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req ReplaySeek
+		if err := msgpack.Unmarshal(message, &req); err != nil {
+			if !send(entities.Message{Type: entities.MessageTypeError, Data: "invalid replay request"}) {
+				return
+			}
+			continue
+		}
+
+		frame, err := seekReplay(replay, req)
+		if err != nil {
+			if !send(entities.Message{Type: entities.MessageTypeError, Data: err.Error()}) {
+				return
+			}
+			continue
+		}
+		if !send(entities.Message{Type: entities.MessageTypeReplayFrame, Data: frame}) {
+			return
+		}
+	}
+}
+
+// handleFork stores a new game whose history is a prefix of another game
+// and starts a hub for it. Only players of the parent game can fork it.
+func (s *Server) handleFork(w http.ResponseWriter, r *http.Request) {
+	var userId string
+	mapstructure.Decode(r.Context().Value(ContextKey("id")), &userId)
+	if userId == "" {
+		WriteJson(w, http.StatusNotFound, map[string]string{"error": "User not found"})
+		return
+	}
+
+	var req ForkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJson(w, http.StatusBadRequest, map[string]string{"error": "Invalid fork request"})
+		return
+	}
+
+	parentId := mux.Vars(r)["id"]
+	replay, err := game.NewReplay(s.store, parentId)
+	if err != nil {
+		log.Println("fork load error:", parentId, err)
+		WriteJson(w, http.StatusNotFound, map[string]string{"error": "Game not available"})
+		return
+	}
+
+	if err := replay.Seek(replay.Len()); err != nil {
+		WriteJson(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	isPlayer := false
+	for _, p := range replay.Game().Players {
+		if p.Id == userId {
+			isPlayer = true
+		}
+	}
+	if !isPlayer {
+		WriteJson(w, http.StatusForbidden, map[string]string{"error": "Only players of the game can fork it"})
+		return
+	}
+
+	switch {
+	case req.Entry != nil:
+		err = replay.Seek(*req.Entry)
+	case req.Turn != nil:
+		err = replay.SeekTurn(*req.Turn)
+	}
+	if err != nil {
+		WriteJson(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	seats := make([]game.ForkSeat, len(req.Seats))
+	for i, seat := range req.Seats {
+		switch {
+		case seat.Bot:
+			seats[i] = game.ForkSeat{Id: uuid.New().String(), Username: randomdata.SillyName() + "*"}
+		case seat.Id != "":
+			user, err := s.store.ReadUser(seat.Id)
+			var username string
+			if err == nil {
+				mapstructure.Decode(user["username"], &username)
+			}
+			if username == "" {
+				WriteJson(w, http.StatusBadRequest, map[string]string{"error": "User not found for seat " + strconv.Itoa(i+1)})
+				return
+			}
+			seats[i] = game.ForkSeat{Id: seat.Id, Username: username}
+		}
+	}
+
+	gameId := req.GameId
+	if gameId == "" {
+		gameId, err = GenerateRandomString(4)
+		if err != nil {
+			WriteJson(w, http.StatusInternalServerError, map[string]string{"error": "Could not generate game ID"})
+			return
+		}
+	}
+	if len(gameId) > 4 {
+		WriteJson(w, http.StatusInternalServerError, map[string]string{"error": "Game ID must be 4 characters or less"})
+		return
+	}
+	if _, ok := s.hubs.Load(gameId); ok {
+		WriteJson(w, http.StatusConflict, map[string]string{"error": "Game already exists"})
+		return
+	}
+
+	if err := replay.Fork(s.store, gameId, seats); err != nil {
+		log.Println("fork error:", parentId, gameId, err)
+		WriteJson(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+
+	s.NewWsHub(gameId)
+	WriteJson(w, http.StatusOK, map[string]string{"id": gameId})
+}
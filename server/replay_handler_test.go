@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"net/http/httptest"
+	"sakura/entities"
+	"sakura/game"
+	"sakura/maps"
+	"testing"
+)
+
+func TestUnfinishedReplayOnlyForPlayers(t *testing.T) {
+	settings := entities.GameSettings{
+		Mode:          entities.Base,
+		MapName:       maps.BaseMapName,
+		MapDefn:       maps.GetBaseMap(),
+		VictoryPoints: 10,
+		Speed:         entities.NormalSpeed,
+	}
+	e, err := game.NewEngine(settings, 3, []string{"a*", "b*", "c*"})
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	if _, err := e.Do(0, func(g *game.Game, p *entities.Player) error {
+		g.SetId(p, "player-a")
+		return nil
+	}); err != nil {
+		t.Fatalf("set id failed: %v", err)
+	}
+	if _, err := e.Start(); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+
+	replay, err := game.NewReplay(e.Store(), game.EngineGameID)
+	if err != nil {
+		t.Fatalf("failed to load replay: %v", err)
+	}
+	request := func(id string) bool {
+		r := httptest.NewRequest("GET", "/replay", nil)
+		if id != "" {
+			r = r.WithContext(context.WithValue(r.Context(), ContextKey("id"), id))
+		}
+		return canWatchReplay(replay, r)
+	}
+
+	if !request("player-a") {
+		t.Fatal("expected a player to watch their running game")
+	}
+	if request("stranger") || request("") {
+		t.Fatal("expected others to be refused a running game")
+	}
+
+	if err := replay.Seek(replay.Len()); err != nil {
+		t.Fatalf("seek failed: %v", err)
+	}
+	frame, err := seekReplay(replay, ReplaySeek{By: "entry", N: replay.Len()})
+	if err != nil {
+		t.Fatalf("seek replay failed: %v", err)
+	}
+	if frame.Entry != nil || frame.EntryName == "" {
+		t.Fatal("expected only the name of the entry without secrets")
+	}
+	if _, err := seekReplay(replay, ReplaySeek{Secrets: true}); err != errReplaySecrets {
+		t.Fatalf("expected secrets to be refused, got %v", err)
+	}
+}
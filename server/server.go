@@ -3,7 +3,7 @@ package server
 import (
 	"encoding/json"
 	"fmt"
-	"sakura/mango"
+	"sakura/game"
 	"log"
 	"net/http"
 	"os"
@@ -32,8 +32,15 @@ type (
 		UpdateEmail(string, string) error
 		CountUsers() (int64, error)
 	}
+
+	// GameCleaner is implemented by stores that can drop abandoned games
+	GameCleaner interface {
+		CleanupInactiveGames() (int64, int64, error)
+	}
+
 	Server struct {
 		hubs     sync.Map
+		store    game.Store
 		registry Registry
 	}
 
@@ -43,9 +50,10 @@ type (
 	}
 )
 
-func NewServer() *Server {
+func NewServer(store game.Store, registry Registry) *Server {
 	server := &Server{}
-	server.registry = &mango.MangoRegistry{}
+	server.store = store
+	server.registry = registry
 	server.registry.Init()
 	return server
 }
@@ -55,6 +63,12 @@ func (s *Server) Run() {
 
 	r.HandleFunc("/heartbeat", s.handleHeartbeat).Methods("GET")
 	r.HandleFunc("/socket", s.socketHandler)
+	r.HandleFunc("/agent", s.agentHandler)
+	r.HandleFunc("/replay", s.replaySocketHandler)
+	r.HandleFunc("/games/{id}/replay", s.handleReplay).Methods("GET")
+	r.HandleFunc("/games/{id}/fork", s.handleFork).Methods("POST")
+	r.HandleFunc("/games/{id}/export", s.handleExport).Methods("GET")
+	r.HandleFunc("/games/import", s.handleImport).Methods("POST")
 	r.HandleFunc("/games", s.handleGame).Methods("GET", "POST")
 	r.HandleFunc("/anon", s.getAnonymousJWT).Methods("GET", "POST")
 	r.HandleFunc("/verify", s.verifyUser).Methods("GET")
@@ -324,8 +338,8 @@ func (s *Server) RemoveHub(id string) {
 	s.hubs.Delete(id)
 }
 
-func RunServer() {
-	server := NewServer()
+func RunServer(store game.Store, registry Registry) {
+	server := NewServer(store, registry)
 	err := server.registry.Register(os.Getenv("SERVER_URL"), os.Getenv("AWS_REGION"))
 	if err != nil {
 		panic(err)
@@ -333,16 +347,20 @@ func RunServer() {
 
 	ticker := time.NewTicker(10 * time.Second)
 	cleanupTicker := time.NewTicker(30 * time.Second)
-	cleanupStore := &mango.MangoStore{}
 	go func(ticker *time.Ticker) {
 		for {
 			<-ticker.C
 			server.registry.Heartbeat(os.Getenv("SERVER_URL"))
 		}
 	}(ticker)
+	cleanupStore, canCleanup := store.(GameCleaner)
 	go func(ticker *time.Ticker) {
 		for {
 			<-ticker.C
+			if !canCleanup {
+				ticker.Stop()
+				return
+			}
 			deletedPrestart, deletedPlaying, err := cleanupStore.CleanupInactiveGames()
 			if err != nil {
 				log.Println("cleanup error:", err)
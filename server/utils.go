@@ -3,6 +3,8 @@ package server
 import (
 	"encoding/json"
 	"net/http"
+
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 func WriteJson(w http.ResponseWriter, status int, data interface{}) {
@@ -16,3 +18,15 @@ func WriteJson(w http.ResponseWriter, status int, data interface{}) {
 	w.WriteHeader(status)
 	w.Write(jsonData)
 }
+
+func WriteMsgpack(w http.ResponseWriter, status int, data interface{}) {
+	msgpackData, err := msgpack.Marshal(data)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/msgpack")
+	w.WriteHeader(status)
+	w.Write(msgpackData)
+}
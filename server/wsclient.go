@@ -62,6 +62,11 @@ type WsClient struct {
 
 	// Chat Toggle
 	ChatEnabled bool
+
+	// Program playing the seat over JSON, and the seconds it has kept a
+	// prompt or its turn waiting
+	Agent        bool
+	agentStalled int32
 }
 
 // ReadPump pumps messages from the websocket connection to the hub.
@@ -77,7 +82,12 @@ func (c *WsClient) ReadPump() {
 	c.Conn.SetReadLimit(maxMessageSize)
 	c.Conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.Conn.SetPongHandler(func(string) error {
-		c.Player.ResetInactivity()
+		// A stalled agent only gets its seat back by sending something
+		if c.Agent {
+			atomic.StoreInt32(&c.Player.InactiveSeconds, 0)
+		} else {
+			c.Player.ResetInactivity()
+		}
 		c.Conn.SetReadDeadline(time.Now().Add(pongWait))
 		return nil
 	})
@@ -89,6 +99,13 @@ func (c *WsClient) ReadPump() {
 			}
 			break
 		}
+		if c.Agent {
+			if message, err = agentCommand(message); err != nil {
+				c.sendAgentError(err)
+				continue
+			}
+			c.agentActive()
+		}
 		atomic.AddInt32(&c.Hub.activity, 1)
 		c.Player.ResetInactivity()
 		go c.handleMessage(message)
@@ -116,6 +133,18 @@ func (c *WsClient) WritePump() {
 				return
 			}
 
+			if c.Agent {
+				text, err := agentMessage(message)
+				if err != nil {
+					log.Println("failed to encode agent message", err)
+					continue
+				}
+				if err := c.Conn.WriteMessage(websocket.TextMessage, text); err != nil {
+					return
+				}
+				continue
+			}
+
 			w, err := c.Conn.NextWriter(websocket.BinaryMessage)
 			if err != nil {
 				return
@@ -169,6 +198,16 @@ func RejectWs(w http.ResponseWriter, r *http.Request, status int, data string) {
 }
 
 func StartWs(hub *WsHub, w http.ResponseWriter, r *http.Request) {
+	startClient(hub, w, r, false)
+}
+
+// StartAgent connects a program to play a seat over JSON. The level in
+// the query picks the built-in AI that plays the seat when it stalls.
+func StartAgent(hub *WsHub, w http.ResponseWriter, r *http.Request) {
+	startClient(hub, w, r, true)
+}
+
+func startClient(hub *WsHub, w http.ResponseWriter, r *http.Request, agent bool) {
 	hub.Mutex.Lock()
 	defer hub.Mutex.Unlock()
 
@@ -214,7 +253,8 @@ func StartWs(hub *WsHub, w http.ResponseWriter, r *http.Request) {
 		Disconnect:    make(chan bool),
 		GamesStarted:  gamesStarted,
 		GamesFinished: gamesFinished,
-		ChatEnabled:   true,
+		ChatEnabled:   !agent,
+		Agent:         agent,
 	}
 	player, err := entities.NewPlayer(
 		entities.Base,
@@ -231,12 +271,26 @@ func StartWs(hub *WsHub, w http.ResponseWriter, r *http.Request) {
 
 	client.Player = player
 
+	// Agents name the strategy that plays for them when they stall
+	if agent {
+		player.BotLevel = entities.BotLevelNormal
+		if level := r.URL.Query().Get("level"); entities.IsBotLevel(level) {
+			player.BotLevel = level
+		}
+	}
+
 	if hub.Game.Initialized {
 		gamePlayer, err := hub.Game.ReplacePlayer(player)
 		if err != nil {
 			hub.Game.AddSpectator(client.Player)
 		} else {
 			client.Player = gamePlayer
+			if agent {
+				if hub.Game.Lock() {
+					hub.Game.SetBotLevel(gamePlayer, player.BotLevel)
+				}
+				hub.Game.Unlock()
+			}
 		}
 		client.Player.ResetInactivity()
 	}
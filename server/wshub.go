@@ -4,7 +4,6 @@ import (
 	"log"
 	"sakura/entities"
 	"sakura/game"
-	"sakura/mango"
 	"sakura/maps"
 	"sync"
 	"sync/atomic"
@@ -66,7 +65,7 @@ func (s *Server) NewWsHub(id string) *WsHub {
 		Game: game.Game{
 			ID:          id,
 			Initialized: false,
-			Store:       &mango.MangoStore{},
+			Store:       s.store,
 			Settings: entities.GameSettings{
 				Mode:          entities.Base,
 				MapName:       "Base",
@@ -79,7 +78,12 @@ func (s *Server) NewWsHub(id string) *WsHub {
 				Advanced:      false,
 			},
 			AdvancedSettings: entities.AdvancedSettings{
-				RerollOn7: false,
+				RerollOn7:             false,
+				EventCards:            false,
+				FriendlyRobber:        false,
+				RobberFromRound:       0,
+				RerollEarly7:          false,
+				RobberReturnsToDesert: false,
 			},
 		},
 		Server: s,
@@ -142,6 +146,7 @@ func (h *WsHub) Tick(tickerPeriod int) bool {
 				}
 			}
 		}
+		changedToBot = h.checkAgents(tickerPeriod) || changedToBot
 	}
 
 	if atomic.LoadInt32(&h.activity) == 0 {
@@ -42,6 +42,12 @@ const turnTimerOptions = [
     { value: "240s", label: "240s" },
     { value: "200m", label: "200m" },
 ];
+const botLevelOptions = [
+    { value: "easy", label: "Easy" },
+    { value: "normal", label: "Normal" },
+    { value: "hard", label: "Hard" },
+    { value: "expert", label: "Expert" },
+];
 
 function normalizeTimerSpeed(speed: string) {
     const normalized = (speed || "").trim().toLowerCase();
@@ -68,7 +74,18 @@ function getDefaultVictoryPointsForSettings(
     mode: number,
     mapName: string,
     fallback: number,
-) {
+): number {
+    // Combined games need two more points than the Seafarers scenario
+    if (mode === GAME_MODE.SeafarersCitiesAndKnights) {
+        return (
+            getDefaultVictoryPointsForSettings(
+                GAME_MODE.Seafarers,
+                mapName,
+                fallback,
+            ) + 2
+        );
+    }
+
     if (mode === GAME_MODE.Seafarers) {
         switch (mapName) {
             case "Seafarers - Heading for New Shores":
@@ -128,6 +145,7 @@ const Game: FunctionComponent<{ gameId: string }> = ({ gameId }) => {
     const mapOptions = lobbyState.settingsOptions?.MapName || [];
     const [showTimerInfo, setShowTimerInfo] = useState(false);
     const [showGameOptions, setShowGameOptions] = useState(false);
+    const [botLevel, setBotLevel] = useState("normal");
 
     const changeMode: ChangeEventHandler<HTMLSelectElement> = (event) => {
         const mode = Number(event.target.value);
@@ -161,6 +179,7 @@ const Game: FunctionComponent<{ gameId: string }> = ({ gameId }) => {
     const maxDiscardLimit = 15;
     const minVictoryPoints = 5;
     const maxVictoryPoints = 21;
+    const maxRobberFromRound = 10;
 
     const setMaxPlayers = (maxPlayers: number) => {
         sendSettings({
@@ -184,6 +203,15 @@ const Game: FunctionComponent<{ gameId: string }> = ({ gameId }) => {
         });
     };
 
+    const changeRobberFromRound: ChangeEventHandler<HTMLInputElement> = (
+        event,
+    ) => {
+        sendAdvancedSettings({
+            ...lobbyState.advanced,
+            RobberFromRound: Number(event.target.value),
+        });
+    };
+
     const changeSpeed = (nextSpeed: string) => {
         sendSettings({
             ...lobbyState.settings,
@@ -207,7 +235,7 @@ const Game: FunctionComponent<{ gameId: string }> = ({ gameId }) => {
     };
 
     const botAdd = () => {
-        commands?.addBot();
+        commands?.addBot(botLevel);
     };
 
     const changeReady: ChangeEventHandler<HTMLInputElement> = (event) => {
@@ -370,6 +398,13 @@ const Game: FunctionComponent<{ gameId: string }> = ({ gameId }) => {
                                         >
                                             Cities and Knights
                                         </option>
+                                        <option
+                                            value={
+                                                GAME_MODE.SeafarersCitiesAndKnights
+                                            }
+                                        >
+                                            Seafarers + Cities and Knights
+                                        </option>
                                     </select>
                                 </div>
                                 <div className={settingCardClasses}>
@@ -682,6 +717,40 @@ const Game: FunctionComponent<{ gameId: string }> = ({ gameId }) => {
                                             "Re-roll on 7",
                                             "RerollOn7",
                                         )}
+                                        {getAdvancedCheckBox(
+                                            "Event cards instead of dice",
+                                            "EventCards",
+                                        )}
+                                        {getAdvancedCheckBox(
+                                            "Friendly robber",
+                                            "FriendlyRobber",
+                                        )}
+                                        {getAdvancedCheckBox(
+                                            "Re-roll early 7s",
+                                            "RerollEarly7",
+                                        )}
+                                        {getAdvancedCheckBox(
+                                            "Robber returns to desert",
+                                            "RobberReturnsToDesert",
+                                        )}
+                                        <div className={settingCardClasses}>
+                                            <label className={labelClasses} htmlFor="robberFromRound">
+                                                No robber until round (
+                                                {lobbyState.advanced.RobberFromRound || "off"})
+                                            </label>
+                                            <input
+                                                className={rangeInputClasses}
+                                                aria-label="No robber until round"
+                                                id="robberFromRound"
+                                                type="range"
+                                                min={0}
+                                                max={maxRobberFromRound}
+                                                step={1}
+                                                onChange={changeRobberFromRound}
+                                                disabled={lobbyState.order !== 0}
+                                                value={lobbyState.advanced.RobberFromRound || 0}
+                                            />
+                                        </div>
                                     </div>
                                 </>
                             )}
@@ -740,11 +809,26 @@ const Game: FunctionComponent<{ gameId: string }> = ({ gameId }) => {
                         </div>
                         <PlayerList lobbyState={lobbyState} socket={socketRef} />
 
-                        <div className="basis-auto mt-2">
+                        <div className="basis-auto mt-2 flex justify-center gap-2">
+                            <select
+                                className={classNames(selectClasses, "!w-1/3")}
+                                aria-label="Bot Level"
+                                disabled={lobbyState.order != 0}
+                                onChange={(event) =>
+                                    setBotLevel(event.target.value)
+                                }
+                                value={botLevel}
+                            >
+                                {botLevelOptions.map((opt) => (
+                                    <option key={opt.value} value={opt.value}>
+                                        {opt.label}
+                                    </option>
+                                ))}
+                            </select>
                             <button
                                 disabled={lobbyState.order != 0}
                                 className={classNames(
-                                    "ui-button h-11 w-2/3 text-lg rounded-xl",
+                                    "ui-button h-11 w-1/2 text-lg rounded-xl",
                                     lobbyState.order == 0
                                         ? "ui-button-secondary"
                                         : "bg-stone-700 opacity-40",
@@ -93,6 +93,7 @@ const PlayerList: FunctionComponent<{
                             <p>{player.Username}</p>
                             <p className="font-normal text-xs text-[color:var(--ui-ivory-soft)]">
                                 {player.GamesFinished}/{player.GamesStarted}
+                                {player.BotLevel && ` · ${player.BotLevel}`}
                             </p>
                         </span>
 
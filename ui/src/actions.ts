@@ -22,6 +22,7 @@ export enum PlayerActionType {
     ChooseBuildable = "cb",
     ChooseDice = "cd",
     ChooseImprovement = "ci",
+    ChooseWarship = "cw",
 }
 
 let chooseDiceWindow: PIXI.Container | undefined;
@@ -68,6 +69,7 @@ export function handle(action: tsg.PlayerAction) {
             break;
 
         case PlayerActionType.ChooseEdge:
+        case PlayerActionType.ChooseWarship:
             chooseEdge(new tsg.PlayerActionChooseEdge(action.Data), action);
             break;
 
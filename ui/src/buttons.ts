@@ -332,6 +332,38 @@ function rerender() {
     // Originally: invalidate bitmap cache
 }
 
+/**
+ * Cards shown on the tooltip of a price
+ * @param cost Number of cards of each type
+ * @param fallback Cards of the rulebook price
+ */
+function getCostCards(cost: number[] | undefined, fallback: number[]) {
+    if (!cost) {
+        return fallback;
+    }
+    const cards: number[] = [];
+    cost.forEach((q, ct) => {
+        for (let i = 0; i < q; i++) {
+            cards.push(ct);
+        }
+    });
+    return cards;
+}
+
+/**
+ * Cards paid for a piece in this game
+ * @param piece Name of the piece in the build rules
+ * @param fallback Cards of the rulebook price
+ */
+function getBuildCostCards(piece: string, fallback: number[]) {
+    return getCostCards(state.settings?.BuildRules?.Costs?.[piece], fallback);
+}
+
+/** Cards paid for an action card in this game */
+function getDevelopmentCardCostCards() {
+    return getCostCards(state.settings?.BuildRules?.DevelopmentCard, [3, 4, 5]);
+}
+
 function isPlayerPieceButton(type: ButtonType) {
     return (
         type === ButtonType.Settlement ||
@@ -725,7 +757,7 @@ export function render(commandHub: CommandHub) {
         buttons.buildSettlement.tooltip = new windows.TooltipHandler(
             buttons.buildSettlement,
             "Build a village",
-        ).setCards([1, 2, 3, 4]);
+        ).setCards(getBuildCostCards("settlement", [1, 2, 3, 4]));
     }
 
     // Build city
@@ -761,7 +793,7 @@ export function render(commandHub: CommandHub) {
         buttons.buildCity.tooltip = new windows.TooltipHandler(
             buttons.buildCity,
             "Build a town",
-        ).setCards([4, 4, 5, 5, 5]);
+        ).setCards(getBuildCostCards("city", [4, 4, 5, 5, 5]));
     }
 
     // Build road
@@ -797,7 +829,7 @@ export function render(commandHub: CommandHub) {
         buttons.buildRoad.tooltip = new windows.TooltipHandler(
             buttons.buildRoad,
             "Build a road",
-        ).setCards([1, 2]);
+        ).setCards(getBuildCostCards("road", [1, 2]));
     }
 
     // Buy Development Card
@@ -822,7 +854,7 @@ export function render(commandHub: CommandHub) {
         buttons.buyDevelopmentCard.tooltip = new windows.TooltipHandler(
             buttons.buyDevelopmentCard,
             "Buy an action card",
-        ).setCards([3, 4, 5]);
+        ).setCards(getDevelopmentCardCostCards());
     }
 
     if (state.settings.Mode == state.GameMode.Seafarers) {
@@ -846,10 +878,10 @@ export function render(commandHub: CommandHub) {
         buttons.buyDevelopmentCard.tooltip = new windows.TooltipHandler(
             buttons.buyDevelopmentCard,
             "Buy an action card",
-        ).setCards([3, 4, 5]);
+        ).setCards(getDevelopmentCardCostCards());
     }
 
-    if (state.settings.Mode == state.GameMode.Seafarers) {
+    if (state.hasSeafarers(state.settings.Mode)) {
         seafarersShipContainer = new PIXI.Container();
         seafarersShipContainer.addChild(
             createDockBackground(
@@ -896,7 +928,7 @@ export function render(commandHub: CommandHub) {
         buttons.buildShip.tooltip = new windows.TooltipHandler(
             buttons.buildShip,
             "Build a ship",
-        ).setCards([1, 3]);
+        ).setCards(getBuildCostCards("ship", [1, 3]));
 
         buttons.moveShip = getButtonSprite(
             ButtonType.MoveShip,
@@ -922,7 +954,7 @@ export function render(commandHub: CommandHub) {
     }
 
     // Build wall
-    if (state.settings.Mode == state.GameMode.CitiesAndKnights) {
+    if (state.hasCitiesAndKnights(state.settings.Mode)) {
         buttons.buildWall = getButtonSprite(
             ButtonType.Wall,
             getButtonWidth(),
@@ -954,11 +986,11 @@ export function render(commandHub: CommandHub) {
         buttons.buildWall.tooltip = new windows.TooltipHandler(
             buttons.buildWall,
             "Buy a town fence",
-        ).setCards([2, 2]);
+        ).setCards(getBuildCostCards("wall", [2, 2]));
     }
 
     // Second container
-    if (state.settings.Mode == state.GameMode.CitiesAndKnights) {
+    if (state.hasCitiesAndKnights(state.settings.Mode)) {
         container1 = new PIXI.Container();
         container1.addChild(
             createDockBackground(
@@ -977,7 +1009,7 @@ export function render(commandHub: CommandHub) {
     }
 
     // Knight Box
-    if (state.settings.Mode == state.GameMode.CitiesAndKnights) {
+    if (state.hasCitiesAndKnights(state.settings.Mode)) {
         buttons.openKnightBox = getButtonSprite(
             ButtonType.KnightBox,
             getButtonWidth(),
@@ -1044,7 +1076,7 @@ export function render(commandHub: CommandHub) {
             b.tooltip = new windows.TooltipHandler(
                 b,
                 "Build or upgrade a warrior",
-            ).setCards([3, 5]);
+            ).setCards(getBuildCostCards("knight1", [3, 5]));
         }
 
         {
@@ -1070,7 +1102,7 @@ export function render(commandHub: CommandHub) {
             b.tooltip = new windows.TooltipHandler(
                 b,
                 "Activate a warrior",
-            ).setCards([4]);
+            ).setCards(getBuildCostCards("activate_knight", [4]));
         }
 
         {
@@ -1125,7 +1157,7 @@ export function render(commandHub: CommandHub) {
     }
 
     // City improvement
-    if (state.settings.Mode == state.GameMode.CitiesAndKnights) {
+    if (state.hasCitiesAndKnights(state.settings.Mode)) {
         const b = getButtonSprite(
             ButtonType.CityImprove,
             getButtonWidth(),
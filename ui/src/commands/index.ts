@@ -24,10 +24,11 @@ export function createLobbyCommands(transport: TransportClient) {
                 advanced,
             });
         },
-        addBot: () => {
+        addBot: (level: string) => {
             transport.send({
                 l: MSG_LOCATION_TYPE.LOBBY,
                 t: MSG_TYPE.BOT_ADD,
+                level,
             });
         },
         setReady: (ready: boolean) => {
@@ -192,11 +192,28 @@ function summarizeGain(moves: tsg.CardMoveInfo[]) {
     });
 }
 
+// Events of the event card deck, by entities.EventCardEvent
+const EVENT_CARD_NAMES: Record<number, string> = {
+    1: "Plentiful Year",
+    2: "Tournament",
+    3: "Trade Advantage",
+    4: "Earthquake",
+    5: "Epidemic",
+    6: "Good Neighbors",
+    7: "Calm Sea",
+    8: "Conflict",
+    9: "Neighborly Assistance",
+};
+
 export function logDiceRoll(d: tsg.DieRollState) {
     if (d.IsInit) {
         return;
     }
 
+    if (d.NewYear) {
+        pushEntry("New Year! The event cards were shuffled");
+    }
+
     const roller =
         state.lastKnownGameState?.CurrentPlayerOrder !== undefined
             ? getPlayerName(state.lastKnownGameState.CurrentPlayerOrder)
@@ -206,6 +223,10 @@ export function logDiceRoll(d: tsg.DieRollState) {
         `${roller} rolled ${d.RedRoll + d.WhiteRoll} (${d.RedRoll}+${d.WhiteRoll})`,
     );
 
+    if (d.Event && EVENT_CARD_NAMES[d.Event]) {
+        pushEntry(`Event: ${EVENT_CARD_NAMES[d.Event]}`);
+    }
+
     if (d.GainInfo?.length) {
         summarizeGain(d.GainInfo);
     }
@@ -6,12 +6,14 @@ export enum GAME_MODE {
     Base = 1,
     CitiesAndKnights = 2,
     Seafarers = 3,
+    SeafarersCitiesAndKnights = 4,
 }
 
 export const DISPLAY_GAME_MODE = {
     [GAME_MODE.Base]: "Basic",
     [GAME_MODE.CitiesAndKnights]: "Cities and Knights",
     [GAME_MODE.Seafarers]: "Seafarers",
+    [GAME_MODE.SeafarersCitiesAndKnights]: "Seafarers + Cities and Knights",
 };
 
 function canHostStart(players: LobbyPlayerState[]) {
@@ -134,6 +136,11 @@ export const getInitialLobbyState = (): LobbyState => ({
     },
     advanced: {
         RerollOn7: false,
+        EventCards: false,
+        FriendlyRobber: false,
+        RobberFromRound: 0,
+        RerollEarly7: false,
+        RobberReturnsToDesert: false,
     },
     ready: false,
     canStart: false,
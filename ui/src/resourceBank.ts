@@ -205,8 +205,9 @@ export function setMode(mode: number) {
         [CardType.Ore]: 19,
     };
 
-    // Base has 25 development cards. Cities and Knights has 3 progress stacks (17/18/18).
-    devRemaining = mode === 2 ? 53 : 25;
+    // Base has 25 development cards. Cities and Knights has 3 progress stacks (17/18/18),
+    // also when played with Seafarers.
+    devRemaining = mode === 2 || mode === 4 ? 53 : 25;
     refreshText();
 }
 
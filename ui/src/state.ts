@@ -260,6 +260,20 @@ export enum GameMode {
     Base = 1,
     CitiesAndKnights = 2,
     Seafarers = 3,
+    SeafarersCitiesAndKnights = 4,
+}
+
+export function hasCitiesAndKnights(mode: GameMode) {
+    return (
+        mode == GameMode.CitiesAndKnights ||
+        mode == GameMode.SeafarersCitiesAndKnights
+    );
+}
+
+export function hasSeafarers(mode: GameMode) {
+    return (
+        mode == GameMode.Seafarers || mode == GameMode.SeafarersCitiesAndKnights
+    );
 }
 
 // Game settings
@@ -758,7 +772,7 @@ export function renderGameState(gs: GameState, commandHub: CommandHub) {
                 "Length of the longest road of this player",
             );
             const knightTooltip =
-                settings.Mode == GameMode.CitiesAndKnights
+                hasCitiesAndKnights(settings.Mode)
                     ? "Active number of Warriors with allegiance to this player"
                     : "Number of played Knight cards (Largest Army progress)";
             spriteset.knights = createIconCounter(
@@ -767,12 +781,12 @@ export function renderGameState(gs: GameState, commandHub: CommandHub) {
                 assets.ICON.KNIGHT,
                 knightTooltip,
             );
-            const showKnightStat = settings.Mode != GameMode.CitiesAndKnights;
+            const showKnightStat = !hasCitiesAndKnights(settings.Mode);
             spriteset.knights.img.visible = showKnightStat;
             spriteset.knights.text.visible = showKnightStat;
 
             // City improvements
-            if (settings.Mode == GameMode.CitiesAndKnights) {
+            if (hasCitiesAndKnights(settings.Mode)) {
                 const p = players[state.Order];
                 p.improvements = {};
                 const improvementsBaseX = rowLayout.roadX - 4;
@@ -867,7 +881,7 @@ export function renderGameState(gs: GameState, commandHub: CommandHub) {
         );
         p.victoryPoint.text.text = `${vp}`;
         p.road.text.text = `${state.LongestRoad}`;
-        if (settings.Mode == GameMode.CitiesAndKnights) {
+        if (hasCitiesAndKnights(settings.Mode)) {
             p.knights.text.text = "";
             p.knights.img.visible = false;
             p.knights.text.visible = false;
@@ -922,7 +936,7 @@ export function renderGameState(gs: GameState, commandHub: CommandHub) {
             p.cards.text.style.fill = 0xf8fafc;
         }
 
-        if (settings.Mode == GameMode.CitiesAndKnights) {
+        if (hasCitiesAndKnights(settings.Mode)) {
             Object.keys(state.Improvements).forEach((k) => {
                 if (p.improvements[Number(k)]) {
                     for (let i = 0; i < state.Improvements[Number(k)]!; i++) {
@@ -949,10 +963,12 @@ export function renderGameState(gs: GameState, commandHub: CommandHub) {
                     const buttonSprite = <buttons.ButtonSprite>(
                         buttons.buttons.improveBox![key]
                     );
+                    const level = state.Improvements[Number(k)] ?? 0;
+                    const cost =
+                        settings.BuildRules?.Improvements?.[level] ??
+                        level + 1;
                     buttonSprite.tooltip!.setCards(
-                        new Array(
-                            (state.Improvements[Number(k)] ?? 0) + 1,
-                        ).fill(Number(k)),
+                        new Array(cost).fill(Number(k)),
                     );
                 }
             });
@@ -8,6 +8,7 @@ export enum GAME_MODE {
     Base = 1,
     CitiesAndKnights = 2,
     Seafarers = 3,
+    SeafarersCitiesAndKnights = 4,
 }
 
 export const getInitialLobbyState = (): LobbyState => ({
@@ -30,6 +31,11 @@ export const getInitialLobbyState = (): LobbyState => ({
     },
     advanced: {
         RerollOn7: false,
+        EventCards: false,
+        FriendlyRobber: false,
+        RobberFromRound: 0,
+        RerollEarly7: false,
+        RobberReturnsToDesert: false,
     },
     ready: false,
     canStart: false,
@@ -518,7 +518,7 @@ export function clearOfferEditor() {
  * Initialize the trade windows
  */
 export function initialize() {
-    const isCK = state.settings.Mode == state.GameMode.CitiesAndKnights;
+    const isCK = state.hasCitiesAndKnights(state.settings.Mode);
     const isCK_1 = isCK ? 1 : 0;
     const tradeEditor = getTradeConfig().editor;
     const cardWidth = tradeEditor.cardWidth;
@@ -1202,7 +1202,7 @@ export function showTradeOffer(offer: tsg.TradeOffer) {
  * Clears the offers and reset everything
  */
 export function closeTradeOffer() {
-    const isCK = state.settings.Mode == state.GameMode.CitiesAndKnights;
+    const isCK = state.hasCitiesAndKnights(state.settings.Mode);
     const isCK_1 = isCK ? 1 : 0;
 
     state.showPendingAction();
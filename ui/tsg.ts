@@ -12,6 +12,7 @@ EnableKarma: boolean;
 CreativeMode: boolean;
 Speed: string;
 Advanced: boolean;
+BuildRules?: BuildRules /* entities.BuildRules */;
 }
 
 export class GameSettings implements IGameSettings { 
@@ -26,6 +27,7 @@ public EnableKarma: boolean;
 public CreativeMode: boolean;
 public Speed: string;
 public Advanced: boolean;
+public BuildRules?: BuildRules /* entities.BuildRules */;
 
 constructor(input: any) {
 this.Mode = input.Mode;
@@ -39,6 +41,7 @@ this.EnableKarma = input.EnableKarma;
 this.CreativeMode = input.CreativeMode;
 this.Speed = input.Speed;
 this.Advanced = input.Advanced;
+this.BuildRules = input.BuildRules ? new BuildRules(input.BuildRules) : input.BuildRules;
 }
 
 public encode() {
@@ -54,25 +57,77 @@ out.EnableKarma = this.EnableKarma;
 out.CreativeMode = this.CreativeMode;
 out.Speed = this.Speed;
 out.Advanced = this.Advanced;
+out.BuildRules = this.BuildRules?.encode?.();
 return out; }
 }
 
 export type GameMode = number;
 export type IGameMode = number;
+export type IBuildRules = {
+Costs: {[key: string]: int[] | undefined};
+DevelopmentCard: int /* entities.BuildCost */[];
+Improvements: int /* []int */[];
+Pieces: {[key: string]: int | undefined};
+}
+
+export class BuildRules implements IBuildRules { 
+public Costs: {[key: string]: int[] | undefined};
+public DevelopmentCard: int /* entities.BuildCost */[];
+public Improvements: int /* []int */[];
+public Pieces: {[key: string]: int | undefined};
+
+constructor(input: any) {
+this.Costs = input.Costs;
+this.DevelopmentCard = input.DevelopmentCard;
+this.Improvements = input.Improvements;
+this.Pieces = input.Pieces;
+}
+
+public encode() {
+const out: any = {};
+out.Costs = this.Costs;
+out.DevelopmentCard = this.DevelopmentCard;
+out.Improvements = this.Improvements;
+out.Pieces = this.Pieces;
+return out; }
+}
+
+export type int = number;
+export type Iint = number;
 export type IAdvancedSettings = {
 RerollOn7: boolean;
+EventCards: boolean;
+FriendlyRobber: boolean;
+RobberFromRound: number;
+RerollEarly7: boolean;
+RobberReturnsToDesert: boolean;
 }
 
 export class AdvancedSettings implements IAdvancedSettings { 
 public RerollOn7: boolean;
+public EventCards: boolean;
+public FriendlyRobber: boolean;
+public RobberFromRound: number;
+public RerollEarly7: boolean;
+public RobberReturnsToDesert: boolean;
 
 constructor(input: any) {
 this.RerollOn7 = input.RerollOn7;
+this.EventCards = input.EventCards;
+this.FriendlyRobber = input.FriendlyRobber;
+this.RobberFromRound = input.RobberFromRound;
+this.RerollEarly7 = input.RerollEarly7;
+this.RobberReturnsToDesert = input.RobberReturnsToDesert;
 }
 
 public encode() {
 const out: any = {};
 out.RerollOn7 = this.RerollOn7;
+out.EventCards = this.EventCards;
+out.FriendlyRobber = this.FriendlyRobber;
+out.RobberFromRound = this.RobberFromRound;
+out.RerollEarly7 = this.RerollEarly7;
+out.RobberReturnsToDesert = this.RobberReturnsToDesert;
 return out; }
 }
 
@@ -97,6 +152,11 @@ BarbarianPosition: number;
 BarbarianStrength: number;
 BarbarianKnights: number;
 Merchant: Merchant /* entities.Merchant */;
+TribeMarkers?: TribeMarker /* []*entities.TribeMarker */[];
+ClothVillages?: ClothVillage /* []*entities.ClothVillage */[];
+Fortresses?: PirateFortress /* []*entities.PirateFortress */[];
+Warships?: EdgeCoordinate /* []entities.EdgeCoordinate */[];
+Wonders?: Wonder /* []*entities.Wonder */[];
 }
 
 export class GameState implements IGameState { 
@@ -120,6 +180,11 @@ public BarbarianPosition: number;
 public BarbarianStrength: number;
 public BarbarianKnights: number;
 public Merchant: Merchant /* entities.Merchant */;
+public TribeMarkers?: TribeMarker /* []*entities.TribeMarker */[];
+public ClothVillages?: ClothVillage /* []*entities.ClothVillage */[];
+public Fortresses?: PirateFortress /* []*entities.PirateFortress */[];
+public Warships?: EdgeCoordinate /* []entities.EdgeCoordinate */[];
+public Wonders?: Wonder /* []*entities.Wonder */[];
 
 constructor(input: any) {
 this.CurrentPlayerOrder = input.c;
@@ -142,6 +207,11 @@ this.BarbarianPosition = input.bp;
 this.BarbarianStrength = input.bs;
 this.BarbarianKnights = input.bk;
 this.Merchant = input.tm ? new Merchant(input.tm) : input.tm;
+this.TribeMarkers = input.fm?.map((v: any) => v ? new TribeMarker(v) : undefined);
+this.ClothVillages = input.cv?.map((v: any) => v ? new ClothVillage(v) : undefined);
+this.Fortresses = input.pf?.map((v: any) => v ? new PirateFortress(v) : undefined);
+this.Warships = input.ws?.map((v: any) => v ? new EdgeCoordinate(v) : undefined);
+this.Wonders = input.wd?.map((v: any) => v ? new Wonder(v) : undefined);
 }
 
 public encode() {
@@ -166,6 +236,11 @@ out.bp = this.BarbarianPosition;
 out.bs = this.BarbarianStrength;
 out.bk = this.BarbarianKnights;
 out.tm = this.Merchant?.encode?.();
+out.fm = this.TribeMarkers?.map((v: any) => v?.encode?.());
+out.cv = this.ClothVillages?.map((v: any) => v?.encode?.());
+out.pf = this.Fortresses?.map((v: any) => v?.encode?.());
+out.ws = this.Warships?.map((v: any) => v?.encode?.());
+out.wd = this.Wonders?.map((v: any) => v?.encode?.());
 return out; }
 }
 
@@ -272,9 +347,13 @@ TimeLeft: number;
 Improvements: {[key: int]: int | undefined};
 DiscardLimit: number;
 IsBot?: boolean;
+BotLevel?: string;
 HasLongestRoad?: boolean;
 HasLargestArmy?: boolean;
 DevCardVp?: number;
+Cloth?: number;
+Wonder?: WonderType /* entities.WonderType */;
+WonderStage?: number;
 }
 
 export class PlayerState implements IPlayerState { 
@@ -294,9 +373,13 @@ public TimeLeft: number;
 public Improvements: {[key: int]: int | undefined};
 public DiscardLimit: number;
 public IsBot?: boolean;
+public BotLevel?: string;
 public HasLongestRoad?: boolean;
 public HasLargestArmy?: boolean;
 public DevCardVp?: number;
+public Cloth?: number;
+public Wonder?: WonderType /* entities.WonderType */;
+public WonderStage?: number;
 
 constructor(input: any) {
 this.Id = input.id;
@@ -315,9 +398,13 @@ this.TimeLeft = input.i;
 this.Improvements = input.ci;
 this.DiscardLimit = input.l;
 this.IsBot = input.b;
+this.BotLevel = input.bl;
 this.HasLongestRoad = input.lr;
 this.HasLargestArmy = input.la;
 this.DevCardVp = input.dv;
+this.Cloth = input.cl;
+this.Wonder = input.wo;
+this.WonderStage = input.wst;
 }
 
 public encode() {
@@ -338,14 +425,18 @@ out.i = this.TimeLeft;
 out.ci = this.Improvements;
 out.l = this.DiscardLimit;
 out.b = this.IsBot;
+out.bl = this.BotLevel;
 out.lr = this.HasLongestRoad;
 out.la = this.HasLargestArmy;
 out.dv = this.DevCardVp;
+out.cl = this.Cloth;
+out.wo = this.Wonder;
+out.wst = this.WonderStage;
 return out; }
 }
 
-export type int = number;
-export type Iint = number;
+export type WonderType = number;
+export type IWonderType = number;
 export type IMerchant = {
 Tile: Tile /* entities.Tile */;
 Owner: Player /* entities.Player */;
@@ -392,20 +483,28 @@ out.o = this.Order;
 return out; }
 }
 
-export type IVertex = {
-C: Coordinate /* entities.Coordinate */;
+export type ITribeMarker = {
+Edge: Edge /* entities.Edge */;
+Type: TribeMarkerType /* entities.TribeMarkerType */;
+Port?: PortType /* entities.PortType */;
 }
 
-export class Vertex implements IVertex { 
-public C: Coordinate /* entities.Coordinate */;
+export class TribeMarker implements ITribeMarker { 
+public Edge: Edge /* entities.Edge */;
+public Type: TribeMarkerType /* entities.TribeMarkerType */;
+public Port?: PortType /* entities.PortType */;
 
 constructor(input: any) {
-this.C = input.c ? new Coordinate(input.c) : input.c;
+this.Edge = input.e ? new Edge(input.e) : input.e;
+this.Type = input.t;
+this.Port = input.p;
 }
 
 public encode() {
 const out: any = {};
-out.c = this.C?.encode?.();
+out.e = this.Edge?.encode?.();
+out.t = this.Type;
+out.p = this.Port;
 return out; }
 }
 
@@ -455,6 +554,102 @@ out.c2 = this.C2?.encode?.();
 return out; }
 }
 
+export type TribeMarkerType = number;
+export type ITribeMarkerType = number;
+export type PortType = number;
+export type IPortType = number;
+export type IClothVillage = {
+Tile: Tile /* entities.Tile */;
+Number: number;
+Cloth: number;
+}
+
+export class ClothVillage implements IClothVillage { 
+public Tile: Tile /* entities.Tile */;
+public Number: number;
+public Cloth: number;
+
+constructor(input: any) {
+this.Tile = input.t ? new Tile(input.t) : input.t;
+this.Number = input.n;
+this.Cloth = input.c;
+}
+
+public encode() {
+const out: any = {};
+out.t = this.Tile?.encode?.();
+out.n = this.Number;
+out.c = this.Cloth;
+return out; }
+}
+
+export type IPirateFortress = {
+Vertex: Vertex /* entities.Vertex */;
+Owner: number;
+Strength: number;
+}
+
+export class PirateFortress implements IPirateFortress { 
+public Vertex: Vertex /* entities.Vertex */;
+public Owner: number;
+public Strength: number;
+
+constructor(input: any) {
+this.Vertex = input.v ? new Vertex(input.v) : input.v;
+this.Owner = input.o;
+this.Strength = input.s;
+}
+
+public encode() {
+const out: any = {};
+out.v = this.Vertex?.encode?.();
+out.o = this.Owner;
+out.s = this.Strength;
+return out; }
+}
+
+export type IVertex = {
+C: Coordinate /* entities.Coordinate */;
+}
+
+export class Vertex implements IVertex { 
+public C: Coordinate /* entities.Coordinate */;
+
+constructor(input: any) {
+this.C = input.c ? new Coordinate(input.c) : input.c;
+}
+
+public encode() {
+const out: any = {};
+out.c = this.C?.encode?.();
+return out; }
+}
+
+export type IWonder = {
+Type: WonderType /* entities.WonderType */;
+Owner: number;
+Stage: number;
+}
+
+export class Wonder implements IWonder { 
+public Type: WonderType /* entities.WonderType */;
+public Owner: number;
+public Stage: number;
+
+constructor(input: any) {
+this.Type = input.t;
+this.Owner = input.o;
+this.Stage = input.s;
+}
+
+public encode() {
+const out: any = {};
+out.t = this.Type;
+out.o = this.Owner;
+out.s = this.Stage;
+return out; }
+}
+
 export type IVertexPlacement = {
 Owner: Player /* entities.Player */;
 Location: Vertex /* entities.Vertex */;
@@ -550,8 +745,6 @@ out.r = this.Ratio;
 return out; }
 }
 
-export type PortType = number;
-export type IPortType = number;
 export type IPlayerSecretState = {
 Cards: {[key: CardType]: int | undefined};
 DevelopmentCards: int /* []int */[];
@@ -607,6 +800,9 @@ ImprovePaper?: boolean;
 ImproveCloth?: boolean;
 ImproveCoin?: boolean;
 SpecialBuild?: boolean;
+AttackFortress?: boolean;
+ClaimWonder?: boolean;
+BuildWonder?: boolean;
 }
 
 export class AllowedActionsMap implements IAllowedActionsMap { 
@@ -627,6 +823,9 @@ public ImprovePaper?: boolean;
 public ImproveCloth?: boolean;
 public ImproveCoin?: boolean;
 public SpecialBuild?: boolean;
+public AttackFortress?: boolean;
+public ClaimWonder?: boolean;
+public BuildWonder?: boolean;
 
 constructor(input: any) {
 this.BuildSettlement = input.s;
@@ -646,6 +845,9 @@ this.ImprovePaper = input.ip;
 this.ImproveCloth = input.il;
 this.ImproveCoin = input.ic;
 this.SpecialBuild = input.sb;
+this.AttackFortress = input.af;
+this.ClaimWonder = input.wc;
+this.BuildWonder = input.wb;
 }
 
 public encode() {
@@ -667,6 +869,9 @@ out.ip = this.ImprovePaper;
 out.il = this.ImproveCloth;
 out.ic = this.ImproveCoin;
 out.sb = this.SpecialBuild;
+out.af = this.AttackFortress;
+out.wc = this.ClaimWonder;
+out.wb = this.BuildWonder;
 return out; }
 }
 
@@ -676,6 +881,8 @@ WhiteRoll: number;
 EventRoll: number;
 GainInfo: CardMoveInfo /* []entities.CardMoveInfo */[];
 IsInit?: boolean;
+Event?: EventCardEvent /* entities.EventCardEvent */;
+NewYear?: boolean;
 }
 
 export class DieRollState implements IDieRollState { 
@@ -684,6 +891,8 @@ public WhiteRoll: number;
 public EventRoll: number;
 public GainInfo: CardMoveInfo /* []entities.CardMoveInfo */[];
 public IsInit?: boolean;
+public Event?: EventCardEvent /* entities.EventCardEvent */;
+public NewYear?: boolean;
 
 constructor(input: any) {
 this.RedRoll = input.r;
@@ -691,6 +900,8 @@ this.WhiteRoll = input.w;
 this.EventRoll = input.e;
 this.GainInfo = input.g?.map((v: any) => v ? new CardMoveInfo(v) : undefined);
 this.IsInit = input.ii;
+this.Event = input.ev;
+this.NewYear = input.ny;
 }
 
 public encode() {
@@ -700,6 +911,8 @@ out.w = this.WhiteRoll;
 out.e = this.EventRoll;
 out.g = this.GainInfo?.map((v: any) => v?.encode?.());
 out.ii = this.IsInit;
+out.ev = this.Event;
+out.ny = this.NewYear;
 return out; }
 }
 
@@ -736,6 +949,8 @@ out.q = this.Quantity;
 return out; }
 }
 
+export type EventCardEvent = number;
+export type IEventCardEvent = number;
 export type ICardDeck = {
 Type: CardType /* entities.CardType */;
 Quantity: number;
@@ -923,14 +1138,14 @@ return out; }
 
 export type IPlayerActionChooseEdge = {
 Allowed: Edge /* []*entities.Edge */[];
-AllowRoad: Edge /* []*entities.Edge */[];
-AllowShip: Edge /* []*entities.Edge */[];
+AllowRoad?: Edge /* []*entities.Edge */[];
+AllowShip?: Edge /* []*entities.Edge */[];
 }
 
 export class PlayerActionChooseEdge implements IPlayerActionChooseEdge { 
 public Allowed: Edge /* []*entities.Edge */[];
-public AllowRoad: Edge /* []*entities.Edge */[];
-public AllowShip: Edge /* []*entities.Edge */[];
+public AllowRoad?: Edge /* []*entities.Edge */[];
+public AllowShip?: Edge /* []*entities.Edge */[];
 
 constructor(input: any) {
 this.Allowed = input.e?.map((v: any) => v ? new Edge(v) : undefined);
@@ -1043,6 +1258,7 @@ Order: number;
 Ready: boolean;
 GamesStarted: number;
 GamesFinished: number;
+BotLevel?: string;
 }
 
 export class LobbyPlayerState implements ILobbyPlayerState { 
@@ -1052,6 +1268,7 @@ public Order: number;
 public Ready: boolean;
 public GamesStarted: number;
 public GamesFinished: number;
+public BotLevel?: string;
 
 constructor(input: any) {
 this.Username = input.u;
@@ -1060,6 +1277,7 @@ this.Order = input.o;
 this.Ready = input.r;
 this.GamesStarted = input.s;
 this.GamesFinished = input.f;
+this.BotLevel = input.bl;
 }
 
 public encode() {
@@ -1070,6 +1288,7 @@ out.o = this.Order;
 out.r = this.Ready;
 out.s = this.GamesStarted;
 out.f = this.GamesFinished;
+out.bl = this.BotLevel;
 return out; }
 }
 
@@ -1082,6 +1301,9 @@ PlayerStates: PlayerState /* []*entities.PlayerState */[];
 PlayerSecretStates: PlayerSecretState /* []*entities.PlayerSecretState */[];
 GameOver: boolean;
 Winner: number;
+Seed: number;
+ParentID?: string;
+ParentIndex?: number;
 }
 
 export class StoreGameState implements IStoreGameState { 
@@ -1093,6 +1315,9 @@ public PlayerStates: PlayerState /* []*entities.PlayerState */[];
 public PlayerSecretStates: PlayerSecretState /* []*entities.PlayerSecretState */[];
 public GameOver: boolean;
 public Winner: number;
+public Seed: number;
+public ParentID?: string;
+public ParentIndex?: number;
 
 constructor(input: any) {
 this.ID = input.id;
@@ -1103,6 +1328,9 @@ this.PlayerStates = input.ps?.map((v: any) => v ? new PlayerState(v) : undefined
 this.PlayerSecretStates = input.pss?.map((v: any) => v ? new PlayerSecretState(v) : undefined);
 this.GameOver = input.g;
 this.Winner = input.w;
+this.Seed = input.sd;
+this.ParentID = input.pa;
+this.ParentIndex = input.pi;
 }
 
 public encode() {
@@ -1115,5 +1343,9 @@ out.ps = this.PlayerStates?.map((v: any) => v?.encode?.());
 out.pss = this.PlayerSecretStates?.map((v: any) => v?.encode?.());
 out.g = this.GameOver;
 out.w = this.Winner;
+out.sd = this.Seed;
+out.pa = this.ParentID;
+out.pi = this.ParentIndex;
 return out; }
 }
+